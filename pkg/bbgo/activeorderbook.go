@@ -15,6 +15,8 @@ import (
 
 const DefaultCancelOrderWaitTime = 20 * time.Millisecond
 
+var ErrExceededMaxOpenOrders = errors.New("exceeded the max number of open orders")
+
 // ActiveOrderBook manages the local active order books.
 //
 //go:generate callbackgen -type ActiveOrderBook
@@ -26,6 +28,8 @@ type ActiveOrderBook struct {
 	filledCallbacks   []func(o types.Order)
 	canceledCallbacks []func(o types.Order)
 
+	maxOpenOrdersExceededCallbacks []func(symbol string, numOfOrders int)
+
 	pendingOrderUpdates *types.SyncOrderMap
 
 	// sig is the order update signal
@@ -35,6 +39,11 @@ type ActiveOrderBook struct {
 	mu sync.Mutex
 
 	cancelOrderWaitTime time.Duration
+
+	// maxOpenOrders is the max number of the tracked open orders per symbol, zero means no limit.
+	maxOpenOrders int
+	// blockExceededOrders makes CheckMaxOpenOrders reject the orders exceeding maxOpenOrders.
+	blockExceededOrders bool
 }
 
 func NewActiveOrderBook(symbol string) *ActiveOrderBook {
@@ -51,6 +60,46 @@ func (b *ActiveOrderBook) SetCancelOrderWaitTime(duration time.Duration) {
 	b.cancelOrderWaitTime = duration
 }
 
+// SetMaxOpenOrders sets the max number of the tracked open orders per symbol. Exceeding it logs a warning and emits
+// the MaxOpenOrdersExceeded event, and if block is true, CheckMaxOpenOrders rejects the new orders.
+func (b *ActiveOrderBook) SetMaxOpenOrders(maxOpenOrders int, block bool) {
+	b.maxOpenOrders = maxOpenOrders
+	b.blockExceededOrders = block
+}
+
+// CheckMaxOpenOrders returns ErrExceededMaxOpenOrders if the blocking is enabled and the submit orders will exceed
+// the max number of open orders.
+func (b *ActiveOrderBook) CheckMaxOpenOrders(submitOrders ...types.SubmitOrder) error {
+	if b.maxOpenOrders <= 0 || !b.blockExceededOrders {
+		return nil
+	}
+
+	numOfNewOrders := map[string]int{}
+	for _, o := range submitOrders {
+		numOfNewOrders[o.Symbol]++
+	}
+
+	for symbol, n := range numOfNewOrders {
+		if numOfOrders := b.NumOfOrdersBySymbol(symbol); numOfOrders+n > b.maxOpenOrders {
+			return errors.Wrapf(ErrExceededMaxOpenOrders, "symbol: %s, open orders: %d, new orders: %d, max: %d",
+				symbol, numOfOrders, n, b.maxOpenOrders)
+		}
+	}
+
+	return nil
+}
+
+func (b *ActiveOrderBook) checkMaxOpenOrders(symbol string) {
+	if b.maxOpenOrders <= 0 {
+		return
+	}
+
+	if numOfOrders := b.NumOfOrdersBySymbol(symbol); numOfOrders > b.maxOpenOrders {
+		log.Warnf("[ActiveOrderBook] %s open orders %d exceeded the max number %d", symbol, numOfOrders, b.maxOpenOrders)
+		b.EmitMaxOpenOrdersExceeded(symbol, numOfOrders)
+	}
+}
+
 func (b *ActiveOrderBook) MarshalJSON() ([]byte, error) {
 	orders := b.Backup()
 	return json.Marshal(orders)
@@ -434,6 +483,8 @@ func (b *ActiveOrderBook) add(order types.Order) {
 	} else {
 		b.orders.Add(order)
 	}
+
+	b.checkMaxOpenOrders(order.Symbol)
 }
 
 func (b *ActiveOrderBook) Exists(order types.Order) bool {
@@ -456,6 +507,15 @@ func (b *ActiveOrderBook) NumOfOrders() int {
 	return b.orders.Len()
 }
 
+func (b *ActiveOrderBook) NumOfOrdersBySymbol(symbol string) (n int) {
+	for _, o := range b.orders.Orders() {
+		if o.Symbol == symbol {
+			n++
+		}
+	}
+	return n
+}
+
 func (b *ActiveOrderBook) Orders() types.OrderSlice {
 	return b.orders.Orders()
 }
@@ -35,3 +35,13 @@ func (b *ActiveOrderBook) EmitCanceled(o types.Order) {
 		cb(o)
 	}
 }
+
+func (b *ActiveOrderBook) OnMaxOpenOrdersExceeded(cb func(symbol string, numOfOrders int)) {
+	b.maxOpenOrdersExceededCallbacks = append(b.maxOpenOrdersExceededCallbacks, cb)
+}
+
+func (b *ActiveOrderBook) EmitMaxOpenOrdersExceeded(symbol string, numOfOrders int) {
+	for _, cb := range b.maxOpenOrdersExceededCallbacks {
+		cb(symbol, numOfOrders)
+	}
+}
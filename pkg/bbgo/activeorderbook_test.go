@@ -152,3 +152,60 @@ func Test_isNewerUpdateTime(t *testing.T) {
 	ret := isNewerOrderUpdateTime(a, b)
 	assert.True(t, ret)
 }
+
+func TestActiveOrderBook_maxOpenOrders(t *testing.T) {
+	newOrder := func(orderID uint64) types.Order {
+		return types.Order{
+			OrderID: orderID,
+			SubmitOrder: types.SubmitOrder{
+				Symbol:   "BTCUSDT",
+				Side:     types.SideTypeBuy,
+				Type:     types.OrderTypeLimit,
+				Quantity: Number("0.01"),
+				Price:    Number(19000.0),
+			},
+			Status: types.OrderStatusNew,
+		}
+	}
+
+	submitOrder := types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: Number("0.01"),
+		Price:    Number(19000.0),
+	}
+
+	t.Run("warn only", func(t *testing.T) {
+		ob := NewActiveOrderBook("BTCUSDT")
+		ob.SetMaxOpenOrders(2, false)
+
+		var exceeded []int
+		ob.OnMaxOpenOrdersExceeded(func(symbol string, numOfOrders int) {
+			assert.Equal(t, "BTCUSDT", symbol)
+			exceeded = append(exceeded, numOfOrders)
+		})
+
+		ob.Add(newOrder(1), newOrder(2))
+		assert.Empty(t, exceeded)
+
+		ob.Add(newOrder(3))
+		assert.Equal(t, []int{3}, exceeded)
+		assert.NoError(t, ob.CheckMaxOpenOrders(submitOrder))
+	})
+
+	t.Run("block", func(t *testing.T) {
+		ob := NewActiveOrderBook("BTCUSDT")
+		ob.SetMaxOpenOrders(2, true)
+
+		ob.Add(newOrder(1))
+		assert.NoError(t, ob.CheckMaxOpenOrders(submitOrder))
+		assert.ErrorIs(t, ob.CheckMaxOpenOrders(submitOrder, submitOrder), ErrExceededMaxOpenOrders)
+
+		ob.Add(newOrder(2))
+		assert.ErrorIs(t, ob.CheckMaxOpenOrders(submitOrder), ErrExceededMaxOpenOrders)
+
+		ob.Remove(newOrder(2))
+		assert.NoError(t, ob.CheckMaxOpenOrders(submitOrder))
+	})
+}
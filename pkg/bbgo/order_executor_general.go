@@ -229,6 +229,10 @@ func (e *GeneralOrderExecutor) SubmitOrders(
 		return nil, err
 	}
 
+	if err := e.activeMakerOrders.CheckMaxOpenOrders(formattedOrders...); err != nil {
+		return nil, err
+	}
+
 	orderCreateCallback := func(createdOrder types.Order) {
 		e.orderStore.Add(createdOrder)
 		e.activeMakerOrders.Add(createdOrder)
@@ -35,9 +35,9 @@ func (t *TestStruct) InstanceID() string {
 	return "test-struct"
 }
 
-func preparePersistentServices() []service.PersistenceService {
+func preparePersistentServices(t *testing.T) []service.PersistenceService {
 	mem := service.NewMemoryService()
-	jsonDir := &service.JsonPersistenceService{Directory: "testoutput/persistence"}
+	jsonDir := &service.JsonPersistenceService{Directory: t.TempDir()}
 	pss := []service.PersistenceService{
 		mem,
 		jsonDir,
@@ -69,7 +69,7 @@ func Test_CallID(t *testing.T) {
 }
 
 func Test_loadPersistenceFields(t *testing.T) {
-	var pss = preparePersistentServices()
+	var pss = preparePersistentServices(t)
 
 	for _, ps := range pss {
 		psName := reflect.TypeOf(ps).Elem().String()
@@ -105,7 +105,7 @@ func Test_loadPersistenceFields(t *testing.T) {
 }
 
 func Test_storePersistenceFields(t *testing.T) {
-	var pss = preparePersistentServices()
+	var pss = preparePersistentServices(t)
 
 	var a = &TestStruct{
 		Integer:  1,
@@ -0,0 +1,46 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+type AmendOrderResponse struct {
+	OrderId     string `json:"orderId"`
+	OrderLinkId string `json:"orderLinkId"`
+}
+
+//go:generate PostRequest -url "/v5/order/amend" -type AmendOrderRequest -responseDataType .AmendOrderResponse
+type AmendOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category Category `param:"category" validValues:"spot,linear,inverse"`
+	symbol   string   `param:"symbol"`
+	// Either orderId or orderLinkId is required
+	orderId     *string `param:"orderId"`
+	orderLinkId *string `param:"orderLinkId"`
+
+	// the fields which are not given are not modified.
+	qty          *string    `param:"qty"`
+	price        *string    `param:"price"`
+	triggerPrice *string    `param:"triggerPrice"`
+	triggerBy    *TriggerBy `param:"triggerBy" validValues:"LastPrice,MarkPrice,IndexPrice"`
+	takeProfit   *string    `param:"takeProfit"`
+	stopLoss     *string    `param:"stopLoss"`
+}
+
+// NewAmendOrderRequest modifies the quantity, the price or the trigger price of an unfilled or partially filled
+// order, the order is identified by either the order id or the client order id (orderLinkId).
+func (c *RestClient) NewAmendOrderRequest() *AmendOrderRequest {
+	return &AmendOrderRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
+
+// Validate checks either the order id or the client order id is given.
+func (r *AmendOrderRequest) Validate() error {
+	return validateOrderIdentity(r.orderId, r.orderLinkId)
+}
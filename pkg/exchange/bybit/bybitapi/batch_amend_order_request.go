@@ -0,0 +1,70 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// BatchAmendOrder is an amendment of the BatchAmendOrderRequest, either OrderId or OrderLinkId is required, and so is
+// either Qty or Price. The empty Qty or Price is not modified.
+type BatchAmendOrder struct {
+	Symbol      string `json:"symbol"`
+	OrderId     string `json:"orderId,omitempty"`
+	OrderLinkId string `json:"orderLinkId,omitempty"`
+
+	Qty   string `json:"qty,omitempty"`
+	Price string `json:"price,omitempty"`
+}
+
+type BatchAmendOrderRequest struct {
+	client *RestClient
+
+	category Category
+	orders   []BatchAmendOrder
+}
+
+// NewBatchAmendOrderRequest amends up to 10 orders of the same category in one request.
+func (c *RestClient) NewBatchAmendOrderRequest() *BatchAmendOrderRequest {
+	return &BatchAmendOrderRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
+
+func (r *BatchAmendOrderRequest) Category(category Category) *BatchAmendOrderRequest {
+	r.category = category
+	return r
+}
+
+func (r *BatchAmendOrderRequest) Add(orders ...BatchAmendOrder) *BatchAmendOrderRequest {
+	r.orders = append(r.orders, orders...)
+	return r
+}
+
+// Validate checks the symbol, the order identity and the amended fields of every order are given.
+func (r *BatchAmendOrderRequest) Validate() error {
+	for i, order := range r.orders {
+		if len(order.Symbol) == 0 {
+			return fmt.Errorf("order %d: symbol is required", i)
+		}
+
+		if err := validateOrderIdentity(&order.OrderId, &order.OrderLinkId); err != nil {
+			return fmt.Errorf("order %d: %w", i, err)
+		}
+
+		if len(order.Qty) == 0 && len(order.Price) == 0 {
+			return fmt.Errorf("order %d: either qty or price is required", i)
+		}
+	}
+	return nil
+}
+
+// Do amends the orders and returns the results in the order of the added orders. The order which failed to amend,
+// e.g. filled already, doesn't fail the others, its error is returned in the Err of its result.
+func (r *BatchAmendOrderRequest) Do(ctx context.Context) ([]BatchOrderResult, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r.client.doBatchOrderRequest(ctx, "/v5/order/amend-batch", r.category, r.orders, len(r.orders))
+}
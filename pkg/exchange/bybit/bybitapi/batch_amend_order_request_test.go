@@ -0,0 +1,73 @@
+package bybitapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestBatchAmendOrderRequest(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.POST("/v5/order/amend-batch", func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"category": "spot", "request": [
+			{"symbol": "BTCUSDT", "orderId": "1666800494330512128", "price": "28000"},
+			{"symbol": "BTCUSDT", "orderLinkId": "btc-02", "qty": "0.02", "price": "27900"}
+		]}`, string(body))
+
+		return httptesting.BuildResponseString(http.StatusOK, `{
+			"retCode": 0,
+			"retMsg": "OK",
+			"result": {"list": [
+				{"category": "spot", "symbol": "BTCUSDT", "orderId": "", "orderLinkId": ""},
+				{"category": "spot", "symbol": "BTCUSDT", "orderId": "1666800494330512129", "orderLinkId": "btc-02"}
+			]},
+			"retExtInfo": {"list": [
+				{"code": 170213, "msg": "Order does not exist."},
+				{"code": 0, "msg": "OK"}
+			]},
+			"time": 1713434299047
+		}`), nil
+	})
+
+	results, err := client.NewBatchAmendOrderRequest().
+		Add(
+			BatchAmendOrder{Symbol: "BTCUSDT", OrderId: "1666800494330512128", Price: "28000"},
+			BatchAmendOrder{Symbol: "BTCUSDT", OrderLinkId: "btc-02", Qty: "0.02", Price: "27900"},
+		).
+		Do(context.Background())
+	assert.NoError(t, err)
+
+	if assert.Len(t, results, 2) {
+		var apiErr *APIError
+		if assert.True(t, errors.As(results[0].Err, &apiErr)) {
+			assert.True(t, apiErr.IsOrderNotFound())
+		}
+
+		assert.NoError(t, results[1].Err)
+		assert.Equal(t, "1666800494330512129", results[1].OrderId)
+	}
+}
+
+func TestBatchAmendOrderRequest_Validate(t *testing.T) {
+	req := (&RestClient{}).NewBatchAmendOrderRequest().
+		Add(BatchAmendOrder{Symbol: "BTCUSDT", OrderId: "1"})
+	assert.ErrorContains(t, req.Validate(), "order 0: either qty or price is required")
+
+	req = (&RestClient{}).NewBatchAmendOrderRequest().
+		Add(BatchAmendOrder{Symbol: "BTCUSDT", OrderId: "1", OrderLinkId: "btc-01", Qty: "1"})
+	assert.ErrorContains(t, req.Validate(), "order 0: only one of orderId and orderLinkId is accepted")
+}
@@ -0,0 +1,62 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// BatchCancelOrder is an order to cancel of the BatchCancelOrderRequest, either OrderId or OrderLinkId is required.
+type BatchCancelOrder struct {
+	Symbol      string `json:"symbol"`
+	OrderId     string `json:"orderId,omitempty"`
+	OrderLinkId string `json:"orderLinkId,omitempty"`
+}
+
+type BatchCancelOrderRequest struct {
+	client *RestClient
+
+	category Category
+	orders   []BatchCancelOrder
+}
+
+// NewBatchCancelOrderRequest cancels up to 10 orders of the same category in one request.
+func (c *RestClient) NewBatchCancelOrderRequest() *BatchCancelOrderRequest {
+	return &BatchCancelOrderRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
+
+func (r *BatchCancelOrderRequest) Category(category Category) *BatchCancelOrderRequest {
+	r.category = category
+	return r
+}
+
+func (r *BatchCancelOrderRequest) Add(orders ...BatchCancelOrder) *BatchCancelOrderRequest {
+	r.orders = append(r.orders, orders...)
+	return r
+}
+
+// Validate checks the symbol and either the order id or the client order id of every order is given.
+func (r *BatchCancelOrderRequest) Validate() error {
+	for i, order := range r.orders {
+		if len(order.Symbol) == 0 {
+			return fmt.Errorf("order %d: symbol is required", i)
+		}
+
+		if err := validateOrderIdentity(&order.OrderId, &order.OrderLinkId); err != nil {
+			return fmt.Errorf("order %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Do cancels the orders and returns the results in the order of the added orders. The order which failed to cancel,
+// e.g. filled already, doesn't fail the others, its error is returned in the Err of its result.
+func (r *BatchCancelOrderRequest) Do(ctx context.Context) ([]BatchOrderResult, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r.client.doBatchOrderRequest(ctx, "/v5/order/cancel-batch", r.category, r.orders, len(r.orders))
+}
@@ -0,0 +1,89 @@
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestBatchCancelOrderRequest(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.POST("/v5/order/cancel-batch", func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"category": "linear", "request": [
+			{"symbol": "BTCUSDT", "orderId": "b0d5cbd1-8a3c-4d49-8b88-1ad1d9e59c2b"},
+			{"symbol": "ETHUSDT", "orderLinkId": "eth-01"}
+		]}`, string(body))
+
+		return httptesting.BuildResponseString(http.StatusOK, `{
+			"retCode": 0,
+			"retMsg": "OK",
+			"result": {"list": [
+				{"category": "linear", "symbol": "BTCUSDT", "orderId": "b0d5cbd1-8a3c-4d49-8b88-1ad1d9e59c2b", "orderLinkId": ""},
+				{"category": "linear", "symbol": "ETHUSDT", "orderId": "", "orderLinkId": "eth-01"}
+			]},
+			"retExtInfo": {"list": [
+				{"code": 0, "msg": "OK"},
+				{"code": 110001, "msg": "order not exists or too late to cancel"}
+			]},
+			"time": 1713434299047
+		}`), nil
+	})
+
+	results, err := client.NewBatchCancelOrderRequest().
+		Category(CategoryLinear).
+		Add(
+			BatchCancelOrder{Symbol: "BTCUSDT", OrderId: "b0d5cbd1-8a3c-4d49-8b88-1ad1d9e59c2b"},
+			BatchCancelOrder{Symbol: "ETHUSDT", OrderLinkId: "eth-01"},
+		).
+		Do(context.Background())
+	assert.NoError(t, err)
+
+	if assert.Len(t, results, 2) {
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "b0d5cbd1-8a3c-4d49-8b88-1ad1d9e59c2b", results[0].OrderId)
+
+		assert.Equal(t, "eth-01", results[1].OrderLinkId)
+		var apiErr *APIError
+		if assert.True(t, errors.As(results[1].Err, &apiErr)) {
+			assert.True(t, apiErr.IsOrderNotFound())
+			assert.Equal(t, "/v5/order/cancel-batch", apiErr.Endpoint)
+		}
+	}
+}
+
+func TestBatchCancelOrderRequest_Validate(t *testing.T) {
+	req := (&RestClient{}).NewBatchCancelOrderRequest()
+	_, err := req.Do(context.Background())
+	assert.ErrorContains(t, err, "no order is given")
+
+	req.Add(BatchCancelOrder{Symbol: "BTCUSDT"})
+	assert.ErrorContains(t, req.Validate(), "order 0: either orderId or orderLinkId is required")
+
+	req = (&RestClient{}).NewBatchCancelOrderRequest()
+	for i := 0; i < 11; i++ {
+		req.Add(BatchCancelOrder{Symbol: "BTCUSDT", OrderId: "1"})
+	}
+	assert.NoError(t, req.Validate())
+	_, err = req.Do(context.Background())
+	assert.ErrorContains(t, err, "too many orders: 11, the max is 10")
+
+	// the omitted ids are not sent
+	raw, err := json.Marshal(BatchCancelOrder{Symbol: "BTCUSDT", OrderId: "1"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"symbol": "BTCUSDT", "orderId": "1"}`, string(raw))
+}
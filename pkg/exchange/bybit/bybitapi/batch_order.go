@@ -0,0 +1,109 @@
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// maxBatchOrders is the max number of the orders of a batch request.
+const maxBatchOrders = 10
+
+// BatchOrderResult is the result of an order of the batch request.
+type BatchOrderResult struct {
+	Category    Category `json:"category"`
+	Symbol      string   `json:"symbol"`
+	OrderId     string   `json:"orderId"`
+	OrderLinkId string   `json:"orderLinkId"`
+
+	// Err is the *APIError of the order if it failed, e.g. the order was filled before it's cancelled. It's nil if the
+	// order succeeded.
+	Err error `json:"-"`
+}
+
+type batchOrderStatus struct {
+	Code uint   `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+/*
+sample:
+
+	{
+	  "retCode": 0,
+	  "retMsg": "OK",
+	  "result": {
+	    "list": [
+	      {"category": "spot", "symbol": "BTCUSDT", "orderId": "1666800494330512128", "orderLinkId": "spot-btc-03"},
+	      {"category": "spot", "symbol": "ATOMUSDT", "orderId": "", "orderLinkId": "spot-atom-03"}
+	    ]
+	  },
+	  "retExtInfo": {
+	    "list": [
+	      {"code": 0, "msg": "OK"},
+	      {"code": 170213, "msg": "Order does not exist."}
+	    ]
+	  },
+	  "time": 1713434299047
+	}
+*/
+type batchOrderResponse struct {
+	Result struct {
+		List []BatchOrderResult `json:"list"`
+	} `json:"result"`
+	RetExtInfo struct {
+		List []batchOrderStatus `json:"list"`
+	} `json:"retExtInfo"`
+}
+
+// doBatchOrderRequest sends the batch request and returns the results in the order of the requested orders. The
+// failures of the individual orders don't fail the request, they're returned in the Err of the results.
+func (c *RestClient) doBatchOrderRequest(ctx context.Context, path string, category Category, orders interface{}, numOrders int) ([]BatchOrderResult, error) {
+	if numOrders == 0 {
+		return nil, fmt.Errorf("no order is given")
+	}
+
+	if numOrders > maxBatchOrders {
+		return nil, fmt.Errorf("too many orders: %d, the max is %d", numOrders, maxBatchOrders)
+	}
+
+	payload := map[string]interface{}{
+		"category": category,
+		"request":  orders,
+	}
+
+	req, err := c.NewAuthenticatedRequest(ctx, http.MethodPost, path, nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse batchOrderResponse
+	if err := json.Unmarshal(response.Body, &apiResponse); err != nil {
+		return nil, err
+	}
+
+	results := apiResponse.Result.List
+	statuses := apiResponse.RetExtInfo.List
+	if len(results) != numOrders || len(statuses) != numOrders {
+		return nil, fmt.Errorf("unexpected number of the batch results: %d, statuses: %d, orders: %d",
+			len(results), len(statuses), numOrders)
+	}
+
+	for i, status := range statuses {
+		if status.Code != 0 {
+			results[i].Err = &APIError{
+				RetCode:  status.Code,
+				RetMsg:   status.Msg,
+				Endpoint: path,
+			}
+		}
+	}
+
+	return results, nil
+}
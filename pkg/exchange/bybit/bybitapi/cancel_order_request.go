@@ -1,6 +1,8 @@
 package bybitapi
 
 import (
+	"errors"
+
 	"github.com/c9s/requestgen"
 )
 
@@ -16,15 +18,15 @@ type CancelOrderResponse struct {
 type CancelOrderRequest struct {
 	client requestgen.AuthenticatedAPIClient
 
-	category Category `param:"category" validValues:"spot"`
+	category Category `param:"category" validValues:"spot,linear,inverse"`
 	symbol   string   `param:"symbol"`
 	// User customised order ID. Either orderId or orderLinkId is required
-	orderLinkId string `param:"orderLinkId"`
+	orderLinkId *string `param:"orderLinkId"`
 
 	orderId *string `param:"orderId"`
-	// orderFilter default type is Order
+	// orderFilter default type is Order, StopOrder cancels the spot conditional order.
 	// tpsl order type are not currently supported
-	orderFilter *string `param:"timeInForce" validValues:"Order"`
+	orderFilter *string `param:"orderFilter" validValues:"Order,StopOrder"`
 }
 
 func (c *RestClient) NewCancelOrderRequest() *CancelOrderRequest {
@@ -33,3 +35,23 @@ func (c *RestClient) NewCancelOrderRequest() *CancelOrderRequest {
 		category: CategorySpot,
 	}
 }
+
+// Validate checks either the order id or the client order id is given.
+func (r *CancelOrderRequest) Validate() error {
+	return validateOrderIdentity(r.orderId, r.orderLinkId)
+}
+
+// validateOrderIdentity checks exactly one of the order id and the client order id (orderLinkId) is given, it's shared
+// by the requests which operate on an existing order.
+func validateOrderIdentity(orderId, orderLinkId *string) error {
+	hasOrderId := orderId != nil && len(*orderId) > 0
+	hasOrderLinkId := orderLinkId != nil && len(*orderLinkId) > 0
+
+	switch {
+	case !hasOrderId && !hasOrderLinkId:
+		return errors.New("either orderId or orderLinkId is required")
+	case hasOrderId && hasOrderLinkId:
+		return errors.New("only one of orderId and orderLinkId is accepted")
+	}
+	return nil
+}
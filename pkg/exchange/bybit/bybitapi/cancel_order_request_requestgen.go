@@ -22,7 +22,7 @@ func (p *CancelOrderRequest) Symbol(symbol string) *CancelOrderRequest {
 }
 
 func (p *CancelOrderRequest) OrderLinkId(orderLinkId string) *CancelOrderRequest {
-	p.orderLinkId = orderLinkId
+	p.orderLinkId = &orderLinkId
 	return p
 }
 
@@ -56,7 +56,7 @@ func (p *CancelOrderRequest) GetParameters() (map[string]interface{}, error) {
 
 	// TEMPLATE check-valid-values
 	switch category {
-	case "spot":
+	case "spot", "linear", "inverse":
 		params["category"] = category
 
 	default:
@@ -73,10 +73,13 @@ func (p *CancelOrderRequest) GetParameters() (map[string]interface{}, error) {
 	// assign parameter of symbol
 	params["symbol"] = symbol
 	// check orderLinkId field -> json key orderLinkId
-	orderLinkId := p.orderLinkId
+	if p.orderLinkId != nil {
+		orderLinkId := *p.orderLinkId
 
-	// assign parameter of orderLinkId
-	params["orderLinkId"] = orderLinkId
+		// assign parameter of orderLinkId
+		params["orderLinkId"] = orderLinkId
+	} else {
+	}
 	// check orderId field -> json key orderId
 	if p.orderId != nil {
 		orderId := *p.orderId
@@ -85,23 +88,23 @@ func (p *CancelOrderRequest) GetParameters() (map[string]interface{}, error) {
 		params["orderId"] = orderId
 	} else {
 	}
-	// check orderFilter field -> json key timeInForce
+	// check orderFilter field -> json key orderFilter
 	if p.orderFilter != nil {
 		orderFilter := *p.orderFilter
 
 		// TEMPLATE check-valid-values
 		switch orderFilter {
-		case "Order":
-			params["timeInForce"] = orderFilter
+		case "Order", "StopOrder":
+			params["orderFilter"] = orderFilter
 
 		default:
-			return nil, fmt.Errorf("timeInForce value %v is invalid", orderFilter)
+			return nil, fmt.Errorf("orderFilter value %v is invalid", orderFilter)
 
 		}
 		// END TEMPLATE check-valid-values
 
 		// assign parameter of orderFilter
-		params["timeInForce"] = orderFilter
+		params["orderFilter"] = orderFilter
 	} else {
 	}
 
@@ -0,0 +1,23 @@
+package bybitapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelOrderRequest_Validate(t *testing.T) {
+	req := (&RestClient{}).NewCancelOrderRequest().Symbol("BTCUSDT")
+	assert.ErrorContains(t, req.Validate(), "either orderId or orderLinkId is required")
+
+	req.OrderLinkId("my-order")
+	assert.NoError(t, req.Validate())
+
+	params, err := req.GetParameters()
+	assert.NoError(t, err)
+	assert.Equal(t, "my-order", params["orderLinkId"])
+	assert.NotContains(t, params, "orderId")
+
+	req.OrderId("1")
+	assert.ErrorContains(t, req.Validate(), "only one of orderId and orderLinkId is accepted")
+}
@@ -11,12 +11,14 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/c9s/requestgen"
 	"github.com/pkg/errors"
 
 	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util/traceid"
 )
 
 const (
@@ -25,31 +27,139 @@ const (
 	RestBaseURL         = "https://api.bybit.com"
 	WsSpotPublicSpotUrl = "wss://stream.bybit.com/v5/public/spot"
 	WsSpotPrivateUrl    = "wss://stream.bybit.com/v5/private"
+
+	// WsPublicLinearUrl and WsPublicInverseUrl are the public streams of the derivatives, e.g. the liquidation topic.
+	WsPublicLinearUrl  = "wss://stream.bybit.com/v5/public/linear"
+	WsPublicInverseUrl = "wss://stream.bybit.com/v5/public/inverse"
+
+	// DemoTradingRestBaseURL and WsDemoTradingPrivateUrl are the hosts of the demo trading, which trades the paper
+	// funds against the mainnet market data, see WithDemoTrading.
+	DemoTradingRestBaseURL  = "https://api-demo.bybit.com"
+	WsDemoTradingPrivateUrl = "wss://stream-demo.bybit.com/v5/private"
+
+	// TestnetRestBaseURL and the WsTestnet urls are the hosts of the testnet, which has its own market data and
+	// liquidity, see WithTestnet.
+	TestnetRestBaseURL        = "https://api-testnet.bybit.com"
+	WsTestnetPublicSpotUrl    = "wss://stream-testnet.bybit.com/v5/public/spot"
+	WsTestnetPublicLinearUrl  = "wss://stream-testnet.bybit.com/v5/public/linear"
+	WsTestnetPublicInverseUrl = "wss://stream-testnet.bybit.com/v5/public/inverse"
+	WsTestnetPrivateUrl       = "wss://stream-testnet.bybit.com/v5/private"
 )
 
 // defaultRequestWindowMilliseconds specify how long an HTTP request is valid. It is also used to prevent replay attacks.
 var defaultRequestWindowMilliseconds = fmt.Sprintf("%d", 5*time.Second.Milliseconds())
 
+// maxRecvWindow caps the recv window since a wider window weakens the replay protection.
+const maxRecvWindow = time.Minute
+
+// ErrFundTransferDisabled is returned by the withdrawal and the transfer requests if the fund transfer is not enabled,
+// see EnableFundTransfer.
+var ErrFundTransferDisabled = errors.New("the fund transfer is disabled, call EnableFundTransfer to enable it")
+
+// ErrDemoTradingWithTestnet is returned by NewClient if both the demo trading and the testnet are enabled, the demo
+// trading runs against the mainnet market data.
+var ErrDemoTradingWithTestnet = errors.New("the demo trading can not be enabled with the testnet")
+
+// fundTransferPaths are the paths which move the funds out of the account, they're rejected unless the fund transfer
+// is enabled explicitly.
+var fundTransferPaths = map[string]struct{}{
+	"/v5/asset/transfer/inter-transfer": {},
+	"/v5/asset/withdraw/create":         {},
+}
+
 type RestClient struct {
 	requestgen.BaseAPIClient
 
 	key, secret string
+
+	fundTransferEnabled bool
+
+	retryPolicy *RetryPolicy
+
+	// recvWindow is the recv window in milliseconds of the signed requests, see SetRecvWindow.
+	recvWindow string
+
+	// testnet and demoTrading are set by WithTestnet and WithDemoTrading.
+	testnet, demoTrading bool
+
+	// authBaseURL overrides the base url of the authenticated requests, see WithDemoTrading.
+	authBaseURL *url.URL
+
+	// rateLimits are the rate limits of the endpoints reported by the responses, see RateLimitStatus.
+	rateLimitsMu      sync.Mutex
+	rateLimits        map[string]RateLimitStatus
+	rateLimitThrottle bool
 }
 
-func NewClient() (*RestClient, error) {
-	u, err := url.Parse(RestBaseURL)
-	if err != nil {
-		return nil, err
+// ClientOption configures the RestClient created by NewClient.
+type ClientOption func(c *RestClient)
+
+// WithTestnet sends all the requests to the testnet, which has its own market data and liquidity.
+func WithTestnet(enabled bool) ClientOption {
+	return func(c *RestClient) {
+		c.testnet = enabled
+	}
+}
+
+// WithDemoTrading sends the authenticated requests, i.e. the account, the order and the position requests, to the
+// demo trading host, while the public market data requests are still sent to the mainnet. The demo trading account
+// has its own api key. It can't be used with WithTestnet.
+func WithDemoTrading(enabled bool) ClientOption {
+	return func(c *RestClient) {
+		c.demoTrading = enabled
 	}
+}
 
-	return &RestClient{
+func NewClient(options ...ClientOption) (*RestClient, error) {
+	retryPolicy := DefaultRetryPolicy
+	client := &RestClient{
 		BaseAPIClient: requestgen.BaseAPIClient{
-			BaseURL: u,
 			HttpClient: &http.Client{
 				Timeout: defaultHTTPTimeout,
 			},
 		},
-	}, nil
+		retryPolicy: &retryPolicy,
+		recvWindow:  defaultRequestWindowMilliseconds,
+	}
+
+	for _, o := range options {
+		o(client)
+	}
+
+	if client.testnet && client.demoTrading {
+		return nil, ErrDemoTradingWithTestnet
+	}
+
+	baseURL := RestBaseURL
+	if client.testnet {
+		baseURL = TestnetRestBaseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	client.BaseURL = u
+
+	if client.demoTrading {
+		authBaseURL, err := url.Parse(DemoTradingRestBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		client.authBaseURL = authBaseURL
+	}
+
+	return client, nil
+}
+
+// IsTestnet returns true if the client is created with WithTestnet.
+func (c *RestClient) IsTestnet() bool {
+	return c.testnet
+}
+
+// IsDemoTrading returns true if the client is created with WithDemoTrading.
+func (c *RestClient) IsDemoTrading() bool {
+	return c.demoTrading
 }
 
 func (c *RestClient) Auth(key, secret string) {
@@ -58,8 +168,62 @@ func (c *RestClient) Auth(key, secret string) {
 	c.secret = secret
 }
 
+// EnableFundTransfer enables the withdrawal and the internal transfer requests, they're disabled by default since they
+// move the funds.
+func (c *RestClient) EnableFundTransfer() {
+	c.fundTransferEnabled = true
+}
+
+// SetRecvWindow sets how long the signed request is valid after its timestamp, bybit rejects the request arriving
+// later than that. The default is 5 seconds, a wider window tolerates the high-latency links at the cost of a wider
+// replay window. It must be positive and at most 1 minute, and it's truncated to milliseconds.
+func (c *RestClient) SetRecvWindow(window time.Duration) error {
+	if window < time.Millisecond || window > maxRecvWindow {
+		return fmt.Errorf("invalid recv window: %s, it must be between 1ms and %s", window, maxRecvWindow)
+	}
+
+	c.recvWindow = strconv.FormatInt(window.Milliseconds(), 10)
+	return nil
+}
+
+// SendRequest sends the request with the retry policy, see SetRetryPolicy.
+func (c *RestClient) SendRequest(req *http.Request) (*requestgen.Response, error) {
+	return c.sendWithRetry(req)
+}
+
+// send sends the request once. The response with the non-zero retCode is returned with the APIError carrying the
+// endpoint, even if the http status is 200. The rate limit headers are recorded even if the request is rejected.
+func (c *RestClient) send(req *http.Request) (*requestgen.Response, error) {
+	if err := c.waitRateLimit(req.Context(), req.URL.Path); err != nil {
+		return nil, err
+	}
+
+	response, err := c.BaseAPIClient.SendRequest(req)
+	if response != nil && response.Response != nil {
+		c.recordRateLimit(req.URL.Path, response.Header)
+	}
+	if err != nil {
+		return response, err
+	}
+
+	var apiResponse APIResponse
+	// the malformed body is left to the decoding of the caller
+	if err := response.DecodeJSON(&apiResponse); err == nil && apiResponse.RetCode != 0 {
+		apiErr := apiResponse.Error().(*APIError)
+		apiErr.Endpoint = req.URL.Path
+		apiErr.TraceID, _ = traceid.FromContext(req.Context())
+		return response, apiErr
+	}
+
+	return response, nil
+}
+
 // newAuthenticatedRequest creates new http request for authenticated routes.
 func (c *RestClient) NewAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	if _, ok := fundTransferPaths[refURL]; ok && !c.fundTransferEnabled {
+		return nil, ErrFundTransferDisabled
+	}
+
 	if len(c.key) == 0 {
 		return nil, errors.New("empty api key")
 	}
@@ -77,7 +241,12 @@ func (c *RestClient) NewAuthenticatedRequest(ctx context.Context, method, refURL
 		rel.RawQuery = params.Encode()
 	}
 
-	pathURL := c.BaseURL.ResolveReference(rel)
+	baseURL := c.BaseURL
+	if c.authBaseURL != nil {
+		baseURL = c.authBaseURL
+	}
+
+	pathURL := baseURL.ResolveReference(rel)
 	path := pathURL.Path
 	if rel.RawQuery != "" {
 		path += "?" + rel.RawQuery
@@ -94,9 +263,9 @@ func (c *RestClient) NewAuthenticatedRequest(ctx context.Context, method, refURL
 	var signKey string
 	switch method {
 	case http.MethodPost:
-		signKey = timestamp + c.key + defaultRequestWindowMilliseconds + string(body)
+		signKey = timestamp + c.key + c.recvWindow + string(body)
 	case http.MethodGet:
-		signKey = timestamp + c.key + defaultRequestWindowMilliseconds + rel.RawQuery
+		signKey = timestamp + c.key + c.recvWindow + rel.RawQuery
 	default:
 		return nil, fmt.Errorf("unexpected method: %s", method)
 	}
@@ -118,7 +287,7 @@ func (c *RestClient) NewAuthenticatedRequest(ctx context.Context, method, refURL
 	req.Header.Add("X-BAPI-API-KEY", c.key)
 	req.Header.Add("X-BAPI-TIMESTAMP", timestamp)
 	req.Header.Add("X-BAPI-SIGN", signature)
-	req.Header.Add("X-BAPI-RECV-WINDOW", defaultRequestWindowMilliseconds)
+	req.Header.Add("X-BAPI-RECV-WINDOW", c.recvWindow)
 	return req, nil
 }
 
@@ -182,5 +351,89 @@ func (a APIResponse) Validate() error {
 }
 
 func (a APIResponse) Error() error {
-	return fmt.Errorf("retCode: %d, retMsg: %s, retExtInfo: %q, time: %s", a.RetCode, a.RetMsg, a.RetExtInfo, a.Time)
+	return &APIError{
+		RetCode:    a.RetCode,
+		RetMsg:     a.RetMsg,
+		RetExtInfo: a.RetExtInfo,
+		Time:       a.Time,
+	}
+}
+
+// The retCodes of the common failures, see https://bybit-exchange.github.io/docs/v5/error
+const (
+	RetCodeTooManyVisits            = 10006
+	RetCodeIPRateLimitExceeded      = 10018
+	RetCodeOrderNotExists           = 110001
+	RetCodeWalletBalanceTooLow      = 110004
+	RetCodeAvailableBalanceLow      = 110007
+	RetCodeInsufficientBalance      = 110012
+	RetCodeSpotInsufficientBalance  = 170131
+	RetCodeSpotOrderNotExists       = 170213
+	RetCodeDuplicateOrderLinkId     = 110072
+	RetCodeSpotDuplicateOrderLinkId = 170141
+)
+
+// APIError is the error of the response with the non-zero retCode, use errors.As to inspect it.
+type APIError struct {
+	RetCode    uint
+	RetMsg     string
+	RetExtInfo json.RawMessage
+	Time       types.MillisecondTimestamp
+	// Endpoint is the path of the request, e.g. /v5/order/create.
+	Endpoint string
+	// TraceID is the trace id of the context of the request, see the traceid package.
+	TraceID string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("retCode: %d, retMsg: %s, retExtInfo: %q, time: %s", e.RetCode, e.RetMsg, e.RetExtInfo, e.Time)
+	if len(e.Endpoint) > 0 {
+		msg = fmt.Sprintf("endpoint: %s, %s", e.Endpoint, msg)
+	}
+	if len(e.TraceID) > 0 {
+		msg = fmt.Sprintf("%s, trace id: %s", msg, e.TraceID)
+	}
+	return msg
+}
+
+// IsRateLimited returns true if the request is rejected by the rate limit.
+func (e *APIError) IsRateLimited() bool {
+	switch e.RetCode {
+	case RetCodeTooManyVisits, RetCodeIPRateLimitExceeded:
+		return true
+	}
+	return false
+}
+
+// IsInsufficientBalance returns true if the order is rejected since the balance is not enough.
+func (e *APIError) IsInsufficientBalance() bool {
+	switch e.RetCode {
+	case RetCodeWalletBalanceTooLow, RetCodeAvailableBalanceLow, RetCodeInsufficientBalance, RetCodeSpotInsufficientBalance:
+		return true
+	}
+	return false
+}
+
+// IsOrderNotFound returns true if the order to query, amend or cancel does not exist.
+func (e *APIError) IsOrderNotFound() bool {
+	switch e.RetCode {
+	case RetCodeOrderNotExists, RetCodeSpotOrderNotExists:
+		return true
+	}
+	return false
+}
+
+// IsDuplicateOrderLinkId returns true if the order is rejected since the client order id (orderLinkId) is used already.
+func (e *APIError) IsDuplicateOrderLinkId() bool {
+	switch e.RetCode {
+	case RetCodeDuplicateOrderLinkId, RetCodeSpotDuplicateOrderLinkId:
+		return true
+	}
+	return false
+}
+
+// IsRetCode returns true if the error is the APIError of the given retCode.
+func IsRetCode(err error, retCode uint) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.RetCode == retCode
 }
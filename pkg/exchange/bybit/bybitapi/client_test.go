@@ -2,15 +2,20 @@ package bybitapi
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
 	"github.com/c9s/bbgo/pkg/testutil"
+	"github.com/c9s/bbgo/pkg/util/traceid"
 )
 
 func getTestClientOrSkip(t *testing.T) *RestClient {
@@ -181,3 +186,100 @@ func TestClient(t *testing.T) {
 		t.Logf("apiResp: %+v", apiResp)
 	})
 }
+
+func TestRestClient_APIError(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 170131, "retMsg": "Insufficient balance.", "result": {}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	_, err = client.NewPlaceOrderRequest().
+		Symbol("BTCUSDT").
+		Side(SideBuy).
+		OrderType(OrderTypeMarket).
+		Qty("1").
+		OrderLinkId("test").
+		TimeInForce(TimeInForceGTC).
+		Do(traceid.NewContext(context.Background(), "trace-1"))
+
+	var apiErr *APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, uint(RetCodeSpotInsufficientBalance), apiErr.RetCode)
+		assert.Equal(t, "Insufficient balance.", apiErr.RetMsg)
+		assert.Equal(t, "/v5/order/create", apiErr.Endpoint)
+		assert.Equal(t, "trace-1", apiErr.TraceID)
+		assert.Contains(t, apiErr.Error(), "trace id: trace-1")
+		assert.True(t, apiErr.IsInsufficientBalance())
+		assert.False(t, apiErr.IsRateLimited())
+		assert.False(t, apiErr.IsOrderNotFound())
+		assert.False(t, apiErr.IsDuplicateOrderLinkId())
+	}
+}
+
+func TestRestClient_SetRecvWindow(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	req, err := client.NewAuthenticatedRequest(context.Background(), http.MethodGet, "/v5/order/realtime", url.Values{"symbol": []string{"BTCUSDT"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "5000", req.Header.Get("X-BAPI-RECV-WINDOW"))
+
+	assert.NoError(t, client.SetRecvWindow(20*time.Second))
+	req, err = client.NewAuthenticatedRequest(context.Background(), http.MethodGet, "/v5/order/realtime", url.Values{"symbol": []string{"BTCUSDT"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "20000", req.Header.Get("X-BAPI-RECV-WINDOW"))
+
+	// the recv window is signed
+	timestamp := req.Header.Get("X-BAPI-TIMESTAMP")
+	assert.Equal(t, Sign(timestamp+"key"+"20000"+"symbol=BTCUSDT", "secret"), req.Header.Get("X-BAPI-SIGN"))
+
+	assert.Error(t, client.SetRecvWindow(0))
+	assert.Error(t, client.SetRecvWindow(2*time.Minute))
+	assert.Equal(t, "20000", client.recvWindow)
+}
+
+func TestRestClient_WithDemoTrading(t *testing.T) {
+	client, err := NewClient(WithDemoTrading(true))
+	assert.NoError(t, err)
+	assert.True(t, client.IsDemoTrading())
+	client.Auth("key", "secret")
+
+	req, err := client.NewAuthenticatedRequest(context.Background(), http.MethodGet, "/v5/order/realtime", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api-demo.bybit.com/v5/order/realtime", req.URL.String())
+
+	// the market data is still from the mainnet
+	req, err = client.NewRequest(context.Background(), http.MethodGet, "/v5/market/tickers", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.bybit.com/v5/market/tickers", req.URL.String())
+
+	// the demo trading runs against the mainnet market data
+	_, err = NewClient(WithTestnet(true), WithDemoTrading(true))
+	assert.ErrorIs(t, err, ErrDemoTradingWithTestnet)
+
+	client, err = NewClient(WithTestnet(true), WithDemoTrading(false))
+	assert.NoError(t, err)
+	assert.False(t, client.IsDemoTrading())
+}
+
+func TestRestClient_WithTestnet(t *testing.T) {
+	client, err := NewClient(WithTestnet(true))
+	assert.NoError(t, err)
+	assert.True(t, client.IsTestnet())
+	client.Auth("key", "secret")
+
+	req, err := client.NewAuthenticatedRequest(context.Background(), http.MethodGet, "/v5/order/realtime", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api-testnet.bybit.com/v5/order/realtime", req.URL.String())
+
+	req, err = client.NewRequest(context.Background(), http.MethodGet, "/v5/market/tickers", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api-testnet.bybit.com/v5/market/tickers", req.URL.String())
+}
@@ -0,0 +1,35 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// AccountTypeFund is the funding account, it's only used by the asset transfer.
+const AccountTypeFund AccountType = "FUND"
+
+type CreateInternalTransferResponse struct {
+	TransferId string `json:"transferId"`
+}
+
+//go:generate PostRequest -url "/v5/asset/transfer/inter-transfer" -type CreateInternalTransferRequest -responseDataType .CreateInternalTransferResponse
+type CreateInternalTransferRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	// transferId is the UUID generated by the client.
+	transferId      string      `param:"transferId"`
+	coin            string      `param:"coin"`
+	amount          string      `param:"amount"`
+	fromAccountType AccountType `param:"fromAccountType" validValues:"SPOT,UNIFIED,CONTRACT,FUND"`
+	toAccountType   AccountType `param:"toAccountType" validValues:"SPOT,UNIFIED,CONTRACT,FUND"`
+}
+
+// NewCreateInternalTransferRequest creates the transfer between the accounts of the same user. The request is rejected
+// unless the fund transfer is enabled by EnableFundTransfer.
+func (c *RestClient) NewCreateInternalTransferRequest() *CreateInternalTransferRequest {
+	return &CreateInternalTransferRequest{
+		client: c,
+	}
+}
@@ -0,0 +1,231 @@
+// Code generated by "requestgen -method POST -responseType .APIResponse -responseDataField Result -url /v5/asset/transfer/inter-transfer -type CreateInternalTransferRequest -responseDataType .CreateInternalTransferResponse"; DO NOT EDIT.
+
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+func (c *CreateInternalTransferRequest) TransferId(transferId string) *CreateInternalTransferRequest {
+	c.transferId = transferId
+	return c
+}
+
+func (c *CreateInternalTransferRequest) Coin(coin string) *CreateInternalTransferRequest {
+	c.coin = coin
+	return c
+}
+
+func (c *CreateInternalTransferRequest) Amount(amount string) *CreateInternalTransferRequest {
+	c.amount = amount
+	return c
+}
+
+func (c *CreateInternalTransferRequest) FromAccountType(fromAccountType AccountType) *CreateInternalTransferRequest {
+	c.fromAccountType = fromAccountType
+	return c
+}
+
+func (c *CreateInternalTransferRequest) ToAccountType(toAccountType AccountType) *CreateInternalTransferRequest {
+	c.toAccountType = toAccountType
+	return c
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (c *CreateInternalTransferRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (c *CreateInternalTransferRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check transferId field -> json key transferId
+	transferId := c.transferId
+
+	// assign parameter of transferId
+	params["transferId"] = transferId
+	// check coin field -> json key coin
+	coin := c.coin
+
+	// assign parameter of coin
+	params["coin"] = coin
+	// check amount field -> json key amount
+	amount := c.amount
+
+	// assign parameter of amount
+	params["amount"] = amount
+	// check fromAccountType field -> json key fromAccountType
+	fromAccountType := c.fromAccountType
+
+	// TEMPLATE check-valid-values
+	switch fromAccountType {
+	case "SPOT", "UNIFIED", "CONTRACT", "FUND":
+		params["fromAccountType"] = fromAccountType
+
+	default:
+		return nil, fmt.Errorf("fromAccountType value %v is invalid", fromAccountType)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of fromAccountType
+	params["fromAccountType"] = fromAccountType
+	// check toAccountType field -> json key toAccountType
+	toAccountType := c.toAccountType
+
+	// TEMPLATE check-valid-values
+	switch toAccountType {
+	case "SPOT", "UNIFIED", "CONTRACT", "FUND":
+		params["toAccountType"] = toAccountType
+
+	default:
+		return nil, fmt.Errorf("toAccountType value %v is invalid", toAccountType)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of toAccountType
+	params["toAccountType"] = toAccountType
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (c *CreateInternalTransferRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := c.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if c.isVarSlice(_v) {
+			c.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (c *CreateInternalTransferRequest) GetParametersJSON() ([]byte, error) {
+	params, err := c.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (c *CreateInternalTransferRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (c *CreateInternalTransferRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (c *CreateInternalTransferRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (c *CreateInternalTransferRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (c *CreateInternalTransferRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := c.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+// GetPath returns the request path of the API
+func (c *CreateInternalTransferRequest) GetPath() string {
+	return "/v5/asset/transfer/inter-transfer"
+}
+
+// Do generates the request object and send the request object to the API endpoint
+func (c *CreateInternalTransferRequest) Do(ctx context.Context) (*CreateInternalTransferResponse, error) {
+
+	params, err := c.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+
+	var apiURL string
+
+	apiURL = c.GetPath()
+
+	req, err := c.client.NewAuthenticatedRequest(ctx, "POST", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	type responseValidator interface {
+		Validate() error
+	}
+	validator, ok := interface{}(apiResponse).(responseValidator)
+	if ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	var data CreateInternalTransferResponse
+	if err := json.Unmarshal(apiResponse.Result, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
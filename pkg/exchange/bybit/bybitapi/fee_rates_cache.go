@@ -0,0 +1,91 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FeeRatesCache caches the maker and taker fee rates of the account keyed by category and symbol, the fee rates depend
+// on the VIP tier, so they should be queried instead of hardcoded. The fee rates of the option are per base coin, so
+// the base coin, e.g. BTC, is used as the symbol of the option.
+type FeeRatesCache struct {
+	client *RestClient
+
+	mu       sync.RWMutex
+	feeRates map[Category]map[string]FeeRate
+}
+
+func NewFeeRatesCache(client *RestClient) *FeeRatesCache {
+	return &FeeRatesCache{
+		client:   client,
+		feeRates: map[Category]map[string]FeeRate{},
+	}
+}
+
+// Refresh reloads the fee rates of all the symbols of the category into the cache.
+func (c *FeeRatesCache) Refresh(ctx context.Context, category Category) error {
+	feeRates, err := c.client.NewGetFeeRatesRequest().Category(category).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get fee rates, category: %s, err: %w", category, err)
+	}
+
+	c.Update(category, feeRates.List...)
+	return nil
+}
+
+// Update stores the given fee rates of the category into the cache.
+func (c *FeeRatesCache) Update(category Category, feeRates ...FeeRate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	categoryFeeRates, ok := c.feeRates[category]
+	if !ok {
+		categoryFeeRates = map[string]FeeRate{}
+		c.feeRates[category] = categoryFeeRates
+	}
+
+	for _, feeRate := range feeRates {
+		key := feeRate.Symbol
+		if category == CategoryOption {
+			key = feeRate.BaseCoin
+		}
+		categoryFeeRates[key] = feeRate
+	}
+}
+
+// Get returns the cached fee rate of the symbol of the category.
+func (c *FeeRatesCache) Get(category Category, symbol string) (FeeRate, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	feeRate, ok := c.feeRates[category][symbol]
+	return feeRate, ok
+}
+
+// GetFeeRate returns the fee rate of the symbol of the category, it queries the fee rate if it's not cached yet.
+func (c *FeeRatesCache) GetFeeRate(ctx context.Context, category Category, symbol string) (FeeRate, error) {
+	if feeRate, ok := c.Get(category, symbol); ok {
+		return feeRate, nil
+	}
+
+	req := c.client.NewGetFeeRatesRequest().Category(category)
+	if category == CategoryOption {
+		req.BaseCoin(symbol)
+	} else {
+		req.Symbol(symbol)
+	}
+
+	feeRates, err := req.Do(ctx)
+	if err != nil {
+		return FeeRate{}, fmt.Errorf("failed to get fee rates, category: %s, symbol: %s, err: %w", category, symbol, err)
+	}
+
+	c.Update(category, feeRates.List...)
+
+	feeRate, ok := c.Get(category, symbol)
+	if !ok {
+		return FeeRate{}, fmt.Errorf("fee rate not found, category: %s, symbol: %s", category, symbol)
+	}
+	return feeRate, nil
+}
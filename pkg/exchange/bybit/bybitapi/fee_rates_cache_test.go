@@ -0,0 +1,105 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestFeeRatesCache(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	numOfRequests := 0
+	transport.GET("/v5/account/fee-rate", func(req *http.Request) (*http.Response, error) {
+		numOfRequests++
+
+		query := req.URL.Query()
+		switch Category(query.Get("category")) {
+		case CategorySpot:
+			assert.Equal(t, "BTCUSDT", query.Get("symbol"))
+			return httptesting.BuildResponseString(http.StatusOK, `{
+				"retCode": 0,
+				"retMsg": "OK",
+				"result": {
+					"list": [{"symbol": "BTCUSDT", "baseCoin": "", "takerFeeRate": "0.001", "makerFeeRate": "0.001"}]
+				},
+				"retExtInfo": {},
+				"time": 1700000000000
+			}`), nil
+
+		case CategoryLinear:
+			assert.Equal(t, "BTCUSDT", query.Get("symbol"))
+			return httptesting.BuildResponseString(http.StatusOK, `{
+				"retCode": 0,
+				"retMsg": "OK",
+				"result": {
+					"list": [{"symbol": "BTCUSDT", "baseCoin": "", "takerFeeRate": "0.0006", "makerFeeRate": "0.0001"}]
+				},
+				"retExtInfo": {},
+				"time": 1700000000000
+			}`), nil
+
+		case CategoryOption:
+			assert.Equal(t, "BTC", query.Get("baseCoin"))
+			assert.Empty(t, query.Get("symbol"))
+			return httptesting.BuildResponseString(http.StatusOK, `{
+				"retCode": 0,
+				"retMsg": "OK",
+				"result": {
+					"list": [{"symbol": "", "baseCoin": "BTC", "takerFeeRate": "0.0003", "makerFeeRate": "0.0003"}]
+				},
+				"retExtInfo": {},
+				"time": 1700000000000
+			}`), nil
+		}
+
+		t.Errorf("unexpected category: %s", query.Get("category"))
+		return httptesting.BuildResponseString(http.StatusBadRequest, ""), nil
+	})
+
+	cache := NewFeeRatesCache(client)
+	_, ok := cache.Get(CategoryLinear, "BTCUSDT")
+	assert.False(t, ok)
+
+	for i := 0; i < 2; i++ {
+		feeRate, err := cache.GetFeeRate(context.Background(), CategoryLinear, "BTCUSDT")
+		assert.NoError(t, err)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.0006"), feeRate.TakerFeeRate)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.0001"), feeRate.MakerFeeRate)
+	}
+
+	// the second lookup hits the cache
+	assert.Equal(t, 1, numOfRequests)
+
+	// the same symbol of another category is cached separately
+	_, ok = cache.Get(CategorySpot, "BTCUSDT")
+	assert.False(t, ok)
+	feeRate, err := cache.GetFeeRate(context.Background(), CategorySpot, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.001"), feeRate.TakerFeeRate)
+	assert.Equal(t, 2, numOfRequests)
+
+	// the option is keyed by the base coin
+	feeRate, err = cache.GetFeeRate(context.Background(), CategoryOption, "BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.0003"), feeRate.TakerFeeRate)
+	assert.Equal(t, 3, numOfRequests)
+
+	feeRate, ok = cache.Get(CategoryLinear, "BTCUSDT")
+	assert.True(t, ok)
+	assert.Equal(t, "BTCUSDT", feeRate.Symbol)
+
+	feeRate, ok = cache.Get(CategoryOption, "BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "BTC", feeRate.BaseCoin)
+}
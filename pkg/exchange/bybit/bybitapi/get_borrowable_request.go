@@ -0,0 +1,52 @@
+package bybitapi
+
+import (
+	"context"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// Borrowable is the max quantity of the spot order on the side, with and without the borrowing of the spot margin
+// trading.
+type Borrowable struct {
+	Symbol string `json:"symbol"`
+	Side   Side   `json:"side"`
+	// MaxTradeQty and MaxTradeAmount are the max base quantity and the max quote amount of the margin order, including
+	// the borrowable amount.
+	MaxTradeQty    fixedpoint.Value `json:"maxTradeQty"`
+	MaxTradeAmount fixedpoint.Value `json:"maxTradeAmount"`
+	// SpotMaxTradeQty and SpotMaxTradeAmount are the max base quantity and the max quote amount of the order without
+	// the borrowing.
+	SpotMaxTradeQty    fixedpoint.Value `json:"spotMaxTradeQty"`
+	SpotMaxTradeAmount fixedpoint.Value `json:"spotMaxTradeAmount"`
+	// BorrowCoin is the coin borrowed by the order, the quote coin for the buy side and the base coin for the sell one.
+	BorrowCoin string `json:"borrowCoin"`
+}
+
+//go:generate GetRequest -url "/v5/order/spot-borrow-check" -type GetBorrowableRequest -responseDataType .Borrowable
+type GetBorrowableRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category Category `param:"category,query" validValues:"spot"`
+	symbol   string   `param:"symbol,query"`
+	side     Side     `param:"side,query" validValues:"Buy,Sell"`
+}
+
+// NewGetBorrowableRequest queries the borrowable quantity of the spot margin trading, it's only available to the
+// unified trading account.
+func (c *RestClient) NewGetBorrowableRequest() *GetBorrowableRequest {
+	return &GetBorrowableRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
+
+// QueryBorrowable returns the max quantity of the spot margin order of the symbol on the side.
+func (c *RestClient) QueryBorrowable(ctx context.Context, symbol string, side Side) (*Borrowable, error) {
+	return c.NewGetBorrowableRequest().Symbol(symbol).Side(side).Do(ctx)
+}
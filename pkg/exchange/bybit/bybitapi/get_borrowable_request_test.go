@@ -0,0 +1,45 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_QueryBorrowable(t *testing.T) {
+	client, err := NewClient()
+	require.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.GET("/v5/order/spot-borrow-check", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "spot", query.Get("category"))
+		assert.Equal(t, "BTCUSDT", query.Get("symbol"))
+		assert.Equal(t, "Buy", query.Get("side"))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"OK","result":{"symbol":"BTCUSDT","side":"Buy","maxTradeQty":"6.6065","maxTradeAmount":"218030.59","spotMaxTradeQty":"0.5","spotMaxTradeAmount":"16500","borrowCoin":"USDT"},"retExtInfo":{},"time":1698315480187}`), nil
+	})
+
+	borrowable, err := client.QueryBorrowable(context.Background(), "BTCUSDT", SideBuy)
+	require.NoError(t, err)
+	assert.Equal(t, &Borrowable{
+		Symbol:             "BTCUSDT",
+		Side:               SideBuy,
+		MaxTradeQty:        fixedpoint.MustNewFromString("6.6065"),
+		MaxTradeAmount:     fixedpoint.MustNewFromString("218030.59"),
+		SpotMaxTradeQty:    fixedpoint.MustNewFromString("0.5"),
+		SpotMaxTradeAmount: fixedpoint.MustNewFromString("16500"),
+		BorrowCoin:         "USDT",
+	}, borrowable)
+
+	_, err = client.QueryBorrowable(context.Background(), "BTCUSDT", Side("Long"))
+	assert.Error(t, err)
+}
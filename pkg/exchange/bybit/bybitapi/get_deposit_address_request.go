@@ -0,0 +1,39 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+type DepositAddress struct {
+	Coin string `json:"coin"`
+	// Chains contains all the chains of the coin if the chain is not specified.
+	Chains []DepositChainAddress `json:"chains"`
+}
+
+type DepositChainAddress struct {
+	ChainType      string `json:"chainType"`
+	AddressDeposit string `json:"addressDeposit"`
+	// TagDeposit is the tag or the memo of the address, it's empty if the chain doesn't need it.
+	TagDeposit string `json:"tagDeposit"`
+	Chain      string `json:"chain"`
+	// BatchReleaseLimit is the deposit limit for the batch release, -1 means no limit.
+	BatchReleaseLimit string `json:"batchReleaseLimit"`
+}
+
+//go:generate GetRequest -url "/v5/asset/deposit/query-address" -type GetDepositAddressRequest -responseDataType .DepositAddress
+type GetDepositAddressRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	coin string `param:"coin,query"`
+	// chainType returns all the chains of the coin if it's not specified.
+	chainType *string `param:"chainType,query"`
+}
+
+func (c *RestClient) NewGetDepositAddressRequest() *GetDepositAddressRequest {
+	return &GetDepositAddressRequest{
+		client: c,
+	}
+}
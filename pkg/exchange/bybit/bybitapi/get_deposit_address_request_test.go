@@ -0,0 +1,49 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestGetDepositAddressRequest(t *testing.T) {
+	client, err := NewClient()
+	require.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.GET("/v5/asset/deposit/query-address", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "USDT", query.Get("coin"))
+
+		if query.Get("chainType") == "TRX" {
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"success","result":{"coin":"USDT","chains":[{"chainType":"TRC20","addressDeposit":"T1234","tagDeposit":"","chain":"TRX","batchReleaseLimit":"-1"}]},"retExtInfo":{},"time":1700000000000}`), nil
+		}
+
+		// all the chains of the coin are returned without the chain type
+		_, ok := query["chainType"]
+		assert.False(t, ok)
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"success","result":{"coin":"USDT","chains":[{"chainType":"ERC20","addressDeposit":"0x1234","tagDeposit":"","chain":"ETH","batchReleaseLimit":"-1"},{"chainType":"TRC20","addressDeposit":"T1234","tagDeposit":"","chain":"TRX","batchReleaseLimit":"-1"},{"chainType":"TON","addressDeposit":"EQ1234","tagDeposit":"123456","chain":"TON","batchReleaseLimit":"1000"}]},"retExtInfo":{},"time":1700000000000}`), nil
+	})
+
+	address, err := client.NewGetDepositAddressRequest().Coin("USDT").Do(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "USDT", address.Coin)
+	assert.Equal(t, []DepositChainAddress{
+		{ChainType: "ERC20", AddressDeposit: "0x1234", Chain: "ETH", BatchReleaseLimit: "-1"},
+		{ChainType: "TRC20", AddressDeposit: "T1234", Chain: "TRX", BatchReleaseLimit: "-1"},
+		{ChainType: "TON", AddressDeposit: "EQ1234", TagDeposit: "123456", Chain: "TON", BatchReleaseLimit: "1000"},
+	}, address.Chains)
+
+	address, err = client.NewGetDepositAddressRequest().Coin("USDT").ChainType("TRX").Do(context.Background())
+	require.NoError(t, err)
+	require.Len(t, address.Chains, 1)
+	assert.Equal(t, "T1234", address.Chains[0].AddressDeposit)
+}
@@ -0,0 +1,107 @@
+package bybitapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// assetRecordsLimit is the max page size of the deposit and the withdrawal records.
+const assetRecordsLimit = 50
+
+type DepositStatus int
+
+const (
+	DepositStatusUnknown       DepositStatus = 0
+	DepositStatusToBeConfirmed DepositStatus = 1
+	DepositStatusProcessing    DepositStatus = 2
+	DepositStatusSuccess       DepositStatus = 3
+	DepositStatusFailed        DepositStatus = 4
+	// DepositStatusPendingToFund is pending to be credited to the funding pool.
+	DepositStatusPendingToFund DepositStatus = 10011
+	// DepositStatusCreditedToFund is credited to the funding pool successfully.
+	DepositStatusCreditedToFund DepositStatus = 10012
+)
+
+type DepositRecords struct {
+	Rows           []DepositRecord `json:"rows"`
+	NextPageCursor string          `json:"nextPageCursor"`
+}
+
+type DepositRecord struct {
+	Id         string           `json:"id"`
+	Coin       string           `json:"coin"`
+	Chain      string           `json:"chain"`
+	Amount     fixedpoint.Value `json:"amount"`
+	TxID       string           `json:"txID"`
+	Status     DepositStatus    `json:"status"`
+	ToAddress  string           `json:"toAddress"`
+	Tag        string           `json:"tag"`
+	DepositFee fixedpoint.Value `json:"depositFee"`
+	// SuccessAt is the time the deposit is credited.
+	SuccessAt     types.MillisecondTimestamp `json:"successAt"`
+	Confirmations string                     `json:"confirmations"`
+	TxIndex       string                     `json:"txIndex"`
+	BlockHash     string                     `json:"blockHash"`
+}
+
+//go:generate GetRequest -url "/v5/asset/deposit/query-record" -type GetDepositRecordsRequest -responseDataType .DepositRecords
+type GetDepositRecordsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	id   *string `param:"id,query"`
+	txID *string `param:"txID,query"`
+	coin *string `param:"coin,query"`
+
+	// startTime and endTime interval should be less than 30 days, the past 30 days are queried by default.
+	startTime *time.Time `param:"startTime,query,milliseconds"`
+	endTime   *time.Time `param:"endTime,query,milliseconds"`
+
+	// limit for data size per page. [1, 50]. Default: 50
+	limit *uint64 `param:"limit,query"`
+	// cursor uses the nextPageCursor token from the response to retrieve the next page of the result set
+	cursor *string `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetDepositRecordsRequest() *GetDepositRecordsRequest {
+	return &GetDepositRecordsRequest{
+		client: c,
+	}
+}
+
+// QueryDepositRecords queries all the deposit records between startTime and endTime by following the nextPageCursor.
+// The coin is optional, and the interval must be less than 30 days.
+func (c *RestClient) QueryDepositRecords(ctx context.Context, coin string, startTime, endTime time.Time) ([]DepositRecord, error) {
+	var records []DepositRecord
+	cursor := ""
+	for {
+		req := c.NewGetDepositRecordsRequest().
+			StartTime(startTime).
+			EndTime(endTime).
+			Limit(assetRecordsLimit)
+		if len(coin) > 0 {
+			req.Coin(coin)
+		}
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, res.Rows...)
+		if len(res.NextPageCursor) == 0 || len(res.Rows) == 0 {
+			return records, nil
+		}
+		cursor = res.NextPageCursor
+	}
+}
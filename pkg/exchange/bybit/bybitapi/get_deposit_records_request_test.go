@@ -0,0 +1,55 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_QueryDepositRecords(t *testing.T) {
+	client, err := NewClient()
+	require.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	startTime := time.UnixMilli(1700000000000)
+	endTime := startTime.Add(24 * time.Hour)
+
+	var cursors []string
+	transport.GET("/v5/asset/deposit/query-record", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "USDT", query.Get("coin"))
+		assert.Equal(t, "1700000000000", query.Get("startTime"))
+		assert.Equal(t, "1700086400000", query.Get("endTime"))
+		assert.Equal(t, "50", query.Get("limit"))
+
+		cursor := query.Get("cursor")
+		cursors = append(cursors, cursor)
+		if cursor == "" {
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"success","result":{"rows":[{"id":"1","coin":"USDT","chain":"ETH","amount":"100.5","txID":"0xabc","status":3,"toAddress":"0x1234","tag":"","depositFee":"","successAt":"1700000100000","confirmations":"65","txIndex":"1","blockHash":"0xdef"}],"nextPageCursor":"next"},"retExtInfo":{},"time":1700000200000}`), nil
+		}
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"success","result":{"rows":[{"id":"2","coin":"USDT","chain":"TRX","amount":"1","txID":"0x123","status":2,"toAddress":"T1234","successAt":"1700000300000"}],"nextPageCursor":""},"retExtInfo":{},"time":1700000400000}`), nil
+	})
+
+	records, err := client.QueryDepositRecords(context.Background(), "USDT", startTime, endTime)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "next"}, cursors)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "ETH", records[0].Chain)
+	assert.Equal(t, fixedpoint.MustNewFromString("100.5"), records[0].Amount)
+	assert.Equal(t, "0xabc", records[0].TxID)
+	assert.Equal(t, DepositStatusSuccess, records[0].Status)
+	assert.Equal(t, fixedpoint.Zero, records[0].DepositFee)
+	assert.Equal(t, int64(1700000100000), records[0].SuccessAt.Time().UnixMilli())
+	assert.Equal(t, DepositStatusProcessing, records[1].Status)
+}
@@ -0,0 +1,105 @@
+package bybitapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// executionListLimit is the max page size of the execution list.
+const executionListLimit = 100
+
+type ExecutionListResponse struct {
+	List           []Execution `json:"list"`
+	NextPageCursor string      `json:"nextPageCursor"`
+	Category       Category    `json:"category"`
+}
+
+type Execution struct {
+	Symbol      string    `json:"symbol"`
+	OrderId     string    `json:"orderId"`
+	OrderLinkId string    `json:"orderLinkId"`
+	Side        Side      `json:"side"`
+	OrderType   OrderType `json:"orderType"`
+	ExecId      string    `json:"execId"`
+	ExecType    ExecType  `json:"execType"`
+
+	OrderPrice fixedpoint.Value `json:"orderPrice"`
+	OrderQty   fixedpoint.Value `json:"orderQty"`
+	ExecPrice  fixedpoint.Value `json:"execPrice"`
+	ExecQty    fixedpoint.Value `json:"execQty"`
+	ExecValue  fixedpoint.Value `json:"execValue"`
+	ExecFee    fixedpoint.Value `json:"execFee"`
+	FeeRate    fixedpoint.Value `json:"feeRate"`
+	// FeeCurrency is the currency of the execution fee, it's only returned for the spot category.
+	FeeCurrency string `json:"feeCurrency"`
+	IsMaker     bool   `json:"isMaker"`
+
+	ExecTime types.MillisecondTimestamp `json:"execTime"`
+}
+
+//go:generate GetRequest -url "/v5/execution/list" -type GetExecutionListRequest -responseDataType .ExecutionListResponse
+type GetExecutionListRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category Category `param:"category,query" validValues:"spot,linear,inverse"`
+	symbol   *string  `param:"symbol,query"`
+	orderId  *string  `param:"orderId,query"`
+
+	// startTime and endTime must be within 7 days, the last 24 hours are queried if neither is passed.
+	startTime *time.Time `param:"startTime,query,milliseconds"`
+	endTime   *time.Time `param:"endTime,query,milliseconds"`
+
+	// limit for data size per page. [1, 100]. Default: 50
+	limit *uint64 `param:"limit,query"`
+	// cursor uses the nextPageCursor token from the response to retrieve the next page of the result set
+	cursor *string `param:"cursor,query"`
+}
+
+// NewGetExecutionListRequest queries the executions of the account in descending order by execTime. Bybit only
+// retains the executions of a limited period (two years for the unified account), and the time range of each request
+// must be within 7 days.
+func (c *RestClient) NewGetExecutionListRequest() *GetExecutionListRequest {
+	return &GetExecutionListRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
+
+// QueryExecutions queries all the executions of the symbol between startTime and endTime by following the
+// nextPageCursor, the time range must be within 7 days. The executions are returned in descending order by execTime.
+func (c *RestClient) QueryExecutions(ctx context.Context, category Category, symbol string, startTime, endTime time.Time) ([]Execution, error) {
+	var executions []Execution
+	cursor := ""
+	for {
+		req := c.NewGetExecutionListRequest().
+			Category(category).
+			StartTime(startTime).
+			EndTime(endTime).
+			Limit(executionListLimit)
+		if len(symbol) > 0 {
+			req.Symbol(symbol)
+		}
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		executions = append(executions, res.List...)
+		if len(res.NextPageCursor) == 0 || len(res.List) == 0 {
+			return executions, nil
+		}
+		cursor = res.NextPageCursor
+	}
+}
@@ -14,7 +14,10 @@ type FeeRates struct {
 }
 
 type FeeRate struct {
-	Symbol       string           `json:"symbol"`
+	// Symbol is empty for the option, the fee rates of the option are per base coin.
+	Symbol string `json:"symbol"`
+	// BaseCoin is only set for the option.
+	BaseCoin     string           `json:"baseCoin"`
 	TakerFeeRate fixedpoint.Value `json:"takerFeeRate"`
 	MakerFeeRate fixedpoint.Value `json:"makerFeeRate"`
 }
@@ -23,7 +26,7 @@ type FeeRate struct {
 type GetFeeRatesRequest struct {
 	client requestgen.AuthenticatedAPIClient
 
-	category Category `param:"category,query" validValues:"spot"`
+	category Category `param:"category,query" validValues:"spot,linear,inverse,option"`
 	// Symbol name. Valid for linear, inverse, spot
 	symbol *string `param:"symbol,query"`
 	// Base coin. SOL, BTC, ETH. Valid for option
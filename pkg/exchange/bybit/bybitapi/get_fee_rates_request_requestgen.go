@@ -34,7 +34,7 @@ func (g *GetFeeRatesRequest) GetQueryParameters() (url.Values, error) {
 
 	// TEMPLATE check-valid-values
 	switch category {
-	case "spot":
+	case "spot", "linear", "inverse", "option":
 		params["category"] = category
 
 	default:
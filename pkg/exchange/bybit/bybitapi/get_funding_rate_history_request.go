@@ -0,0 +1,110 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// fundingRateHistoryLimit is the max number of the funding rates per request.
+const fundingRateHistoryLimit = 200
+
+type FundingRateHistory struct {
+	Category Category `json:"category"`
+	// List is sorted by the funding rate timestamp in descending order.
+	List []FundingRate `json:"list"`
+}
+
+type FundingRate struct {
+	Symbol               string                     `json:"symbol"`
+	FundingRate          fixedpoint.Value           `json:"fundingRate"`
+	FundingRateTimestamp types.MillisecondTimestamp `json:"fundingRateTimestamp"`
+}
+
+//go:generate GetRequest -url "/v5/market/funding/history" -type GetFundingRateHistoryRequest -responseDataType .FundingRateHistory
+type GetFundingRateHistoryRequest struct {
+	client requestgen.APIClient
+
+	category Category `param:"category,query" validValues:"linear,inverse"`
+	symbol   string   `param:"symbol,query"`
+	// startTime can't be passed without the endTime.
+	startTime *time.Time `param:"startTime,query,milliseconds"`
+	endTime   *time.Time `param:"endTime,query,milliseconds"`
+	// Limit for data size per page. [1, 200]. Default: 200
+	limit *uint64 `param:"limit,query"`
+}
+
+func (c *RestClient) NewGetFundingRateHistoryRequest() *GetFundingRateHistoryRequest {
+	return &GetFundingRateHistoryRequest{
+		client:   c,
+		category: CategoryLinear,
+	}
+}
+
+// Validate checks the category has the funding rates, the generated code only rejects the unknown categories.
+func (p *GetFundingRateHistoryRequest) Validate() error {
+	if err := p.category.Validate(); err != nil {
+		return err
+	}
+
+	if !p.category.SupportsFunding() {
+		return fmt.Errorf("the funding rate is not supported by the %s category", p.category)
+	}
+	return nil
+}
+
+// QueryFundingRateHistory queries the funding rates between the start time and the end time, and returns them in
+// ascending order. Bybit returns at most 200 funding rates per request from the newest one, so it pages backward by
+// moving the end time before the oldest funding rate of the last page.
+func (c *RestClient) QueryFundingRateHistory(ctx context.Context, category Category, symbol string, startTime, endTime time.Time) ([]FundingRate, error) {
+	var rates []FundingRate
+	seen := map[int64]struct{}{}
+
+	for !endTime.Before(startTime) {
+		req := c.NewGetFundingRateHistoryRequest().
+			Category(category).
+			Symbol(symbol).
+			StartTime(startTime).
+			EndTime(endTime).
+			Limit(fundingRateHistoryLimit)
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rate := range res.List {
+			ts := rate.FundingRateTimestamp.Time().UnixMilli()
+			if _, ok := seen[ts]; ok {
+				continue
+			}
+
+			seen[ts] = struct{}{}
+			rates = append(rates, rate)
+		}
+
+		if len(res.List) < fundingRateHistoryLimit {
+			break
+		}
+
+		endTime = res.List[len(res.List)-1].FundingRateTimestamp.Time().Add(-time.Millisecond)
+	}
+
+	// reverse to the ascending order
+	for i, j := 0, len(rates)-1; i < j; i, j = i+1, j-1 {
+		rates[i], rates[j] = rates[j], rates[i]
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,93 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestRestClient_QueryFundingRateHistory(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	// 450 funding rates, 8 hours apart
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	var allRates []FundingRate
+	for i := 0; i < 450; i++ {
+		allRates = append(allRates, FundingRate{
+			Symbol:               "BTCUSDT",
+			FundingRate:          fixedpoint.NewFromFloat(0.0001),
+			FundingRateTimestamp: types.MillisecondTimestamp(startTime.Add(time.Duration(i) * 8 * time.Hour)),
+		})
+	}
+	endTime := allRates[len(allRates)-1].FundingRateTimestamp.Time()
+
+	numOfRequests := 0
+	transport.GET("/v5/market/funding/history", func(req *http.Request) (*http.Response, error) {
+		numOfRequests++
+
+		query := req.URL.Query()
+		assert.Equal(t, "linear", query.Get("category"))
+		assert.Equal(t, "BTCUSDT", query.Get("symbol"))
+
+		start, err := strconv.ParseInt(query.Get("startTime"), 10, 64)
+		assert.NoError(t, err)
+		end, err := strconv.ParseInt(query.Get("endTime"), 10, 64)
+		assert.NoError(t, err)
+
+		// newest first
+		var list []map[string]string
+		for i := len(allRates) - 1; i >= 0 && len(list) < fundingRateHistoryLimit; i-- {
+			ts := allRates[i].FundingRateTimestamp.Time().UnixMilli()
+			if ts >= start && ts <= end {
+				list = append(list, map[string]string{
+					"symbol":               allRates[i].Symbol,
+					"fundingRate":          allRates[i].FundingRate.String(),
+					"fundingRateTimestamp": strconv.FormatInt(ts, 10),
+				})
+			}
+		}
+
+		return httptesting.BuildResponseJson(http.StatusOK, map[string]interface{}{
+			"retCode": 0,
+			"retMsg":  "OK",
+			"result": map[string]interface{}{
+				"category": CategoryLinear,
+				"list":     list,
+			},
+			"time": time.Now().UnixMilli(),
+		}), nil
+	})
+
+	rates, err := client.QueryFundingRateHistory(context.Background(), CategoryLinear, "BTCUSDT", startTime, endTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, numOfRequests)
+	if assert.Len(t, rates, len(allRates)) {
+		for i := range rates {
+			assert.True(t, allRates[i].FundingRateTimestamp.Time().Equal(rates[i].FundingRateTimestamp.Time()))
+		}
+	}
+}
+
+func TestRestClient_QueryFundingRateHistory_unsupportedCategory(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+
+	// the request is rejected before sending
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	_, err = client.QueryFundingRateHistory(context.Background(), CategorySpot, "BTCUSDT", time.Now().Add(-time.Hour), time.Now())
+	assert.ErrorContains(t, err, "the funding rate is not supported by the spot category")
+}
@@ -1,6 +1,8 @@
 package bybitapi
 
 import (
+	"context"
+
 	"github.com/c9s/requestgen"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -12,34 +14,82 @@ import (
 type InstrumentsInfo struct {
 	Category Category     `json:"category"`
 	List     []Instrument `json:"list"`
+	// NextPageCursor is the cursor of the next page, it's empty on the last page. The spot category isn't paginated.
+	NextPageCursor string `json:"nextPageCursor"`
 }
 
 type Instrument struct {
-	Symbol        string `json:"symbol"`
-	BaseCoin      string `json:"baseCoin"`
-	QuoteCoin     string `json:"quoteCoin"`
-	Innovation    string `json:"innovation"`
-	Status        Status `json:"status"`
-	MarginTrading string `json:"marginTrading"`
-	LotSizeFilter struct {
-		BasePrecision  fixedpoint.Value `json:"basePrecision"`
-		QuotePrecision fixedpoint.Value `json:"quotePrecision"`
-		MinOrderQty    fixedpoint.Value `json:"minOrderQty"`
-		MaxOrderQty    fixedpoint.Value `json:"maxOrderQty"`
-		MinOrderAmt    fixedpoint.Value `json:"minOrderAmt"`
-		MaxOrderAmt    fixedpoint.Value `json:"maxOrderAmt"`
-	} `json:"lotSizeFilter"`
-
-	PriceFilter struct {
-		TickSize fixedpoint.Value `json:"tickSize"`
-	} `json:"priceFilter"`
+	Symbol        string        `json:"symbol"`
+	BaseCoin      string        `json:"baseCoin"`
+	QuoteCoin     string        `json:"quoteCoin"`
+	Innovation    string        `json:"innovation"`
+	Status        Status        `json:"status"`
+	MarginTrading string        `json:"marginTrading"`
+	LotSizeFilter LotSizeFilter `json:"lotSizeFilter"`
+	PriceFilter   PriceFilter   `json:"priceFilter"`
+}
+
+type LotSizeFilter struct {
+	BasePrecision  fixedpoint.Value `json:"basePrecision"`
+	QuotePrecision fixedpoint.Value `json:"quotePrecision"`
+	MinOrderQty    fixedpoint.Value `json:"minOrderQty"`
+	MaxOrderQty    fixedpoint.Value `json:"maxOrderQty"`
+	MinOrderAmt    fixedpoint.Value `json:"minOrderAmt"`
+	MaxOrderAmt    fixedpoint.Value `json:"maxOrderAmt"`
+
+	// QtyStep is the step to increase/reduce order quantity. Valid for linear, inverse, option
+	QtyStep fixedpoint.Value `json:"qtyStep"`
+	// MinNotionalValue is the minimum notional value. Valid for linear
+	MinNotionalValue fixedpoint.Value `json:"minNotionalValue"`
+}
+
+type PriceFilter struct {
+	TickSize fixedpoint.Value `json:"tickSize"`
+
+	// MinPrice and MaxPrice are not returned for spot.
+	MinPrice fixedpoint.Value `json:"minPrice"`
+	MaxPrice fixedpoint.Value `json:"maxPrice"`
+}
+
+// QuantityStep returns the step of the order quantity. The spot category uses the base precision as the step.
+func (i Instrument) QuantityStep() fixedpoint.Value {
+	if !i.LotSizeFilter.QtyStep.IsZero() {
+		return i.LotSizeFilter.QtyStep
+	}
+	return i.LotSizeFilter.BasePrecision
+}
+
+// MinNotional returns the minimum order value. The spot category uses the min order amount as the min notional.
+func (i Instrument) MinNotional() fixedpoint.Value {
+	if !i.LotSizeFilter.MinNotionalValue.IsZero() {
+		return i.LotSizeFilter.MinNotionalValue
+	}
+	return i.LotSizeFilter.MinOrderAmt
+}
+
+// RoundPrice rounds down the price to the multiple of the tick size.
+func (i Instrument) RoundPrice(price fixedpoint.Value) fixedpoint.Value {
+	return roundToStep(price, i.PriceFilter.TickSize, fixedpoint.Down)
+}
+
+// RoundQuantity rounds down the quantity to the multiple of the quantity step.
+func (i Instrument) RoundQuantity(qty fixedpoint.Value) fixedpoint.Value {
+	return roundToStep(qty, i.QuantityStep(), fixedpoint.Down)
+}
+
+func roundToStep(v, step fixedpoint.Value, mode fixedpoint.RoundingMode) fixedpoint.Value {
+	if step.Sign() <= 0 {
+		return v
+	}
+	// round the product with the precision of the step to avoid the floating point error.
+	return v.Div(step).Round(0, mode).Mul(step).Round(step.NumFractionalDigits(), fixedpoint.HalfUp)
 }
 
 //go:generate GetRequest -url "/v5/market/instruments-info" -type GetInstrumentsInfoRequest -responseDataType .InstrumentsInfo
 type GetInstrumentsInfoRequest struct {
 	client requestgen.APIClient
 
-	category Category `param:"category,query" validValues:"spot"`
+	category Category `param:"category,query" validValues:"spot,linear,inverse"`
 	symbol   *string  `param:"symbol,query"`
 
 	// limit is invalid if category spot.
@@ -54,3 +104,30 @@ func (c *RestClient) NewGetInstrumentsInfoRequest() *GetInstrumentsInfoRequest {
 		category: CategorySpot,
 	}
 }
+
+// QueryInstruments queries all the instruments of the category by following the nextPageCursor, the spot category
+// isn't paginated.
+func (c *RestClient) QueryInstruments(ctx context.Context, category Category) ([]Instrument, error) {
+	var instruments []Instrument
+	cursor := ""
+	for {
+		req := c.NewGetInstrumentsInfoRequest().Category(category)
+		if category != CategorySpot {
+			req.Limit(maxInstrumentsPageSize)
+		}
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+
+		info, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		instruments = append(instruments, info.List...)
+		if len(info.NextPageCursor) == 0 || info.NextPageCursor == cursor {
+			return instruments, nil
+		}
+		cursor = info.NextPageCursor
+	}
+}
@@ -39,7 +39,7 @@ func (g *GetInstrumentsInfoRequest) GetQueryParameters() (url.Values, error) {
 
 	// TEMPLATE check-valid-values
 	switch category {
-	case "spot":
+	case "spot", "linear", "inverse":
 		params["category"] = category
 
 	default:
@@ -51,7 +51,7 @@ func (g *GetKLinesRequest) GetQueryParameters() (url.Values, error) {
 
 	// TEMPLATE check-valid-values
 	switch category {
-	case "spot":
+	case "spot", "linear", "inverse":
 		params["category"] = category
 
 	default:
@@ -0,0 +1,116 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// longShortRatioLimit is the max page size of the long short ratios.
+const longShortRatioLimit = 500
+
+type LongShortRatioResponse struct {
+	// List is sorted by the timestamp in descending order.
+	List           []LongShortRatio `json:"list"`
+	NextPageCursor string           `json:"nextPageCursor"`
+}
+
+// LongShortRatio is the ratios of the users with the net long and the net short positions.
+type LongShortRatio struct {
+	Symbol    string                     `json:"symbol"`
+	BuyRatio  fixedpoint.Value           `json:"buyRatio"`
+	SellRatio fixedpoint.Value           `json:"sellRatio"`
+	Timestamp types.MillisecondTimestamp `json:"timestamp"`
+}
+
+// Ratio returns the buy ratio divided by the sell ratio, it's zero if the sell ratio is zero.
+func (r LongShortRatio) Ratio() fixedpoint.Value {
+	if r.SellRatio.IsZero() {
+		return fixedpoint.Zero
+	}
+	return r.BuyRatio.Div(r.SellRatio)
+}
+
+//go:generate GetRequest -url "/v5/market/account-ratio" -type GetLongShortRatioRequest -responseDataType .LongShortRatioResponse
+type GetLongShortRatioRequest struct {
+	client requestgen.APIClient
+
+	category  Category       `param:"category,query" validValues:"linear,inverse"`
+	symbol    string         `param:"symbol,query"`
+	period    IntervalPeriod `param:"period,query"`
+	startTime *time.Time     `param:"startTime,query,milliseconds"`
+	endTime   *time.Time     `param:"endTime,query,milliseconds"`
+	// Limit for data size per page. [1, 500]. Default: 50
+	limit *uint64 `param:"limit,query"`
+	// cursor uses the nextPageCursor token from the response to retrieve the next page of the result set
+	cursor *string `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetLongShortRatioRequest() *GetLongShortRatioRequest {
+	return &GetLongShortRatioRequest{
+		client:   c,
+		category: CategoryLinear,
+	}
+}
+
+// Validate checks the category has the long short ratio and the period is supported, the generated code only rejects
+// the unknown categories.
+func (p *GetLongShortRatioRequest) Validate() error {
+	if err := p.category.Validate(); err != nil {
+		return err
+	}
+
+	if !p.category.SupportsMarketStats() {
+		return fmt.Errorf("the long short ratio is not supported by the %s category", p.category)
+	}
+
+	return p.period.Validate()
+}
+
+// QueryLongShortRatio queries the long short ratios of the symbol between the start time and the end time by following
+// the nextPageCursor, and returns them in ascending order like the k lines.
+func (c *RestClient) QueryLongShortRatio(ctx context.Context, category Category, symbol string, period IntervalPeriod, startTime, endTime time.Time) ([]LongShortRatio, error) {
+	var list []LongShortRatio
+	cursor := ""
+	for {
+		req := c.NewGetLongShortRatioRequest().
+			Category(category).
+			Symbol(symbol).
+			Period(period).
+			StartTime(startTime).
+			EndTime(endTime).
+			Limit(longShortRatioLimit)
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, res.List...)
+		if len(res.NextPageCursor) == 0 || len(res.List) == 0 {
+			break
+		}
+		cursor = res.NextPageCursor
+	}
+
+	// reverse to the ascending order
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+
+	return list, nil
+}
@@ -0,0 +1,57 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_QueryLongShortRatio(t *testing.T) {
+	client, err := NewClient()
+	require.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	startTime := time.UnixMilli(1695700000000)
+	endTime := time.UnixMilli(1695800000000)
+
+	var cursors []string
+	transport.GET("/v5/market/account-ratio", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "inverse", query.Get("category"))
+		assert.Equal(t, "BTCUSD", query.Get("symbol"))
+		assert.Equal(t, "1d", query.Get("period"))
+		assert.Equal(t, "500", query.Get("limit"))
+
+		cursor := query.Get("cursor")
+		cursors = append(cursors, cursor)
+		if cursor == "" {
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"OK","result":{"list":[{"symbol":"BTCUSD","buyRatio":"0.6","sellRatio":"0.4","timestamp":"1695772800000"}],"nextPageCursor":"next"},"retExtInfo":{},"time":1695782821828}`), nil
+		}
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"OK","result":{"list":[{"symbol":"BTCUSD","buyRatio":"0.5","sellRatio":"0.5","timestamp":"1695686400000"}],"nextPageCursor":""},"retExtInfo":{},"time":1695782821828}`), nil
+	})
+
+	list, err := client.QueryLongShortRatio(context.Background(), CategoryInverse, "BTCUSD", IntervalPeriod1d, startTime, endTime)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "next"}, cursors)
+
+	// in ascending order
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(1695686400000), list[0].Timestamp.Time().UnixMilli())
+	assert.Equal(t, fixedpoint.One, list[0].Ratio())
+	assert.Equal(t, fixedpoint.MustNewFromString("0.6"), list[1].BuyRatio)
+	assert.InDelta(t, 1.5, list[1].Ratio().Float64(), 1e-6)
+
+	assert.Equal(t, fixedpoint.Zero, LongShortRatio{BuyRatio: fixedpoint.One}.Ratio())
+
+	_, err = client.QueryLongShortRatio(context.Background(), CategoryOption, "BTCUSD", IntervalPeriod1d, startTime, endTime)
+	assert.ErrorContains(t, err, "the long short ratio is not supported by the option category")
+}
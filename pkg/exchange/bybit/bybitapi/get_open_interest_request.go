@@ -0,0 +1,108 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// openInterestLimit is the max page size of the open interests.
+const openInterestLimit = 200
+
+type OpenInterestResponse struct {
+	Category Category `json:"category"`
+	Symbol   string   `json:"symbol"`
+	// List is sorted by the timestamp in descending order.
+	List           []OpenInterest `json:"list"`
+	NextPageCursor string         `json:"nextPageCursor"`
+}
+
+type OpenInterest struct {
+	// OpenInterest is in the base coin for the linear contracts, and in the quote coin for the inverse ones.
+	OpenInterest fixedpoint.Value           `json:"openInterest"`
+	Timestamp    types.MillisecondTimestamp `json:"timestamp"`
+}
+
+//go:generate GetRequest -url "/v5/market/open-interest" -type GetOpenInterestRequest -responseDataType .OpenInterestResponse
+type GetOpenInterestRequest struct {
+	client requestgen.APIClient
+
+	category     Category       `param:"category,query" validValues:"linear,inverse"`
+	symbol       string         `param:"symbol,query"`
+	intervalTime IntervalPeriod `param:"intervalTime,query"`
+	startTime    *time.Time     `param:"startTime,query,milliseconds"`
+	endTime      *time.Time     `param:"endTime,query,milliseconds"`
+	// Limit for data size per page. [1, 200]. Default: 50
+	limit *uint64 `param:"limit,query"`
+	// cursor uses the nextPageCursor token from the response to retrieve the next page of the result set
+	cursor *string `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetOpenInterestRequest() *GetOpenInterestRequest {
+	return &GetOpenInterestRequest{
+		client:   c,
+		category: CategoryLinear,
+	}
+}
+
+// Validate checks the category has the open interest and the interval period is supported, the generated code only
+// rejects the unknown categories.
+func (p *GetOpenInterestRequest) Validate() error {
+	if err := p.category.Validate(); err != nil {
+		return err
+	}
+
+	if !p.category.SupportsMarketStats() {
+		return fmt.Errorf("the open interest is not supported by the %s category", p.category)
+	}
+
+	return p.intervalTime.Validate()
+}
+
+// QueryOpenInterest queries the open interests of the symbol between the start time and the end time by following the
+// nextPageCursor, and returns them in ascending order like the k lines.
+func (c *RestClient) QueryOpenInterest(ctx context.Context, category Category, symbol string, period IntervalPeriod, startTime, endTime time.Time) ([]OpenInterest, error) {
+	var list []OpenInterest
+	cursor := ""
+	for {
+		req := c.NewGetOpenInterestRequest().
+			Category(category).
+			Symbol(symbol).
+			IntervalTime(period).
+			StartTime(startTime).
+			EndTime(endTime).
+			Limit(openInterestLimit)
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, res.List...)
+		if len(res.NextPageCursor) == 0 || len(res.List) == 0 {
+			break
+		}
+		cursor = res.NextPageCursor
+	}
+
+	// reverse to the ascending order
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+
+	return list, nil
+}
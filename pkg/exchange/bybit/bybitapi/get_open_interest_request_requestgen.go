@@ -0,0 +1,268 @@
+// Code generated by "requestgen -method GET -responseType .APIResponse -responseDataField Result -url /v5/market/open-interest -type GetOpenInterestRequest -responseDataType .OpenInterestResponse"; DO NOT EDIT.
+
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+func (p *GetOpenInterestRequest) Category(category Category) *GetOpenInterestRequest {
+	p.category = category
+	return p
+}
+
+func (p *GetOpenInterestRequest) Symbol(symbol string) *GetOpenInterestRequest {
+	p.symbol = symbol
+	return p
+}
+
+func (p *GetOpenInterestRequest) IntervalTime(intervalTime IntervalPeriod) *GetOpenInterestRequest {
+	p.intervalTime = intervalTime
+	return p
+}
+
+func (p *GetOpenInterestRequest) StartTime(startTime time.Time) *GetOpenInterestRequest {
+	p.startTime = &startTime
+	return p
+}
+
+func (p *GetOpenInterestRequest) EndTime(endTime time.Time) *GetOpenInterestRequest {
+	p.endTime = &endTime
+	return p
+}
+
+func (p *GetOpenInterestRequest) Limit(limit uint64) *GetOpenInterestRequest {
+	p.limit = &limit
+	return p
+}
+
+func (p *GetOpenInterestRequest) Cursor(cursor string) *GetOpenInterestRequest {
+	p.cursor = &cursor
+	return p
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (p *GetOpenInterestRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+	// check category field -> json key category
+	category := p.category
+
+	// TEMPLATE check-valid-values
+	switch category {
+	case "linear", "inverse":
+		params["category"] = category
+
+	default:
+		return nil, fmt.Errorf("category value %v is invalid", category)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of category
+	params["category"] = category
+	// check symbol field -> json key symbol
+	symbol := p.symbol
+
+	// assign parameter of symbol
+	params["symbol"] = symbol
+	// check intervalTime field -> json key intervalTime
+	intervalTime := p.intervalTime
+
+	// TEMPLATE check-valid-values
+	switch intervalTime {
+	case IntervalPeriod5m, IntervalPeriod15m, IntervalPeriod30m, IntervalPeriod1h, IntervalPeriod4h, IntervalPeriod1d:
+		params["intervalTime"] = intervalTime
+
+	default:
+		return nil, fmt.Errorf("intervalTime value %v is invalid", intervalTime)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of intervalTime
+	params["intervalTime"] = intervalTime
+	// check startTime field -> json key startTime
+	if p.startTime != nil {
+		startTime := *p.startTime
+
+		// assign parameter of startTime
+		// convert time.Time to milliseconds time stamp
+		params["startTime"] = strconv.FormatInt(startTime.UnixNano()/int64(time.Millisecond), 10)
+	} else {
+	}
+	// check endTime field -> json key endTime
+	if p.endTime != nil {
+		endTime := *p.endTime
+
+		// assign parameter of endTime
+		// convert time.Time to milliseconds time stamp
+		params["endTime"] = strconv.FormatInt(endTime.UnixNano()/int64(time.Millisecond), 10)
+	} else {
+	}
+	// check limit field -> json key limit
+	if p.limit != nil {
+		limit := *p.limit
+
+		// assign parameter of limit
+		params["limit"] = limit
+	} else {
+	}
+	// check cursor field -> json key cursor
+	if p.cursor != nil {
+		cursor := *p.cursor
+
+		// assign parameter of cursor
+		params["cursor"] = cursor
+	} else {
+	}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (p *GetOpenInterestRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (p *GetOpenInterestRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := p.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if p.isVarSlice(_v) {
+			p.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (p *GetOpenInterestRequest) GetParametersJSON() ([]byte, error) {
+	params, err := p.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (p *GetOpenInterestRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (p *GetOpenInterestRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (p *GetOpenInterestRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (p *GetOpenInterestRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (p *GetOpenInterestRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := p.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+// GetPath returns the request path of the API
+func (p *GetOpenInterestRequest) GetPath() string {
+	return "/v5/market/open-interest"
+}
+
+// Do generates the request object and send the request object to the API endpoint
+func (p *GetOpenInterestRequest) Do(ctx context.Context) (*OpenInterestResponse, error) {
+
+	// no body params
+	var params interface{}
+	query, err := p.GetQueryParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	var apiURL string
+
+	apiURL = p.GetPath()
+
+	req, err := p.client.NewRequest(ctx, "GET", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := p.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	type responseValidator interface {
+		Validate() error
+	}
+	validator, ok := interface{}(apiResponse).(responseValidator)
+	if ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	var data OpenInterestResponse
+	if err := json.Unmarshal(apiResponse.Result, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,61 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_QueryOpenInterest(t *testing.T) {
+	client, err := NewClient()
+	require.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	startTime := time.UnixMilli(1669500000000)
+	endTime := time.UnixMilli(1669600000000)
+
+	var cursors []string
+	transport.GET("/v5/market/open-interest", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "linear", query.Get("category"))
+		assert.Equal(t, "BTCUSDT", query.Get("symbol"))
+		assert.Equal(t, "1h", query.Get("intervalTime"))
+		assert.Equal(t, "1669500000000", query.Get("startTime"))
+		assert.Equal(t, "1669600000000", query.Get("endTime"))
+		assert.Equal(t, "200", query.Get("limit"))
+
+		cursor := query.Get("cursor")
+		cursors = append(cursors, cursor)
+		// newest first
+		if cursor == "" {
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"OK","result":{"symbol":"BTCUSDT","category":"linear","list":[{"openInterest":"461134384.00000000","timestamp":"1669579200000"},{"openInterest":"461134292.00000000","timestamp":"1669575600000"}],"nextPageCursor":"next"},"retExtInfo":{},"time":1672053548579}`), nil
+		}
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"OK","result":{"symbol":"BTCUSDT","category":"linear","list":[{"openInterest":"461134000.00000000","timestamp":"1669572000000"}],"nextPageCursor":""},"retExtInfo":{},"time":1672053548579}`), nil
+	})
+
+	list, err := client.QueryOpenInterest(context.Background(), CategoryLinear, "BTCUSDT", IntervalPeriod1h, startTime, endTime)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "next"}, cursors)
+
+	// in ascending order
+	require.Len(t, list, 3)
+	assert.Equal(t, int64(1669572000000), list[0].Timestamp.Time().UnixMilli())
+	assert.Equal(t, fixedpoint.MustNewFromString("461134000"), list[0].OpenInterest)
+	assert.Equal(t, int64(1669579200000), list[2].Timestamp.Time().UnixMilli())
+
+	_, err = client.QueryOpenInterest(context.Background(), CategorySpot, "BTCUSDT", IntervalPeriod1h, startTime, endTime)
+	assert.ErrorContains(t, err, "the open interest is not supported by the spot category")
+
+	_, err = client.QueryOpenInterest(context.Background(), CategoryLinear, "BTCUSDT", IntervalPeriod("1m"), startTime, endTime)
+	assert.ErrorContains(t, err, "unknown interval period")
+	assert.Len(t, cursors, 2)
+}
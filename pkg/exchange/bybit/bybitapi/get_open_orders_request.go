@@ -1,6 +1,8 @@
 package bybitapi
 
 import (
+	"context"
+
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/requestgen"
@@ -57,7 +59,7 @@ type Order struct {
 	RejectReason       string           `json:"rejectReason"`
 	LeavesQty          fixedpoint.Value `json:"leavesQty"`
 	LeavesValue        fixedpoint.Value `json:"leavesValue"`
-	StopOrderType      string           `json:"stopOrderType"`
+	StopOrderType      StopOrderType    `json:"stopOrderType"`
 	OrderIv            string           `json:"orderIv"`
 	TriggerPrice       fixedpoint.Value `json:"triggerPrice"`
 	TakeProfit         fixedpoint.Value `json:"takeProfit"`
@@ -65,11 +67,11 @@ type Order struct {
 	TpTriggerBy        string           `json:"tpTriggerBy"`
 	SlTriggerBy        string           `json:"slTriggerBy"`
 	TriggerDirection   int              `json:"triggerDirection"`
-	TriggerBy          string           `json:"triggerBy"`
+	TriggerBy          TriggerBy        `json:"triggerBy"`
 	LastPriceOnCreated string           `json:"lastPriceOnCreated"`
 	ReduceOnly         bool             `json:"reduceOnly"`
 	CloseOnTrigger     bool             `json:"closeOnTrigger"`
-	SmpType            string           `json:"smpType"`
+	SmpType            SmpType          `json:"smpType"`
 	SmpGroup           int              `json:"smpGroup"`
 	SmpOrderId         string           `json:"smpOrderId"`
 	TpslMode           string           `json:"tpslMode"`
@@ -78,11 +80,38 @@ type Order struct {
 	PlaceType          string           `json:"placeType"`
 }
 
+// IsConditional returns true if the order is placed with the trigger price.
+func (o Order) IsConditional() bool {
+	return o.TriggerPrice.Sign() > 0
+}
+
+// IsCancelledBySmp returns true if the order is cancelled by the self match prevention, the order it would match is
+// SmpOrderId.
+func (o Order) IsCancelledBySmp() bool {
+	return o.CancelType == CancelTypeCancelBySmp
+}
+
+// IsTriggered returns true if the conditional order has been triggered.
+func (o Order) IsTriggered() bool {
+	if !o.IsConditional() {
+		return false
+	}
+
+	switch o.OrderStatus {
+	case OrderStatusUntriggered, OrderStatusDeactivated:
+		return false
+	}
+	return true
+}
+
+// openOrdersLimit is the max page size of the open orders.
+const openOrdersLimit = 50
+
 //go:generate GetRequest -url "/v5/order/realtime" -type GetOpenOrdersRequest -responseDataType .OrdersResponse
 type GetOpenOrdersRequest struct {
 	client requestgen.AuthenticatedAPIClient
 
-	category    Category  `param:"category,query" validValues:"spot"`
+	category    Category  `param:"category,query" validValues:"spot,linear,inverse"`
 	symbol      *string   `param:"symbol,query"`
 	baseCoin    *string   `param:"baseCoin,query"`
 	settleCoin  *string   `param:"settleCoin,query"`
@@ -102,3 +131,30 @@ func (c *RestClient) NewGetOpenOrderRequest() *GetOpenOrdersRequest {
 		category: CategorySpot,
 	}
 }
+
+// QueryAllOpenOrders queries all the open orders of the category by following the nextPageCursor. The symbol is
+// optional, but the linear and inverse categories require it.
+func (c *RestClient) QueryAllOpenOrders(ctx context.Context, category Category, symbol string) ([]Order, error) {
+	var orders []Order
+	cursor := ""
+	for {
+		req := c.NewGetOpenOrderRequest().Category(category).Limit(openOrdersLimit)
+		if len(symbol) > 0 {
+			req.Symbol(symbol)
+		}
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, res.List...)
+		if len(res.NextPageCursor) == 0 || len(res.List) == 0 {
+			return orders, nil
+		}
+		cursor = res.NextPageCursor
+	}
+}
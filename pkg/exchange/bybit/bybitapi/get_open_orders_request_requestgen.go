@@ -69,7 +69,7 @@ func (g *GetOpenOrdersRequest) GetQueryParameters() (url.Values, error) {
 
 	// TEMPLATE check-valid-values
 	switch category {
-	case "spot":
+	case "spot", "linear", "inverse":
 		params["category"] = category
 
 	default:
@@ -0,0 +1,39 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_QueryAllOpenOrders(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	pages := map[string]string{
+		"":      `{"retCode": 0, "retMsg": "OK", "result": {"category": "linear", "nextPageCursor": "page2", "list": [{"orderId": "1", "symbol": "BTCUSDT"}]}, "retExtInfo": {}, "time": 1700000000000}`,
+		"page2": `{"retCode": 0, "retMsg": "OK", "result": {"category": "linear", "nextPageCursor": "", "list": [{"orderId": "2", "symbol": "BTCUSDT"}]}, "retExtInfo": {}, "time": 1700000000000}`,
+	}
+
+	transport.GET("/v5/order/realtime", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "linear", query.Get("category"))
+		assert.Equal(t, "BTCUSDT", query.Get("symbol"))
+		return httptesting.BuildResponseString(http.StatusOK, pages[query.Get("cursor")]), nil
+	})
+
+	orders, err := client.QueryAllOpenOrders(context.Background(), CategoryLinear, "BTCUSDT")
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, "1", orders[0].OrderId)
+		assert.Equal(t, "2", orders[1].OrderId)
+	}
+}
@@ -0,0 +1,71 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+/*
+sample:
+
+	{
+	  "s": "BTCUSDT",
+	  "a": [["65557.7", "16.606555"]],
+	  "b": [["65485.47", "47.081829"]],
+	  "ts": 1716863719031,
+	  "u": 230704,
+	  "seq": 1432604333,
+	  "cts": 1716863718905
+	}
+*/
+type OrderBook struct {
+	Symbol string `json:"s"`
+	// Bids are sorted by price in descending order
+	Bids types.PriceVolumeSlice `json:"b"`
+	// Asks are sorted by price in ascending order
+	Asks types.PriceVolumeSlice `json:"a"`
+	// Time is the time the book was generated
+	Time types.MillisecondTimestamp `json:"ts"`
+	// UpdateId is the update id of the book
+	UpdateId int64 `json:"u"`
+	// SequenceId is the cross sequence
+	SequenceId int64 `json:"seq"`
+	// MatchingEngineTime is the time the book was generated by the matching engine
+	MatchingEngineTime types.MillisecondTimestamp `json:"cts"`
+}
+
+// SliceOrderBook converts the book to the global order book.
+func (b *OrderBook) SliceOrderBook() types.SliceOrderBook {
+	return types.SliceOrderBook{
+		Symbol:       b.Symbol,
+		Bids:         b.Bids,
+		Asks:         b.Asks,
+		Time:         b.Time.Time(),
+		LastUpdateId: b.UpdateId,
+		SequenceId:   b.SequenceId,
+	}
+}
+
+// GetOrderBookRequest queries the order book snapshot, e.g. to rebuild the local book without waiting for the next
+// websocket snapshot.
+//
+//go:generate GetRequest -url "/v5/market/orderbook" -type GetOrderBookRequest -responseDataType .OrderBook
+type GetOrderBookRequest struct {
+	client requestgen.APIClient
+
+	category Category `param:"category,query" validValues:"spot,linear,inverse"`
+	symbol   string   `param:"symbol,query"`
+	// Limit size for each bid and ask. spot: [1, 200], default: 1. linear and inverse: [1, 500], default: 25.
+	limit *uint64 `param:"limit,query"`
+}
+
+func (c *RestClient) NewGetOrderBookRequest() *GetOrderBookRequest {
+	return &GetOrderBookRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
@@ -0,0 +1,55 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestGetOrderBookRequest(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.GET("/v5/market/orderbook", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "spot", query.Get("category"))
+		assert.Equal(t, "BTCUSDT", query.Get("symbol"))
+		assert.Equal(t, "200", query.Get("limit"))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {
+			"s": "BTCUSDT",
+			"a": [["65557.7", "16.606555"], ["65558.1", "1"]],
+			"b": [["65485.47", "47.081829"]],
+			"ts": 1716863719031,
+			"u": 230704,
+			"seq": 1432604333,
+			"cts": 1716863718905
+		}, "retExtInfo": {}, "time": 1716863719382}`), nil
+	})
+
+	book, err := client.NewGetOrderBookRequest().Symbol("BTCUSDT").Limit(200).Do(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, types.SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("65485.47"), Volume: fixedpoint.MustNewFromString("47.081829")},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("65557.7"), Volume: fixedpoint.MustNewFromString("16.606555")},
+			{Price: fixedpoint.MustNewFromString("65558.1"), Volume: fixedpoint.One},
+		},
+		Time:         types.NewMillisecondTimestampFromInt(1716863719031).Time(),
+		LastUpdateId: 230704,
+		SequenceId:   1432604333,
+	}, book.SliceOrderBook())
+	assert.Equal(t, int64(1716863718905), book.MatchingEngineTime.Time().UnixMilli())
+}
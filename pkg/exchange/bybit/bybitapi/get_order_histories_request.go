@@ -1,6 +1,7 @@
 package bybitapi
 
 import (
+	"context"
 	"time"
 
 	"github.com/c9s/requestgen"
@@ -9,11 +10,14 @@ import (
 //go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
 //go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
 
+// orderHistoriesLimit is the max page size of the order histories.
+const orderHistoriesLimit = 50
+
 //go:generate GetRequest -url "/v5/order/history" -type GetOrderHistoriesRequest -responseDataType .OrdersResponse
 type GetOrderHistoriesRequest struct {
 	client requestgen.AuthenticatedAPIClient
 
-	category Category `param:"category,query" validValues:"spot"`
+	category Category `param:"category,query" validValues:"spot,linear,inverse"`
 
 	symbol      *string `param:"symbol,query"`
 	orderId     *string `param:"orderId,query"`
@@ -50,3 +54,35 @@ func (c *RestClient) NewGetOrderHistoriesRequest() *GetOrderHistoriesRequest {
 		category: CategorySpot,
 	}
 }
+
+// QueryOrderHistories queries the latest order histories of the category up to limit orders by following the
+// nextPageCursor, the orders are in descending order by createdTime. The symbol is optional.
+func (c *RestClient) QueryOrderHistories(ctx context.Context, category Category, symbol string, limit int) ([]Order, error) {
+	var orders []Order
+	cursor := ""
+	for len(orders) < limit {
+		req := c.NewGetOrderHistoriesRequest().Category(category).Limit(orderHistoriesLimit)
+		if len(symbol) > 0 {
+			req.Symbol(symbol)
+		}
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, res.List...)
+		if len(res.NextPageCursor) == 0 || len(res.List) == 0 {
+			break
+		}
+		cursor = res.NextPageCursor
+	}
+
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
@@ -71,7 +71,7 @@ func (g *GetOrderHistoriesRequest) GetQueryParameters() (url.Values, error) {
 
 	// TEMPLATE check-valid-values
 	switch category {
-	case "spot":
+	case "spot", "linear", "inverse":
 		params["category"] = category
 
 	default:
@@ -0,0 +1,123 @@
+package bybitapi
+
+import (
+	"context"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// positionsLimit is the max page size of the position list.
+const positionsLimit = 200
+
+type PositionsResponse struct {
+	List           []Position `json:"list"`
+	NextPageCursor string     `json:"nextPageCursor"`
+	Category       Category   `json:"category"`
+}
+
+type Position struct {
+	Symbol string `json:"symbol"`
+	// PositionIdx is the position index, it distinguishes the buy side and the sell side positions in the hedge mode.
+	PositionIdx PositionIdx `json:"positionIdx"`
+	// Side is Buy for the long position, Sell for the short position and empty for the empty position.
+	Side          Side             `json:"side"`
+	Size          fixedpoint.Value `json:"size"`
+	AvgPrice      fixedpoint.Value `json:"avgPrice"`
+	PositionValue fixedpoint.Value `json:"positionValue"`
+	TradeMode     TradeMode        `json:"tradeMode"`
+	Leverage      fixedpoint.Value `json:"leverage"`
+	MarkPrice     fixedpoint.Value `json:"markPrice"`
+	LiqPrice      fixedpoint.Value `json:"liqPrice"`
+	TakeProfit    fixedpoint.Value `json:"takeProfit"`
+	StopLoss      fixedpoint.Value `json:"stopLoss"`
+	TrailingStop  fixedpoint.Value `json:"trailingStop"`
+	UnrealisedPnl fixedpoint.Value `json:"unrealisedPnl"`
+	// CumRealisedPnl is the cumulative realised pnl of the position.
+	CumRealisedPnl fixedpoint.Value           `json:"cumRealisedPnl"`
+	PositionStatus string                     `json:"positionStatus"`
+	CreatedTime    types.MillisecondTimestamp `json:"createdTime"`
+	UpdatedTime    types.MillisecondTimestamp `json:"updatedTime"`
+}
+
+//go:generate GetRequest -url "/v5/position/list" -type GetPositionsRequest -responseDataType .PositionsResponse
+type GetPositionsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category Category `param:"category,query" validValues:"linear,inverse"`
+	// one of symbol and settleCoin is required for the linear category.
+	symbol     *string `param:"symbol,query"`
+	baseCoin   *string `param:"baseCoin,query"`
+	settleCoin *string `param:"settleCoin,query"`
+
+	// limit for data size per page. [1, 200]. Default: 20
+	limit *uint64 `param:"limit,query"`
+	// cursor uses the nextPageCursor token from the response to retrieve the next page of the result set
+	cursor *string `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetPositionsRequest() *GetPositionsRequest {
+	return &GetPositionsRequest{
+		client:   c,
+		category: CategoryLinear,
+	}
+}
+
+// QueryPositions queries the positions of the given symbols. Bybit omits the symbols without any position, so the empty
+// position of size zero is returned for them, the positions are in the order of the symbols.
+func (c *RestClient) QueryPositions(ctx context.Context, category Category, symbols ...string) ([]Position, error) {
+	var positions []Position
+	for _, symbol := range symbols {
+		res, err := c.NewGetPositionsRequest().
+			Category(category).
+			Symbol(symbol).
+			Limit(positionsLimit).
+			Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(res.List) == 0 {
+			positions = append(positions, Position{
+				Symbol: symbol,
+				Size:   fixedpoint.Zero,
+			})
+			continue
+		}
+
+		positions = append(positions, res.List...)
+	}
+
+	return positions, nil
+}
+
+// QuerySettleCoinPositions queries all the positions settled in the settle coin by following the nextPageCursor.
+func (c *RestClient) QuerySettleCoinPositions(ctx context.Context, category Category, settleCoin string) ([]Position, error) {
+	var positions []Position
+	cursor := ""
+	for {
+		req := c.NewGetPositionsRequest().
+			Category(category).
+			SettleCoin(settleCoin).
+			Limit(positionsLimit)
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		positions = append(positions, res.List...)
+		if len(res.NextPageCursor) == 0 || len(res.List) == 0 {
+			return positions, nil
+		}
+		cursor = res.NextPageCursor
+	}
+}
@@ -0,0 +1,50 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_QueryPositions(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	responses := map[string]string{
+		"BTCUSDT": `{"retCode": 0, "retMsg": "OK", "result": {"category": "linear", "nextPageCursor": "", "list": [{
+			"symbol": "BTCUSDT", "positionIdx": 0, "side": "Buy", "size": "0.01", "avgPrice": "28000",
+			"leverage": "3", "unrealisedPnl": "1.5", "liqPrice": "19000"
+		}]}, "retExtInfo": {}, "time": 1700000000000}`,
+		"ETHUSDT": `{"retCode": 0, "retMsg": "OK", "result": {"category": "linear", "nextPageCursor": "", "list": []}, "retExtInfo": {}, "time": 1700000000000}`,
+	}
+
+	transport.GET("/v5/position/list", func(req *http.Request) (*http.Response, error) {
+		return httptesting.BuildResponseString(http.StatusOK, responses[req.URL.Query().Get("symbol")]), nil
+	})
+
+	positions, err := client.QueryPositions(context.Background(), CategoryLinear, "BTCUSDT", "ETHUSDT")
+	assert.NoError(t, err)
+	if assert.Len(t, positions, 2) {
+		assert.Equal(t, "BTCUSDT", positions[0].Symbol)
+		assert.Equal(t, SideBuy, positions[0].Side)
+		assert.Equal(t, PositionIdxOneWay, positions[0].PositionIdx)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.01"), positions[0].Size)
+		assert.Equal(t, fixedpoint.MustNewFromString("28000"), positions[0].AvgPrice)
+		assert.Equal(t, fixedpoint.MustNewFromString("3"), positions[0].Leverage)
+		assert.Equal(t, fixedpoint.MustNewFromString("1.5"), positions[0].UnrealisedPnl)
+		assert.Equal(t, fixedpoint.MustNewFromString("19000"), positions[0].LiqPrice)
+
+		// the empty position is returned for the symbol without position
+		assert.Equal(t, "ETHUSDT", positions[1].Symbol)
+		assert.True(t, positions[1].Size.IsZero())
+	}
+}
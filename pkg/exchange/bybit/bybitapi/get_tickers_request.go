@@ -34,16 +34,26 @@ type Ticker struct {
 	Turnover24H   fixedpoint.Value `json:"turnover24h"`
 	Volume24H     fixedpoint.Value `json:"volume24h"`
 	UsdIndexPrice fixedpoint.Value `json:"usdIndexPrice"`
+
+	// The following fields are only available for the linear and inverse categories.
+	MarkPrice         fixedpoint.Value           `json:"markPrice"`
+	IndexPrice        fixedpoint.Value           `json:"indexPrice"`
+	OpenInterest      fixedpoint.Value           `json:"openInterest"`
+	OpenInterestValue fixedpoint.Value           `json:"openInterestValue"`
+	FundingRate       fixedpoint.Value           `json:"fundingRate"`
+	NextFundingTime   types.MillisecondTimestamp `json:"nextFundingTime"`
 }
 
-// GetTickersRequest without **-responseDataType .InstrumentsInfo** in generation command, because the caller
+// GetTickersRequest without **-responseDataType .Tickers** in generation command, because the caller
 // needs the APIResponse.Time. We implemented the DoWithResponseTime to handle this.
 //
+// All the tickers of the category are returned if the symbol is not given.
+//
 //go:generate GetRequest -url "/v5/market/tickers" -type GetTickersRequest
 type GetTickersRequest struct {
 	client requestgen.APIClient
 
-	category Category `param:"category,query" validValues:"spot"`
+	category Category `param:"category,query" validValues:"spot,linear,inverse"`
 	symbol   *string  `param:"symbol,query"`
 }
 
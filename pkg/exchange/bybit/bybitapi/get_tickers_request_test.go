@@ -0,0 +1,41 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestGetTickersRequest_DoWithResponseTime(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.GET("/v5/market/tickers", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "linear", query.Get("category"))
+		assert.False(t, query.Has("symbol"))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"category": "linear", "list": [
+			{"symbol": "BTCUSDT", "lastPrice": "30000", "volume24h": "1000", "markPrice": "30001", "fundingRate": "0.0001", "nextFundingTime": "1700006400000"},
+			{"symbol": "ETHUSDT", "lastPrice": "2000", "volume24h": "5000", "markPrice": "2000.5", "fundingRate": "-0.0001", "nextFundingTime": "1700006400000"}
+		]}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	tickers, err := client.NewGetTickersRequest().Category(CategoryLinear).DoWithResponseTime(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, CategoryLinear, tickers.Category)
+	assert.Equal(t, int64(1700000000000), tickers.ClosedTime.Time().UnixMilli())
+	if assert.Len(t, tickers.List, 2) {
+		assert.Equal(t, "BTCUSDT", tickers.List[0].Symbol)
+		assert.Equal(t, fixedpoint.NewFromInt(30000), tickers.List[0].LastPrice)
+		assert.Equal(t, fixedpoint.NewFromInt(30001), tickers.List[0].MarkPrice)
+		assert.Equal(t, fixedpoint.NewFromFloat(-0.0001), tickers.List[1].FundingRate)
+	}
+}
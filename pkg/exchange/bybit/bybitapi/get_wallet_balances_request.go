@@ -27,47 +27,76 @@ type WalletBalances struct {
 	// Account LTV: account total borrowed size / (account total equity + account total borrowed size).
 	// In non-unified mode & unified (inverse) & unified (isolated_margin), the field will be returned as an empty string.
 	AccountLTV fixedpoint.Value `json:"accountLTV"`
-	Coins      []struct {
-		Coin string `json:"coin"`
-		// Equity of current coin
-		Equity fixedpoint.Value `json:"equity"`
-		// UsdValue of current coin. If this coin cannot be collateral, then it is 0
-		UsdValue fixedpoint.Value `json:"usdValue"`
-		// WalletBalance of current coin
-		WalletBalance fixedpoint.Value `json:"walletBalance"`
-		// Free available balance for Spot wallet. This is a unique field for Normal SPOT
-		Free fixedpoint.Value
-		// Locked balance for Spot wallet. This is a unique field for Normal SPOT
-		Locked fixedpoint.Value
-		// Available amount to withdraw of current coin
-		AvailableToWithdraw fixedpoint.Value `json:"availableToWithdraw"`
-		// Available amount to borrow of current coin
-		AvailableToBorrow fixedpoint.Value `json:"availableToBorrow"`
-		// Borrow amount of current coin
-		BorrowAmount fixedpoint.Value `json:"borrowAmount"`
-		// Accrued interest
-		AccruedInterest fixedpoint.Value `json:"accruedInterest"`
-		// Pre-occupied margin for order. For portfolio margin mode, it returns ""
-		TotalOrderIM fixedpoint.Value `json:"totalOrderIM"`
-		// Sum of initial margin of all positions + Pre-occupied liquidation fee. For portfolio margin mode, it returns ""
-		TotalPositionIM fixedpoint.Value `json:"totalPositionIM"`
-		// Sum of maintenance margin for all positions. For portfolio margin mode, it returns ""
-		TotalPositionMM fixedpoint.Value `json:"totalPositionMM"`
-		// Unrealised P&L
-		UnrealisedPnl fixedpoint.Value `json:"unrealisedPnl"`
-		// Cumulative Realised P&L
-		CumRealisedPnl fixedpoint.Value `json:"cumRealisedPnl"`
-		// Bonus. This is a unique field for UNIFIED account
-		Bonus fixedpoint.Value `json:"bonus"`
-		// Whether it can be used as a margin collateral currency (platform)
-		// - When marginCollateral=false, then collateralSwitch is meaningless
-		// -  This is a unique field for UNIFIED account
-		CollateralSwitch bool `json:"collateralSwitch"`
-		// Whether the collateral is turned on by user (user)
-		// - When marginCollateral=true, then collateralSwitch is meaningful
-		// - This is a unique field for UNIFIED account
-		MarginCollateral bool `json:"marginCollateral"`
-	} `json:"coin"`
+	Coins      []WalletCoin     `json:"coin"`
+}
+
+// CoinBalance is the per-coin breakdown of the wallet balance.
+type CoinBalance struct {
+	WalletBalance       fixedpoint.Value
+	AvailableToWithdraw fixedpoint.Value
+	Equity              fixedpoint.Value
+	UnrealizedPnL       fixedpoint.Value
+}
+
+// CoinBalances returns the coin balances of the accounts keyed by the coin, the balances of the same coin in different
+// accounts are summed up.
+func (r WalletBalancesResponse) CoinBalances() map[string]CoinBalance {
+	balances := make(map[string]CoinBalance)
+	for _, account := range r.List {
+		for _, coin := range account.Coins {
+			b := balances[coin.Coin]
+			balances[coin.Coin] = CoinBalance{
+				WalletBalance:       b.WalletBalance.Add(coin.WalletBalance),
+				AvailableToWithdraw: b.AvailableToWithdraw.Add(coin.AvailableToWithdraw),
+				Equity:              b.Equity.Add(coin.Equity),
+				UnrealizedPnL:       b.UnrealizedPnL.Add(coin.UnrealisedPnl),
+			}
+		}
+	}
+	return balances
+}
+
+// WalletCoin is the balance of a coin in the account, the UNIFIED account returns the balances under the coin list.
+type WalletCoin struct {
+	Coin string `json:"coin"`
+	// Equity of current coin
+	Equity fixedpoint.Value `json:"equity"`
+	// UsdValue of current coin. If this coin cannot be collateral, then it is 0
+	UsdValue fixedpoint.Value `json:"usdValue"`
+	// WalletBalance of current coin
+	WalletBalance fixedpoint.Value `json:"walletBalance"`
+	// Free available balance for Spot wallet. This is a unique field for Normal SPOT
+	Free fixedpoint.Value
+	// Locked balance for Spot wallet. This is a unique field for Normal SPOT
+	Locked fixedpoint.Value
+	// Available amount to withdraw of current coin
+	AvailableToWithdraw fixedpoint.Value `json:"availableToWithdraw"`
+	// Available amount to borrow of current coin
+	AvailableToBorrow fixedpoint.Value `json:"availableToBorrow"`
+	// Borrow amount of current coin
+	BorrowAmount fixedpoint.Value `json:"borrowAmount"`
+	// Accrued interest
+	AccruedInterest fixedpoint.Value `json:"accruedInterest"`
+	// Pre-occupied margin for order. For portfolio margin mode, it returns ""
+	TotalOrderIM fixedpoint.Value `json:"totalOrderIM"`
+	// Sum of initial margin of all positions + Pre-occupied liquidation fee. For portfolio margin mode, it returns ""
+	TotalPositionIM fixedpoint.Value `json:"totalPositionIM"`
+	// Sum of maintenance margin for all positions. For portfolio margin mode, it returns ""
+	TotalPositionMM fixedpoint.Value `json:"totalPositionMM"`
+	// Unrealised P&L
+	UnrealisedPnl fixedpoint.Value `json:"unrealisedPnl"`
+	// Cumulative Realised P&L
+	CumRealisedPnl fixedpoint.Value `json:"cumRealisedPnl"`
+	// Bonus. This is a unique field for UNIFIED account
+	Bonus fixedpoint.Value `json:"bonus"`
+	// Whether it can be used as a margin collateral currency (platform)
+	// - When marginCollateral=false, then collateralSwitch is meaningless
+	// -  This is a unique field for UNIFIED account
+	CollateralSwitch bool `json:"collateralSwitch"`
+	// Whether the collateral is turned on by user (user)
+	// - When marginCollateral=true, then collateralSwitch is meaningful
+	// - This is a unique field for UNIFIED account
+	MarginCollateral bool `json:"marginCollateral"`
 }
 
 //go:generate GetRequest -url "/v5/account/wallet-balance" -type GetWalletBalancesRequest -responseDataType .WalletBalancesResponse
@@ -77,7 +106,7 @@ type GetWalletBalancesRequest struct {
 	// Account type
 	// - Unified account: UNIFIED (trade spot/linear/options), CONTRACT(trade inverse)
 	// - Normal account: CONTRACT, SPOT
-	accountType AccountType `param:"accountType,query" validValues:"SPOT"`
+	accountType AccountType `param:"accountType,query" validValues:"SPOT,UNIFIED,CONTRACT"`
 	// Coin name
 	// - If not passed, it returns non-zero asset info
 	// - You can pass multiple coins to query, separated by comma. USDT,USDC
@@ -29,7 +29,7 @@ func (g *GetWalletBalancesRequest) GetQueryParameters() (url.Values, error) {
 
 	// TEMPLATE check-valid-values
 	switch accountType {
-	case "SPOT":
+	case "SPOT", "UNIFIED", "CONTRACT":
 		params["accountType"] = accountType
 
 	default:
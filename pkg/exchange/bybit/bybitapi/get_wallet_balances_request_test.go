@@ -0,0 +1,45 @@
+package bybitapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestWalletBalancesResponse_CoinBalances(t *testing.T) {
+	data := `{
+		"list": [{
+			"accountType": "UNIFIED",
+			"totalEquity": "3.31216591",
+			"coin": [{
+				"coin": "BTC",
+				"equity": "0.00007553",
+				"walletBalance": "0.00007553",
+				"availableToWithdraw": "0.00007553",
+				"unrealisedPnl": "0"
+			}, {
+				"coin": "USDT",
+				"equity": "2.5",
+				"walletBalance": "2",
+				"availableToWithdraw": "1.5",
+				"unrealisedPnl": "0.5"
+			}]
+		}]
+	}`
+
+	var resp WalletBalancesResponse
+	assert.NoError(t, json.Unmarshal([]byte(data), &resp))
+
+	balances := resp.CoinBalances()
+	assert.Len(t, balances, 2)
+	assert.Equal(t, CoinBalance{
+		WalletBalance:       fixedpoint.MustNewFromString("2"),
+		AvailableToWithdraw: fixedpoint.MustNewFromString("1.5"),
+		Equity:              fixedpoint.MustNewFromString("2.5"),
+		UnrealizedPnL:       fixedpoint.MustNewFromString("0.5"),
+	}, balances["USDT"])
+	assert.Equal(t, fixedpoint.MustNewFromString("0.00007553"), balances["BTC"].WalletBalance)
+}
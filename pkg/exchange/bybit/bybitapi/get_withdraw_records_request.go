@@ -0,0 +1,114 @@
+package bybitapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+type WithdrawStatus string
+
+const (
+	WithdrawStatusSecurityCheck           WithdrawStatus = "SecurityCheck"
+	WithdrawStatusPending                 WithdrawStatus = "Pending"
+	WithdrawStatusSuccess                 WithdrawStatus = "success"
+	WithdrawStatusCancelByUser            WithdrawStatus = "CancelByUser"
+	WithdrawStatusReject                  WithdrawStatus = "Reject"
+	WithdrawStatusFail                    WithdrawStatus = "Fail"
+	WithdrawStatusBlockchainConfirmed     WithdrawStatus = "BlockchainConfirmed"
+	WithdrawStatusMoreInformationRequired WithdrawStatus = "MoreInformationRequired"
+	WithdrawStatusUnknown                 WithdrawStatus = "Unknown"
+)
+
+type WithdrawType int
+
+const (
+	WithdrawTypeOnChain WithdrawType = 0
+	// WithdrawTypeOffChain is the internal transfer to another bybit account.
+	WithdrawTypeOffChain WithdrawType = 1
+	WithdrawTypeAll      WithdrawType = 2
+)
+
+type WithdrawRecords struct {
+	Rows           []WithdrawRecord `json:"rows"`
+	NextPageCursor string           `json:"nextPageCursor"`
+}
+
+type WithdrawRecord struct {
+	WithdrawId   string                     `json:"withdrawId"`
+	TxID         string                     `json:"txID"`
+	WithdrawType WithdrawType               `json:"withdrawType"`
+	Coin         string                     `json:"coin"`
+	Chain        string                     `json:"chain"`
+	Amount       fixedpoint.Value           `json:"amount"`
+	WithdrawFee  fixedpoint.Value           `json:"withdrawFee"`
+	Status       WithdrawStatus             `json:"status"`
+	ToAddress    string                     `json:"toAddress"`
+	Tag          string                     `json:"tag"`
+	CreateTime   types.MillisecondTimestamp `json:"createTime"`
+	UpdateTime   types.MillisecondTimestamp `json:"updateTime"`
+}
+
+//go:generate GetRequest -url "/v5/asset/withdraw/query-record" -type GetWithdrawRecordsRequest -responseDataType .WithdrawRecords
+type GetWithdrawRecordsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	withdrawID *string `param:"withdrawID,query"`
+	txID       *string `param:"txID,query"`
+	coin       *string `param:"coin,query"`
+	// withdrawType is on chain by default.
+	withdrawType *WithdrawType `param:"withdrawType,query"`
+
+	// startTime and endTime interval should be less than 30 days, the past 30 days are queried by default.
+	startTime *time.Time `param:"startTime,query,milliseconds"`
+	endTime   *time.Time `param:"endTime,query,milliseconds"`
+
+	// limit for data size per page. [1, 50]. Default: 50
+	limit *uint64 `param:"limit,query"`
+	// cursor uses the nextPageCursor token from the response to retrieve the next page of the result set
+	cursor *string `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetWithdrawRecordsRequest() *GetWithdrawRecordsRequest {
+	return &GetWithdrawRecordsRequest{
+		client: c,
+	}
+}
+
+// QueryWithdrawRecords queries all the withdrawal records, on chain and off chain, between startTime and endTime by
+// following the nextPageCursor. The coin is optional, and the interval must be less than 30 days.
+func (c *RestClient) QueryWithdrawRecords(ctx context.Context, coin string, startTime, endTime time.Time) ([]WithdrawRecord, error) {
+	var records []WithdrawRecord
+	cursor := ""
+	for {
+		req := c.NewGetWithdrawRecordsRequest().
+			WithdrawType(WithdrawTypeAll).
+			StartTime(startTime).
+			EndTime(endTime).
+			Limit(assetRecordsLimit)
+		if len(coin) > 0 {
+			req.Coin(coin)
+		}
+		if len(cursor) > 0 {
+			req.Cursor(cursor)
+		}
+
+		res, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, res.Rows...)
+		if len(res.NextPageCursor) == 0 || len(res.Rows) == 0 {
+			return records, nil
+		}
+		cursor = res.NextPageCursor
+	}
+}
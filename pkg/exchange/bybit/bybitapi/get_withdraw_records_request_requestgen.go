@@ -0,0 +1,279 @@
+// Code generated by "requestgen -method GET -responseType .APIResponse -responseDataField Result -url /v5/asset/withdraw/query-record -type GetWithdrawRecordsRequest -responseDataType .WithdrawRecords"; DO NOT EDIT.
+
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+func (g *GetWithdrawRecordsRequest) WithdrawID(withdrawID string) *GetWithdrawRecordsRequest {
+	g.withdrawID = &withdrawID
+	return g
+}
+
+func (g *GetWithdrawRecordsRequest) TxID(txID string) *GetWithdrawRecordsRequest {
+	g.txID = &txID
+	return g
+}
+
+func (g *GetWithdrawRecordsRequest) Coin(coin string) *GetWithdrawRecordsRequest {
+	g.coin = &coin
+	return g
+}
+
+func (g *GetWithdrawRecordsRequest) WithdrawType(withdrawType WithdrawType) *GetWithdrawRecordsRequest {
+	g.withdrawType = &withdrawType
+	return g
+}
+
+func (g *GetWithdrawRecordsRequest) StartTime(startTime time.Time) *GetWithdrawRecordsRequest {
+	g.startTime = &startTime
+	return g
+}
+
+func (g *GetWithdrawRecordsRequest) EndTime(endTime time.Time) *GetWithdrawRecordsRequest {
+	g.endTime = &endTime
+	return g
+}
+
+func (g *GetWithdrawRecordsRequest) Limit(limit uint64) *GetWithdrawRecordsRequest {
+	g.limit = &limit
+	return g
+}
+
+func (g *GetWithdrawRecordsRequest) Cursor(cursor string) *GetWithdrawRecordsRequest {
+	g.cursor = &cursor
+	return g
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (g *GetWithdrawRecordsRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+	// check withdrawID field -> json key withdrawID
+	if g.withdrawID != nil {
+		withdrawID := *g.withdrawID
+
+		// assign parameter of withdrawID
+		params["withdrawID"] = withdrawID
+	} else {
+	}
+	// check txID field -> json key txID
+	if g.txID != nil {
+		txID := *g.txID
+
+		// assign parameter of txID
+		params["txID"] = txID
+	} else {
+	}
+	// check coin field -> json key coin
+	if g.coin != nil {
+		coin := *g.coin
+
+		// assign parameter of coin
+		params["coin"] = coin
+	} else {
+	}
+	// check withdrawType field -> json key withdrawType
+	if g.withdrawType != nil {
+		withdrawType := *g.withdrawType
+
+		// TEMPLATE check-valid-values
+		switch withdrawType {
+		case WithdrawTypeOnChain, WithdrawTypeOffChain, WithdrawTypeAll:
+			params["withdrawType"] = withdrawType
+
+		default:
+			return nil, fmt.Errorf("withdrawType value %v is invalid", withdrawType)
+
+		}
+		// END TEMPLATE check-valid-values
+
+		// assign parameter of withdrawType
+		params["withdrawType"] = withdrawType
+	} else {
+	}
+	// check startTime field -> json key startTime
+	if g.startTime != nil {
+		startTime := *g.startTime
+
+		// assign parameter of startTime
+		// convert time.Time to milliseconds time stamp
+		params["startTime"] = strconv.FormatInt(startTime.UnixNano()/int64(time.Millisecond), 10)
+	} else {
+	}
+	// check endTime field -> json key endTime
+	if g.endTime != nil {
+		endTime := *g.endTime
+
+		// assign parameter of endTime
+		// convert time.Time to milliseconds time stamp
+		params["endTime"] = strconv.FormatInt(endTime.UnixNano()/int64(time.Millisecond), 10)
+	} else {
+	}
+	// check limit field -> json key limit
+	if g.limit != nil {
+		limit := *g.limit
+
+		// assign parameter of limit
+		params["limit"] = limit
+	} else {
+	}
+	// check cursor field -> json key cursor
+	if g.cursor != nil {
+		cursor := *g.cursor
+
+		// assign parameter of cursor
+		params["cursor"] = cursor
+	} else {
+	}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (g *GetWithdrawRecordsRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (g *GetWithdrawRecordsRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := g.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if g.isVarSlice(_v) {
+			g.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (g *GetWithdrawRecordsRequest) GetParametersJSON() ([]byte, error) {
+	params, err := g.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (g *GetWithdrawRecordsRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (g *GetWithdrawRecordsRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (g *GetWithdrawRecordsRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (g *GetWithdrawRecordsRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (g *GetWithdrawRecordsRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := g.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+// GetPath returns the request path of the API
+func (g *GetWithdrawRecordsRequest) GetPath() string {
+	return "/v5/asset/withdraw/query-record"
+}
+
+// Do generates the request object and send the request object to the API endpoint
+func (g *GetWithdrawRecordsRequest) Do(ctx context.Context) (*WithdrawRecords, error) {
+
+	// no body params
+	var params interface{}
+	query, err := g.GetQueryParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	var apiURL string
+
+	apiURL = g.GetPath()
+
+	req, err := g.client.NewAuthenticatedRequest(ctx, "GET", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := g.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	type responseValidator interface {
+		Validate() error
+	}
+	validator, ok := interface{}(apiResponse).(responseValidator)
+	if ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	var data WithdrawRecords
+	if err := json.Unmarshal(apiResponse.Result, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,56 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_QueryWithdrawRecords(t *testing.T) {
+	client, err := NewClient()
+	require.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	startTime := time.UnixMilli(1700000000000)
+	endTime := startTime.Add(24 * time.Hour)
+
+	var cursors []string
+	transport.GET("/v5/asset/withdraw/query-record", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Empty(t, query.Get("coin"))
+		assert.Equal(t, "2", query.Get("withdrawType"))
+		assert.Equal(t, "1700000000000", query.Get("startTime"))
+		assert.Equal(t, "1700086400000", query.Get("endTime"))
+
+		cursor := query.Get("cursor")
+		cursors = append(cursors, cursor)
+		if cursor == "" {
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"success","result":{"rows":[{"withdrawId":"10","txID":"0xabc","withdrawType":0,"coin":"USDT","chain":"ETH","amount":"20","withdrawFee":"1.5","status":"success","toAddress":"0x1234","tag":"","createTime":"1700000100000","updateTime":"1700000200000"}],"nextPageCursor":"next"},"retExtInfo":{},"time":1700000300000}`), nil
+		}
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"success","result":{"rows":[{"withdrawId":"11","txID":"","withdrawType":1,"coin":"BTC","chain":"","amount":"0.1","withdrawFee":"0","status":"Pending","toAddress":"123456","createTime":"1700000400000","updateTime":"1700000400000"}],"nextPageCursor":""},"retExtInfo":{},"time":1700000500000}`), nil
+	})
+
+	records, err := client.QueryWithdrawRecords(context.Background(), "", startTime, endTime)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "next"}, cursors)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, WithdrawTypeOnChain, records[0].WithdrawType)
+	assert.Equal(t, fixedpoint.MustNewFromString("20"), records[0].Amount)
+	assert.Equal(t, fixedpoint.MustNewFromString("1.5"), records[0].WithdrawFee)
+	assert.Equal(t, "0xabc", records[0].TxID)
+	assert.Equal(t, WithdrawStatusSuccess, records[0].Status)
+	assert.Equal(t, int64(1700000100000), records[0].CreateTime.Time().UnixMilli())
+	assert.Equal(t, WithdrawTypeOffChain, records[1].WithdrawType)
+	assert.Equal(t, WithdrawStatusPending, records[1].Status)
+}
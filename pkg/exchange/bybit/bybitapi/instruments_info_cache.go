@@ -0,0 +1,208 @@
+package bybitapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrAmbiguousCategory is returned by ResolveCategory if the symbol exists in multiple categories, e.g. BTCUSDT is
+// both a spot and a linear symbol, so the category must be given explicitly.
+var ErrAmbiguousCategory = errors.New("the symbol exists in multiple categories")
+
+// maxInstrumentsPageSize is the max page size of the instruments info of the linear and the inverse categories.
+const maxInstrumentsPageSize = 1000
+
+// InstrumentsInfoCache caches the instruments info keyed by symbol, so that the price and quantity can be rounded
+// to the valid increments before placing orders.
+type InstrumentsInfoCache struct {
+	client *RestClient
+
+	mu          sync.RWMutex
+	instruments map[string]Instrument
+	// derivatives are the instruments of the linear and the inverse categories by the category and the symbol, since
+	// the same symbol has different filters in the spot category, see GetCategoryInstrument.
+	derivatives map[Category]map[string]Instrument
+
+	// symbolCategories is the categories of the symbols loaded by Refresh and RefreshCategories, see ResolveCategory.
+	symbolCategories map[string][]Category
+}
+
+func NewInstrumentsInfoCache(client *RestClient) *InstrumentsInfoCache {
+	return &InstrumentsInfoCache{
+		client:           client,
+		instruments:      map[string]Instrument{},
+		derivatives:      map[Category]map[string]Instrument{},
+		symbolCategories: map[string][]Category{},
+	}
+}
+
+// Refresh reloads all the instruments into the cache.
+func (c *InstrumentsInfoCache) Refresh(ctx context.Context) error {
+	info, err := c.client.NewGetInstrumentsInfoRequest().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get instruments info, err: %w", err)
+	}
+
+	c.Update(info.List...)
+	c.updateCategory(CategorySpot, info.List)
+	return nil
+}
+
+// RefreshCategories loads the instruments of the given categories, so that ResolveCategory can resolve them and
+// GetCategoryInstrument doesn't query them one by one.
+func (c *InstrumentsInfoCache) RefreshCategories(ctx context.Context, categories ...Category) error {
+	for _, category := range categories {
+		if category == CategorySpot {
+			if err := c.Refresh(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		instruments, err := c.client.QueryInstruments(ctx, category)
+		if err != nil {
+			return fmt.Errorf("failed to get instruments info, category: %s, err: %w", category, err)
+		}
+
+		c.UpdateInstruments(category, instruments...)
+		c.updateCategory(category, instruments)
+	}
+
+	return nil
+}
+
+// updateCategory adds the category to the symbols of the instruments.
+func (c *InstrumentsInfoCache) updateCategory(category Category, instruments []Instrument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, instrument := range instruments {
+		categories := c.symbolCategories[instrument.Symbol]
+		found := false
+		for _, existing := range categories {
+			if existing == category {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			c.symbolCategories[instrument.Symbol] = append(categories, category)
+		}
+	}
+}
+
+// ResolveCategory returns the category of the symbol from the categories loaded by Refresh and RefreshCategories, so
+// the caller which only knows the symbol doesn't need to give the category. It returns ErrAmbiguousCategory if the
+// symbol exists in multiple categories.
+func (c *InstrumentsInfoCache) ResolveCategory(symbol string) (Category, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	categories := c.symbolCategories[symbol]
+	switch len(categories) {
+	case 0:
+		return "", fmt.Errorf("instrument not found: %s", symbol)
+
+	case 1:
+		return categories[0], nil
+
+	default:
+		return "", fmt.Errorf("%w: %s in %v", ErrAmbiguousCategory, symbol, categories)
+	}
+}
+
+// Update stores the given instruments into the cache.
+func (c *InstrumentsInfoCache) Update(instruments ...Instrument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, instrument := range instruments {
+		c.instruments[instrument.Symbol] = instrument
+	}
+}
+
+// Get returns the cached instrument of the symbol.
+func (c *InstrumentsInfoCache) Get(symbol string) (Instrument, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	instrument, ok := c.instruments[symbol]
+	return instrument, ok
+}
+
+// GetInstrumentsInfo returns the instrument of the symbol, it queries the instruments info if it's not cached yet.
+func (c *InstrumentsInfoCache) GetInstrumentsInfo(ctx context.Context, symbol string) (Instrument, error) {
+	if instrument, ok := c.Get(symbol); ok {
+		return instrument, nil
+	}
+
+	info, err := c.client.NewGetInstrumentsInfoRequest().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return Instrument{}, fmt.Errorf("failed to get instruments info, symbol: %s, err: %w", symbol, err)
+	}
+
+	c.Update(info.List...)
+
+	instrument, ok := c.Get(symbol)
+	if !ok {
+		return Instrument{}, fmt.Errorf("instrument not found: %s", symbol)
+	}
+	return instrument, nil
+}
+
+// UpdateInstruments stores the instruments of the category into the cache, the spot instruments are stored like Update.
+func (c *InstrumentsInfoCache) UpdateInstruments(category Category, instruments ...Instrument) {
+	if category == CategorySpot {
+		c.Update(instruments...)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	symbols, ok := c.derivatives[category]
+	if !ok {
+		symbols = map[string]Instrument{}
+		c.derivatives[category] = symbols
+	}
+
+	for _, instrument := range instruments {
+		symbols[instrument.Symbol] = instrument
+	}
+}
+
+// GetCategoryInstrument returns the instrument of the symbol in the category, it queries the instruments info of the
+// category if it's not cached yet.
+func (c *InstrumentsInfoCache) GetCategoryInstrument(ctx context.Context, category Category, symbol string) (Instrument, error) {
+	if category == CategorySpot {
+		return c.GetInstrumentsInfo(ctx, symbol)
+	}
+
+	get := func() (Instrument, bool) {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		instrument, ok := c.derivatives[category][symbol]
+		return instrument, ok
+	}
+
+	if instrument, ok := get(); ok {
+		return instrument, nil
+	}
+
+	info, err := c.client.NewGetInstrumentsInfoRequest().Category(category).Symbol(symbol).Do(ctx)
+	if err != nil {
+		return Instrument{}, fmt.Errorf("failed to get instruments info, category: %s, symbol: %s, err: %w", category, symbol, err)
+	}
+
+	c.UpdateInstruments(category, info.List...)
+
+	instrument, ok := get()
+	if !ok {
+		return Instrument{}, fmt.Errorf("instrument not found: %s in %s", symbol, category)
+	}
+	return instrument, nil
+}
@@ -0,0 +1,132 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestInstrumentsInfoCache(t *testing.T) {
+	cache := NewInstrumentsInfoCache(nil)
+	_, ok := cache.Get("BTCUSDT")
+	assert.False(t, ok)
+
+	cache.Update(Instrument{
+		Symbol: "BTCUSDT",
+		PriceFilter: PriceFilter{
+			TickSize: fixedpoint.NewFromFloat(0.01),
+		},
+	})
+	inst, ok := cache.Get("BTCUSDT")
+	assert.True(t, ok)
+	assert.Equal(t, "BTCUSDT", inst.Symbol)
+}
+
+func TestInstrument_Round(t *testing.T) {
+	t.Run("spot", func(t *testing.T) {
+		inst := Instrument{
+			Symbol: "BTCUSDT",
+			LotSizeFilter: LotSizeFilter{
+				BasePrecision: fixedpoint.NewFromFloat(0.000001),
+				MinOrderAmt:   fixedpoint.NewFromInt(1),
+			},
+			PriceFilter: PriceFilter{
+				TickSize: fixedpoint.NewFromFloat(0.01),
+			},
+		}
+
+		assert.Equal(t, fixedpoint.MustNewFromString("28829.76"), inst.RoundPrice(fixedpoint.MustNewFromString("28829.7699")))
+		assert.Equal(t, fixedpoint.MustNewFromString("0.002289"), inst.RoundQuantity(fixedpoint.MustNewFromString("0.0022899")))
+		assert.Equal(t, fixedpoint.NewFromInt(1), inst.MinNotional())
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		inst := Instrument{
+			Symbol: "BTCUSDT",
+			LotSizeFilter: LotSizeFilter{
+				QtyStep:          fixedpoint.NewFromFloat(0.001),
+				MinNotionalValue: fixedpoint.NewFromInt(5),
+			},
+			PriceFilter: PriceFilter{
+				TickSize: fixedpoint.NewFromFloat(0.5),
+			},
+		}
+
+		assert.Equal(t, fixedpoint.MustNewFromString("28829.5"), inst.RoundPrice(fixedpoint.MustNewFromString("28829.9")))
+		assert.Equal(t, fixedpoint.MustNewFromString("0.123"), inst.RoundQuantity(fixedpoint.MustNewFromString("0.1239")))
+		assert.Equal(t, fixedpoint.NewFromInt(5), inst.MinNotional())
+	})
+}
+
+func TestInstrumentsInfoCache_ResolveCategory(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		switch Category(query.Get("category")) {
+		case CategorySpot:
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {
+				"category": "spot", "list": [{"symbol": "BTCUSDT"}, {"symbol": "ETHBTC"}]
+			}}`), nil
+
+		case CategoryLinear:
+			assert.Equal(t, "1000", query.Get("limit"))
+			if query.Get("cursor") == "" {
+				return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {
+					"category": "linear", "list": [{"symbol": "BTCUSDT"}], "nextPageCursor": "page2"
+				}}`), nil
+			}
+
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {
+				"category": "linear", "list": [{"symbol": "1000PEPEUSDT"}], "nextPageCursor": ""
+			}}`), nil
+
+		default:
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {
+				"category": "inverse", "list": [{"symbol": "BTCUSD"}]
+			}}`), nil
+		}
+	})
+
+	cache := NewInstrumentsInfoCache(client)
+	assert.NoError(t, cache.RefreshCategories(context.Background(), CategorySpot, CategoryLinear, CategoryInverse))
+
+	category, err := cache.ResolveCategory("ETHBTC")
+	assert.NoError(t, err)
+	assert.Equal(t, CategorySpot, category)
+
+	category, err = cache.ResolveCategory("1000PEPEUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, CategoryLinear, category)
+
+	category, err = cache.ResolveCategory("BTCUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, CategoryInverse, category)
+
+	_, err = cache.ResolveCategory("BTCUSDT")
+	assert.ErrorIs(t, err, ErrAmbiguousCategory)
+
+	_, err = cache.ResolveCategory("XRPUSDT")
+	assert.ErrorContains(t, err, "instrument not found")
+
+	// only the spot instruments are used for the rounding
+	_, ok := cache.Get("BTCUSDT")
+	assert.True(t, ok)
+	_, ok = cache.Get("1000PEPEUSDT")
+	assert.False(t, ok)
+
+	// refreshing again doesn't duplicate the categories
+	assert.NoError(t, cache.RefreshCategories(context.Background(), CategoryInverse))
+	category, err = cache.ResolveCategory("BTCUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, CategoryInverse, category)
+}
@@ -1,7 +1,14 @@
 package bybitapi
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
 	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
 
 //go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
@@ -16,37 +23,47 @@ type PlaceOrderResponse struct {
 type PlaceOrderRequest struct {
 	client requestgen.AuthenticatedAPIClient
 
-	category    Category    `param:"category" validValues:"spot"`
+	category    Category    `param:"category" validValues:"spot,linear,inverse"`
 	symbol      string      `param:"symbol"`
 	side        Side        `param:"side" validValues:"Buy,Sell"`
 	orderType   OrderType   `param:"orderType" validValues:"Market,Limit"`
 	qty         string      `param:"qty"`
-	orderLinkId string      `param:"orderLinkId"`
 	timeInForce TimeInForce `param:"timeInForce"`
 
-	isLeverage       *bool   `param:"isLeverage"`
-	price            *string `param:"price"`
-	triggerDirection *int    `param:"triggerDirection"`
+	// orderLinkId is the client order id, it's generated by the server if it's not given.
+	orderLinkId *string `param:"orderLinkId"`
+
+	// isLeverage is only valid for the spot category.
+	isLeverage *IsLeverage `param:"isLeverage"`
+	price      *string     `param:"price"`
+	// triggerDirection is required by the conditional order.
+	triggerDirection *TriggerDirection `param:"triggerDirection"`
 	// orderFilter default spot
 	orderFilter *string `param:"orderFilter"`
 	// triggerPrice when submitting an order, if triggerPrice is set, the order will be automatically converted into a conditional order.
-	triggerPrice   *string `param:"triggerPrice"`
-	triggerBy      *string `param:"triggerBy"`
-	orderIv        *string `param:"orderIv"`
-	positionIdx    *string `param:"positionIdx"`
-	takeProfit     *string `param:"takeProfit"`
-	stopLoss       *string `param:"stopLoss"`
-	tpTriggerBy    *string `param:"tpTriggerBy"`
-	slTriggerBy    *string `param:"slTriggerBy"`
-	reduceOnly     *bool   `param:"reduceOnly"`
-	closeOnTrigger *bool   `param:"closeOnTrigger"`
-	smpType        *string `param:"smpType"`
-	mmp            *bool   `param:"mmp"` // option only
-	tpslMode       *string `param:"tpslMode"`
-	tpLimitPrice   *string `param:"tpLimitPrice"`
-	slLimitPrice   *string `param:"slLimitPrice"`
-	tpOrderType    *string `param:"tpOrderType"`
-	slOrderType    *string `param:"slOrderType"`
+	triggerPrice *string    `param:"triggerPrice"`
+	triggerBy    *TriggerBy `param:"triggerBy" validValues:"LastPrice,MarkPrice,IndexPrice"`
+	orderIv      *string    `param:"orderIv"`
+	positionIdx  *string    `param:"positionIdx"`
+	takeProfit   *string    `param:"takeProfit"`
+	stopLoss     *string    `param:"stopLoss"`
+	tpTriggerBy  *string    `param:"tpTriggerBy"`
+	slTriggerBy  *string    `param:"slTriggerBy"`
+	// reduceOnly and closeOnTrigger are only valid for the derivative categories.
+	reduceOnly     *bool `param:"reduceOnly"`
+	closeOnTrigger *bool `param:"closeOnTrigger"`
+	// smpType is the self match prevention type, the account default is used if it's not given.
+	smpType      *SmpType `param:"smpType" validValues:"None,CancelMaker,CancelTaker,CancelBoth"`
+	mmp          *bool    `param:"mmp"` // option only
+	tpslMode     *string  `param:"tpslMode"`
+	tpLimitPrice *string  `param:"tpLimitPrice"`
+	slLimitPrice *string  `param:"slLimitPrice"`
+	tpOrderType  *string  `param:"tpOrderType"`
+	slOrderType  *string  `param:"slOrderType"`
+
+	// instruments and roundingMode round the price and the quantity before sending, see WithAutoRound.
+	instruments  *InstrumentsInfoCache
+	roundingMode fixedpoint.RoundingMode
 }
 
 func (c *RestClient) NewPlaceOrderRequest() *PlaceOrderRequest {
@@ -55,3 +72,120 @@ func (c *RestClient) NewPlaceOrderRequest() *PlaceOrderRequest {
 		category: CategorySpot,
 	}
 }
+
+// Validate checks the parameter combinations which can not be verified by the generated code.
+func (p *PlaceOrderRequest) Validate() error {
+	if err := p.category.Validate(); err != nil {
+		return err
+	}
+
+	if p.isLeverage != nil && p.category != CategorySpot {
+		return fmt.Errorf("isLeverage is only supported by the spot category, got: %s", p.category)
+	}
+
+	if p.reduceOnly != nil && !p.category.SupportsReduceOnly() {
+		return fmt.Errorf("reduceOnly is not supported by the %s category", p.category)
+	}
+
+	if p.closeOnTrigger != nil && !p.category.SupportsReduceOnly() {
+		return fmt.Errorf("closeOnTrigger is not supported by the %s category", p.category)
+	}
+
+	if p.smpType != nil && !p.category.SupportsSmp() {
+		return fmt.Errorf("smpType is not supported by the %s category", p.category)
+	}
+
+	if p.triggerPrice != nil && p.triggerDirection == nil {
+		return fmt.Errorf("triggerDirection is required by the conditional order")
+	}
+
+	return nil
+}
+
+// WithAutoRound rounds the price and the trigger price to the tick size, and the quantity to the quantity step of the
+// instrument of the category with the rounding mode when the request is sent by Do, e.g. fixedpoint.Down,
+// fixedpoint.HalfUp or fixedpoint.Up. The instrument is loaded by the cache if it's not cached yet. The quantity of the
+// spot market buy order is in the quote coin, so it's rounded to the quote precision instead.
+func (p *PlaceOrderRequest) WithAutoRound(instruments *InstrumentsInfoCache, mode fixedpoint.RoundingMode) *PlaceOrderRequest {
+	p.instruments = instruments
+	p.roundingMode = mode
+	if _, ok := p.client.(*autoRoundClient); !ok {
+		p.client = &autoRoundClient{AuthenticatedAPIClient: p.client, request: p}
+	}
+	return p
+}
+
+// autoRoundClient rounds the request before building the http request, since the generated Do builds the parameters
+// from the request fields without the rounding.
+type autoRoundClient struct {
+	requestgen.AuthenticatedAPIClient
+
+	request *PlaceOrderRequest
+}
+
+func (c *autoRoundClient) NewAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, _ interface{}) (*http.Request, error) {
+	if err := c.request.Round(ctx); err != nil {
+		return nil, err
+	}
+
+	payload, err := c.request.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.AuthenticatedAPIClient.NewAuthenticatedRequest(ctx, method, refURL, params, payload)
+}
+
+// Round rounds the price, the trigger price and the quantity by the instrument filters if WithAutoRound is set, it's
+// called by Do, so the caller only needs it to inspect the rounded values. It returns an error if the rounded quantity
+// is below the minimum order quantity. The rounding is idempotent.
+func (p *PlaceOrderRequest) Round(ctx context.Context) error {
+	if p.instruments == nil {
+		return nil
+	}
+
+	instrument, err := p.instruments.GetCategoryInstrument(ctx, p.category, p.symbol)
+	if err != nil {
+		return err
+	}
+
+	qty, err := fixedpoint.NewFromString(p.qty)
+	if err != nil {
+		return fmt.Errorf("invalid qty %q, err: %w", p.qty, err)
+	}
+
+	step, minQty := instrument.QuantityStep(), instrument.LotSizeFilter.MinOrderQty
+	if p.category == CategorySpot && p.orderType == OrderTypeMarket && p.side == SideBuy {
+		step, minQty = instrument.LotSizeFilter.QuotePrecision, instrument.LotSizeFilter.MinOrderAmt
+	}
+
+	rounded := roundToStep(qty, step, p.roundingMode)
+	if rounded.Sign() <= 0 || rounded.Compare(minQty) < 0 {
+		return fmt.Errorf("the rounded qty %s of %s is below the min qty %s", rounded.String(), p.symbol, minQty.String())
+	}
+	p.qty = rounded.String()
+
+	for _, price := range []*string{p.price, p.triggerPrice} {
+		if price == nil {
+			continue
+		}
+
+		v, err := fixedpoint.NewFromString(*price)
+		if err != nil {
+			return fmt.Errorf("invalid price %q, err: %w", *price, err)
+		}
+		*price = roundToStep(v, instrument.PriceFilter.TickSize, p.roundingMode).String()
+	}
+
+	return nil
+}
+
+// PlaceOrder validates the request and places the order, the price and the quantity are rounded by Do if
+// WithAutoRound is set.
+func (c *RestClient) PlaceOrder(ctx context.Context, req *PlaceOrderRequest) (*PlaceOrderResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return req.Do(ctx)
+}
@@ -36,17 +36,17 @@ func (p *PlaceOrderRequest) Qty(qty string) *PlaceOrderRequest {
 	return p
 }
 
-func (p *PlaceOrderRequest) OrderLinkId(orderLinkId string) *PlaceOrderRequest {
-	p.orderLinkId = orderLinkId
+func (p *PlaceOrderRequest) TimeInForce(timeInForce TimeInForce) *PlaceOrderRequest {
+	p.timeInForce = timeInForce
 	return p
 }
 
-func (p *PlaceOrderRequest) TimeInForce(timeInForce TimeInForce) *PlaceOrderRequest {
-	p.timeInForce = timeInForce
+func (p *PlaceOrderRequest) OrderLinkId(orderLinkId string) *PlaceOrderRequest {
+	p.orderLinkId = &orderLinkId
 	return p
 }
 
-func (p *PlaceOrderRequest) IsLeverage(isLeverage bool) *PlaceOrderRequest {
+func (p *PlaceOrderRequest) IsLeverage(isLeverage IsLeverage) *PlaceOrderRequest {
 	p.isLeverage = &isLeverage
 	return p
 }
@@ -56,7 +56,7 @@ func (p *PlaceOrderRequest) Price(price string) *PlaceOrderRequest {
 	return p
 }
 
-func (p *PlaceOrderRequest) TriggerDirection(triggerDirection int) *PlaceOrderRequest {
+func (p *PlaceOrderRequest) TriggerDirection(triggerDirection TriggerDirection) *PlaceOrderRequest {
 	p.triggerDirection = &triggerDirection
 	return p
 }
@@ -71,7 +71,7 @@ func (p *PlaceOrderRequest) TriggerPrice(triggerPrice string) *PlaceOrderRequest
 	return p
 }
 
-func (p *PlaceOrderRequest) TriggerBy(triggerBy string) *PlaceOrderRequest {
+func (p *PlaceOrderRequest) TriggerBy(triggerBy TriggerBy) *PlaceOrderRequest {
 	p.triggerBy = &triggerBy
 	return p
 }
@@ -116,7 +116,7 @@ func (p *PlaceOrderRequest) CloseOnTrigger(closeOnTrigger bool) *PlaceOrderReque
 	return p
 }
 
-func (p *PlaceOrderRequest) SmpType(smpType string) *PlaceOrderRequest {
+func (p *PlaceOrderRequest) SmpType(smpType SmpType) *PlaceOrderRequest {
 	p.smpType = &smpType
 	return p
 }
@@ -171,7 +171,7 @@ func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 
 	// TEMPLATE check-valid-values
 	switch category {
-	case "spot":
+	case "spot", "linear", "inverse":
 		params["category"] = category
 
 	default:
@@ -224,17 +224,12 @@ func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 
 	// assign parameter of qty
 	params["qty"] = qty
-	// check orderLinkId field -> json key orderLinkId
-	orderLinkId := p.orderLinkId
-
-	// assign parameter of orderLinkId
-	params["orderLinkId"] = orderLinkId
 	// check timeInForce field -> json key timeInForce
 	timeInForce := p.timeInForce
 
 	// TEMPLATE check-valid-values
 	switch timeInForce {
-	case TimeInForceGTC, TimeInForceIOC, TimeInForceFOK:
+	case TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForcePostOnly:
 		params["timeInForce"] = timeInForce
 
 	default:
@@ -245,10 +240,29 @@ func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 
 	// assign parameter of timeInForce
 	params["timeInForce"] = timeInForce
+	// check orderLinkId field -> json key orderLinkId
+	if p.orderLinkId != nil {
+		orderLinkId := *p.orderLinkId
+
+		// assign parameter of orderLinkId
+		params["orderLinkId"] = orderLinkId
+	} else {
+	}
 	// check isLeverage field -> json key isLeverage
 	if p.isLeverage != nil {
 		isLeverage := *p.isLeverage
 
+		// TEMPLATE check-valid-values
+		switch isLeverage {
+		case IsLeverageFalse, IsLeverageTrue:
+			params["isLeverage"] = isLeverage
+
+		default:
+			return nil, fmt.Errorf("isLeverage value %v is invalid", isLeverage)
+
+		}
+		// END TEMPLATE check-valid-values
+
 		// assign parameter of isLeverage
 		params["isLeverage"] = isLeverage
 	} else {
@@ -265,6 +279,17 @@ func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 	if p.triggerDirection != nil {
 		triggerDirection := *p.triggerDirection
 
+		// TEMPLATE check-valid-values
+		switch triggerDirection {
+		case TriggerDirectionRise, TriggerDirectionFall:
+			params["triggerDirection"] = triggerDirection
+
+		default:
+			return nil, fmt.Errorf("triggerDirection value %v is invalid", triggerDirection)
+
+		}
+		// END TEMPLATE check-valid-values
+
 		// assign parameter of triggerDirection
 		params["triggerDirection"] = triggerDirection
 	} else {
@@ -289,6 +314,17 @@ func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 	if p.triggerBy != nil {
 		triggerBy := *p.triggerBy
 
+		// TEMPLATE check-valid-values
+		switch triggerBy {
+		case "LastPrice", "MarkPrice", "IndexPrice":
+			params["triggerBy"] = triggerBy
+
+		default:
+			return nil, fmt.Errorf("triggerBy value %v is invalid", triggerBy)
+
+		}
+		// END TEMPLATE check-valid-values
+
 		// assign parameter of triggerBy
 		params["triggerBy"] = triggerBy
 	} else {
@@ -361,6 +397,17 @@ func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 	if p.smpType != nil {
 		smpType := *p.smpType
 
+		// TEMPLATE check-valid-values
+		switch smpType {
+		case "None", "CancelMaker", "CancelTaker", "CancelBoth":
+			params["smpType"] = smpType
+
+		default:
+			return nil, fmt.Errorf("smpType value %v is invalid", smpType)
+
+		}
+		// END TEMPLATE check-valid-values
+
 		// assign parameter of smpType
 		params["smpType"] = smpType
 	} else {
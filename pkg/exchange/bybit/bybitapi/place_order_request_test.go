@@ -0,0 +1,251 @@
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestPlaceOrderRequest_Validate(t *testing.T) {
+	t.Run("spot margin order", func(t *testing.T) {
+		req := (&RestClient{}).NewPlaceOrderRequest().
+			Symbol("BTCUSDT").
+			Side(SideBuy).
+			OrderType(OrderTypeLimit).
+			Qty("0.001").
+			Price("28000").
+			TimeInForce(TimeInForceGTC).
+			IsLeverage(IsLeverageTrue)
+		assert.NoError(t, req.Validate())
+
+		params, err := req.GetParameters()
+		assert.NoError(t, err)
+		assert.Equal(t, IsLeverageTrue, params["isLeverage"])
+	})
+
+	t.Run("isLeverage with non-spot category", func(t *testing.T) {
+		req := (&RestClient{}).NewPlaceOrderRequest().IsLeverage(IsLeverageTrue).Category("linear")
+		assert.ErrorContains(t, req.Validate(), "isLeverage is only supported by the spot category")
+	})
+
+	t.Run("reduce only with spot category", func(t *testing.T) {
+		req := (&RestClient{}).NewPlaceOrderRequest().ReduceOnly(true)
+		assert.ErrorContains(t, req.Validate(), "reduceOnly is not supported by the spot category")
+
+		req = (&RestClient{}).NewPlaceOrderRequest().CloseOnTrigger(true)
+		assert.ErrorContains(t, req.Validate(), "closeOnTrigger is not supported by the spot category")
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		req := (&RestClient{}).NewPlaceOrderRequest().Category("futures")
+		assert.ErrorContains(t, req.Validate(), "unknown category")
+	})
+
+	t.Run("reduce only with linear category", func(t *testing.T) {
+		req := (&RestClient{}).NewPlaceOrderRequest().
+			Category(CategoryLinear).
+			Symbol("BTCUSDT").
+			Side(SideSell).
+			OrderType(OrderTypeMarket).
+			Qty("0.001").
+			TimeInForce(TimeInForceIOC).
+			ReduceOnly(true).
+			CloseOnTrigger(true)
+		assert.NoError(t, req.Validate())
+
+		params, err := req.GetParameters()
+		assert.NoError(t, err)
+		assert.Equal(t, true, params["reduceOnly"])
+		assert.Equal(t, true, params["closeOnTrigger"])
+	})
+
+	t.Run("smp type", func(t *testing.T) {
+		req := (&RestClient{}).NewPlaceOrderRequest().
+			Symbol("BTCUSDT").
+			Side(SideBuy).
+			OrderType(OrderTypeLimit).
+			Qty("0.001").
+			Price("28000").
+			TimeInForce(TimeInForceGTC).
+			SmpType(SmpTypeCancelMaker)
+		assert.NoError(t, req.Validate())
+
+		params, err := req.GetParameters()
+		assert.NoError(t, err)
+		assert.Equal(t, SmpTypeCancelMaker, params["smpType"])
+
+		_, err = req.SmpType("CancelNone").GetParameters()
+		assert.ErrorContains(t, err, "smpType value CancelNone is invalid")
+
+		req = (&RestClient{}).NewPlaceOrderRequest().Category(CategoryOption).SmpType(SmpTypeCancelBoth)
+		assert.ErrorContains(t, req.Validate(), "smpType is not supported by the option category")
+	})
+
+	t.Run("conditional order", func(t *testing.T) {
+		req := (&RestClient{}).NewPlaceOrderRequest().TriggerPrice("30000")
+		assert.ErrorContains(t, req.Validate(), "triggerDirection is required")
+
+		req.TriggerDirection(TriggerDirectionRise).TriggerBy(TriggerByLastPrice)
+		assert.NoError(t, req.Validate())
+	})
+}
+
+func TestPlaceOrderRequest_Round(t *testing.T) {
+	instruments := NewInstrumentsInfoCache(nil)
+	instruments.Update(Instrument{
+		Symbol: "BTCUSDT",
+		LotSizeFilter: LotSizeFilter{
+			BasePrecision:  fixedpoint.MustNewFromString("0.000001"),
+			QuotePrecision: fixedpoint.MustNewFromString("0.01"),
+			MinOrderQty:    fixedpoint.MustNewFromString("0.000048"),
+			MinOrderAmt:    fixedpoint.NewFromInt(1),
+		},
+		PriceFilter: PriceFilter{
+			TickSize: fixedpoint.MustNewFromString("0.01"),
+		},
+	})
+
+	newRequest := func() *PlaceOrderRequest {
+		return (&RestClient{}).NewPlaceOrderRequest().
+			Symbol("BTCUSDT").
+			Side(SideBuy).
+			OrderType(OrderTypeLimit).
+			Qty("0.0022899").
+			Price("28829.765").
+			TimeInForce(TimeInForceGTC)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		req := newRequest()
+		assert.NoError(t, req.Round(context.Background()))
+		assert.Equal(t, "0.0022899", req.qty)
+	})
+
+	t.Run("rounding modes", func(t *testing.T) {
+		for mode, expected := range map[fixedpoint.RoundingMode][2]string{
+			fixedpoint.Down:   {"0.002289", "28829.76"},
+			fixedpoint.HalfUp: {"0.00229", "28829.77"},
+			fixedpoint.Up:     {"0.00229", "28829.77"},
+		} {
+			req := newRequest().WithAutoRound(instruments, mode)
+			if assert.NoError(t, req.Round(context.Background())) {
+				assert.Equal(t, expected[0], req.qty)
+				assert.Equal(t, expected[1], *req.price)
+			}
+		}
+
+		req := newRequest().Qty("0.0022891").Price("28829.761").WithAutoRound(instruments, fixedpoint.HalfUp)
+		if assert.NoError(t, req.Round(context.Background())) {
+			assert.Equal(t, "0.002289", req.qty)
+			assert.Equal(t, "28829.76", *req.price)
+		}
+	})
+
+	t.Run("market buy in quote coin", func(t *testing.T) {
+		req := newRequest().OrderType(OrderTypeMarket).Qty("10.555").WithAutoRound(instruments, fixedpoint.Down)
+		req.price = nil
+		if assert.NoError(t, req.Round(context.Background())) {
+			assert.Equal(t, "10.55", req.qty)
+		}
+
+		req = newRequest().OrderType(OrderTypeMarket).Qty("0.999").WithAutoRound(instruments, fixedpoint.Down)
+		assert.ErrorContains(t, req.Round(context.Background()), "below the min qty 1")
+	})
+
+	t.Run("below the min qty", func(t *testing.T) {
+		req := newRequest().Qty("0.0000479").WithAutoRound(instruments, fixedpoint.Down)
+		assert.ErrorContains(t, req.Round(context.Background()), "the rounded qty 0.000047 of BTCUSDT is below the min qty 0.000048")
+
+		// the rounding up qty is accepted
+		req = newRequest().Qty("0.0000479").WithAutoRound(instruments, fixedpoint.Up)
+		if assert.NoError(t, req.Round(context.Background())) {
+			assert.Equal(t, "0.000048", req.qty)
+		}
+	})
+
+	t.Run("linear category", func(t *testing.T) {
+		instruments.UpdateInstruments(CategoryLinear, Instrument{
+			Symbol: "BTCUSDT",
+			LotSizeFilter: LotSizeFilter{
+				QtyStep:     fixedpoint.MustNewFromString("0.001"),
+				MinOrderQty: fixedpoint.MustNewFromString("0.001"),
+			},
+			PriceFilter: PriceFilter{
+				TickSize: fixedpoint.MustNewFromString("0.1"),
+			},
+		})
+
+		// the filters of the linear instrument are used, and the market buy qty is in the base coin
+		req := newRequest().Category(CategoryLinear).WithAutoRound(instruments, fixedpoint.Down)
+		if assert.NoError(t, req.Round(context.Background())) {
+			assert.Equal(t, "0.002", req.qty)
+			assert.Equal(t, "28829.7", *req.price)
+		}
+
+		req = newRequest().Category(CategoryLinear).OrderType(OrderTypeMarket).Qty("0.0009").WithAutoRound(instruments, fixedpoint.Down)
+		assert.ErrorContains(t, req.Round(context.Background()), "below the min qty 0.001")
+	})
+}
+
+func TestPlaceOrderRequest_Do_autoRound(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	var params map[string]interface{}
+	transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+		params = map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&params))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"orderId": "1", "orderLinkId": ""}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	instruments := NewInstrumentsInfoCache(client)
+	instruments.Update(Instrument{
+		Symbol: "BTCUSDT",
+		LotSizeFilter: LotSizeFilter{
+			BasePrecision: fixedpoint.MustNewFromString("0.000001"),
+			MinOrderQty:   fixedpoint.MustNewFromString("0.000048"),
+		},
+		PriceFilter: PriceFilter{
+			TickSize: fixedpoint.MustNewFromString("0.01"),
+		},
+	})
+
+	// the request is rounded by Do without PlaceOrder
+	_, err = client.NewPlaceOrderRequest().
+		Symbol("BTCUSDT").
+		Side(SideBuy).
+		OrderType(OrderTypeLimit).
+		Qty("0.0022899").
+		Price("28829.765").
+		TimeInForce(TimeInForceGTC).
+		WithAutoRound(instruments, fixedpoint.Down).
+		WithAutoRound(instruments, fixedpoint.Down).
+		Do(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "0.002289", params["qty"])
+	assert.Equal(t, "28829.76", params["price"])
+
+	// the request below the min qty isn't sent
+	params = nil
+	_, err = client.NewPlaceOrderRequest().
+		Symbol("BTCUSDT").
+		Side(SideBuy).
+		OrderType(OrderTypeLimit).
+		Qty("0.0000479").
+		Price("28829.765").
+		TimeInForce(TimeInForceGTC).
+		WithAutoRound(instruments, fixedpoint.Down).
+		Do(context.Background())
+	assert.ErrorContains(t, err, "below the min qty")
+	assert.Nil(t, params)
+}
@@ -0,0 +1,128 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The headers of the rate limit of the endpoint, they're returned by the authenticated endpoints.
+// See https://bybit-exchange.github.io/docs/v5/rate-limit
+const (
+	headerRateLimit          = "X-Bapi-Limit"
+	headerRateLimitStatus    = "X-Bapi-Limit-Status"
+	headerRateLimitResetTime = "X-Bapi-Limit-Reset-Timestamp"
+)
+
+// RateLimitStatus is the rate limit of an endpoint reported by the headers of the last response. The limit is counted
+// per endpoint and per uid by bybit.
+type RateLimitStatus struct {
+	// Endpoint is the path of the request, e.g. /v5/order/create.
+	Endpoint string
+	// Limit is the number of the requests allowed in the current window.
+	Limit int
+	// Remaining is the number of the requests left in the current window.
+	Remaining int
+	// ResetTime is when the window is reset, it's zero if the header is absent.
+	ResetTime time.Time
+	// UpdatedTime is when the response was received.
+	UpdatedTime time.Time
+}
+
+// IsExhausted returns true if there is no request left in the window which is not reset yet at the given time.
+func (s RateLimitStatus) IsExhausted(now time.Time) bool {
+	return s.Remaining <= 0 && now.Before(s.ResetTime)
+}
+
+// parseRateLimitStatus parses the rate limit headers, it returns false if the response has no rate limit headers,
+// e.g. the public endpoints.
+func parseRateLimitStatus(endpoint string, header http.Header, now time.Time) (RateLimitStatus, bool) {
+	limit, err := strconv.Atoi(header.Get(headerRateLimit))
+	if err != nil {
+		return RateLimitStatus{}, false
+	}
+
+	remaining, err := strconv.Atoi(header.Get(headerRateLimitStatus))
+	if err != nil {
+		return RateLimitStatus{}, false
+	}
+
+	status := RateLimitStatus{
+		Endpoint:    endpoint,
+		Limit:       limit,
+		Remaining:   remaining,
+		UpdatedTime: now,
+	}
+	if ms, err := strconv.ParseInt(header.Get(headerRateLimitResetTime), 10, 64); err == nil {
+		status.ResetTime = time.UnixMilli(ms)
+	}
+	return status, true
+}
+
+// EnableRateLimitThrottle holds the request of the endpoint whose quota is exhausted by the last response until the
+// window is reset, instead of sending it to be rejected. The request fails if the context is done before the reset.
+func (c *RestClient) EnableRateLimitThrottle() {
+	c.rateLimitThrottle = true
+}
+
+// RateLimitStatus returns the rate limits of the endpoints by the path, only the endpoints which returned the rate
+// limit headers are included.
+func (c *RestClient) RateLimitStatus() map[string]RateLimitStatus {
+	c.rateLimitsMu.Lock()
+	defer c.rateLimitsMu.Unlock()
+
+	statuses := make(map[string]RateLimitStatus, len(c.rateLimits))
+	for endpoint, status := range c.rateLimits {
+		statuses[endpoint] = status
+	}
+	return statuses
+}
+
+// EndpointRateLimitStatus returns the rate limit of the endpoint, it returns false if the endpoint has not returned
+// the rate limit headers yet.
+func (c *RestClient) EndpointRateLimitStatus(endpoint string) (RateLimitStatus, bool) {
+	c.rateLimitsMu.Lock()
+	defer c.rateLimitsMu.Unlock()
+
+	status, ok := c.rateLimits[endpoint]
+	return status, ok
+}
+
+func (c *RestClient) recordRateLimit(endpoint string, header http.Header) {
+	status, ok := parseRateLimitStatus(endpoint, header, time.Now())
+	if !ok {
+		return
+	}
+
+	c.rateLimitsMu.Lock()
+	defer c.rateLimitsMu.Unlock()
+
+	if c.rateLimits == nil {
+		c.rateLimits = make(map[string]RateLimitStatus)
+	}
+	c.rateLimits[endpoint] = status
+}
+
+// waitRateLimit waits until the window of the endpoint is reset if the quota is exhausted and the throttle is enabled.
+func (c *RestClient) waitRateLimit(ctx context.Context, endpoint string) error {
+	if !c.rateLimitThrottle {
+		return nil
+	}
+
+	status, ok := c.EndpointRateLimitStatus(endpoint)
+	now := time.Now()
+	if !ok || !status.IsExhausted(now) {
+		return nil
+	}
+
+	timer := time.NewTimer(status.ResetTime.Sub(now))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
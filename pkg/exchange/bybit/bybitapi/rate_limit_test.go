@@ -0,0 +1,78 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_RateLimitStatus(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+	client.SetRetryPolicy(nil)
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	var resetTime time.Time
+	transport.GET("/v5/order/realtime", func(req *http.Request) (*http.Response, error) {
+		resp := httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "list": [], "nextPageCursor": ""}, "retExtInfo": {}, "time": 1700000000000}`)
+		resp.Header = http.Header{}
+		resp.Header.Set("X-Bapi-Limit", "10")
+		resp.Header.Set("X-Bapi-Limit-Status", "0")
+		resp.Header.Set("X-Bapi-Limit-Reset-Timestamp", strconv.FormatInt(resetTime.UnixMilli(), 10))
+		return resp, nil
+	})
+	transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "list": []}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	ctx := context.Background()
+	resetTime = time.Now().Add(200 * time.Millisecond)
+	_, err = client.NewGetOpenOrderRequest().Do(ctx)
+	assert.NoError(t, err)
+
+	// the public endpoints have no rate limit headers
+	_, err = client.NewGetInstrumentsInfoRequest().Do(ctx)
+	assert.NoError(t, err)
+
+	statuses := client.RateLimitStatus()
+	if assert.Len(t, statuses, 1) {
+		status := statuses["/v5/order/realtime"]
+		assert.Equal(t, "/v5/order/realtime", status.Endpoint)
+		assert.Equal(t, 10, status.Limit)
+		assert.Equal(t, 0, status.Remaining)
+		assert.Equal(t, resetTime.UnixMilli(), status.ResetTime.UnixMilli())
+		assert.True(t, status.IsExhausted(time.Now()))
+		assert.False(t, status.IsExhausted(resetTime))
+	}
+
+	t.Run("throttle", func(t *testing.T) {
+		client.EnableRateLimitThrottle()
+
+		// the request is held until the window of the last response is reset, and the new window is exhausted again
+		start := time.Now()
+		resetTime = start.Add(time.Minute)
+		_, err = client.NewGetOpenOrderRequest().Do(ctx)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err = client.NewGetOpenOrderRequest().Do(timeoutCtx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// the other endpoints are not held
+		_, ok := client.EndpointRateLimitStatus("/v5/market/instruments-info")
+		assert.False(t, ok)
+		_, err = client.NewGetInstrumentsInfoRequest().Do(ctx)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,79 @@
+package bybitapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/c9s/requestgen"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
+)
+
+// RetryPolicy is the retry policy of the idempotent read requests. Only the GET requests are retried, and only on the
+// transport errors, the 5xx responses and the rate limited responses, so the order creation or the other write requests
+// will never be sent twice.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of the attempts including the first one, 1 or less means no retry.
+	MaxAttempts int
+	// InitialInterval is the interval before the first retry, the interval is doubled with the jitter on every retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the interval between the retries.
+	MaxInterval time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of the new client. The intervals are kept short since the signed request is
+// only valid in the recv window.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     3,
+	InitialInterval: 200 * time.Millisecond,
+	MaxInterval:     2 * time.Second,
+}
+
+func (p RetryPolicy) newBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = p.InitialInterval
+	b.MaxInterval = p.MaxInterval
+	// the elapsed time is bounded by the max attempts
+	b.MaxElapsedTime = 0
+	return backoff.WithMaxRetries(b, uint64(p.MaxAttempts-1))
+}
+
+// SetRetryPolicy sets the retry policy of the GET requests, pass nil to disable the retry.
+func (c *RestClient) SetRetryPolicy(policy *RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// sendWithRetry sends the request, the GET request is retried by the retry policy.
+func (c *RestClient) sendWithRetry(req *http.Request) (*requestgen.Response, error) {
+	policy := c.retryPolicy
+	if policy == nil || policy.MaxAttempts <= 1 || req.Method != http.MethodGet {
+		return c.send(req)
+	}
+
+	var response *requestgen.Response
+	op := func() (err error) {
+		response, err = c.send(req)
+		if err != nil && !isRetryable(response, err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+
+	err := backoff.Retry(op, backoff.WithContext(policy.newBackOff(), req.Context()))
+	return response, err
+}
+
+// isRetryable returns true if the request failed before the response (the transport errors) or the response is a
+// server error or rate limited.
+func isRetryable(response *requestgen.Response, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsRateLimited()
+	}
+
+	if response == nil {
+		return true
+	}
+
+	return response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests
+}
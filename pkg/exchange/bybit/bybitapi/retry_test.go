@@ -0,0 +1,127 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func newRetryTestClient(t *testing.T) (*RestClient, *httptesting.MockTransport) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+	client.SetRetryPolicy(&RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+	})
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+	return client, transport
+}
+
+func TestRestClient_SendRequest_retry(t *testing.T) {
+	t.Run("retry GET on server errors", func(t *testing.T) {
+		client, transport := newRetryTestClient(t)
+
+		attempts := 0
+		transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts < 3 {
+				return httptesting.BuildResponseString(http.StatusServiceUnavailable, "unavailable"), nil
+			}
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "list": []}, "retExtInfo": {}, "time": 1700000000000}`), nil
+		})
+
+		_, err := client.NewGetInstrumentsInfoRequest().Do(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("retry GET on rate limited retCode", func(t *testing.T) {
+		client, transport := newRetryTestClient(t)
+
+		attempts := 0
+		transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts < 2 {
+				return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 10006, "retMsg": "Too many visits!", "result": {}, "retExtInfo": {}, "time": 1700000000000}`), nil
+			}
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "list": []}, "retExtInfo": {}, "time": 1700000000000}`), nil
+		})
+
+		_, err := client.NewGetInstrumentsInfoRequest().Do(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("stop after max attempts", func(t *testing.T) {
+		client, transport := newRetryTestClient(t)
+
+		attempts := 0
+		transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return httptesting.BuildResponseString(http.StatusBadGateway, "bad gateway"), nil
+		})
+
+		_, err := client.NewGetInstrumentsInfoRequest().Do(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("no retry on client errors", func(t *testing.T) {
+		client, transport := newRetryTestClient(t)
+
+		attempts := 0
+		transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return httptesting.BuildResponseString(http.StatusBadRequest, "bad request"), nil
+		})
+
+		_, err := client.NewGetInstrumentsInfoRequest().Do(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("never retry order creation", func(t *testing.T) {
+		client, transport := newRetryTestClient(t)
+
+		attempts := 0
+		transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return httptesting.BuildResponseString(http.StatusServiceUnavailable, "unavailable"), nil
+		})
+
+		_, err := client.NewPlaceOrderRequest().
+			Symbol("BTCUSDT").
+			Side(SideBuy).
+			OrderType(OrderTypeMarket).
+			Qty("1").
+			OrderLinkId("test").
+			TimeInForce(TimeInForceGTC).
+			Do(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		client, transport := newRetryTestClient(t)
+		client.SetRetryPolicy(nil)
+
+		attempts := 0
+		transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return httptesting.BuildResponseString(http.StatusServiceUnavailable, "unavailable"), nil
+		})
+
+		_, err := client.NewGetInstrumentsInfoRequest().Do(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
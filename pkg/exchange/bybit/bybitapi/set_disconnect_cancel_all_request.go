@@ -0,0 +1,74 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/requestgen"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+const (
+	// MinDisconnectCancelAllWindow and MaxDisconnectCancelAllWindow are the range of the time window of the
+	// disconnected cancel all.
+	MinDisconnectCancelAllWindow = 3 * time.Second
+	MaxDisconnectCancelAllWindow = 300 * time.Second
+)
+
+// DisconnectCancelAllProduct is the product of which the orders are cancelled on the disconnection.
+type DisconnectCancelAllProduct string
+
+const (
+	DisconnectCancelAllProductOptions     DisconnectCancelAllProduct = "OPTIONS"
+	DisconnectCancelAllProductDerivatives DisconnectCancelAllProduct = "DERIVATIVES"
+	DisconnectCancelAllProductSpot        DisconnectCancelAllProduct = "SPOT"
+)
+
+type SetDisconnectCancelAllResponse struct{}
+
+// SetDisconnectCancelAllRequest sets the time window of the disconnected cancel all (DCP, the dead man's switch). Once
+// the private stream is disconnected and not reconnected in the time window, bybit cancels all the open orders of the
+// product. The DCP must be enabled for the account by bybit first.
+//
+//go:generate PostRequest -url "/v5/order/disconnected-cancel-all" -type SetDisconnectCancelAllRequest -responseDataType .SetDisconnectCancelAllResponse
+type SetDisconnectCancelAllRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	// product is OPTIONS by default.
+	product *DisconnectCancelAllProduct `param:"product" validValues:"OPTIONS,DERIVATIVES,SPOT"`
+	// timeWindow is in seconds, [3, 300].
+	timeWindow int `param:"timeWindow"`
+}
+
+func (c *RestClient) NewSetDisconnectCancelAllRequest() *SetDisconnectCancelAllRequest {
+	return &SetDisconnectCancelAllRequest{
+		client: c,
+	}
+}
+
+// Validate checks the time window is in the range, the generated code only checks the product.
+func (p *SetDisconnectCancelAllRequest) Validate() error {
+	window := time.Duration(p.timeWindow) * time.Second
+	if window < MinDisconnectCancelAllWindow || window > MaxDisconnectCancelAllWindow {
+		return fmt.Errorf("the time window of the disconnected cancel all must be between %s and %s, got %s",
+			MinDisconnectCancelAllWindow, MaxDisconnectCancelAllWindow, window)
+	}
+	return nil
+}
+
+// SetDisconnectCancelAll sets the time window of the disconnected cancel all of the product, the window is truncated to
+// seconds.
+func (c *RestClient) SetDisconnectCancelAll(ctx context.Context, product DisconnectCancelAllProduct, window time.Duration) error {
+	req := c.NewSetDisconnectCancelAllRequest().
+		Product(product).
+		TimeWindow(int(window / time.Second))
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	_, err := req.Do(ctx)
+	return err
+}
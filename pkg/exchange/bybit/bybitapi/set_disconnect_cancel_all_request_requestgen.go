@@ -0,0 +1,193 @@
+// Code generated by "requestgen -method POST -responseType .APIResponse -responseDataField Result -url /v5/order/disconnected-cancel-all -type SetDisconnectCancelAllRequest -responseDataType .SetDisconnectCancelAllResponse"; DO NOT EDIT.
+
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+func (p *SetDisconnectCancelAllRequest) Product(product DisconnectCancelAllProduct) *SetDisconnectCancelAllRequest {
+	p.product = &product
+	return p
+}
+
+func (p *SetDisconnectCancelAllRequest) TimeWindow(timeWindow int) *SetDisconnectCancelAllRequest {
+	p.timeWindow = timeWindow
+	return p
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (p *SetDisconnectCancelAllRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (p *SetDisconnectCancelAllRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check product field -> json key product
+	if p.product != nil {
+		product := *p.product
+
+		// TEMPLATE check-valid-values
+		switch product {
+		case "OPTIONS", "DERIVATIVES", "SPOT":
+			params["product"] = product
+
+		default:
+			return nil, fmt.Errorf("product value %v is invalid", product)
+
+		}
+		// END TEMPLATE check-valid-values
+
+		// assign parameter of product
+		params["product"] = product
+	} else {
+	}
+	// check timeWindow field -> json key timeWindow
+	timeWindow := p.timeWindow
+
+	// assign parameter of timeWindow
+	params["timeWindow"] = timeWindow
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (p *SetDisconnectCancelAllRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := p.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if p.isVarSlice(_v) {
+			p.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (p *SetDisconnectCancelAllRequest) GetParametersJSON() ([]byte, error) {
+	params, err := p.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (p *SetDisconnectCancelAllRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (p *SetDisconnectCancelAllRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (p *SetDisconnectCancelAllRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (p *SetDisconnectCancelAllRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (p *SetDisconnectCancelAllRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := p.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+// GetPath returns the request path of the API
+func (p *SetDisconnectCancelAllRequest) GetPath() string {
+	return "/v5/order/disconnected-cancel-all"
+}
+
+// Do generates the request object and send the request object to the API endpoint
+func (p *SetDisconnectCancelAllRequest) Do(ctx context.Context) (*SetDisconnectCancelAllResponse, error) {
+
+	params, err := p.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+
+	var apiURL string
+
+	apiURL = p.GetPath()
+
+	req, err := p.client.NewAuthenticatedRequest(ctx, "POST", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := p.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	type responseValidator interface {
+		Validate() error
+	}
+	validator, ok := interface{}(apiResponse).(responseValidator)
+	if ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	var data SetDisconnectCancelAllResponse
+	if err := json.Unmarshal(apiResponse.Result, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
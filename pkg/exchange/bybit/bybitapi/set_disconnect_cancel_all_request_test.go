@@ -0,0 +1,21 @@
+package bybitapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetDisconnectCancelAllRequest_Validate(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+
+	for _, window := range []time.Duration{MinDisconnectCancelAllWindow, time.Minute, MaxDisconnectCancelAllWindow} {
+		assert.NoError(t, client.NewSetDisconnectCancelAllRequest().TimeWindow(int(window/time.Second)).Validate())
+	}
+
+	err = client.NewSetDisconnectCancelAllRequest().TimeWindow(2).Validate()
+	assert.ErrorContains(t, err, "must be between 3s and 5m0s, got 2s")
+	assert.Error(t, client.NewSetDisconnectCancelAllRequest().TimeWindow(301).Validate())
+}
@@ -0,0 +1,66 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// RetCodeLeverageNotModified is returned when the leverage is set to the current leverage.
+const RetCodeLeverageNotModified = 110043
+
+type SetLeverageResponse struct{}
+
+//go:generate PostRequest -url "/v5/position/set-leverage" -type SetLeverageRequest -responseDataType .SetLeverageResponse
+type SetLeverageRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category Category `param:"category" validValues:"linear,inverse"`
+	symbol   string   `param:"symbol"`
+	// buyLeverage and sellLeverage must be the same in the one-way mode and the cross margin mode.
+	buyLeverage  string `param:"buyLeverage"`
+	sellLeverage string `param:"sellLeverage"`
+}
+
+func (c *RestClient) NewSetLeverageRequest() *SetLeverageRequest {
+	return &SetLeverageRequest{
+		client:   c,
+		category: CategoryLinear,
+	}
+}
+
+// Validate checks the category supports the leverage, the generated code only rejects the unknown categories.
+func (p *SetLeverageRequest) Validate() error {
+	if err := p.category.Validate(); err != nil {
+		return err
+	}
+
+	if !p.category.SupportsLeverage() {
+		return fmt.Errorf("the leverage is not supported by the %s category", p.category)
+	}
+	return nil
+}
+
+// SetLeverage sets the leverage of the linear symbol. Setting the leverage which is not modified is harmless, so the
+// not modified error is ignored.
+func (c *RestClient) SetLeverage(ctx context.Context, symbol string, buyLeverage, sellLeverage fixedpoint.Value) error {
+	req := c.NewSetLeverageRequest().
+		Symbol(symbol).
+		BuyLeverage(buyLeverage.String()).
+		SellLeverage(sellLeverage.String())
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	_, err := req.Do(ctx)
+	if err != nil && !IsRetCode(err, RetCodeLeverageNotModified) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+// Code generated by "requestgen -method POST -responseType .APIResponse -responseDataField Result -url /v5/position/set-leverage -type SetLeverageRequest -responseDataType .SetLeverageResponse"; DO NOT EDIT.
+
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+func (s *SetLeverageRequest) Category(category Category) *SetLeverageRequest {
+	s.category = category
+	return s
+}
+
+func (s *SetLeverageRequest) Symbol(symbol string) *SetLeverageRequest {
+	s.symbol = symbol
+	return s
+}
+
+func (s *SetLeverageRequest) BuyLeverage(buyLeverage string) *SetLeverageRequest {
+	s.buyLeverage = buyLeverage
+	return s
+}
+
+func (s *SetLeverageRequest) SellLeverage(sellLeverage string) *SetLeverageRequest {
+	s.sellLeverage = sellLeverage
+	return s
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (s *SetLeverageRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (s *SetLeverageRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check category field -> json key category
+	category := s.category
+
+	// TEMPLATE check-valid-values
+	switch category {
+	case "linear", "inverse":
+		params["category"] = category
+
+	default:
+		return nil, fmt.Errorf("category value %v is invalid", category)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of category
+	params["category"] = category
+	// check symbol field -> json key symbol
+	symbol := s.symbol
+
+	// assign parameter of symbol
+	params["symbol"] = symbol
+	// check buyLeverage field -> json key buyLeverage
+	buyLeverage := s.buyLeverage
+
+	// assign parameter of buyLeverage
+	params["buyLeverage"] = buyLeverage
+	// check sellLeverage field -> json key sellLeverage
+	sellLeverage := s.sellLeverage
+
+	// assign parameter of sellLeverage
+	params["sellLeverage"] = sellLeverage
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (s *SetLeverageRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := s.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if s.isVarSlice(_v) {
+			s.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (s *SetLeverageRequest) GetParametersJSON() ([]byte, error) {
+	params, err := s.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (s *SetLeverageRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (s *SetLeverageRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (s *SetLeverageRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (s *SetLeverageRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (s *SetLeverageRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := s.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+// GetPath returns the request path of the API
+func (s *SetLeverageRequest) GetPath() string {
+	return "/v5/position/set-leverage"
+}
+
+// Do generates the request object and send the request object to the API endpoint
+func (s *SetLeverageRequest) Do(ctx context.Context) (*SetLeverageResponse, error) {
+
+	params, err := s.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+
+	var apiURL string
+
+	apiURL = s.GetPath()
+
+	req, err := s.client.NewAuthenticatedRequest(ctx, "POST", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	type responseValidator interface {
+		Validate() error
+	}
+	validator, ok := interface{}(apiResponse).(responseValidator)
+	if ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	var data SetLeverageResponse
+	if err := json.Unmarshal(apiResponse.Result, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,51 @@
+package bybitapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestRestClient_SetLeverage(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	retCode := 0
+	transport.POST("/v5/position/set-leverage", func(req *http.Request) (*http.Response, error) {
+		return httptesting.BuildResponseString(http.StatusOK, fmt.Sprintf(
+			`{"retCode": %d, "retMsg": "", "result": {}, "retExtInfo": {}, "time": 1700000000000}`, retCode)), nil
+	})
+
+	leverage := fixedpoint.NewFromInt(3)
+
+	assert.NoError(t, client.SetLeverage(context.Background(), "BTCUSDT", leverage, leverage))
+
+	retCode = RetCodeLeverageNotModified
+	assert.NoError(t, client.SetLeverage(context.Background(), "BTCUSDT", leverage, leverage))
+
+	retCode = 10001
+	err = client.SetLeverage(context.Background(), "BTCUSDT", leverage, leverage)
+	assert.Error(t, err)
+	assert.True(t, IsRetCode(err, 10001))
+}
+
+func TestSetLeverageRequest_Validate(t *testing.T) {
+	req := (&RestClient{}).NewSetLeverageRequest()
+	assert.NoError(t, req.Validate())
+
+	req.Category(CategorySpot)
+	assert.ErrorContains(t, req.Validate(), "the leverage is not supported by the spot category")
+
+	req.Category("futures")
+	assert.ErrorContains(t, req.Validate(), "unknown category")
+}
@@ -0,0 +1,73 @@
+package bybitapi
+
+import (
+	"strings"
+
+	"github.com/c9s/requestgen"
+	"github.com/pkg/errors"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// PositionIdx identifies the position, the one-way mode has only one position of the symbol, and the hedge mode has
+// the buy side and the sell side positions.
+type PositionIdx int
+
+const (
+	PositionIdxOneWay    PositionIdx = 0
+	PositionIdxHedgeBuy  PositionIdx = 1
+	PositionIdxHedgeSell PositionIdx = 2
+)
+
+// TriggerBy is the price type to trigger the take profit, stop loss or trailing stop.
+type TriggerBy string
+
+const (
+	TriggerByLastPrice  TriggerBy = "LastPrice"
+	TriggerByMarkPrice  TriggerBy = "MarkPrice"
+	TriggerByIndexPrice TriggerBy = "IndexPrice"
+)
+
+// RetCodeZeroPosition is returned with the params error code when setting the trading stop without a position.
+const RetCodeZeroPosition = 10001
+
+type SetTradingStopResponse struct{}
+
+//go:generate PostRequest -url "/v5/position/trading-stop" -type SetTradingStopRequest -responseDataType .SetTradingStopResponse
+type SetTradingStopRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category    `param:"category" validValues:"linear,inverse"`
+	symbol      string      `param:"symbol"`
+	positionIdx PositionIdx `param:"positionIdx"`
+
+	// takeProfit and stopLoss cancel the take profit or the stop loss if it's 0.
+	takeProfit *string `param:"takeProfit"`
+	stopLoss   *string `param:"stopLoss"`
+	// trailingStop is the price distance of the trailing stop, it cancels the trailing stop if it's 0.
+	trailingStop *string    `param:"trailingStop"`
+	tpTriggerBy  *TriggerBy `param:"tpTriggerBy" validValues:"LastPrice,MarkPrice,IndexPrice"`
+	slTriggerBy  *TriggerBy `param:"slTriggerBy" validValues:"LastPrice,MarkPrice,IndexPrice"`
+	// activePrice is the price to trigger the trailing stop.
+	activePrice *string `param:"activePrice"`
+	tpslMode    *string `param:"tpslMode" validValues:"Full,Partial"`
+	tpSize      *string `param:"tpSize"`
+	slSize      *string `param:"slSize"`
+}
+
+func (c *RestClient) NewSetTradingStopRequest() *SetTradingStopRequest {
+	return &SetTradingStopRequest{
+		client:      c,
+		category:    CategoryLinear,
+		positionIdx: PositionIdxOneWay,
+	}
+}
+
+// IsZeroPositionError returns true if the trading stop is rejected since there is no position.
+func IsZeroPositionError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) &&
+		apiErr.RetCode == RetCodeZeroPosition &&
+		strings.Contains(strings.ToLower(apiErr.RetMsg), "zero position")
+}
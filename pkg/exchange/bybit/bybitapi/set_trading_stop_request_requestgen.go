@@ -0,0 +1,361 @@
+// Code generated by "requestgen -method POST -responseType .APIResponse -responseDataField Result -url /v5/position/trading-stop -type SetTradingStopRequest -responseDataType .SetTradingStopResponse"; DO NOT EDIT.
+
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+func (s *SetTradingStopRequest) Category(category Category) *SetTradingStopRequest {
+	s.category = category
+	return s
+}
+
+func (s *SetTradingStopRequest) Symbol(symbol string) *SetTradingStopRequest {
+	s.symbol = symbol
+	return s
+}
+
+func (s *SetTradingStopRequest) PositionIdx(positionIdx PositionIdx) *SetTradingStopRequest {
+	s.positionIdx = positionIdx
+	return s
+}
+
+func (s *SetTradingStopRequest) TakeProfit(takeProfit string) *SetTradingStopRequest {
+	s.takeProfit = &takeProfit
+	return s
+}
+
+func (s *SetTradingStopRequest) StopLoss(stopLoss string) *SetTradingStopRequest {
+	s.stopLoss = &stopLoss
+	return s
+}
+
+func (s *SetTradingStopRequest) TrailingStop(trailingStop string) *SetTradingStopRequest {
+	s.trailingStop = &trailingStop
+	return s
+}
+
+func (s *SetTradingStopRequest) TpTriggerBy(tpTriggerBy TriggerBy) *SetTradingStopRequest {
+	s.tpTriggerBy = &tpTriggerBy
+	return s
+}
+
+func (s *SetTradingStopRequest) SlTriggerBy(slTriggerBy TriggerBy) *SetTradingStopRequest {
+	s.slTriggerBy = &slTriggerBy
+	return s
+}
+
+func (s *SetTradingStopRequest) ActivePrice(activePrice string) *SetTradingStopRequest {
+	s.activePrice = &activePrice
+	return s
+}
+
+func (s *SetTradingStopRequest) TpslMode(tpslMode string) *SetTradingStopRequest {
+	s.tpslMode = &tpslMode
+	return s
+}
+
+func (s *SetTradingStopRequest) TpSize(tpSize string) *SetTradingStopRequest {
+	s.tpSize = &tpSize
+	return s
+}
+
+func (s *SetTradingStopRequest) SlSize(slSize string) *SetTradingStopRequest {
+	s.slSize = &slSize
+	return s
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (s *SetTradingStopRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (s *SetTradingStopRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check category field -> json key category
+	category := s.category
+
+	// TEMPLATE check-valid-values
+	switch category {
+	case "linear", "inverse":
+		params["category"] = category
+
+	default:
+		return nil, fmt.Errorf("category value %v is invalid", category)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of category
+	params["category"] = category
+	// check symbol field -> json key symbol
+	symbol := s.symbol
+
+	// assign parameter of symbol
+	params["symbol"] = symbol
+	// check positionIdx field -> json key positionIdx
+	positionIdx := s.positionIdx
+
+	// TEMPLATE check-valid-values
+	switch positionIdx {
+	case PositionIdxOneWay, PositionIdxHedgeBuy, PositionIdxHedgeSell:
+		params["positionIdx"] = positionIdx
+
+	default:
+		return nil, fmt.Errorf("positionIdx value %v is invalid", positionIdx)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of positionIdx
+	params["positionIdx"] = positionIdx
+	// check takeProfit field -> json key takeProfit
+	if s.takeProfit != nil {
+		takeProfit := *s.takeProfit
+
+		// assign parameter of takeProfit
+		params["takeProfit"] = takeProfit
+	} else {
+	}
+	// check stopLoss field -> json key stopLoss
+	if s.stopLoss != nil {
+		stopLoss := *s.stopLoss
+
+		// assign parameter of stopLoss
+		params["stopLoss"] = stopLoss
+	} else {
+	}
+	// check trailingStop field -> json key trailingStop
+	if s.trailingStop != nil {
+		trailingStop := *s.trailingStop
+
+		// assign parameter of trailingStop
+		params["trailingStop"] = trailingStop
+	} else {
+	}
+	// check tpTriggerBy field -> json key tpTriggerBy
+	if s.tpTriggerBy != nil {
+		tpTriggerBy := *s.tpTriggerBy
+
+		// TEMPLATE check-valid-values
+		switch tpTriggerBy {
+		case "LastPrice", "MarkPrice", "IndexPrice":
+			params["tpTriggerBy"] = tpTriggerBy
+
+		default:
+			return nil, fmt.Errorf("tpTriggerBy value %v is invalid", tpTriggerBy)
+
+		}
+		// END TEMPLATE check-valid-values
+
+		// assign parameter of tpTriggerBy
+		params["tpTriggerBy"] = tpTriggerBy
+	} else {
+	}
+	// check slTriggerBy field -> json key slTriggerBy
+	if s.slTriggerBy != nil {
+		slTriggerBy := *s.slTriggerBy
+
+		// TEMPLATE check-valid-values
+		switch slTriggerBy {
+		case "LastPrice", "MarkPrice", "IndexPrice":
+			params["slTriggerBy"] = slTriggerBy
+
+		default:
+			return nil, fmt.Errorf("slTriggerBy value %v is invalid", slTriggerBy)
+
+		}
+		// END TEMPLATE check-valid-values
+
+		// assign parameter of slTriggerBy
+		params["slTriggerBy"] = slTriggerBy
+	} else {
+	}
+	// check activePrice field -> json key activePrice
+	if s.activePrice != nil {
+		activePrice := *s.activePrice
+
+		// assign parameter of activePrice
+		params["activePrice"] = activePrice
+	} else {
+	}
+	// check tpslMode field -> json key tpslMode
+	if s.tpslMode != nil {
+		tpslMode := *s.tpslMode
+
+		// TEMPLATE check-valid-values
+		switch tpslMode {
+		case "Full", "Partial":
+			params["tpslMode"] = tpslMode
+
+		default:
+			return nil, fmt.Errorf("tpslMode value %v is invalid", tpslMode)
+
+		}
+		// END TEMPLATE check-valid-values
+
+		// assign parameter of tpslMode
+		params["tpslMode"] = tpslMode
+	} else {
+	}
+	// check tpSize field -> json key tpSize
+	if s.tpSize != nil {
+		tpSize := *s.tpSize
+
+		// assign parameter of tpSize
+		params["tpSize"] = tpSize
+	} else {
+	}
+	// check slSize field -> json key slSize
+	if s.slSize != nil {
+		slSize := *s.slSize
+
+		// assign parameter of slSize
+		params["slSize"] = slSize
+	} else {
+	}
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (s *SetTradingStopRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := s.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if s.isVarSlice(_v) {
+			s.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (s *SetTradingStopRequest) GetParametersJSON() ([]byte, error) {
+	params, err := s.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (s *SetTradingStopRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (s *SetTradingStopRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (s *SetTradingStopRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (s *SetTradingStopRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (s *SetTradingStopRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := s.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+// GetPath returns the request path of the API
+func (s *SetTradingStopRequest) GetPath() string {
+	return "/v5/position/trading-stop"
+}
+
+// Do generates the request object and send the request object to the API endpoint
+func (s *SetTradingStopRequest) Do(ctx context.Context) (*SetTradingStopResponse, error) {
+
+	params, err := s.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+
+	var apiURL string
+
+	apiURL = s.GetPath()
+
+	req, err := s.client.NewAuthenticatedRequest(ctx, "POST", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	type responseValidator interface {
+		Validate() error
+	}
+	validator, ok := interface{}(apiResponse).(responseValidator)
+	if ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	var data SetTradingStopResponse
+	if err := json.Unmarshal(apiResponse.Result, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
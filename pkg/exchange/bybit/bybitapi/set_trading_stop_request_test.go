@@ -0,0 +1,47 @@
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestSetTradingStopRequest(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.POST("/v5/position/trading-stop", func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+
+		params := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(body, &params))
+		assert.Equal(t, "linear", params["category"])
+		assert.Equal(t, float64(PositionIdxHedgeSell), params["positionIdx"])
+		assert.Equal(t, "25000", params["stopLoss"])
+		assert.Equal(t, "MarkPrice", params["slTriggerBy"])
+
+		return httptesting.BuildResponseString(http.StatusOK,
+			`{"retCode": 10001, "retMsg": "can not set tp/sl/ts for zero position", "result": {}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	_, err = client.NewSetTradingStopRequest().
+		Symbol("BTCUSDT").
+		PositionIdx(PositionIdxHedgeSell).
+		StopLoss("25000").
+		SlTriggerBy(TriggerByMarkPrice).
+		Do(context.Background())
+	assert.Error(t, err)
+	assert.True(t, IsZeroPositionError(err))
+	assert.False(t, IsZeroPositionError(&APIError{RetCode: RetCodeZeroPosition, RetMsg: "params error"}))
+}
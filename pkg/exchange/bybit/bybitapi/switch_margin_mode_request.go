@@ -0,0 +1,58 @@
+package bybitapi
+
+import (
+	"context"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// RetCodeMarginModeNotModified is returned when the margin mode is switched to the current margin mode.
+const RetCodeMarginModeNotModified = 110026
+
+// TradeMode is the margin mode of the position.
+type TradeMode int
+
+const (
+	TradeModeCrossMargin    TradeMode = 0
+	TradeModeIsolatedMargin TradeMode = 1
+)
+
+type SwitchMarginModeResponse struct{}
+
+//go:generate PostRequest -url "/v5/position/switch-isolated" -type SwitchMarginModeRequest -responseDataType .SwitchMarginModeResponse
+type SwitchMarginModeRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category  Category  `param:"category" validValues:"linear,inverse"`
+	symbol    string    `param:"symbol"`
+	tradeMode TradeMode `param:"tradeMode"`
+	// buyLeverage and sellLeverage must be the same in the cross margin mode.
+	buyLeverage  string `param:"buyLeverage"`
+	sellLeverage string `param:"sellLeverage"`
+}
+
+func (c *RestClient) NewSwitchMarginModeRequest() *SwitchMarginModeRequest {
+	return &SwitchMarginModeRequest{
+		client:   c,
+		category: CategoryLinear,
+	}
+}
+
+// SwitchMarginMode switches the margin mode of the linear symbol, switching to the current margin mode is not an error.
+func (c *RestClient) SwitchMarginMode(ctx context.Context, symbol string, tradeMode TradeMode, leverage fixedpoint.Value) error {
+	_, err := c.NewSwitchMarginModeRequest().
+		Symbol(symbol).
+		TradeMode(tradeMode).
+		BuyLeverage(leverage.String()).
+		SellLeverage(leverage.String()).
+		Do(ctx)
+	if err != nil && !IsRetCode(err, RetCodeMarginModeNotModified) {
+		return err
+	}
+	return nil
+}
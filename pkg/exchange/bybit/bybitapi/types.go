@@ -1,6 +1,10 @@
 package bybitapi
 
-import "github.com/c9s/bbgo/pkg/types"
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
 
 var (
 	SupportedIntervals = map[types.Interval]int{
@@ -39,9 +43,107 @@ var (
 type Category string
 
 const (
-	CategorySpot Category = "spot"
+	CategorySpot    Category = "spot"
+	CategoryLinear  Category = "linear"
+	CategoryInverse Category = "inverse"
+	CategoryOption  Category = "option"
+)
+
+// Validate returns an error if the category is not one of the categories of bybit.
+func (c Category) Validate() error {
+	switch c {
+	case CategorySpot, CategoryLinear, CategoryInverse, CategoryOption:
+		return nil
+	}
+	return fmt.Errorf("unknown category: %q", string(c))
+}
+
+// SupportsLeverage returns true if the leverage of the symbols of the category can be set, i.e. the futures.
+func (c Category) SupportsLeverage() bool {
+	return c == CategoryLinear || c == CategoryInverse
+}
+
+// SupportsReduceOnly returns true if the orders of the category can be reduce-only or close-on-trigger, i.e. the
+// categories with the positions.
+func (c Category) SupportsReduceOnly() bool {
+	return c == CategoryLinear || c == CategoryInverse || c == CategoryOption
+}
+
+// SupportsFunding returns true if the symbols of the category have the funding rates, i.e. the perpetual futures.
+func (c Category) SupportsFunding() bool {
+	return c == CategoryLinear || c == CategoryInverse
+}
+
+// SupportsMarketStats returns true if the symbols of the category have the market statistics of the derivatives, e.g.
+// the open interest and the long short ratio, i.e. the futures.
+func (c Category) SupportsMarketStats() bool {
+	return c == CategoryLinear || c == CategoryInverse
+}
+
+// IntervalPeriod is the period of the market statistics of the derivatives, e.g. the open interest and the long short
+// ratio. It's different from the interval of the k lines.
+type IntervalPeriod string
+
+const (
+	IntervalPeriod5m  IntervalPeriod = "5min"
+	IntervalPeriod15m IntervalPeriod = "15min"
+	IntervalPeriod30m IntervalPeriod = "30min"
+	IntervalPeriod1h  IntervalPeriod = "1h"
+	IntervalPeriod4h  IntervalPeriod = "4h"
+	IntervalPeriod1d  IntervalPeriod = "1d"
+)
+
+// ToIntervalPeriod converts the global interval to the interval period, the intervals without a period are absent.
+var ToIntervalPeriod = map[types.Interval]IntervalPeriod{
+	types.Interval5m:  IntervalPeriod5m,
+	types.Interval15m: IntervalPeriod15m,
+	types.Interval30m: IntervalPeriod30m,
+	types.Interval1h:  IntervalPeriod1h,
+	types.Interval4h:  IntervalPeriod4h,
+	types.Interval1d:  IntervalPeriod1d,
+}
+
+// Validate returns an error if the period is not one of the interval periods of bybit.
+func (p IntervalPeriod) Validate() error {
+	switch p {
+	case IntervalPeriod5m, IntervalPeriod15m, IntervalPeriod30m, IntervalPeriod1h, IntervalPeriod4h, IntervalPeriod1d:
+		return nil
+	}
+	return fmt.Errorf("unknown interval period: %q", string(p))
+}
+
+// SupportsSmp returns true if the orders of the category can be placed with the self match prevention type.
+func (c Category) SupportsSmp() bool {
+	return c == CategorySpot || c == CategoryLinear || c == CategoryInverse
+}
+
+// SmpType is the self match prevention (SMP) type of the order, it decides which order is cancelled if the order
+// would match another order of the same uid or smp group.
+// https://bybit-exchange.github.io/docs/v5/smp
+type SmpType string
+
+const (
+	SmpTypeNone SmpType = "None"
+	// SmpTypeCancelMaker cancels the maker order, the taker order is kept.
+	SmpTypeCancelMaker SmpType = "CancelMaker"
+	// SmpTypeCancelTaker cancels the taker order, the maker order is kept.
+	SmpTypeCancelTaker SmpType = "CancelTaker"
+	// SmpTypeCancelBoth cancels both the maker and the taker orders.
+	SmpTypeCancelBoth SmpType = "CancelBoth"
 )
 
+// Validate returns an error if the type is not one of the smp types of bybit.
+func (t SmpType) Validate() error {
+	switch t {
+	case SmpTypeNone, SmpTypeCancelMaker, SmpTypeCancelTaker, SmpTypeCancelBoth:
+		return nil
+	}
+	return fmt.Errorf("unknown smp type: %q", string(t))
+}
+
+// CancelTypeCancelBySmp is the cancel type of the order cancelled by the self match prevention.
+const CancelTypeCancelBySmp = "CancelBySmp"
+
 type Status string
 
 const (
@@ -62,6 +164,51 @@ const (
 	SideSell Side = "Sell"
 )
 
+// ExecType is the type of the execution.
+// https://bybit-exchange.github.io/docs/v5/enum#exectype
+type ExecType string
+
+const (
+	ExecTypeTrade ExecType = "Trade"
+	// ExecTypeAdlTrade is the auto-deleveraging execution.
+	ExecTypeAdlTrade ExecType = "AdlTrade"
+	ExecTypeFunding  ExecType = "Funding"
+	// ExecTypeBustTrade is the liquidation execution.
+	ExecTypeBustTrade    ExecType = "BustTrade"
+	ExecTypeDelivery     ExecType = "Delivery"
+	ExecTypeSettle       ExecType = "Settle"
+	ExecTypeBlockTrade   ExecType = "BlockTrade"
+	ExecTypeMovePosition ExecType = "MovePosition"
+)
+
+// TriggerDirection is the price direction which triggers the conditional order.
+type TriggerDirection int
+
+const (
+	// TriggerDirectionRise triggers the order when the price rises to the trigger price.
+	TriggerDirectionRise TriggerDirection = 1
+	// TriggerDirectionFall triggers the order when the price falls to the trigger price.
+	TriggerDirectionFall TriggerDirection = 2
+)
+
+// OrderFilterStopOrder is the order filter of the spot conditional orders.
+const OrderFilterStopOrder = "StopOrder"
+
+// StopOrderType is the type of the conditional order, it's empty for the plain order.
+type StopOrderType string
+
+const (
+	// StopOrderTypeStop is the conditional order placed with the trigger price.
+	StopOrderTypeStop         StopOrderType = "Stop"
+	StopOrderTypeTakeProfit   StopOrderType = "TakeProfit"
+	StopOrderTypeStopLoss     StopOrderType = "StopLoss"
+	StopOrderTypeTrailingStop StopOrderType = "TrailingStop"
+	// StopOrderTypeTpslOrder is the spot take profit or stop loss order.
+	StopOrderTypeTpslOrder StopOrderType = "tpslOrder"
+	// StopOrderTypeOcoOrder is the spot one-cancels-the-other order.
+	StopOrderTypeOcoOrder StopOrderType = "OcoOrder"
+)
+
 type OrderStatus string
 
 const (
@@ -79,12 +226,11 @@ const (
 	// Following statuses is conditional orders. Once you place conditional orders, it will be in untriggered status.
 	// Untriggered -> Triggered ->  New
 	// Once the trigger price reached, order status will be moved to triggered
-	// Singe BBGO not support Untriggered/Triggered, so comment it.
 	//
 	// OrderStatusUntriggered means that the order not triggered
-	//OrderStatusUntriggered OrderStatus = "Untriggered"
-	//// OrderStatusTriggered means that the order has been triggered
-	//OrderStatusTriggered OrderStatus = "Triggered"
+	OrderStatusUntriggered OrderStatus = "Untriggered"
+	// OrderStatusTriggered means that the order has been triggered
+	OrderStatusTriggered OrderStatus = "Triggered"
 
 	// Following statuses is stop orders
 	// OrderStatusDeactivated is an order status for stopOrders.
@@ -123,8 +269,69 @@ const (
 	TimeInForceGTC TimeInForce = "GTC"
 	TimeInForceIOC TimeInForce = "IOC"
 	TimeInForceFOK TimeInForce = "FOK"
+	// TimeInForcePostOnly is the maker only order, it's cancelled if it would take the liquidity.
+	TimeInForcePostOnly TimeInForce = "PostOnly"
+)
+
+// ToGlobalTimeInForce converts the time in force to the global one. The global time in force has no post only, the
+// post only order is a GTC limit maker order, so postOnly is true for TimeInForcePostOnly.
+func ToGlobalTimeInForce(tif TimeInForce) (timeInForce types.TimeInForce, postOnly bool, err error) {
+	switch tif {
+	case TimeInForceGTC:
+		return types.TimeInForceGTC, false, nil
+
+	case TimeInForceIOC:
+		return types.TimeInForceIOC, false, nil
+
+	case TimeInForceFOK:
+		return types.TimeInForceFOK, false, nil
+
+	case TimeInForcePostOnly:
+		return types.TimeInForceGTC, true, nil
+
+	default:
+		return types.TimeInForce(tif), false, fmt.Errorf("unexpected timeInForce type: %s", tif)
+	}
+}
+
+// ToLocalTimeInForce converts the global time in force to the local one, the post only order (the limit maker order)
+// is always TimeInForcePostOnly. The empty time in force is GTC.
+func ToLocalTimeInForce(tif types.TimeInForce, postOnly bool) (TimeInForce, error) {
+	if postOnly {
+		return TimeInForcePostOnly, nil
+	}
+
+	switch tif {
+	case types.TimeInForceGTC, "":
+		return TimeInForceGTC, nil
+
+	case types.TimeInForceIOC:
+		return TimeInForceIOC, nil
+
+	case types.TimeInForceFOK:
+		return TimeInForceFOK, nil
+
+	default:
+		return "", fmt.Errorf("timeInForce %s not supported", tif)
+	}
+}
+
+// IsLeverage indicates whether to borrow, it's only valid for the unified spot margin trading.
+type IsLeverage int
+
+const (
+	// IsLeverageFalse places the order as the normal spot trading
+	IsLeverageFalse IsLeverage = 0
+	// IsLeverageTrue places the order as the margin trading
+	IsLeverageTrue IsLeverage = 1
 )
 
 type AccountType string
 
-const AccountTypeSpot AccountType = "SPOT"
+const (
+	AccountTypeSpot AccountType = "SPOT"
+	// AccountTypeUnified is the unified trading account, it trades spot/linear/options.
+	AccountTypeUnified AccountType = "UNIFIED"
+	// AccountTypeContract trades inverse in the unified account, or derivatives in the normal account.
+	AccountTypeContract AccountType = "CONTRACT"
+)
@@ -39,3 +39,76 @@ func Test_ToGlobalInterval(t *testing.T) {
 	assert.Equal(t, ToGlobalInterval["W"], types.Interval1w)
 	assert.Equal(t, ToGlobalInterval["M"], types.Interval1mo)
 }
+
+func Test_TimeInForce(t *testing.T) {
+	for _, tif := range []TimeInForce{TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForcePostOnly} {
+		global, postOnly, err := ToGlobalTimeInForce(tif)
+		assert.NoError(t, err)
+		assert.Equal(t, tif == TimeInForcePostOnly, postOnly)
+
+		local, err := ToLocalTimeInForce(global, postOnly)
+		assert.NoError(t, err)
+		assert.Equal(t, tif, local)
+	}
+
+	global, postOnly, err := ToGlobalTimeInForce(TimeInForcePostOnly)
+	assert.NoError(t, err)
+	assert.True(t, postOnly)
+	assert.Equal(t, types.TimeInForceGTC, global)
+
+	local, err := ToLocalTimeInForce("", false)
+	assert.NoError(t, err)
+	assert.Equal(t, TimeInForceGTC, local)
+
+	_, _, err = ToGlobalTimeInForce("GG")
+	assert.Error(t, err)
+
+	_, err = ToLocalTimeInForce("GG", false)
+	assert.Error(t, err)
+}
+
+func TestCategory(t *testing.T) {
+	for _, category := range []Category{CategorySpot, CategoryLinear, CategoryInverse, CategoryOption} {
+		assert.NoError(t, category.Validate())
+	}
+	assert.ErrorContains(t, Category("futures").Validate(), `unknown category: "futures"`)
+	assert.Error(t, Category("").Validate())
+
+	assert.False(t, CategorySpot.SupportsLeverage())
+	assert.False(t, CategorySpot.SupportsReduceOnly())
+	assert.False(t, CategorySpot.SupportsFunding())
+	assert.False(t, CategorySpot.SupportsMarketStats())
+
+	assert.True(t, CategoryLinear.SupportsLeverage())
+	assert.True(t, CategoryInverse.SupportsReduceOnly())
+	assert.True(t, CategoryInverse.SupportsFunding())
+	assert.True(t, CategoryLinear.SupportsMarketStats())
+
+	assert.False(t, CategoryOption.SupportsLeverage())
+	assert.True(t, CategoryOption.SupportsReduceOnly())
+	assert.False(t, CategoryOption.SupportsFunding())
+	assert.False(t, CategoryOption.SupportsMarketStats())
+
+	assert.True(t, CategorySpot.SupportsSmp())
+	assert.True(t, CategoryLinear.SupportsSmp())
+	assert.False(t, CategoryOption.SupportsSmp())
+}
+
+func TestSmpType(t *testing.T) {
+	for _, smpType := range []SmpType{SmpTypeNone, SmpTypeCancelMaker, SmpTypeCancelTaker, SmpTypeCancelBoth} {
+		assert.NoError(t, smpType.Validate())
+	}
+	assert.ErrorContains(t, SmpType("CancelNone").Validate(), `unknown smp type: "CancelNone"`)
+}
+
+func TestIntervalPeriod(t *testing.T) {
+	for _, period := range ToIntervalPeriod {
+		assert.NoError(t, period.Validate())
+	}
+	assert.Equal(t, IntervalPeriod15m, ToIntervalPeriod[types.Interval15m])
+	assert.Equal(t, IntervalPeriod1d, ToIntervalPeriod[types.Interval1d])
+
+	_, ok := ToIntervalPeriod[types.Interval1m]
+	assert.False(t, ok)
+	assert.ErrorContains(t, IntervalPeriod("1m").Validate(), `unknown interval period: "1m"`)
+}
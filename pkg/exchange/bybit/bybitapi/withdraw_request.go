@@ -0,0 +1,64 @@
+package bybitapi
+
+import (
+	"strings"
+	"time"
+
+	"github.com/c9s/requestgen"
+	"github.com/pkg/errors"
+)
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Result
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Result
+
+// RetCodeWithdrawAddressNotInWhitelist is returned if the withdrawal address is not in the address whitelist.
+const RetCodeWithdrawAddressNotInWhitelist = 131093
+
+type WithdrawResponse struct {
+	Id string `json:"id"`
+}
+
+//go:generate PostRequest -url "/v5/asset/withdraw/create" -type WithdrawRequest -responseDataType .WithdrawResponse
+type WithdrawRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	coin    string `param:"coin"`
+	chain   string `param:"chain"`
+	address string `param:"address"`
+	// tag is required if the chain needs the tag or the memo.
+	tag    *string `param:"tag"`
+	amount string  `param:"amount"`
+	// timestamp is the current timestamp (ms), it's used to check the request expiry.
+	timestamp int64 `param:"timestamp"`
+	// forceChain 1 withdraws on the chain, 0 may transfer internally if the address is a bybit address.
+	forceChain *int `param:"forceChain"`
+	// accountType is the account to withdraw from, FUND by default.
+	accountType *AccountType `param:"accountType" validValues:"SPOT,FUND"`
+}
+
+// NewWithdrawRequest creates the withdrawal request. The request is rejected unless the fund transfer is enabled by
+// EnableFundTransfer.
+func (c *RestClient) NewWithdrawRequest() *WithdrawRequest {
+	return &WithdrawRequest{
+		client:    c,
+		timestamp: time.Now().UnixMilli(),
+	}
+}
+
+// IsWithdrawWhitelistError returns true if the withdrawal is rejected since the address is not in the whitelist, it
+// won't succeed by retrying.
+func IsWithdrawWhitelistError(err error) bool {
+	return IsRetCode(err, RetCodeWithdrawAddressNotInWhitelist)
+}
+
+// IsWithdraw2FAError returns true if the withdrawal is rejected since the 2FA is required, it won't succeed by
+// retrying.
+func IsWithdraw2FAError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	msg := strings.ToLower(apiErr.RetMsg)
+	return strings.Contains(msg, "2fa") || strings.Contains(msg, "google auth")
+}
@@ -0,0 +1,42 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+)
+
+func TestWithdrawRequest(t *testing.T) {
+	client, err := NewClient()
+	assert.NoError(t, err)
+	client.Auth("key", "secret")
+
+	transport := &httptesting.MockTransport{}
+	client.HttpClient.Transport = transport
+
+	transport.POST("/v5/asset/withdraw/create", func(req *http.Request) (*http.Response, error) {
+		return httptesting.BuildResponseString(http.StatusOK,
+			`{"retCode": 131093, "retMsg": "The withdrawal address is not in the whitelist", "result": {}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	newRequest := func() *WithdrawRequest {
+		return client.NewWithdrawRequest().
+			Coin("USDT").
+			Chain("ETH").
+			Address("0x1234").
+			Amount("10")
+	}
+
+	_, err = newRequest().Do(context.Background())
+	assert.ErrorIs(t, err, ErrFundTransferDisabled)
+
+	client.EnableFundTransfer()
+	_, err = newRequest().Do(context.Background())
+	assert.Error(t, err)
+	assert.True(t, IsWithdrawWhitelistError(err))
+	assert.False(t, IsWithdraw2FAError(err))
+}
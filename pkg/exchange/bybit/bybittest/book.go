@@ -0,0 +1,108 @@
+package bybittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// bookUpdateInterval is the interval of the timestamps of the book events, it's the push frequency of the spot level
+// 50 book.
+const bookUpdateInterval = 20 * time.Millisecond
+
+// BookSequence scripts the book events of the orderbook topic, a snapshot followed by the deltas. The update ids of
+// the deltas are consecutive unless the gap is added by Gap.
+//
+//	events := bybittest.NewBookSequence("BTCUSDT", 50, now).
+//		Snapshot(bids, asks).
+//		Delta(bids, nil).
+//		Events()
+type BookSequence struct {
+	symbol string
+	depth  int
+
+	updateId   int64
+	sequenceId int64
+	ts         time.Time
+
+	events []bybit.WebSocketTopicEvent
+}
+
+// bookData is the wire format of bybit.BookEvent.
+type bookData struct {
+	Symbol     string     `json:"s"`
+	Bids       [][]string `json:"b"`
+	Asks       [][]string `json:"a"`
+	UpdateId   int64      `json:"u"`
+	SequenceId int64      `json:"seq"`
+}
+
+// NewBookSequence creates the book sequence of the symbol and the depth, the first event is at the start time.
+func NewBookSequence(symbol string, depth int, start time.Time) *BookSequence {
+	return &BookSequence{
+		symbol: symbol,
+		depth:  depth,
+		ts:     start,
+	}
+}
+
+// Topic returns the orderbook topic of the sequence, e.g. orderbook.50.BTCUSDT.
+func (b *BookSequence) Topic() string {
+	return fmt.Sprintf("%s.%d.%s", bybit.TopicTypeOrderBook, b.depth, b.symbol)
+}
+
+// Snapshot adds the snapshot, it replaces the book of the client.
+func (b *BookSequence) Snapshot(bids, asks types.PriceVolumeSlice) *BookSequence {
+	return b.add(bybit.DataTypeSnapshot, bids, asks)
+}
+
+// Delta adds the delta, the zero volume removes the price level.
+func (b *BookSequence) Delta(bids, asks types.PriceVolumeSlice) *BookSequence {
+	return b.add(bybit.DataTypeDelta, bids, asks)
+}
+
+// Gap skips the update ids, so the next delta is not consecutive to the previous event.
+func (b *BookSequence) Gap(n int64) *BookSequence {
+	b.updateId += n
+	return b
+}
+
+// Events returns the events of the sequence.
+func (b *BookSequence) Events() []bybit.WebSocketTopicEvent {
+	return b.events
+}
+
+func (b *BookSequence) add(dataType bybit.DataType, bids, asks types.PriceVolumeSlice) *BookSequence {
+	b.updateId++
+	b.sequenceId++
+
+	// the strings and the integers are always encoded.
+	data, _ := json.Marshal(bookData{
+		Symbol:     b.symbol,
+		Bids:       toLevels(bids),
+		Asks:       toLevels(asks),
+		UpdateId:   b.updateId,
+		SequenceId: b.sequenceId,
+	})
+
+	b.events = append(b.events, bybit.WebSocketTopicEvent{
+		Topic: b.Topic(),
+		Type:  dataType,
+		Ts:    types.MillisecondTimestamp(b.ts),
+		Data:  data,
+	})
+	b.ts = b.ts.Add(bookUpdateInterval)
+	return b
+}
+
+// toLevels converts the price levels to the string pairs, an empty slice is encoded as [] instead of null.
+func toLevels(slice types.PriceVolumeSlice) [][]string {
+	levels := make([][]string, 0, len(slice))
+	for _, pv := range slice {
+		levels = append(levels, []string{pv.Price.String(), pv.Volume.String()})
+	}
+	return levels
+}
@@ -0,0 +1,368 @@
+// Package bybittest provides an in-memory bybit websocket server, so the stream of the bybit exchange can be tested
+// end-to-end without connecting to bybit.
+package bybittest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit"
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+)
+
+// opsBufferSize is the size of the channel of the received ops, the ops are dropped if the channel is full.
+const opsBufferSize = 256
+
+const (
+	retMsgNotAuthorized = "Request not authorized"
+	retMsgExpired       = "Params Error: request expired"
+	retMsgUnknownOp     = "Unknown op"
+)
+
+// Server is the in-memory bybit websocket server. It acknowledges the auth, ping, subscribe and unsubscribe ops like
+// bybit, and sends the scripted topic events of the topics after they are subscribed.
+//
+// Connect the stream to the server by the endpoint creator:
+//
+//	server := bybittest.NewServer()
+//	defer server.Close()
+//	stream.SetEndpointCreator(server.Endpoint)
+type Server struct {
+	server *httptest.Server
+
+	mu sync.Mutex
+	// key and secret verify the auth ops, any auth op is accepted if they are not set, see SetAuth.
+	key, secret string
+	// scripts are the topic events sent after the topics are subscribed, see Script.
+	scripts map[string][]bybit.WebSocketTopicEvent
+	// rejected are the topics which fail the subscribe ops with the messages, see RejectTopic.
+	rejected map[string]string
+	conns    map[*conn]struct{}
+	connSeq  int
+
+	ops chan bybit.WebsocketOp
+}
+
+// conn is the server side of a connection, the writes are serialized since the events can be sent by Send from the
+// other goroutines.
+type conn struct {
+	id string
+
+	mu sync.Mutex
+	ws *websocket.Conn
+	// topics are the topics subscribed on the connection, see DropTopic.
+	topics map[string]struct{}
+}
+
+func (c *conn) setTopics(topics []string, subscribed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, topic := range topics {
+		if subscribed {
+			c.topics[topic] = struct{}{}
+		} else {
+			delete(c.topics, topic)
+		}
+	}
+}
+
+func (c *conn) hasTopic(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.topics[topic]
+	return ok
+}
+
+func (c *conn) writeJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws.WriteJSON(v)
+}
+
+// NewServer starts the server on a local port, it must be closed by Close.
+func NewServer() *Server {
+	s := &Server{
+		scripts:  make(map[string][]bybit.WebSocketTopicEvent),
+		rejected: make(map[string]string),
+		conns:    make(map[*conn]struct{}),
+		ops:      make(chan bybit.WebsocketOp, opsBufferSize),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL returns the websocket url of the server.
+func (s *Server) URL() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http")
+}
+
+// Endpoint is the endpoint creator of the stream, see types.StandardStream.SetEndpointCreator.
+func (s *Server) Endpoint(_ context.Context) (string, error) {
+	return s.URL(), nil
+}
+
+// SetAuth sets the api key and secret of the auth handshake. The auth op is accepted only if the key matches, the
+// signature is signed by the secret and the request is not expired.
+func (s *Server) SetAuth(key, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+	// pragma: allowlist nextline secret
+	s.secret = secret
+}
+
+// Script sets the topic events sent in order after the topic is subscribed. The events are sent again on every
+// subscription, e.g. the snapshot after the reconnection.
+func (s *Server) Script(topic string, events ...bybit.WebSocketTopicEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[topic] = events
+}
+
+// RejectTopic fails the subscribe ops of the topic with the message, like the invalid symbols rejected by bybit.
+func (s *Server) RejectTopic(topic, retMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejected[topic] = retMsg
+}
+
+// Ops returns the channel of the ops received from the clients, the ping ops are not included.
+func (s *Server) Ops() <-chan bybit.WebsocketOp {
+	return s.ops
+}
+
+// Send sends the topic event to all the connections.
+func (s *Server) Send(event bybit.WebSocketTopicEvent) error {
+	msg, err := marshalTopicEvent(event)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range s.connections() {
+		if err := c.writeJSON(msg); err != nil {
+			return fmt.Errorf("failed to send the event of %s to %s: %w", event.Topic, c.id, err)
+		}
+	}
+	return nil
+}
+
+// Connections returns the number of the open connections.
+func (s *Server) Connections() int {
+	return len(s.connections())
+}
+
+// DropConnections closes all the connections without the close frame, so the clients reconnect.
+func (s *Server) DropConnections() {
+	for _, c := range s.connections() {
+		_ = c.ws.Close()
+	}
+}
+
+// DropTopic closes the connections which subscribed the topic without the close frame, like DropConnections, the
+// other connections are kept. It returns the number of the dropped connections.
+func (s *Server) DropTopic(topic string) int {
+	dropped := 0
+	for _, c := range s.connections() {
+		if c.hasTopic(topic) {
+			_ = c.ws.Close()
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// Close drops the connections and shuts down the server.
+func (s *Server) Close() {
+	s.DropConnections()
+	s.server.Close()
+}
+
+func (s *Server) connections() []*conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.connSeq++
+	c := &conn{id: "conn-" + strconv.Itoa(s.connSeq), ws: ws, topics: make(map[string]struct{})}
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		_ = ws.Close()
+	}()
+
+	for {
+		var op bybit.WebsocketOp
+		if err := ws.ReadJSON(&op); err != nil {
+			return
+		}
+
+		if err := s.handleOp(c, op); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handleOp(c *conn, op bybit.WebsocketOp) error {
+	if op.Op != bybit.WsOpTypePing {
+		select {
+		case s.ops <- op:
+		default:
+		}
+	}
+
+	ack := bybit.WebSocketOpEvent{
+		Success: true,
+		ReqId:   op.ReqId,
+		ConnId:  c.id,
+		Op:      op.Op,
+	}
+
+	switch op.Op {
+	case bybit.WsOpTypePing:
+		ack.RetMsg = string(bybit.WsOpTypePong)
+		return c.writeJSON(ack)
+
+	case bybit.WsOpTypeAuth:
+		if retMsg := s.verifyAuth(op.Args, time.Now()); retMsg != "" {
+			ack.Success = false
+			ack.RetMsg = retMsg
+		}
+		return c.writeJSON(ack)
+
+	case bybit.WsOpTypeSubscribe:
+		events, retMsg := s.subscribe(op.Args)
+		if retMsg != "" {
+			ack.Success = false
+			ack.RetMsg = retMsg
+			return c.writeJSON(ack)
+		}
+
+		c.setTopics(op.Args, true)
+		if err := c.writeJSON(ack); err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			msg, err := marshalTopicEvent(event)
+			if err != nil {
+				return err
+			}
+
+			if err := c.writeJSON(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case bybit.WsOpTypeUnsubscribe:
+		c.setTopics(op.Args, false)
+		return c.writeJSON(ack)
+
+	default:
+		ack.Success = false
+		ack.RetMsg = retMsgUnknownOp
+		return c.writeJSON(ack)
+	}
+}
+
+// verifyAuth returns the message of the failed auth, it's empty if the auth is accepted. The args are the api key, the
+// expiry in milliseconds and the signature.
+func (s *Server) verifyAuth(args []string, now time.Time) string {
+	s.mu.Lock()
+	key, secret := s.key, s.secret
+	s.mu.Unlock()
+
+	if len(key) == 0 && len(secret) == 0 {
+		return ""
+	}
+
+	if len(args) != 3 || args[0] != key {
+		return retMsgNotAuthorized
+	}
+
+	expires, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return retMsgNotAuthorized
+	}
+
+	if expires < now.UnixMilli() {
+		return retMsgExpired
+	}
+
+	if args[2] != bybitapi.Sign("GET/realtime"+args[1], secret) {
+		return retMsgNotAuthorized
+	}
+	return ""
+}
+
+// subscribe returns the scripted events of the topics in order, or the message of the first rejected topic. Like
+// bybit, the whole op is rejected if any topic is rejected.
+func (s *Server) subscribe(topics []string) ([]bybit.WebSocketTopicEvent, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []bybit.WebSocketTopicEvent
+	for _, topic := range topics {
+		if retMsg, ok := s.rejected[topic]; ok {
+			return nil, retMsg
+		}
+		events = append(events, s.scripts[topic]...)
+	}
+	return events, ""
+}
+
+// topicMessage is the wire format of bybit.WebSocketTopicEvent, the timestamps are in milliseconds.
+type topicMessage struct {
+	Topic        string          `json:"topic"`
+	Type         bybit.DataType  `json:"type,omitempty"`
+	Ts           int64           `json:"ts,omitempty"`
+	CreationTime int64           `json:"creationTime,omitempty"`
+	Data         json.RawMessage `json:"data"`
+}
+
+func marshalTopicEvent(event bybit.WebSocketTopicEvent) (topicMessage, error) {
+	if len(event.Topic) == 0 {
+		return topicMessage{}, fmt.Errorf("the topic of the event is empty")
+	}
+
+	msg := topicMessage{
+		Topic: event.Topic,
+		Type:  event.Type,
+		Data:  event.Data,
+	}
+	if ts := event.Ts.Time(); !ts.IsZero() {
+		msg.Ts = ts.UnixMilli()
+	}
+	if ts := event.CreationTime.Time(); !ts.IsZero() {
+		msg.CreationTime = ts.UnixMilli()
+	}
+	return msg, nil
+}
@@ -0,0 +1,290 @@
+package bybittest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit"
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func level(price, volume string) types.PriceVolume {
+	return types.PriceVolume{
+		Price:  fixedpoint.MustNewFromString(price),
+		Volume: fixedpoint.MustNewFromString(volume),
+	}
+}
+
+func receiveOp(t *testing.T, server *Server) bybit.WebsocketOp {
+	select {
+	case op := <-server.Ops():
+		return op
+	case <-time.After(3 * time.Second):
+		t.Fatal("the op is not received")
+	}
+	return bybit.WebsocketOp{}
+}
+
+func receiveBook(t *testing.T, books <-chan types.SliceOrderBook) types.SliceOrderBook {
+	select {
+	case book := <-books:
+		return book
+	case <-time.After(3 * time.Second):
+		t.Fatal("the book is not received")
+	}
+	return types.SliceOrderBook{}
+}
+
+func TestServer_BookSequence(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	sequence := NewBookSequence("BTCUSDT", 50, time.UnixMilli(1700000000000)).
+		Snapshot(types.PriceVolumeSlice{level("100", "1"), level("99", "2")}, types.PriceVolumeSlice{level("101", "1")}).
+		Delta(types.PriceVolumeSlice{level("100", "0")}, nil)
+	assert.Equal(t, "orderbook.50.BTCUSDT", sequence.Topic())
+	server.Script(sequence.Topic(), sequence.Events()...)
+
+	books := make(chan types.SliceOrderBook, 10)
+	stream := bybit.NewStream("", "", nil)
+	stream.SetPublicOnly()
+	stream.SetEndpointCreator(server.Endpoint)
+	stream.SetReconnectBackoff(10*time.Millisecond, 10*time.Millisecond, 1)
+	stream.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	stream.OnOrderBook(func(book types.SliceOrderBook) {
+		books <- book
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, stream.Connect(ctx))
+	defer stream.Close()
+
+	op := receiveOp(t, server)
+	assert.Equal(t, bybit.WsOpTypeSubscribe, op.Op)
+	assert.Equal(t, []string{"orderbook.50.BTCUSDT"}, op.Args)
+
+	book := receiveBook(t, books)
+	assert.Equal(t, int64(1), book.LastUpdateId)
+	assert.Equal(t, time.UnixMilli(1700000000000), book.Time)
+	assert.Len(t, book.Bids, 2)
+
+	book = receiveBook(t, books)
+	assert.Equal(t, int64(2), book.LastUpdateId)
+	assert.Equal(t, types.PriceVolumeSlice{level("99", "2")}, book.Bids)
+
+	// the stream re-subscribes after the reconnection, and the snapshot is sent again
+	server.DropConnections()
+	op = receiveOp(t, server)
+	assert.Equal(t, []string{"orderbook.50.BTCUSDT"}, op.Args)
+
+	book = receiveBook(t, books)
+	assert.Equal(t, int64(1), book.LastUpdateId)
+	assert.Len(t, book.Bids, 2)
+	book = receiveBook(t, books)
+	assert.Equal(t, int64(2), book.LastUpdateId)
+
+	// the events are sent to the connections by Send
+	assert.NoError(t, server.Send(NewBookSequence("BTCUSDT", 50, time.UnixMilli(1700000001000)).
+		Snapshot(types.PriceVolumeSlice{level("98", "1")}, nil).
+		Events()[0]))
+	book = receiveBook(t, books)
+	assert.Equal(t, types.PriceVolumeSlice{level("98", "1")}, book.Bids)
+	assert.Empty(t, book.Asks)
+}
+
+func TestServer_BookGap(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	sequence := NewBookSequence("BTCUSDT", 50, time.UnixMilli(1700000000000)).
+		Snapshot(types.PriceVolumeSlice{level("100", "1"), level("99", "2")}, types.PriceVolumeSlice{level("101", "1")}).
+		Delta(types.PriceVolumeSlice{level("100", "0")}, nil)
+	server.Script(sequence.Topic(), sequence.Events()...)
+
+	books := make(chan types.SliceOrderBook, 10)
+	stream := bybit.NewStream("", "", nil)
+	stream.SetPublicOnly()
+	stream.SetEndpointCreator(server.Endpoint)
+	stream.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	stream.OnOrderBook(func(book types.SliceOrderBook) {
+		books <- book
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, stream.Connect(ctx))
+	defer stream.Close()
+
+	assert.Equal(t, bybit.WsOpTypeSubscribe, receiveOp(t, server).Op)
+	assert.Equal(t, int64(1), receiveBook(t, books).LastUpdateId)
+	assert.Equal(t, int64(2), receiveBook(t, books).LastUpdateId)
+
+	// the delta after the gap is dropped, and the orderbook topic is resubscribed for a new snapshot
+	assert.NoError(t, server.Send(sequence.Gap(1).Delta(nil, types.PriceVolumeSlice{level("102", "1")}).Events()[2]))
+
+	op := receiveOp(t, server)
+	assert.Equal(t, bybit.WsOpTypeUnsubscribe, op.Op)
+	assert.Equal(t, []string{"orderbook.50.BTCUSDT"}, op.Args)
+	op = receiveOp(t, server)
+	assert.Equal(t, bybit.WsOpTypeSubscribe, op.Op)
+	assert.Equal(t, []string{"orderbook.50.BTCUSDT"}, op.Args)
+
+	// the book recovers from the snapshot sent on the subscription
+	book := receiveBook(t, books)
+	assert.Equal(t, int64(1), book.LastUpdateId)
+	assert.Len(t, book.Bids, 2)
+	book = receiveBook(t, books)
+	assert.Equal(t, int64(2), book.LastUpdateId)
+	assert.Equal(t, types.PriceVolumeSlice{level("99", "2")}, book.Bids)
+}
+
+type instrumentsProviderFunc func(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error)
+
+func (f instrumentsProviderFunc) QueryInstruments(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error) {
+	return f(ctx, category)
+}
+
+func TestServer_CategoryStreamDropConnection(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	// a connection takes 840 topics of "orderbook.50.COIN0000USDT", so the last 10 symbols are on the second one
+	var instruments []bybitapi.Instrument
+	for i := 0; i < 850; i++ {
+		instruments = append(instruments, bybitapi.Instrument{
+			Symbol:    fmt.Sprintf("COIN%04dUSDT", i),
+			QuoteCoin: "USDT",
+			Status:    bybitapi.StatusTrading,
+		})
+	}
+	provider := instrumentsProviderFunc(func(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error) {
+		return instruments, nil
+	})
+
+	categoryStream, err := bybit.NewCategoryStream(context.Background(), provider, bybitapi.CategorySpot, "USDT", types.BookChannel, types.SubscribeOptions{Depth: types.DepthLevel50})
+	if !assert.NoError(t, err) || !assert.Len(t, categoryStream.Streams, 2) {
+		return
+	}
+
+	books := make(chan types.SliceOrderBook, 10)
+	for _, stream := range categoryStream.Streams {
+		stream.SetEndpointCreator(server.Endpoint)
+		stream.SetReconnectBackoff(10*time.Millisecond, 10*time.Millisecond, 1)
+		stream.OnOrderBook(func(book types.SliceOrderBook) {
+			books <- book
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, categoryStream.Connect(ctx))
+	defer categoryStream.Close()
+
+	subscribed := 0
+	for subscribed < 850 {
+		op := receiveOp(t, server)
+		assert.Equal(t, bybit.WsOpTypeSubscribe, op.Op)
+		subscribed += len(op.Args)
+	}
+
+	// only the connection of the dropped topic reconnects and resubscribes its own symbols
+	assert.Equal(t, 1, server.DropTopic("orderbook.50.COIN0845USDT"))
+	op := receiveOp(t, server)
+	assert.Equal(t, bybit.WsOpTypeSubscribe, op.Op)
+	var expected []string
+	for i := 840; i < 850; i++ {
+		expected = append(expected, fmt.Sprintf("orderbook.50.COIN%04dUSDT", i))
+	}
+	assert.Equal(t, expected, op.Args)
+
+	select {
+	case op := <-server.Ops():
+		t.Fatalf("unexpected op after the reconnection: %+v", op)
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.Equal(t, 2, server.Connections())
+
+	// the events of the kept connection are still received by the handlers
+	assert.NoError(t, server.Send(NewBookSequence("COIN0000USDT", 50, time.UnixMilli(1700000000000)).
+		Snapshot(types.PriceVolumeSlice{level("100", "1")}, nil).
+		Events()[0]))
+	assert.Equal(t, "COIN0000USDT", receiveBook(t, books).Symbol)
+}
+
+func TestServer_RejectTopic(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.RejectTopic("publicTrade.FOOUSDT", "Invalid symbol :[publicTrade.FOOUSDT]")
+
+	errs := make(chan bybit.SubscriptionErrorEvent, 1)
+	stream := bybit.NewStream("", "", nil)
+	stream.SetPublicOnly()
+	stream.SetEndpointCreator(server.Endpoint)
+	stream.Subscribe(types.MarketTradeChannel, "FOOUSDT", types.SubscribeOptions{})
+	stream.OnSubscriptionError(func(e bybit.SubscriptionErrorEvent) {
+		errs <- e
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, stream.Connect(ctx))
+	defer stream.Close()
+
+	select {
+	case e := <-errs:
+		assert.Equal(t, []string{"publicTrade.FOOUSDT"}, e.Topics)
+		assert.ErrorContains(t, e.Err, "Invalid symbol")
+	case <-time.After(3 * time.Second):
+		t.Fatal("the subscription error is not received")
+	}
+}
+
+func TestServer_SetAuth(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.SetAuth("key", "secret")
+
+	conn, _, err := websocket.DefaultDialer.Dial(server.URL(), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	auth := func(key, secret string, expires time.Time) bybit.WebSocketOpEvent {
+		ts := strconv.FormatInt(expires.UnixMilli(), 10)
+		assert.NoError(t, conn.WriteJSON(bybit.WebsocketOp{
+			ReqId: "auth-1",
+			Op:    bybit.WsOpTypeAuth,
+			Args:  []string{key, ts, bybitapi.Sign("GET/realtime"+ts, secret)},
+		}))
+
+		var ack bybit.WebSocketOpEvent
+		assert.NoError(t, conn.ReadJSON(&ack))
+		return ack
+	}
+
+	ack := auth("key", "secret", time.Now().Add(10*time.Second))
+	assert.True(t, ack.Success)
+	assert.NoError(t, ack.IsValid())
+	assert.Equal(t, "auth-1", ack.ReqId)
+	assert.True(t, ack.IsAuthenticated())
+
+	ack = auth("key", "wrong", time.Now().Add(10*time.Second))
+	assert.False(t, ack.Success)
+	assert.Equal(t, retMsgNotAuthorized, ack.RetMsg)
+
+	ack = auth("key", "secret", time.Now().Add(-time.Second))
+	assert.False(t, ack.Success)
+	assert.Equal(t, retMsgExpired, ack.RetMsg)
+
+	assert.Equal(t, bybit.WsOpTypeAuth, receiveOp(t, server).Op)
+}
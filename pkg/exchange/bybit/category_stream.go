@@ -0,0 +1,152 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// InstrumentsProvider queries all the instruments of a category, it's implemented by the bybitapi.RestClient.
+type InstrumentsProvider interface {
+	QueryInstruments(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error)
+}
+
+var _ InstrumentsProvider = &bybitapi.RestClient{}
+
+// CategoryStream subscribes a channel of all the trading symbols of a category, so a market wide scanner doesn't need
+// to maintain the symbol list while bybit lists and delists the symbols. The subscriptions are split into the public
+// streams within the args limit of one connection, register the callbacks on every stream of Streams before Connect.
+//
+// Every stream is a connection of its own, a dropped connection reconnects and resubscribes only the symbols of its
+// stream, the other streams and the callbacks registered on them are not affected.
+//
+// The spot, the linear and the inverse categories are supported, e.g. the tickers of all the USDT perpetuals are
+// subscribed by the linear category, the USDT quote coin and the TickerChannel.
+type CategoryStream struct {
+	Streams []*Stream
+
+	symbols   []string
+	connected []*Stream
+}
+
+// NewCategoryStream queries the instruments of the category and subscribes the channel with the options of the
+// symbols in the trading status, and quoted in the quote coin if it's not empty. The symbols are resolved once, create
+// a new category stream to pick up the symbols listed later.
+func NewCategoryStream(ctx context.Context, provider InstrumentsProvider, category bybitapi.Category, quoteCoin string, channel types.Channel, options types.SubscribeOptions) (*CategoryStream, error) {
+	// the template stream validates the category and converts the subscriptions of the category.
+	template := &Stream{}
+	if err := template.SetCategory(category); err != nil {
+		return nil, err
+	}
+
+	instruments, err := provider.QueryInstruments(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the instruments of the %s category: %w", category, err)
+	}
+
+	symbols := filterTradingSymbols(instruments, quoteCoin)
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no trading symbol of the %s category quoted in %q", category, quoteCoin)
+	}
+
+	var subs []types.Subscription
+	for _, symbol := range symbols {
+		subs = append(subs, types.Subscription{Symbol: symbol, Channel: channel, Options: options})
+	}
+
+	groups, err := splitSubscriptions(template, subs)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CategoryStream{symbols: symbols}
+	for _, group := range groups {
+		stream := NewStream("", "", nil)
+		stream.SetPublicOnly()
+		if err := stream.SetCategory(category); err != nil {
+			return nil, err
+		}
+		if err := stream.SetSubscriptions(group); err != nil {
+			return nil, err
+		}
+		s.Streams = append(s.Streams, stream)
+	}
+	return s, nil
+}
+
+// Symbols returns the subscribed symbols in ascending order.
+func (s *CategoryStream) Symbols() []string {
+	return append([]string(nil), s.symbols...)
+}
+
+// Connect connects all the streams, the connected ones are closed if any of them fails.
+func (s *CategoryStream) Connect(ctx context.Context) error {
+	for _, stream := range s.Streams {
+		if err := stream.Connect(ctx); err != nil {
+			return multierr.Append(err, s.Close())
+		}
+		s.connected = append(s.connected, stream)
+	}
+	return nil
+}
+
+func (s *CategoryStream) Close() error {
+	var err error
+	for _, stream := range s.connected {
+		err = multierr.Append(err, stream.Close())
+	}
+	s.connected = nil
+	return err
+}
+
+// filterTradingSymbols returns the sorted symbols of the instruments in the trading status, and quoted in the quote
+// coin if it's not empty.
+func filterTradingSymbols(instruments []bybitapi.Instrument, quoteCoin string) []string {
+	var symbols []string
+	for _, instrument := range instruments {
+		if instrument.Status != bybitapi.StatusTrading {
+			continue
+		}
+
+		if len(quoteCoin) > 0 && instrument.QuoteCoin != quoteCoin {
+			continue
+		}
+
+		symbols = append(symbols, instrument.Symbol)
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+// splitSubscriptions splits the subscriptions into the groups whose topics converted by the stream are within
+// maxArgsLength, so every group can be subscribed on one connection.
+func splitSubscriptions(stream *Stream, subs []types.Subscription) ([][]types.Subscription, error) {
+	var groups [][]types.Subscription
+	var group []types.Subscription
+	argsLength := 0
+	for _, sub := range subs {
+		topic, err := stream.convertSubscription(sub)
+		if err != nil {
+			return nil, fmt.Errorf("convert error, subscription: %+v, err: %w", sub, err)
+		}
+
+		if len(group) > 0 && argsLength+len(topic) > maxArgsLength {
+			groups = append(groups, group)
+			group, argsLength = nil, 0
+		}
+
+		group = append(group, sub)
+		argsLength += len(topic)
+	}
+
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
@@ -0,0 +1,123 @@
+package bybit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type instrumentsProviderFunc func(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error)
+
+func (f instrumentsProviderFunc) QueryInstruments(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error) {
+	return f(ctx, category)
+}
+
+func TestNewCategoryStream(t *testing.T) {
+	ctx := context.Background()
+	provider := instrumentsProviderFunc(func(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error) {
+		return []bybitapi.Instrument{
+			{Symbol: "ETHUSDT", QuoteCoin: "USDT", Status: bybitapi.StatusTrading},
+			{Symbol: "BTCUSDT", QuoteCoin: "USDT", Status: bybitapi.StatusTrading},
+			{Symbol: "ETHBTC", QuoteCoin: "BTC", Status: bybitapi.StatusTrading},
+			{Symbol: "NEWUSDT", QuoteCoin: "USDT", Status: "PreLaunch"},
+		}, nil
+	})
+
+	s, err := NewCategoryStream(ctx, provider, bybitapi.CategorySpot, "USDT", types.MarketTradeChannel, types.SubscribeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, s.Symbols())
+	if assert.Len(t, s.Streams, 1) {
+		assert.True(t, s.Streams[0].PublicOnly)
+		assert.Equal(t, []types.Subscription{
+			{Symbol: "BTCUSDT", Channel: types.MarketTradeChannel},
+			{Symbol: "ETHUSDT", Channel: types.MarketTradeChannel},
+		}, s.Streams[0].GetSubscriptions())
+	}
+
+	// all the quote coins
+	s, err = NewCategoryStream(ctx, provider, bybitapi.CategorySpot, "", types.MarketTradeChannel, types.SubscribeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT", "ETHBTC", "ETHUSDT"}, s.Symbols())
+
+	_, err = NewCategoryStream(ctx, provider, bybitapi.CategorySpot, "EUR", types.MarketTradeChannel, types.SubscribeOptions{})
+	assert.ErrorContains(t, err, "no trading symbol")
+
+	_, err = NewCategoryStream(ctx, provider, bybitapi.CategoryOption, "USDT", types.MarketTradeChannel, types.SubscribeOptions{})
+	assert.ErrorContains(t, err, "not supported")
+
+	// the derivatives only channels are rejected in the spot category
+	_, err = NewCategoryStream(ctx, provider, bybitapi.CategorySpot, "USDT", types.ForceOrderChannel, types.SubscribeOptions{})
+	assert.ErrorContains(t, err, "not available in the spot category")
+
+	_, err = NewCategoryStream(ctx, instrumentsProviderFunc(func(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error) {
+		return nil, errors.New("timeout")
+	}), bybitapi.CategorySpot, "USDT", types.MarketTradeChannel, types.SubscribeOptions{})
+	assert.ErrorContains(t, err, "timeout")
+}
+
+func TestNewCategoryStream_linearTickers(t *testing.T) {
+	var queried bybitapi.Category
+	provider := instrumentsProviderFunc(func(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error) {
+		queried = category
+		return []bybitapi.Instrument{
+			{Symbol: "BTCUSDT", QuoteCoin: "USDT", Status: bybitapi.StatusTrading},
+			{Symbol: "BTCPERP", QuoteCoin: "USDC", Status: bybitapi.StatusTrading},
+			{Symbol: "ETHUSDT", QuoteCoin: "USDT", Status: bybitapi.StatusTrading},
+		}, nil
+	})
+
+	s, err := NewCategoryStream(context.Background(), provider, bybitapi.CategoryLinear, "USDT", TickerChannel, types.SubscribeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, bybitapi.CategoryLinear, queried)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, s.Symbols())
+	if assert.Len(t, s.Streams, 1) {
+		stream := s.Streams[0]
+		url, err := stream.createEndpoint(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, bybitapi.WsPublicLinearUrl, url)
+
+		ops, err := stream.buildSubscriptionOps(WsOpTypeSubscribe)
+		require.NoError(t, err)
+		if assert.Len(t, ops, 1) {
+			assert.Equal(t, []string{"tickers.BTCUSDT", "tickers.ETHUSDT"}, ops[0].Args)
+		}
+	}
+}
+
+func TestNewCategoryStream_split(t *testing.T) {
+	var instruments []bybitapi.Instrument
+	for i := 0; i < 2000; i++ {
+		instruments = append(instruments, bybitapi.Instrument{
+			Symbol:    fmt.Sprintf("COIN%04dUSDT", i),
+			QuoteCoin: "USDT",
+			Status:    bybitapi.StatusTrading,
+		})
+	}
+
+	provider := instrumentsProviderFunc(func(ctx context.Context, category bybitapi.Category) ([]bybitapi.Instrument, error) {
+		return instruments, nil
+	})
+
+	s, err := NewCategoryStream(context.Background(), provider, bybitapi.CategorySpot, "USDT", types.BookChannel, types.SubscribeOptions{Depth: types.DepthLevel50})
+	require.NoError(t, err)
+	assert.Len(t, s.Symbols(), 2000)
+
+	// every topic "orderbook.50.COIN0000USDT" has 25 characters, so a connection takes 840 topics
+	if assert.Len(t, s.Streams, 3) {
+		assert.Len(t, s.Streams[0].GetSubscriptions(), 840)
+		assert.Len(t, s.Streams[1].GetSubscriptions(), 840)
+		assert.Len(t, s.Streams[2].GetSubscriptions(), 320)
+	}
+
+	for _, stream := range s.Streams {
+		_, err := stream.buildSubscriptionOps(WsOpTypeSubscribe)
+		assert.NoError(t, err)
+	}
+}
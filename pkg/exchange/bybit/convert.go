@@ -3,6 +3,7 @@ package bybit
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
@@ -11,6 +12,88 @@ import (
 	"github.com/c9s/bbgo/pkg/types"
 )
 
+// symbolSeparatorReplacer removes the separators of the symbols like BTC/USDT or btc_usdt.
+var symbolSeparatorReplacer = strings.NewReplacer("/", "", "_", "")
+
+// toLocalSymbol converts the global symbol to the bybit symbol of the category. The bybit symbols are upper case
+// without the separators, e.g. BTCUSDT. The dash is only removed for spot, since the derivative delivery contracts
+// carry the expiry after the dash, see toGlobalSymbol for the delivery contracts.
+func toLocalSymbol(symbol string, category bybitapi.Category) string {
+	symbol = symbolSeparatorReplacer.Replace(strings.ToUpper(symbol))
+	switch category {
+	case bybitapi.CategorySpot:
+		symbol = strings.ReplaceAll(symbol, "-", "")
+
+	case bybitapi.CategoryLinear:
+		// the USDC delivery contract omits the quote currency, e.g. BTCUSDC-27DEC24 is BTC-27DEC24
+		if base, expiry, ok := strings.Cut(symbol, "-"); ok && strings.HasSuffix(base, "USDC") {
+			symbol = strings.TrimSuffix(base, "USDC") + "-" + expiry
+		}
+
+	case bybitapi.CategoryInverse:
+		// the inverse delivery contract carries the month code and the year, e.g. BTCUSD-28MAR25 is BTCUSDH25
+		if base, expiry, ok := strings.Cut(symbol, "-"); ok {
+			if t, err := time.Parse(deliveryDateLayout, expiry); err == nil {
+				symbol = base + string(futuresMonthCodes[t.Month()-1]) + t.Format("06")
+			}
+		}
+	}
+	return symbol
+}
+
+// toGlobalSymbol converts the bybit symbol of the category to the global symbol. The spot and the perpetual symbols
+// are the same as the global ones. The delivery contracts are converted to the base and the quote currencies with the
+// expiry date after the dash, e.g. the linear BTC-27DEC24 settled in USDC is BTCUSDC-27DEC24, and the inverse
+// BTCUSDH25 expiring in March 2025 is BTCUSD-28MAR25.
+func toGlobalSymbol(symbol string, category bybitapi.Category) string {
+	symbol = toLocalSymbol(symbol, category)
+	switch category {
+	case bybitapi.CategoryLinear:
+		// the USDT delivery contract carries the quote currency already, e.g. BTCUSDT-27DEC24
+		if base, expiry, ok := strings.Cut(symbol, "-"); ok && !strings.HasSuffix(base, "USDT") {
+			symbol = base + "USDC-" + expiry
+		}
+
+	case bybitapi.CategoryInverse:
+		if base, expiry, ok := parseInverseDeliverySymbol(symbol); ok {
+			symbol = base + "-" + strings.ToUpper(expiry.Format(deliveryDateLayout))
+		}
+	}
+	return symbol
+}
+
+// deliveryDateLayout is the layout of the expiry date of the delivery contracts, e.g. 27DEC24.
+const deliveryDateLayout = "02Jan06"
+
+// futuresMonthCodes are the month codes of the inverse delivery contracts from January to December.
+const futuresMonthCodes = "FGHJKMNQUVXZ"
+
+// parseInverseDeliverySymbol parses the inverse delivery contract like BTCUSDH25, and returns the symbol of the
+// perpetual contract and the expiry date, which is the last Friday of the month.
+func parseInverseDeliverySymbol(symbol string) (string, time.Time, bool) {
+	n := len(symbol)
+	if n < 4 || !isDigit(symbol[n-1]) || !isDigit(symbol[n-2]) {
+		return "", time.Time{}, false
+	}
+
+	base := symbol[:n-3]
+	month := strings.IndexByte(futuresMonthCodes, symbol[n-3])
+	if month < 0 || !strings.HasSuffix(base, "USD") {
+		return "", time.Time{}, false
+	}
+
+	year := 2000 + int(symbol[n-2]-'0')*10 + int(symbol[n-1]-'0')
+	expiry := time.Date(year, time.Month(month+2), 0, 0, 0, 0, 0, time.UTC)
+	for expiry.Weekday() != time.Friday {
+		expiry = expiry.AddDate(0, 0, -1)
+	}
+	return base, expiry, true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
 func toGlobalMarket(m bybitapi.Instrument) types.Market {
 	return types.Market{
 		Symbol:          m.Symbol,
@@ -58,11 +141,25 @@ func toGlobalOrder(order bybitapi.Order) (*types.Order, error) {
 		return nil, err
 	}
 
-	timeInForce, err := toGlobalTimeInForce(order.TimeInForce)
+	timeInForce, postOnly, err := bybitapi.ToGlobalTimeInForce(order.TimeInForce)
 	if err != nil {
 		return nil, err
 	}
 
+	if postOnly && orderType == types.OrderTypeLimit {
+		orderType = types.OrderTypeLimitMaker
+	}
+
+	// the conditional order is the stop order of the global order types.
+	if order.IsConditional() {
+		switch orderType {
+		case types.OrderTypeMarket:
+			orderType = types.OrderTypeStopMarket
+		case types.OrderTypeLimit:
+			orderType = types.OrderTypeStopLimit
+		}
+	}
+
 	status, err := toGlobalOrderStatus(order.OrderStatus, order.Side, order.OrderType)
 	if err != nil {
 		return nil, err
@@ -81,6 +178,13 @@ func toGlobalOrder(order bybitapi.Order) (*types.Order, error) {
 		return nil, err
 	}
 
+	// the order cancelled by the self match prevention is told from the cancellation of the strategy by the original
+	// status.
+	var originalStatus string
+	if order.IsCancelledBySmp() {
+		originalStatus = bybitapi.CancelTypeCancelBySmp
+	}
+
 	return &types.Order{
 		SubmitOrder: types.SubmitOrder{
 			ClientOrderID: order.OrderLinkId,
@@ -89,19 +193,86 @@ func toGlobalOrder(order bybitapi.Order) (*types.Order, error) {
 			Type:          orderType,
 			Quantity:      qty,
 			Price:         order.Price,
+			StopPrice:     order.TriggerPrice,
 			TimeInForce:   timeInForce,
+			ReduceOnly:    order.ReduceOnly,
+			ClosePosition: order.CloseOnTrigger,
 		},
 		Exchange:         types.ExchangeBybit,
 		OrderID:          orderIdNum,
 		UUID:             order.OrderId,
 		Status:           status,
+		OriginalStatus:   originalStatus,
 		ExecutedQuantity: order.CumExecQty,
 		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
 		CreationTime:     types.Time(order.CreatedTime.Time()),
 		UpdateTime:       types.Time(order.UpdatedTime.Time()),
+		IsMargin:         isMarginOrder(order),
 	}, nil
 }
 
+// TriggerState is the state of the trigger of the conditional order.
+type TriggerState string
+
+const (
+	// TriggerStateUntriggered is the conditional order waiting for the trigger price, it's not in the order book.
+	TriggerStateUntriggered TriggerState = "Untriggered"
+	// TriggerStateTriggered is the conditional order of which the trigger price was reached, it's placed into the order
+	// book and goes on like the plain order, e.g. New, PartiallyFilled and Filled.
+	TriggerStateTriggered TriggerState = "Triggered"
+	// TriggerStateDeactivated is the conditional order cancelled before it's triggered.
+	TriggerStateDeactivated TriggerState = "Deactivated"
+)
+
+// ConditionalOrder is the global order of the bybit conditional order with its trigger, the global order has the stop
+// order type and the trigger price as the stop price, but it can't tell the untriggered order from the live one.
+type ConditionalOrder struct {
+	types.Order
+
+	TriggerState     TriggerState
+	StopOrderType    bybitapi.StopOrderType
+	TriggerBy        bybitapi.TriggerBy
+	TriggerDirection bybitapi.TriggerDirection
+}
+
+// IsUntriggered returns true if the order waits for the trigger price.
+func (o ConditionalOrder) IsUntriggered() bool {
+	return o.TriggerState == TriggerStateUntriggered
+}
+
+// toConditionalOrder converts the conditional order, it returns an error if the order is not a conditional one.
+func toConditionalOrder(order bybitapi.Order) (*ConditionalOrder, error) {
+	if !order.IsConditional() {
+		return nil, fmt.Errorf("the order %s is not a conditional order", order.OrderId)
+	}
+
+	gOrder, err := toGlobalOrder(order)
+	if err != nil {
+		return nil, err
+	}
+
+	state := TriggerStateTriggered
+	switch order.OrderStatus {
+	case bybitapi.OrderStatusUntriggered:
+		state = TriggerStateUntriggered
+	case bybitapi.OrderStatusDeactivated:
+		state = TriggerStateDeactivated
+	}
+
+	return &ConditionalOrder{
+		Order:            *gOrder,
+		TriggerState:     state,
+		StopOrderType:    order.StopOrderType,
+		TriggerBy:        order.TriggerBy,
+		TriggerDirection: bybitapi.TriggerDirection(order.TriggerDirection),
+	}, nil
+}
+
+// isMarginOrder returns true if the order is placed with the isLeverage flag (spot margin trading).
+func isMarginOrder(order bybitapi.Order) bool {
+	return order.IsLeverage == strconv.Itoa(int(bybitapi.IsLeverageTrue))
+}
+
 func toGlobalSideType(side bybitapi.Side) (types.SideType, error) {
 	switch side {
 	case bybitapi.SideBuy:
@@ -129,19 +300,8 @@ func toGlobalOrderType(s bybitapi.OrderType) (types.OrderType, error) {
 }
 
 func toGlobalTimeInForce(force bybitapi.TimeInForce) (types.TimeInForce, error) {
-	switch force {
-	case bybitapi.TimeInForceGTC:
-		return types.TimeInForceGTC, nil
-
-	case bybitapi.TimeInForceIOC:
-		return types.TimeInForceIOC, nil
-
-	case bybitapi.TimeInForceFOK:
-		return types.TimeInForceFOK, nil
-
-	default:
-		return types.TimeInForce(force), fmt.Errorf("unexpected timeInForce type: %s", force)
-	}
+	timeInForce, _, err := bybitapi.ToGlobalTimeInForce(force)
+	return timeInForce, err
 }
 
 func toGlobalOrderStatus(status bybitapi.OrderStatus, side bybitapi.Side, orderType bybitapi.OrderType) (types.OrderStatus, error) {
@@ -164,7 +324,10 @@ func processOtherOrderStatus(status bybitapi.OrderStatus) (types.OrderStatus, er
 	switch status {
 	case bybitapi.OrderStatusCreated,
 		bybitapi.OrderStatusNew,
-		bybitapi.OrderStatusActive:
+		bybitapi.OrderStatusActive,
+		// the conditional order is working before and after it's triggered.
+		bybitapi.OrderStatusUntriggered,
+		bybitapi.OrderStatusTriggered:
 		return types.OrderStatusNew, nil
 
 	case bybitapi.OrderStatusFilled:
@@ -181,9 +344,6 @@ func processOtherOrderStatus(status bybitapi.OrderStatus) (types.OrderStatus, er
 		return types.OrderStatusRejected, nil
 
 	default:
-		// following not supported
-		// bybitapi.OrderStatusUntriggered
-		// bybitapi.OrderStatusTriggered
 		return types.OrderStatus(status), fmt.Errorf("unexpected order status: %s", status)
 	}
 }
@@ -226,6 +386,8 @@ func processMarketBuyQuantity(o bybitapi.Order) (fixedpoint.Value, error) {
 
 	case bybitapi.OrderStatusCreated,
 		bybitapi.OrderStatusNew,
+		bybitapi.OrderStatusUntriggered,
+		bybitapi.OrderStatusTriggered,
 		bybitapi.OrderStatusRejected:
 		qty = fixedpoint.Zero
 
@@ -241,12 +403,20 @@ func processMarketBuyQuantity(o bybitapi.Order) (fixedpoint.Value, error) {
 
 func toLocalOrderType(orderType types.OrderType) (bybitapi.OrderType, error) {
 	switch orderType {
-	case types.OrderTypeLimit:
+	// the limit maker order is the post only limit order, see bybitapi.ToLocalTimeInForce.
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
 		return bybitapi.OrderTypeLimit, nil
 
 	case types.OrderTypeMarket:
 		return bybitapi.OrderTypeMarket, nil
 
+	// the stop orders are the conditional orders with the trigger price.
+	case types.OrderTypeStopLimit:
+		return bybitapi.OrderTypeLimit, nil
+
+	case types.OrderTypeStopMarket:
+		return bybitapi.OrderTypeMarket, nil
+
 	default:
 		return "", fmt.Errorf("order type %s not supported", orderType)
 	}
@@ -325,6 +495,71 @@ func v3ToGlobalTrade(trade v3.Trade) (*types.Trade, error) {
 	}, nil
 }
 
+// toGlobalTradeFromExecution converts the execution of the execution list to the trade. The ids of the linear and
+// inverse categories are UUIDs, so they are hashed into the numeric ids. The market of the symbol is used to resolve
+// the fee currency of the spot execution without it, see executionFeeCurrency.
+func toGlobalTradeFromExecution(execution bybitapi.Execution, category bybitapi.Category, market types.Market) (*types.Trade, error) {
+	side, err := toGlobalSideType(execution.Side)
+	if err != nil {
+		return nil, err
+	}
+
+	feeCurrency := execution.FeeCurrency
+	if len(feeCurrency) == 0 && category == bybitapi.CategorySpot {
+		feeCurrency = executionFeeCurrency(execution, market)
+	}
+
+	return &types.Trade{
+		ID:            parseNumericID(execution.ExecId),
+		OrderID:       parseNumericID(execution.OrderId),
+		Exchange:      types.ExchangeBybit,
+		Price:         execution.ExecPrice,
+		Quantity:      execution.ExecQty,
+		QuoteQuantity: execution.ExecPrice.Mul(execution.ExecQty),
+		Symbol:        execution.Symbol,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       isMakerExecution(execution.IsMaker, execution.ExecType),
+		Time:          types.Time(execution.ExecTime.Time()),
+		Fee:           execution.ExecFee,
+		FeeCurrency:   feeCurrency,
+		IsFutures:     category != bybitapi.CategorySpot,
+	}, nil
+}
+
+// executionFeeCurrency returns the fee currency of the spot execution by the rules of calculateFee: the buyer pays the
+// fee in the base currency and the seller pays it in the quote currency, the other way around for the maker rebate.
+func executionFeeCurrency(execution bybitapi.Execution, market types.Market) string {
+	inBase := execution.Side == bybitapi.SideBuy
+	if isMakerExecution(execution.IsMaker, execution.ExecType) && execution.FeeRate.Sign() < 0 {
+		inBase = !inBase
+	}
+
+	if inBase {
+		return market.BaseCurrency
+	}
+	return market.QuoteCurrency
+}
+
+// isMakerExecution classifies the liquidity of the execution. Only the order book and block trades carry the maker flag,
+// the liquidation, auto-deleveraging and delivery executions are always charged as taker.
+func isMakerExecution(isMaker bool, execType bybitapi.ExecType) bool {
+	switch execType {
+	case "", bybitapi.ExecTypeTrade, bybitapi.ExecTypeBlockTrade:
+		return isMaker
+	default:
+		return false
+	}
+}
+
+// parseNumericID parses the numeric id, the non-numeric id is hashed.
+func parseNumericID(id string) uint64 {
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return n
+	}
+	return hashStringID(id)
+}
+
 func toGlobalBalanceMap(events []bybitapi.WalletBalances) types.BalanceMap {
 	bm := types.BalanceMap{}
 	for _, event := range events {
@@ -41,14 +41,7 @@ func TestToGlobalMarket(t *testing.T) {
 		Innovation:    "0",
 		Status:        bybitapi.StatusTrading,
 		MarginTrading: "both",
-		LotSizeFilter: struct {
-			BasePrecision  fixedpoint.Value `json:"basePrecision"`
-			QuotePrecision fixedpoint.Value `json:"quotePrecision"`
-			MinOrderQty    fixedpoint.Value `json:"minOrderQty"`
-			MaxOrderQty    fixedpoint.Value `json:"maxOrderQty"`
-			MinOrderAmt    fixedpoint.Value `json:"minOrderAmt"`
-			MaxOrderAmt    fixedpoint.Value `json:"maxOrderAmt"`
-		}{
+		LotSizeFilter: bybitapi.LotSizeFilter{
 			BasePrecision:  fixedpoint.NewFromFloat(0.000001),
 			QuotePrecision: fixedpoint.NewFromFloat(0.00000001),
 			MinOrderQty:    fixedpoint.NewFromFloat(0.000048),
@@ -56,9 +49,7 @@ func TestToGlobalMarket(t *testing.T) {
 			MinOrderAmt:    fixedpoint.NewFromInt(1),
 			MaxOrderAmt:    fixedpoint.NewFromInt(2000000),
 		},
-		PriceFilter: struct {
-			TickSize fixedpoint.Value `json:"tickSize"`
-		}{
+		PriceFilter: bybitapi.PriceFilter{
 			TickSize: fixedpoint.NewFromFloat(0.01),
 		},
 	}
@@ -569,6 +560,15 @@ func TestToGlobalOrder(t *testing.T) {
 	res, err := toGlobalOrder(openOrder)
 	assert.NoError(t, err)
 	assert.Equal(t, res, &exp)
+
+	t.Run("spot margin order", func(t *testing.T) {
+		marginOrder := openOrder
+		marginOrder.IsLeverage = "1"
+
+		res, err := toGlobalOrder(marginOrder)
+		assert.NoError(t, err)
+		assert.True(t, res.IsMargin)
+	})
 }
 
 func TestToGlobalSideType(t *testing.T) {
@@ -614,6 +614,148 @@ func TestToGlobalTimeInForce(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestToGlobalOrder_postOnly(t *testing.T) {
+	order := bybitapi.Order{
+		OrderId:     "1",
+		Symbol:      "BTCUSDT",
+		Side:        bybitapi.SideBuy,
+		OrderType:   bybitapi.OrderTypeLimit,
+		TimeInForce: bybitapi.TimeInForcePostOnly,
+		OrderStatus: bybitapi.OrderStatusNew,
+		Qty:         fixedpoint.One,
+		Price:       fixedpoint.NewFromInt(100),
+	}
+
+	res, err := toGlobalOrder(order)
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderTypeLimitMaker, res.Type)
+	assert.Equal(t, types.TimeInForceGTC, res.TimeInForce)
+
+	orderType, err := toLocalOrderType(res.Type)
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.OrderTypeLimit, orderType)
+}
+
+func TestToGlobalOrder_reduceOnly(t *testing.T) {
+	order := bybitapi.Order{
+		OrderId:        "1",
+		Symbol:         "BTCUSDT",
+		Side:           bybitapi.SideSell,
+		OrderType:      bybitapi.OrderTypeMarket,
+		TimeInForce:    bybitapi.TimeInForceIOC,
+		OrderStatus:    bybitapi.OrderStatusNew,
+		Qty:            fixedpoint.One,
+		ReduceOnly:     true,
+		CloseOnTrigger: true,
+	}
+
+	res, err := toGlobalOrder(order)
+	assert.NoError(t, err)
+	assert.True(t, res.ReduceOnly)
+	assert.True(t, res.ClosePosition)
+}
+
+func TestToGlobalOrder_cancelledBySmp(t *testing.T) {
+	order := bybitapi.Order{
+		OrderId:     "1",
+		Symbol:      "BTCUSDT",
+		Side:        bybitapi.SideBuy,
+		OrderType:   bybitapi.OrderTypeLimit,
+		TimeInForce: bybitapi.TimeInForceGTC,
+		OrderStatus: bybitapi.OrderStatusCancelled,
+		Qty:         fixedpoint.One,
+		Price:       fixedpoint.NewFromInt(100),
+		CancelType:  bybitapi.CancelTypeCancelBySmp,
+		SmpType:     bybitapi.SmpTypeCancelMaker,
+		SmpOrderId:  "2",
+	}
+	assert.True(t, order.IsCancelledBySmp())
+
+	res, err := toGlobalOrder(order)
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusCanceled, res.Status)
+	assert.Equal(t, "CancelBySmp", res.OriginalStatus)
+
+	order.CancelType = "CancelByUser"
+	res, err = toGlobalOrder(order)
+	assert.NoError(t, err)
+	assert.Empty(t, res.OriginalStatus)
+}
+
+func TestToGlobalOrder_conditional(t *testing.T) {
+	order := bybitapi.Order{
+		OrderId:          "1",
+		Symbol:           "BTCUSDT",
+		Side:             bybitapi.SideSell,
+		OrderType:        bybitapi.OrderTypeLimit,
+		TimeInForce:      bybitapi.TimeInForceGTC,
+		OrderStatus:      bybitapi.OrderStatusUntriggered,
+		Qty:              fixedpoint.One,
+		Price:            fixedpoint.NewFromInt(29000),
+		TriggerPrice:     fixedpoint.NewFromInt(29500),
+		TriggerDirection: int(bybitapi.TriggerDirectionFall),
+	}
+	assert.True(t, order.IsConditional())
+	assert.False(t, order.IsTriggered())
+
+	res, err := toGlobalOrder(order)
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderTypeStopLimit, res.Type)
+	assert.Equal(t, types.OrderStatusNew, res.Status)
+	assert.Equal(t, fixedpoint.NewFromInt(29500), res.StopPrice)
+
+	order.OrderStatus = bybitapi.OrderStatusTriggered
+	assert.True(t, order.IsTriggered())
+
+	orderType, err := toLocalOrderType(res.Type)
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.OrderTypeLimit, orderType)
+}
+
+func TestToConditionalOrder(t *testing.T) {
+	order := bybitapi.Order{
+		OrderId:          "1",
+		Symbol:           "BTCUSDT",
+		Side:             bybitapi.SideSell,
+		OrderType:        bybitapi.OrderTypeLimit,
+		TimeInForce:      bybitapi.TimeInForceGTC,
+		Qty:              fixedpoint.One,
+		Price:            fixedpoint.NewFromInt(29000),
+		TriggerPrice:     fixedpoint.NewFromInt(29500),
+		TriggerDirection: int(bybitapi.TriggerDirectionFall),
+		TriggerBy:        bybitapi.TriggerByLastPrice,
+		StopOrderType:    bybitapi.StopOrderTypeStop,
+	}
+
+	for _, c := range []struct {
+		status bybitapi.OrderStatus
+		state  TriggerState
+		global types.OrderStatus
+	}{
+		{bybitapi.OrderStatusUntriggered, TriggerStateUntriggered, types.OrderStatusNew},
+		{bybitapi.OrderStatusTriggered, TriggerStateTriggered, types.OrderStatusNew},
+		{bybitapi.OrderStatusNew, TriggerStateTriggered, types.OrderStatusNew},
+		{bybitapi.OrderStatusFilled, TriggerStateTriggered, types.OrderStatusFilled},
+		{bybitapi.OrderStatusDeactivated, TriggerStateDeactivated, types.OrderStatusCanceled},
+	} {
+		order.OrderStatus = c.status
+		res, err := toConditionalOrder(order)
+		if assert.NoError(t, err, c.status) {
+			assert.Equal(t, c.state, res.TriggerState, c.status)
+			assert.Equal(t, c.global, res.Status, c.status)
+			assert.Equal(t, c.state == TriggerStateUntriggered, res.IsUntriggered(), c.status)
+			assert.Equal(t, types.OrderTypeStopLimit, res.Type)
+			assert.Equal(t, bybitapi.StopOrderTypeStop, res.StopOrderType)
+			assert.Equal(t, bybitapi.TriggerByLastPrice, res.TriggerBy)
+			assert.Equal(t, bybitapi.TriggerDirectionFall, res.TriggerDirection)
+		}
+	}
+
+	order.TriggerPrice = fixedpoint.Zero
+	_, err := toConditionalOrder(order)
+	assert.ErrorContains(t, err, "not a conditional order")
+}
+
 func Test_toGlobalOrderStatus(t *testing.T) {
 	t.Run("market/buy", func(t *testing.T) {
 		res, err := toGlobalOrderStatus(bybitapi.OrderStatusPartiallyFilledCanceled, bybitapi.SideBuy, bybitapi.OrderTypeMarket)
@@ -864,3 +1006,150 @@ func Test_toGlobalKLines(t *testing.T) {
 
 	assert.Equal(t, toGlobalKLines(symbol, interval, resp.List), expKlines)
 }
+
+func TestToGlobalTradeFromExecution(t *testing.T) {
+	execution := bybitapi.Execution{
+		Symbol:      "BTCUSDT",
+		OrderId:     "1468264727470772736",
+		Side:        bybitapi.SideBuy,
+		OrderType:   bybitapi.OrderTypeLimit,
+		ExecId:      "2100000000007764263",
+		ExecPrice:   fixedpoint.NewFromFloat(28000),
+		ExecQty:     fixedpoint.NewFromFloat(0.1),
+		ExecFee:     fixedpoint.NewFromFloat(0.0001),
+		FeeCurrency: "BTC",
+		IsMaker:     true,
+		ExecTime:    types.NewMillisecondTimestampFromInt(1699999999999),
+	}
+
+	trade, err := toGlobalTradeFromExecution(execution, bybitapi.CategorySpot, types.Market{})
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Trade{
+		ID:            2100000000007764263,
+		OrderID:       1468264727470772736,
+		Exchange:      types.ExchangeBybit,
+		Price:         fixedpoint.NewFromFloat(28000),
+		Quantity:      fixedpoint.NewFromFloat(0.1),
+		QuoteQuantity: fixedpoint.NewFromFloat(28000).Mul(fixedpoint.NewFromFloat(0.1)),
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		IsMaker:       true,
+		Time:          types.Time(execution.ExecTime.Time()),
+		Fee:           fixedpoint.NewFromFloat(0.0001),
+		FeeCurrency:   "BTC",
+	}, trade)
+
+	execution.ExecId = "b0d5cbd1-8a3c-4d49-8b88-1ad1d9e59c2b"
+	trade, err = toGlobalTradeFromExecution(execution, bybitapi.CategoryLinear, types.Market{})
+	assert.NoError(t, err)
+	assert.True(t, trade.IsFutures)
+	assert.Equal(t, hashStringID(execution.ExecId), trade.ID)
+
+	// the liquidation is charged as taker
+	execution.ExecType = bybitapi.ExecTypeBustTrade
+	trade, err = toGlobalTradeFromExecution(execution, bybitapi.CategoryLinear, types.Market{})
+	assert.NoError(t, err)
+	assert.False(t, trade.IsMaker)
+}
+
+func TestToGlobalTradeFromExecution_feeCurrency(t *testing.T) {
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	execution := bybitapi.Execution{
+		Symbol:    "BTCUSDT",
+		OrderId:   "1468264727470772736",
+		ExecId:    "2100000000007764263",
+		ExecPrice: fixedpoint.NewFromFloat(28000),
+		ExecQty:   fixedpoint.NewFromFloat(0.1),
+		ExecFee:   fixedpoint.NewFromFloat(0.0001),
+		FeeRate:   fixedpoint.NewFromFloat(0.001),
+	}
+
+	for _, c := range []struct {
+		side    bybitapi.Side
+		isMaker bool
+		feeRate fixedpoint.Value
+		exp     string
+	}{
+		{bybitapi.SideBuy, false, fixedpoint.NewFromFloat(0.001), "BTC"},
+		{bybitapi.SideSell, false, fixedpoint.NewFromFloat(0.001), "USDT"},
+		{bybitapi.SideBuy, true, fixedpoint.NewFromFloat(0.001), "BTC"},
+		{bybitapi.SideSell, true, fixedpoint.NewFromFloat(0.001), "USDT"},
+		// the maker rebate is paid in the other currency
+		{bybitapi.SideBuy, true, fixedpoint.NewFromFloat(-0.0001), "USDT"},
+		{bybitapi.SideSell, true, fixedpoint.NewFromFloat(-0.0001), "BTC"},
+	} {
+		execution.Side, execution.IsMaker, execution.FeeRate = c.side, c.isMaker, c.feeRate
+		trade, err := toGlobalTradeFromExecution(execution, bybitapi.CategorySpot, market)
+		assert.NoError(t, err)
+		assert.Equal(t, c.exp, trade.FeeCurrency, "side: %s, maker: %v, fee rate: %s", c.side, c.isMaker, c.feeRate)
+	}
+
+	// the fee currency of the execution is used if it's provided
+	execution.Side, execution.IsMaker, execution.FeeCurrency = bybitapi.SideBuy, false, "MNT"
+	trade, err := toGlobalTradeFromExecution(execution, bybitapi.CategorySpot, market)
+	assert.NoError(t, err)
+	assert.Equal(t, "MNT", trade.FeeCurrency)
+
+	// the derivatives are settled in the settle coin, the fee currency is not guessed
+	execution.FeeCurrency = ""
+	trade, err = toGlobalTradeFromExecution(execution, bybitapi.CategoryLinear, market)
+	assert.NoError(t, err)
+	assert.Empty(t, trade.FeeCurrency)
+}
+
+func Test_isMakerExecution(t *testing.T) {
+	assert.True(t, isMakerExecution(true, ""))
+	assert.True(t, isMakerExecution(true, bybitapi.ExecTypeTrade))
+	assert.True(t, isMakerExecution(true, bybitapi.ExecTypeBlockTrade))
+	assert.False(t, isMakerExecution(false, bybitapi.ExecTypeTrade))
+	assert.False(t, isMakerExecution(true, bybitapi.ExecTypeBustTrade))
+	assert.False(t, isMakerExecution(true, bybitapi.ExecTypeAdlTrade))
+	assert.False(t, isMakerExecution(true, bybitapi.ExecTypeDelivery))
+}
+
+func Test_toLocalSymbol(t *testing.T) {
+	assert.Equal(t, "BTCUSDT", toLocalSymbol("BTCUSDT", bybitapi.CategorySpot))
+	assert.Equal(t, "BTCUSDT", toLocalSymbol("btcusdt", bybitapi.CategorySpot))
+	assert.Equal(t, "BTCUSDT", toLocalSymbol("BTC/USDT", bybitapi.CategorySpot))
+	assert.Equal(t, "BTCUSDT", toLocalSymbol("BTC-USDT", bybitapi.CategorySpot))
+	assert.Equal(t, "BTCUSDT", toLocalSymbol("btc_usdt", bybitapi.CategoryLinear))
+	// the delivery contract keeps the expiry
+	assert.Equal(t, "BTC-27DEC24", toLocalSymbol("BTC-27DEC24", bybitapi.CategoryLinear))
+	assert.Equal(t, "BTC-27DEC24", toLocalSymbol("BTCUSDC-27DEC24", bybitapi.CategoryLinear))
+	assert.Equal(t, "BTCUSDT-27DEC24", toLocalSymbol("btcusdt-27dec24", bybitapi.CategoryLinear))
+	assert.Equal(t, "BTCUSD", toLocalSymbol("BTCUSD", bybitapi.CategoryInverse))
+	assert.Equal(t, "BTCUSDH25", toLocalSymbol("BTCUSD-28MAR25", bybitapi.CategoryInverse))
+	assert.Equal(t, "ETHUSDZ24", toLocalSymbol("ethusd-27dec24", bybitapi.CategoryInverse))
+}
+
+func Test_toGlobalSymbol(t *testing.T) {
+	assert.Equal(t, "BTCUSDT", toGlobalSymbol("BTCUSDT", bybitapi.CategorySpot))
+	assert.Equal(t, "BTCUSDT", toGlobalSymbol("BTCUSDT", bybitapi.CategoryLinear))
+	assert.Equal(t, "BTCUSD", toGlobalSymbol("BTCUSD", bybitapi.CategoryInverse))
+
+	// the USDC delivery contract gets the quote currency
+	assert.Equal(t, "BTCUSDC-27DEC24", toGlobalSymbol("BTC-27DEC24", bybitapi.CategoryLinear))
+	assert.Equal(t, "BTCUSDT-27DEC24", toGlobalSymbol("BTCUSDT-27DEC24", bybitapi.CategoryLinear))
+
+	// the inverse delivery contract expires on the last Friday of the month
+	assert.Equal(t, "BTCUSD-28MAR25", toGlobalSymbol("BTCUSDH25", bybitapi.CategoryInverse))
+	assert.Equal(t, "ETHUSD-27DEC24", toGlobalSymbol("ETHUSDZ24", bybitapi.CategoryInverse))
+	assert.Equal(t, "BTCUSD-26JUN26", toGlobalSymbol("BTCUSDM26", bybitapi.CategoryInverse))
+
+	// the conversions are reversible
+	for _, c := range []struct {
+		symbol   string
+		category bybitapi.Category
+	}{
+		{"BTCUSDT", bybitapi.CategorySpot},
+		{"BTC-27DEC24", bybitapi.CategoryLinear},
+		{"BTCUSDT-27DEC24", bybitapi.CategoryLinear},
+		{"BTCUSD", bybitapi.CategoryInverse},
+		{"BTCUSDH25", bybitapi.CategoryInverse},
+	} {
+		global := toGlobalSymbol(c.symbol, c.category)
+		assert.Equal(t, c.symbol, toLocalSymbol(global, c.category), global)
+		assert.Equal(t, global, toGlobalSymbol(global, c.category), global)
+	}
+}
@@ -0,0 +1,119 @@
+package bybit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// dedupCacheSize is the max number of the order ids and the exec ids remembered by the eventDeduper.
+const dedupCacheSize = 10000
+
+type orderUpdate struct {
+	updatedTime time.Time
+	status      bybitapi.OrderStatus
+	cumExecQty  fixedpoint.Value
+}
+
+// eventDeduper drops the events which were processed already, bybit may re-deliver the last snapshot and the deltas
+// after the reconnection.
+type eventDeduper struct {
+	mu sync.Mutex
+
+	// orderUpdates is the last processed update by the order id.
+	orderUpdates map[string]orderUpdate
+	orderIds     []string
+
+	execIds      map[string]struct{}
+	execIdsQueue []string
+
+	// bookSequences is the last processed cross sequence by the symbol.
+	bookSequences map[string]fixedpoint.Value
+}
+
+func newEventDeduper() *eventDeduper {
+	return &eventDeduper{
+		orderUpdates:  make(map[string]orderUpdate),
+		execIds:       make(map[string]struct{}),
+		bookSequences: make(map[string]fixedpoint.Value),
+	}
+}
+
+// IsDuplicateOrder returns true if the order update is not newer than the last processed one.
+func (d *eventDeduper) IsDuplicateOrder(order bybitapi.Order) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	update := orderUpdate{
+		updatedTime: order.UpdatedTime.Time(),
+		status:      order.OrderStatus,
+		cumExecQty:  order.CumExecQty,
+	}
+
+	last, ok := d.orderUpdates[order.OrderId]
+	if ok {
+		if update.updatedTime.Before(last.updatedTime) {
+			return true
+		}
+
+		if update.updatedTime.Equal(last.updatedTime) &&
+			update.status == last.status &&
+			update.cumExecQty.Compare(last.cumExecQty) == 0 {
+			return true
+		}
+	} else {
+		d.orderIds = appendBounded(d.orderIds, order.OrderId, func(id string) {
+			delete(d.orderUpdates, id)
+		})
+	}
+
+	d.orderUpdates[order.OrderId] = update
+	return false
+}
+
+// IsDuplicateExecution returns true if the exec id was processed.
+func (d *eventDeduper) IsDuplicateExecution(execId string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.execIds[execId]; ok {
+		return true
+	}
+
+	d.execIds[execId] = struct{}{}
+	d.execIdsQueue = appendBounded(d.execIdsQueue, execId, func(id string) {
+		delete(d.execIds, id)
+	})
+	return false
+}
+
+// IsDuplicateBook returns true if the book delta is not newer than the last processed book event of the symbol. The
+// snapshot is never dropped since it overwrites the local book.
+func (d *eventDeduper) IsDuplicateBook(e BookEvent) bool {
+	if e.SequenceId.Sign() <= 0 {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	last, ok := d.bookSequences[e.Symbol]
+	if ok && e.Type == DataTypeDelta && e.SequenceId.Compare(last) <= 0 {
+		return true
+	}
+
+	d.bookSequences[e.Symbol] = e.SequenceId
+	return false
+}
+
+// appendBounded appends the id to the queue, and evicts the oldest id if the queue exceeds the dedupCacheSize.
+func appendBounded(queue []string, id string, evict func(id string)) []string {
+	queue = append(queue, id)
+	if len(queue) > dedupCacheSize {
+		evict(queue[0])
+		queue = queue[1:]
+	}
+	return queue
+}
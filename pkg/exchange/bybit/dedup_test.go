@@ -0,0 +1,58 @@
+package bybit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEventDeduper(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		d := newEventDeduper()
+		now := time.Now()
+
+		order := bybitapi.Order{
+			OrderId:     "1",
+			OrderStatus: bybitapi.OrderStatusNew,
+			UpdatedTime: types.MillisecondTimestamp(now),
+		}
+		assert.False(t, d.IsDuplicateOrder(order))
+		assert.True(t, d.IsDuplicateOrder(order))
+
+		filled := order
+		filled.OrderStatus = bybitapi.OrderStatusPartiallyFilled
+		filled.CumExecQty = fixedpoint.NewFromFloat(0.5)
+		assert.False(t, d.IsDuplicateOrder(filled))
+
+		// the replayed older update
+		older := order
+		older.UpdatedTime = types.MillisecondTimestamp(now.Add(-time.Second))
+		assert.True(t, d.IsDuplicateOrder(older))
+	})
+
+	t.Run("execution", func(t *testing.T) {
+		d := newEventDeduper()
+		assert.False(t, d.IsDuplicateExecution("a"))
+		assert.True(t, d.IsDuplicateExecution("a"))
+		assert.False(t, d.IsDuplicateExecution("b"))
+	})
+
+	t.Run("book", func(t *testing.T) {
+		d := newEventDeduper()
+		snapshot := BookEvent{Symbol: "BTCUSDT", Type: DataTypeSnapshot, SequenceId: fixedpoint.NewFromInt(10)}
+		delta := BookEvent{Symbol: "BTCUSDT", Type: DataTypeDelta, SequenceId: fixedpoint.NewFromInt(11)}
+
+		assert.False(t, d.IsDuplicateBook(snapshot))
+		assert.False(t, d.IsDuplicateBook(delta))
+		assert.True(t, d.IsDuplicateBook(delta))
+
+		// the snapshot overwrites the local book, so it's never dropped
+		assert.False(t, d.IsDuplicateBook(snapshot))
+		assert.False(t, d.IsDuplicateBook(delta))
+	})
+}
@@ -0,0 +1,64 @@
+package bybit
+
+import (
+	"context"
+
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DualStream manages the public and the private connections independently, since bybit serves the market data and
+// the account data on different urls. The public topics (orderbook, kline, publicTrade) are subscribed on the public
+// connection, and the private topics (order, wallet, execution) are subscribed on the private connection after the
+// authentication, so a public connection hiccup won't drop the private stream. Both connections share the same event
+// dispatching of Stream, register the market data callbacks on Public and the account data callbacks on Private.
+type DualStream struct {
+	Public  *Stream
+	Private *Stream
+
+	// publicConnected is false if the public connection is skipped since there is no subscription.
+	publicConnected bool
+}
+
+func NewDualStream(key, secret string, streamDataProvider StreamDataProvider) *DualStream {
+	public := NewStream(key, secret, streamDataProvider)
+	public.SetPublicOnly()
+
+	return &DualStream{
+		Public:  public,
+		Private: NewStream(key, secret, streamDataProvider),
+	}
+}
+
+// Subscribe subscribes the public topic on the public connection, the private topics are always subscribed by the
+// private connection.
+func (s *DualStream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	s.Public.Subscribe(channel, symbol, options)
+}
+
+// Connect connects the private connection, and the public connection if there is any subscription.
+func (s *DualStream) Connect(ctx context.Context) error {
+	if err := s.Private.Connect(ctx); err != nil {
+		return err
+	}
+
+	if len(s.Public.GetSubscriptions()) == 0 {
+		return nil
+	}
+
+	if err := s.Public.Connect(ctx); err != nil {
+		return multierr.Append(err, s.Private.Close())
+	}
+
+	s.publicConnected = true
+	return nil
+}
+
+func (s *DualStream) Close() error {
+	var err error
+	if s.publicConnected {
+		err = multierr.Append(err, s.Public.Close())
+	}
+	return multierr.Append(err, s.Private.Close())
+}
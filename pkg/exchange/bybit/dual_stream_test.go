@@ -0,0 +1,29 @@
+package bybit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestDualStream(t *testing.T) {
+	s := NewDualStream("key", "secret", nil)
+	s.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+
+	assert.True(t, s.Public.PublicOnly)
+	assert.False(t, s.Private.PublicOnly)
+	assert.Len(t, s.Public.GetSubscriptions(), 1)
+	assert.Len(t, s.Private.GetSubscriptions(), 0)
+
+	publicURL, err := s.Public.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsSpotPublicSpotUrl, publicURL)
+
+	privateURL, err := s.Private.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsSpotPrivateUrl, privateURL)
+}
@@ -15,14 +15,21 @@ import (
 	v3 "github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi/v3"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util/traceid"
 )
 
 const (
 	maxOrderIdLen     = 36
 	defaultQueryLimit = 50
 	defaultKLineLimit = 1000
+	// defaultDepthLimit is the max depth of the spot order book snapshot.
+	defaultDepthLimit = 200
 
 	halfYearDuration = 6 * 30 * 24 * time.Hour
+
+	// maxCancelOnDisconnectPingInterval is the max ping interval of the private stream with the cancel on disconnect,
+	// which is the interval bybit recommends.
+	maxCancelOnDisconnectPingInterval = 20 * time.Second
 )
 
 // https://bybit-exchange.github.io/docs/zh-TW/v5/rate-limit
@@ -47,16 +54,46 @@ var (
 	_ types.ExchangeTradeService      = &Exchange{}
 	_ types.Exchange                  = &Exchange{}
 	_ types.ExchangeOrderQueryService = &Exchange{}
+	_ types.MarginExchange            = &Exchange{}
 )
 
 type Exchange struct {
+	// MarginSettings enables the spot margin trading by UseMargin, the isolated margin is not supported by bybit.
+	types.MarginSettings
+
 	key, secret string
 	client      *bybitapi.RestClient
 	v3client    *v3.Client
+
+	// category is the category of the orders submitted, canceled and queried by the exchange, see SetCategory.
+	category bybitapi.Category
+
+	// submittedOrders remembers the client order ids submitted by SafeSubmitOrder.
+	submittedOrders *submittedOrders
+
+	// cancelOnDisconnectProduct and cancelOnDisconnectWindow set the disconnected cancel all after the private stream
+	// is authenticated, see EnableCancelOnDisconnect.
+	cancelOnDisconnectProduct bybitapi.DisconnectCancelAllProduct
+	cancelOnDisconnectWindow  time.Duration
+
+	// smpType is the self match prevention type of the submitted orders, see SetSmpType.
+	smpType bybitapi.SmpType
+
+	// autoRoundInstruments and autoRoundMode round the submitted orders by the instrument filters, see
+	// EnableAutoRound.
+	autoRoundInstruments *bybitapi.InstrumentsInfoCache
+	autoRoundMode        fixedpoint.RoundingMode
+
+	// reconcileSettleCoins are the settle coins of the linear positions queried by Reconcile, see
+	// SetReconcileSettleCoins.
+	reconcileSettleCoins []string
 }
 
-func New(key, secret string) (*Exchange, error) {
-	client, err := bybitapi.NewClient()
+// New creates the exchange with the options of the rest client, e.g. bybitapi.WithDemoTrading(true) trades with the
+// paper funds of the demo trading account against the mainnet market data. The streams created by NewStream connect
+// to the testnet or the demo trading host like the client.
+func New(key, secret string, options ...bybitapi.ClientOption) (*Exchange, error) {
+	client, err := bybitapi.NewClient(options...)
 	if err != nil {
 		return nil, err
 	}
@@ -68,12 +105,109 @@ func New(key, secret string) (*Exchange, error) {
 	return &Exchange{
 		key: key,
 		// pragma: allowlist nextline secret
-		secret:   secret,
-		client:   client,
-		v3client: v3.NewClient(client),
+		secret:               secret,
+		client:               client,
+		v3client:             v3.NewClient(client),
+		category:             bybitapi.CategorySpot,
+		submittedOrders:      newSubmittedOrders(),
+		reconcileSettleCoins: defaultReconcileSettleCoins,
 	}, nil
 }
 
+// RateLimitStatus returns the remaining quota of the endpoints reported by bybit, keyed by the path of the endpoint,
+// see bybitapi.RestClient.RateLimitStatus.
+func (e *Exchange) RateLimitStatus() map[string]bybitapi.RateLimitStatus {
+	return e.client.RateLimitStatus()
+}
+
+// EnableRateLimitThrottle holds the requests of the endpoint whose quota is exhausted until the window is reset, on
+// top of the static rate limiters of the exchange.
+func (e *Exchange) EnableRateLimitThrottle() {
+	e.client.EnableRateLimitThrottle()
+}
+
+// EnableAutoRound rounds the price and the quantity of the submitted orders to the tick size and the quantity step of
+// the bybit instrument with the rounding mode, on top of the precision of the market, see
+// bybitapi.PlaceOrderRequest.WithAutoRound. The order whose rounded quantity is below the minimum is rejected before
+// it's sent.
+func (e *Exchange) EnableAutoRound(mode fixedpoint.RoundingMode) {
+	e.autoRoundInstruments = bybitapi.NewInstrumentsInfoCache(e.client)
+	e.autoRoundMode = mode
+}
+
+// validateMarginOrder returns an error if the spot margin trading is not enabled by UseMargin, or the isolated margin
+// is enabled, which bybit doesn't support.
+func (e *Exchange) validateMarginOrder() error {
+	if !e.IsMargin {
+		return fmt.Errorf("the margin order is not allowed since the margin trading is not enabled")
+	}
+
+	if e.IsIsolatedMargin {
+		return fmt.Errorf("the isolated margin of %s is not supported by bybit", e.IsolatedMarginSymbol)
+	}
+
+	return nil
+}
+
+// SetSmpType sets the self match prevention type of the submitted orders, so the strategies on the same account don't
+// trade against each other. The orders cancelled by the self match prevention have the CancelBySmp original status.
+// The account default of bybit is used if it's not set.
+// SetCategory trades the category with the exchange, the default is spot. The orders are submitted, canceled and
+// queried in the category, and the streams created by NewStream emit the order updates of the category. The
+// reduce-only and the close-on-trigger orders are only supported by the linear and the inverse categories. It must be
+// called before creating the streams.
+func (e *Exchange) SetCategory(category bybitapi.Category) error {
+	switch category {
+	case bybitapi.CategorySpot, bybitapi.CategoryLinear, bybitapi.CategoryInverse:
+		e.category = category
+		return nil
+	}
+
+	return fmt.Errorf("the %s category is not supported by the exchange", category)
+}
+
+func (e *Exchange) SetSmpType(smpType bybitapi.SmpType) error {
+	if err := smpType.Validate(); err != nil {
+		return err
+	}
+
+	e.smpType = smpType
+	return nil
+}
+
+// EnableCancelOnDisconnect arms the disconnected cancel all (DCP, the dead man's switch) of the product, so bybit cancels
+// all the open orders of the product if the private stream is disconnected and not reconnected in the window, e.g. the
+// bot dies. The window is set after every authentication of the private stream, and the stream keeps the timer alive
+// by pinging at most every third of the window. The DCP must be enabled for the account by bybit first, the failure of
+// setting the window is logged. It must be called before creating the streams.
+func (e *Exchange) EnableCancelOnDisconnect(product bybitapi.DisconnectCancelAllProduct, window time.Duration) error {
+	if window < bybitapi.MinDisconnectCancelAllWindow || window > bybitapi.MaxDisconnectCancelAllWindow {
+		return fmt.Errorf("the cancel on disconnect window must be between %s and %s, got %s",
+			bybitapi.MinDisconnectCancelAllWindow, bybitapi.MaxDisconnectCancelAllWindow, window)
+	}
+
+	e.cancelOnDisconnectProduct = product
+	e.cancelOnDisconnectWindow = window
+	return nil
+}
+
+func (e *Exchange) setCancelOnDisconnect() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := orderRateLimiter.Wait(ctx); err != nil {
+		log.WithError(err).Errorf("cancel on disconnect rate limiter wait error")
+		return
+	}
+
+	if err := e.client.SetDisconnectCancelAll(ctx, e.cancelOnDisconnectProduct, e.cancelOnDisconnectWindow); err != nil {
+		log.WithError(err).Errorf("failed to set the cancel on disconnect of %s, window: %s", e.cancelOnDisconnectProduct, e.cancelOnDisconnectWindow)
+		return
+	}
+
+	log.Infof("the cancel on disconnect of %s is set, window: %s", e.cancelOnDisconnectProduct, e.cancelOnDisconnectWindow)
+}
+
 func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeBybit
 }
@@ -107,7 +241,7 @@ func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticke
 		return nil, fmt.Errorf("ticker order rate limiter wait error: %w", err)
 	}
 
-	s, err := e.client.NewGetTickersRequest().Symbol(symbol).DoWithResponseTime(ctx)
+	s, err := e.client.NewGetTickersRequest().Symbol(toLocalSymbol(symbol, bybitapi.CategorySpot)).DoWithResponseTime(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call ticker, symbol: %s, err: %w", symbol, err)
 	}
@@ -120,6 +254,26 @@ func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticke
 	return &ticker, nil
 }
 
+// QueryDepth queries the order book snapshot of the symbol, e.g. to rebuild the local book without waiting for the
+// next websocket snapshot. The update id of the snapshot is returned as the final update id.
+func (e *Exchange) QueryDepth(ctx context.Context, symbol string) (types.SliceOrderBook, int64, error) {
+	if err := sharedRateLimiter.Wait(ctx); err != nil {
+		return types.SliceOrderBook{}, 0, fmt.Errorf("depth rate limiter wait error: %w", err)
+	}
+
+	book, err := e.client.NewGetOrderBookRequest().
+		Symbol(toLocalSymbol(symbol, bybitapi.CategorySpot)).
+		Limit(defaultDepthLimit).
+		Do(ctx)
+	if err != nil {
+		return types.SliceOrderBook{}, 0, fmt.Errorf("failed to query depth, symbol: %s, err: %w", symbol, err)
+	}
+
+	snapshot := book.SliceOrderBook()
+	snapshot.Symbol = toGlobalSymbol(book.Symbol, bybitapi.CategorySpot)
+	return snapshot, snapshot.LastUpdateId, nil
+}
+
 func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
 	tickers := map[string]types.Ticker{}
 	if len(symbols) > 0 {
@@ -153,7 +307,7 @@ func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[str
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
 	cursor := ""
 	for {
-		req := e.client.NewGetOpenOrderRequest().Symbol(symbol)
+		req := e.client.NewGetOpenOrderRequest().Category(e.category).Symbol(toLocalSymbol(symbol, e.category))
 		if len(cursor) != 0 {
 			// the default limit is 20.
 			req = req.Cursor(cursor)
@@ -194,9 +348,9 @@ func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.O
 		return nil, errors.New("only accept one parameter of OrderID/ClientOrderID")
 	}
 
-	req := e.client.NewGetOrderHistoriesRequest()
+	req := e.client.NewGetOrderHistoriesRequest().Category(e.category)
 	if len(q.Symbol) != 0 {
-		req.Symbol(q.Symbol)
+		req.Symbol(toLocalSymbol(q.Symbol, e.category))
 	}
 
 	if len(q.OrderID) != 0 {
@@ -229,7 +383,7 @@ func (e *Exchange) QueryOrderTrades(ctx context.Context, q types.OrderQuery) (tr
 	req := e.v3client.NewGetTradesRequest().OrderId(q.OrderID)
 
 	if len(q.Symbol) != 0 {
-		req.Symbol(q.Symbol)
+		req.Symbol(toLocalSymbol(q.Symbol, bybitapi.CategorySpot))
 	}
 
 	if err := queryOrderTradeRateLimiter.Wait(ctx); err != nil {
@@ -262,8 +416,8 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (*t
 		return nil, fmt.Errorf("order.Market.Symbol is required: %+v", order)
 	}
 
-	req := e.client.NewPlaceOrderRequest()
-	req.Symbol(order.Market.Symbol)
+	req := e.client.NewPlaceOrderRequest().Category(e.category)
+	req.Symbol(toLocalSymbol(order.Market.Symbol, e.category))
 
 	// set order type
 	orderType, err := toLocalOrderType(order.Type)
@@ -281,31 +435,50 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (*t
 
 	// set quantity
 	orderQty := order.Quantity
-	// if the order is market buy, the quantity is quote coin, instead of base coin. so we need to convert it.
-	if order.Type == types.OrderTypeMarket && order.Side == types.SideTypeBuy {
+	// if the spot order is market buy, the quantity is quote coin, instead of base coin. so we need to convert it.
+	// the quantity of the derivatives is always in the base coin.
+	isSpot := e.category == bybitapi.CategorySpot
+	if isSpot && order.Type == types.OrderTypeMarket && order.Side == types.SideTypeBuy {
 		ticker, err := e.QueryTicker(ctx, order.Market.Symbol)
 		if err != nil {
 			return nil, err
 		}
 		orderQty = order.Quantity.Mul(ticker.Buy)
 	}
+	// the stop market buy order is filled around the trigger price.
+	if isSpot && order.Type == types.OrderTypeStopMarket && order.Side == types.SideTypeBuy {
+		orderQty = order.Quantity.Mul(order.StopPrice)
+	}
 	req.Qty(order.Market.FormatQuantity(orderQty))
 
 	// set price
 	switch order.Type {
-	case types.OrderTypeLimit:
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker, types.OrderTypeStopLimit:
 		req.Price(order.Market.FormatPrice(order.Price))
 	}
 
+	// set trigger price, the buy stop order is triggered when the price rises, and the sell stop order is triggered
+	// when the price falls.
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+		req.TriggerPrice(order.Market.FormatPrice(order.StopPrice))
+		// the order filter is only for the spot, the derivatives order with the trigger price is conditional.
+		if isSpot {
+			req.OrderFilter(bybitapi.OrderFilterStopOrder)
+		}
+		if order.Side == types.SideTypeBuy {
+			req.TriggerDirection(bybitapi.TriggerDirectionRise)
+		} else {
+			req.TriggerDirection(bybitapi.TriggerDirectionFall)
+		}
+	}
+
 	// set timeInForce
-	switch order.TimeInForce {
-	case types.TimeInForceFOK:
-		req.TimeInForce(bybitapi.TimeInForceFOK)
-	case types.TimeInForceIOC:
-		req.TimeInForce(bybitapi.TimeInForceIOC)
-	default:
-		req.TimeInForce(bybitapi.TimeInForceGTC)
+	timeInForce, err := bybitapi.ToLocalTimeInForce(order.TimeInForce, order.Type == types.OrderTypeLimitMaker)
+	if err != nil {
+		return nil, err
 	}
+	req.TimeInForce(timeInForce)
 
 	// set client order id
 	if len(order.ClientOrderID) > maxOrderIdLen {
@@ -315,6 +488,35 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (*t
 		req.OrderLinkId(order.ClientOrderID)
 	}
 
+	// the margin buy side effect borrows the funds, which is the spot margin trading on bybit.
+	if order.MarginSideEffect == types.SideEffectTypeMarginBuy {
+		if err := e.validateMarginOrder(); err != nil {
+			return nil, err
+		}
+		req.IsLeverage(bybitapi.IsLeverageTrue)
+	}
+
+	// the reduce-only and the close-on-trigger orders are rejected by the validation in the spot category.
+	if order.ReduceOnly {
+		req.ReduceOnly(true)
+	}
+
+	if order.ClosePosition {
+		req.CloseOnTrigger(true)
+	}
+
+	if len(e.smpType) > 0 {
+		req.SmpType(e.smpType)
+	}
+
+	if e.autoRoundInstruments != nil {
+		req.WithAutoRound(e.autoRoundInstruments, e.autoRoundMode)
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid order request, order: %#v, err: %w", order, err)
+	}
+
 	if err := orderRateLimiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("place order rate limiter wait error: %w", err)
 	}
@@ -333,6 +535,15 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (*t
 		return nil, fmt.Errorf("failed to parse orderId: %s", res.OrderId)
 	}
 
+	// the order id and the client order id of the trades and the order updates are correlated to the trace id here
+	if id, ok := traceid.FromContext(ctx); ok {
+		log.WithFields(logrus.Fields{
+			traceid.LogField:  id,
+			"order_id":        res.OrderId,
+			"client_order_id": res.OrderLinkId,
+		}).Infof("the order %s of %s is placed", res.OrderId, order.Market.Symbol)
+	}
+
 	return &types.Order{
 		SubmitOrder:      order,
 		Exchange:         types.ExchangeBybit,
@@ -352,7 +563,7 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 	}
 
 	for _, order := range orders {
-		req := e.client.NewCancelOrderRequest()
+		req := e.client.NewCancelOrderRequest().Category(e.category)
 
 		reqId := ""
 		switch {
@@ -373,7 +584,15 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 			continue
 		}
 
-		req.Symbol(order.Market.Symbol)
+		req.Symbol(toLocalSymbol(order.Market.Symbol, e.category))
+
+		// the spot conditional order is cancelled with the stop order filter.
+		switch order.Type {
+		case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+			if e.category == bybitapi.CategorySpot {
+				req.OrderFilter(bybitapi.OrderFilterStopOrder)
+			}
+		}
 
 		if err := orderRateLimiter.Wait(ctx); err != nil {
 			errs = multierr.Append(errs, fmt.Errorf("cancel order rate limiter wait, order id: %s, error: %w", order.ClientOrderID, err))
@@ -395,6 +614,49 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 	return errs
 }
 
+// AmendOrder modifies the price and the quantity of the open order, the zero price or quantity is not modified. The
+// order is identified by the order id, or the client order id if the order id is not known yet, e.g. the order is
+// submitted again after the reconnection.
+func (e *Exchange) AmendOrder(ctx context.Context, order types.Order, price, quantity fixedpoint.Value) error {
+	// the market is used to format the price and the quantity.
+	if len(order.Market.Symbol) == 0 {
+		return fmt.Errorf("order.Market.Symbol is required: %+v", order)
+	}
+
+	req := e.client.NewAmendOrderRequest()
+	req.Symbol(toLocalSymbol(order.Market.Symbol, bybitapi.CategorySpot))
+
+	switch {
+	case len(order.UUID) != 0:
+		req.OrderId(order.UUID)
+
+	case len(order.ClientOrderID) != 0:
+		req.OrderLinkId(order.ClientOrderID)
+	}
+
+	if !price.IsZero() {
+		req.Price(order.Market.FormatPrice(price))
+	}
+
+	if !quantity.IsZero() {
+		req.Qty(order.Market.FormatQuantity(quantity))
+	}
+
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid amend order request, order: %#v, err: %w", order, err)
+	}
+
+	if err := orderRateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("amend order rate limiter wait error: %w", err)
+	}
+
+	if _, err := req.Do(ctx); err != nil {
+		return fmt.Errorf("failed to amend order, order id: %s, client order id: %s, err: %w", order.UUID, order.ClientOrderID, err)
+	}
+
+	return nil
+}
+
 func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, util time.Time, lastOrderID uint64) (orders []types.Order, err error) {
 	if !since.IsZero() || !util.IsZero() {
 		log.Warn("!!!BYBIT EXCHANGE API NOTICE!!! the since/until conditions will not be effected on SPOT account, bybit exchange does not support time-range-based query currently")
@@ -404,7 +666,7 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 		return nil, fmt.Errorf("query closed order rate limiter wait error: %w", err)
 	}
 	res, err := e.client.NewGetOrderHistoriesRequest().
-		Symbol(symbol).
+		Symbol(toLocalSymbol(symbol, bybitapi.CategorySpot)).
 		Cursor(strconv.FormatUint(lastOrderID, 10)).
 		Limit(defaultQueryLimit).
 		Do(ctx)
@@ -446,7 +708,7 @@ Otherwise, the result is sorted by tradeId in `descend`. **
 func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
 	// using v3 client, since the v5 API does not support feeCurrency.
 	req := e.v3client.NewGetTradesRequest()
-	req.Symbol(symbol)
+	req.Symbol(toLocalSymbol(symbol, bybitapi.CategorySpot))
 
 	// If `lastTradeId` is given and greater than 0, the query will use it as a condition and the retrieved result will be
 	// in `ascending` order. We can use `lastTradeId` to retrieve all the data. So we hack it to '1' if `lastTradeID` is '0'.
@@ -495,6 +757,44 @@ func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *type
 	return trades, nil
 }
 
+// QueryExecutionTrades queries the trades of the symbol between the start time and the end time from the execution
+// list, the fees are the ones charged by the exchange, so it's used to reconcile the realized PnL. The time range must
+// be within 7 days and bybit only retains the executions of a limited period. The trades are in ascending order.
+func (e *Exchange) QueryExecutionTrades(ctx context.Context, category bybitapi.Category, symbol string, startTime, endTime time.Time) ([]types.Trade, error) {
+	if err := queryOrderTradeRateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("query executions rate limiter wait error: %w", err)
+	}
+
+	executions, err := e.client.QueryExecutions(ctx, category, symbol, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query executions, err: %w", err)
+	}
+
+	// the markets are only queried for the fee currency of the spot executions which don't carry it.
+	var markets types.MarketMap
+	var errs error
+	trades := make([]types.Trade, 0, len(executions))
+	for i := len(executions) - 1; i >= 0; i-- {
+		if markets == nil && category == bybitapi.CategorySpot && len(executions[i].FeeCurrency) == 0 {
+			if markets, err = e.QueryMarkets(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		trade, err := toGlobalTradeFromExecution(executions[i], category, markets[executions[i].Symbol])
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		trades = append(trades, *trade)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+	return trades, nil
+}
+
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 	balanceMap, err := e.QueryAccountBalances(ctx)
 	if err != nil {
@@ -534,7 +834,13 @@ A k-line's start time is inclusive, but end time is not(startTime + interval - 1
 e.q. 15m interval k line can be represented as 00:00:00.000 ~ 00:14:59.999
 */
 func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
-	req := e.client.NewGetKLinesRequest().Symbol(symbol)
+	return e.queryKLines(ctx, bybitapi.CategorySpot, symbol, interval, options)
+}
+
+// queryKLines queries the klines of the symbol of the category, only the spot, linear and inverse categories have
+// the klines.
+func (e *Exchange) queryKLines(ctx context.Context, category bybitapi.Category, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	req := e.client.NewGetKLinesRequest().Category(category).Symbol(toLocalSymbol(symbol, category))
 	intervalStr, err := toLocalInterval(interval)
 	if err != nil {
 		return nil, err
@@ -565,12 +871,12 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 		return nil, fmt.Errorf("failed to call k line, err: %w", err)
 	}
 
-	if resp.Category != bybitapi.CategorySpot {
+	if resp.Category != category {
 		return nil, fmt.Errorf("unexpected category: %s", resp.Category)
 	}
 
-	if resp.Symbol != symbol {
-		return nil, fmt.Errorf("unexpected symbol: %s, exp: %s", resp.Category, symbol)
+	if toGlobalSymbol(resp.Symbol, resp.Category) != toGlobalSymbol(symbol, category) {
+		return nil, fmt.Errorf("unexpected symbol: %s, exp: %s", resp.Symbol, symbol)
 	}
 
 	kLines := toGlobalKLines(symbol, interval, resp.List)
@@ -578,6 +884,46 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 
 }
 
+// QueryAllKLines queries the klines of the symbol of the category between the start time and the end time, and returns
+// them in ascending order. The spot, linear and inverse categories are supported. Bybit returns at most 1000 klines per
+// request from the newest one, so it pages backward by moving the end time before the oldest kline of the last page,
+// the duplicated klines at the page boundaries are dropped.
+func (e *Exchange) QueryAllKLines(ctx context.Context, category bybitapi.Category, symbol string, interval types.Interval, startTime, endTime time.Time) ([]types.KLine, error) {
+	var kLines []types.KLine
+	seen := map[int64]struct{}{}
+
+	for !endTime.Before(startTime) {
+		start, end := startTime, endTime
+		page, err := e.queryKLines(ctx, category, symbol, interval, types.KLineQueryOptions{
+			Limit:     defaultKLineLimit,
+			StartTime: &start,
+			EndTime:   &end,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, k := range page {
+			ts := k.StartTime.Time().UnixMilli()
+			if _, ok := seen[ts]; ok {
+				continue
+			}
+
+			seen[ts] = struct{}{}
+			kLines = append(kLines, k)
+		}
+
+		if len(page) < defaultKLineLimit {
+			break
+		}
+
+		// the page is sorted in ascending order
+		endTime = page[0].StartTime.Time().Add(-time.Millisecond)
+	}
+
+	return types.SortKLinesAscending(kLines), nil
+}
+
 func (e *Exchange) SupportedInterval() map[types.Interval]int {
 	return bybitapi.SupportedIntervals
 }
@@ -600,5 +946,27 @@ func (e *Exchange) GetAllFeeRates(ctx context.Context) (bybitapi.FeeRates, error
 }
 
 func (e *Exchange) NewStream() types.Stream {
-	return NewStream(e.key, e.secret, e)
+	stream := NewStream(e.key, e.secret, e)
+	// the category is validated by SetCategory already.
+	_ = stream.SetCategory(e.category)
+	if e.client.IsTestnet() {
+		stream.EnableTestnet()
+	}
+	if e.client.IsDemoTrading() {
+		stream.EnableDemoTrading()
+	}
+
+	if e.cancelOnDisconnectWindow > 0 {
+		pingInterval := e.cancelOnDisconnectWindow / 3
+		if pingInterval > maxCancelOnDisconnectPingInterval {
+			pingInterval = maxCancelOnDisconnectPingInterval
+		}
+		stream.SetPingInterval(pingInterval)
+
+		// the window is set again after the reconnection, it's harmless to set the same window.
+		stream.OnAuth(func() {
+			go e.setCancelOnDisconnect()
+		})
+	}
+	return stream
 }
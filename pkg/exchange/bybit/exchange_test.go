@@ -0,0 +1,432 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchange_QueryAllKLines(t *testing.T) {
+	for _, category := range []bybitapi.Category{bybitapi.CategorySpot, bybitapi.CategoryLinear} {
+		t.Run(string(category), func(t *testing.T) {
+			ex, err := New("", "")
+			assert.NoError(t, err)
+
+			transport := &httptesting.MockTransport{}
+			ex.client.HttpClient.Transport = transport
+
+			startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			numOfKLines := 2500
+			endTime := startTime.Add(time.Duration(numOfKLines-1) * time.Minute)
+
+			numOfRequests := 0
+			transport.GET("/v5/market/kline", func(req *http.Request) (*http.Response, error) {
+				numOfRequests++
+
+				query := req.URL.Query()
+				assert.Equal(t, string(category), query.Get("category"))
+				assert.Equal(t, "1", query.Get("interval"))
+				assert.Equal(t, "1000", query.Get("limit"))
+
+				start, err := strconv.ParseInt(query.Get("start"), 10, 64)
+				assert.NoError(t, err)
+				end, err := strconv.ParseInt(query.Get("end"), 10, 64)
+				assert.NoError(t, err)
+
+				// newest first, and the kline started within one interval after the end time is included to make
+				// the page boundaries overlap.
+				var list [][]string
+				for i := numOfKLines - 1; i >= 0 && len(list) < defaultKLineLimit; i-- {
+					ts := startTime.Add(time.Duration(i) * time.Minute).UnixMilli()
+					if ts >= start && ts <= end+time.Minute.Milliseconds() {
+						list = append(list, []string{strconv.FormatInt(ts, 10), "100", "101", "99", "100.5", "10", "1005"})
+					}
+				}
+
+				return httptesting.BuildResponseJson(http.StatusOK, map[string]interface{}{
+					"retCode": 0,
+					"retMsg":  "OK",
+					"result": map[string]interface{}{
+						"symbol":   "BTCUSDT",
+						"category": string(category),
+						"list":     list,
+					},
+					"time": time.Now().UnixMilli(),
+				}), nil
+			})
+
+			kLines, err := ex.QueryAllKLines(context.Background(), category, "BTCUSDT", types.Interval1m, startTime, endTime)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, numOfRequests)
+			if assert.Len(t, kLines, numOfKLines) {
+				for i, k := range kLines {
+					assert.Equal(t, startTime.Add(time.Duration(i)*time.Minute), k.StartTime.Time().UTC())
+				}
+			}
+		})
+	}
+
+	t.Run("option", func(t *testing.T) {
+		ex, err := New("", "")
+		assert.NoError(t, err)
+
+		// the option has no klines, it's rejected before sending the request
+		ex.client.HttpClient.Transport = &httptesting.MockTransport{}
+		startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		_, err = ex.QueryAllKLines(context.Background(), bybitapi.CategoryOption, "BTC-29MAR24-70000-C", types.Interval1m,
+			startTime, startTime.Add(time.Hour))
+		assert.ErrorContains(t, err, "category value option is invalid")
+	})
+}
+
+func TestNew_clientOptions(t *testing.T) {
+	ex, err := New("key", "secret", bybitapi.WithDemoTrading(true))
+	assert.NoError(t, err)
+
+	stream := ex.NewStream().(*Stream)
+	assert.True(t, stream.demoTrading)
+	assert.False(t, stream.testnet)
+
+	ex, err = New("key", "secret", bybitapi.WithTestnet(true))
+	assert.NoError(t, err)
+
+	stream = ex.NewStream().(*Stream)
+	assert.False(t, stream.demoTrading)
+	assert.True(t, stream.testnet)
+
+	_, err = New("key", "secret", bybitapi.WithTestnet(true), bybitapi.WithDemoTrading(true))
+	assert.ErrorIs(t, err, bybitapi.ErrDemoTradingWithTestnet)
+}
+
+func TestExchange_QueryDepth(t *testing.T) {
+	ex, err := New("key", "secret")
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	transport.GET("/v5/market/orderbook", func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "BTCUSDT", req.URL.Query().Get("symbol"))
+		assert.Equal(t, "200", req.URL.Query().Get("limit"))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {
+			"s": "BTCUSDT", "a": [["101", "2"]], "b": [["100", "1"]], "ts": 1716863719031, "u": 230704, "seq": 1432604333
+		}, "retExtInfo": {}, "time": 1716863719382}`), nil
+	})
+
+	book, finalUpdateId, err := ex.QueryDepth(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(230704), finalUpdateId)
+	assert.Equal(t, "BTCUSDT", book.Symbol)
+	assert.Equal(t, int64(1432604333), book.SequenceId)
+	assert.NoError(t, book.Validate())
+
+	mid, ok := book.MidPrice()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(100.5), mid)
+}
+
+func TestExchange_AmendOrder(t *testing.T) {
+	ex, err := New("key", "secret")
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	var params map[string]interface{}
+	transport.POST("/v5/order/amend", func(req *http.Request) (*http.Response, error) {
+		params = map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&params))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"orderId": "", "orderLinkId": "my-order"}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	market := types.Market{
+		Symbol:          "BTCUSDT",
+		PricePrecision:  2,
+		VolumePrecision: 4,
+		TickSize:        fixedpoint.NewFromFloat(0.01),
+		StepSize:        fixedpoint.NewFromFloat(0.0001),
+	}
+
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: "my-order",
+			Symbol:        "BTCUSDT",
+			Market:        market,
+		},
+	}
+
+	err = ex.AmendOrder(context.Background(), order, fixedpoint.NewFromInt(30000), fixedpoint.Zero)
+	assert.NoError(t, err)
+	assert.Equal(t, "my-order", params["orderLinkId"])
+	assert.Equal(t, "30000.00", params["price"])
+	assert.NotContains(t, params, "orderId")
+	assert.NotContains(t, params, "qty")
+
+	// either the order id or the client order id is required
+	order.ClientOrderID = ""
+	err = ex.AmendOrder(context.Background(), order, fixedpoint.NewFromInt(30000), fixedpoint.Zero)
+	assert.ErrorContains(t, err, "either orderId or orderLinkId is required")
+}
+
+func TestExchange_SetSmpType(t *testing.T) {
+	ex, err := New("key", "secret")
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	var params map[string]interface{}
+	transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+		params = map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&params))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"orderId": "1", "orderLinkId": "my-order"}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	order := types.SubmitOrder{
+		ClientOrderID: "my-order",
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		Type:          types.OrderTypeLimit,
+		Quantity:      fixedpoint.NewFromFloat(0.001),
+		Price:         fixedpoint.NewFromInt(30000),
+		Market: types.Market{
+			Symbol:          "BTCUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 4,
+		},
+	}
+
+	// the account default is used
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.NoError(t, err)
+	assert.NotContains(t, params, "smpType")
+
+	assert.NoError(t, ex.SetSmpType(bybitapi.SmpTypeCancelTaker))
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Equal(t, "CancelTaker", params["smpType"])
+
+	assert.Error(t, ex.SetSmpType("CancelNone"))
+}
+
+func TestExchange_QueryExecutionTrades_feeCurrency(t *testing.T) {
+	ex, err := New("key", "secret")
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	transport.GET("/v5/execution/list", func(req *http.Request) (*http.Response, error) {
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "nextPageCursor": "", "list": [
+			{"symbol": "BTCUSDT", "orderId": "2", "execId": "20", "side": "Sell", "execPrice": "28100", "execQty": "0.1", "execFee": "2.81", "feeRate": "0.001", "feeCurrency": "", "isMaker": false, "execTime": "1700000001000"},
+			{"symbol": "BTCUSDT", "orderId": "1", "execId": "10", "side": "Buy", "execPrice": "28000", "execQty": "0.1", "execFee": "0.0001", "feeRate": "0.001", "feeCurrency": "", "isMaker": false, "execTime": "1700000000000"}
+		]}, "retExtInfo": {}, "time": 1700000002000}`), nil
+	})
+
+	numOfMarketRequests := 0
+	transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+		numOfMarketRequests++
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "list": [
+			{"symbol": "BTCUSDT", "baseCoin": "BTC", "quoteCoin": "USDT", "status": "Trading", "lotSizeFilter": {"basePrecision": "0.0001", "quotePrecision": "0.01", "minOrderQty": "0.0001", "minOrderAmt": "1"}, "priceFilter": {"tickSize": "0.01"}}
+		]}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	trades, err := ex.QueryExecutionTrades(context.Background(), bybitapi.CategorySpot, "BTCUSDT",
+		time.UnixMilli(1700000000000), time.UnixMilli(1700000002000))
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 2) {
+		// the buyer pays the fee in the base currency and the seller pays it in the quote currency
+		assert.Equal(t, "BTC", trades[0].FeeCurrency)
+		assert.Equal(t, "USDT", trades[1].FeeCurrency)
+	}
+	// the markets are queried once
+	assert.Equal(t, 1, numOfMarketRequests)
+}
+
+func TestExchange_EnableAutoRound(t *testing.T) {
+	ex, err := New("key", "secret")
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	transport.GET("/v5/market/instruments-info", func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "BTCUSDT", req.URL.Query().Get("symbol"))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "list": [
+			{"symbol": "BTCUSDT", "status": "Trading", "lotSizeFilter": {"basePrecision": "0.0001", "minOrderQty": "0.0001"}, "priceFilter": {"tickSize": "0.5"}}
+		]}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	var params map[string]interface{}
+	transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+		params = map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&params))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"orderId": "1", "orderLinkId": ""}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	// the market precision is finer than the tick size of the instrument
+	order := types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromFloat(0.00123),
+		Price:    fixedpoint.NewFromFloat(30000.26),
+		Market: types.Market{
+			Symbol:          "BTCUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 6,
+			TickSize:        fixedpoint.NewFromFloat(0.01),
+			StepSize:        fixedpoint.NewFromFloat(0.000001),
+		},
+	}
+
+	ex.EnableAutoRound(fixedpoint.Down)
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0012", params["qty"])
+	assert.Equal(t, "30000", params["price"])
+}
+
+func TestExchange_SubmitOrder_margin(t *testing.T) {
+	ex, err := New("key", "secret")
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	var params map[string]interface{}
+	transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+		params = map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&params))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"orderId": "1", "orderLinkId": "my-order"}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	order := types.SubmitOrder{
+		ClientOrderID:    "my-order",
+		Symbol:           "BTCUSDT",
+		Side:             types.SideTypeBuy,
+		Type:             types.OrderTypeLimit,
+		Quantity:         fixedpoint.NewFromFloat(0.001),
+		Price:            fixedpoint.NewFromInt(30000),
+		MarginSideEffect: types.SideEffectTypeMarginBuy,
+		Market: types.Market{
+			Symbol:          "BTCUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 4,
+		},
+	}
+
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.ErrorContains(t, err, "the margin trading is not enabled")
+	assert.Nil(t, params)
+
+	ex.UseMargin()
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(bybitapi.IsLeverageTrue), params["isLeverage"])
+
+	// the cash order has no isLeverage flag
+	order.MarginSideEffect = types.SideEffectTypeNoSideEffect
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.NoError(t, err)
+	assert.NotContains(t, params, "isLeverage")
+
+	order.MarginSideEffect = types.SideEffectTypeMarginBuy
+	ex.UseIsolatedMargin("BTCUSDT")
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.ErrorContains(t, err, "the isolated margin of BTCUSDT is not supported")
+}
+
+func TestExchange_EnableCancelOnDisconnect(t *testing.T) {
+	ex, err := New("key", "secret")
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	params := make(chan map[string]interface{}, 1)
+	transport.POST("/v5/order/disconnected-cancel-all", func(req *http.Request) (*http.Response, error) {
+		var p map[string]interface{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&p))
+		params <- p
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode":0,"retMsg":"success","result":{},"retExtInfo":{},"time":1675852742817}`), nil
+	})
+
+	assert.Error(t, ex.EnableCancelOnDisconnect(bybitapi.DisconnectCancelAllProductDerivatives, time.Second))
+	assert.Error(t, ex.EnableCancelOnDisconnect(bybitapi.DisconnectCancelAllProductDerivatives, 10*time.Minute))
+	assert.NoError(t, ex.EnableCancelOnDisconnect(bybitapi.DisconnectCancelAllProductDerivatives, 15*time.Second))
+
+	// the window is set after the private stream is authenticated
+	stream := ex.NewStream().(*Stream)
+	stream.EmitAuth()
+
+	select {
+	case p := <-params:
+		assert.Equal(t, map[string]interface{}{"product": "DERIVATIVES", "timeWindow": float64(15)}, p)
+	case <-time.After(time.Second):
+		t.Fatal("the cancel on disconnect is not set")
+	}
+}
+
+func TestExchange_SetCategory(t *testing.T) {
+	ex, err := New("key", "secret")
+	assert.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	var params map[string]interface{}
+	transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+		params = map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&params))
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"orderId": "1", "orderLinkId": ""}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	order := types.SubmitOrder{
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		Type:          types.OrderTypeStopMarket,
+		Quantity:      fixedpoint.NewFromFloat(0.001),
+		StopPrice:     fixedpoint.NewFromInt(30000),
+		ReduceOnly:    true,
+		ClosePosition: true,
+		Market: types.Market{
+			Symbol:          "BTCUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 4,
+			TickSize:        fixedpoint.NewFromFloat(0.01),
+			StepSize:        fixedpoint.NewFromFloat(0.0001),
+		},
+	}
+
+	// the reduce-only order is rejected in the spot category
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.ErrorContains(t, err, "reduceOnly is not supported by the spot category")
+	assert.Nil(t, params)
+
+	assert.NoError(t, ex.SetCategory(bybitapi.CategoryLinear))
+	_, err = ex.SubmitOrder(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Equal(t, "linear", params["category"])
+	assert.Equal(t, true, params["reduceOnly"])
+	assert.Equal(t, true, params["closeOnTrigger"])
+	assert.Equal(t, "30000.00", params["triggerPrice"])
+	// the quantity of the derivatives is in the base coin, and there is no spot order filter
+	assert.Equal(t, "0.0010", params["qty"])
+	assert.NotContains(t, params, "orderFilter")
+
+	// the stream emits the orders of the category
+	assert.Equal(t, bybitapi.CategoryLinear, ex.NewStream().(*Stream).category)
+
+	assert.ErrorContains(t, ex.SetCategory(bybitapi.CategoryOption), "the option category is not supported by the exchange")
+}
@@ -0,0 +1,205 @@
+package bybit
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+var fillDiscrepancyLogLimiter = rate.NewLimiter(rate.Every(time.Minute), 1)
+
+const (
+	// fillTrackerTTL evicts the fills of the orders without an update in the period, e.g. the executions of the
+	// orders whose terminal order event is never received since the order topic isn't subscribed.
+	fillTrackerTTL = 24 * time.Hour
+	// fillTrackerEvictInterval is the min interval between the evictions.
+	fillTrackerEvictInterval = time.Minute
+)
+
+// FillSource is the topic used to drive the trade updates of a symbol.
+type FillSource string
+
+const (
+	// FillSourceExecution emits the trades from the execution topic, it's the default.
+	FillSourceExecution FillSource = "execution"
+	// FillSourceOrder emits the trades derived from the cumExecQty of the order topic.
+	FillSourceOrder FillSource = "order"
+)
+
+type orderFill struct {
+	cumExecQty   fixedpoint.Value
+	cumExecValue fixedpoint.Value
+	cumExecFee   fixedpoint.Value
+	updatedAt    time.Time
+}
+
+type executedFill struct {
+	qty fixedpoint.Value
+	// isMaker is the liquidity of the last execution of the order.
+	isMaker   bool
+	updatedAt time.Time
+}
+
+// fillTracker selects the authoritative fill source per symbol, the fills of the other source are only used for
+// cross-checking, so the same fill won't be counted twice when both topics are subscribed.
+type fillTracker struct {
+	mu      sync.Mutex
+	sources map[string]FillSource
+
+	// executedQty is the sum of the execution quantities by the order id.
+	executedQty map[string]executedFill
+	// orderFills is the last seen cumulative fill of the order topic by the order id.
+	orderFills map[string]orderFill
+	// evictedAt is the time of the last eviction, see evictExpired.
+	evictedAt time.Time
+}
+
+func newFillTracker() *fillTracker {
+	return &fillTracker{
+		sources:     make(map[string]FillSource),
+		executedQty: make(map[string]executedFill),
+		orderFills:  make(map[string]orderFill),
+	}
+}
+
+func (t *fillTracker) SetSource(symbol string, source FillSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sources[symbol] = source
+}
+
+func (t *fillTracker) Source(symbol string) FillSource {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if source, ok := t.sources[symbol]; ok {
+		return source
+	}
+	return FillSourceExecution
+}
+
+// AddExecution records the execution for cross-checking with the order topic.
+func (t *fillTracker) AddExecution(event TradeEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.evictExpired(now)
+
+	fill := t.executedQty[event.OrderId]
+	t.executedQty[event.OrderId] = executedFill{
+		qty:       fill.qty.Add(event.ExecQty),
+		isMaker:   event.IsMaker,
+		updatedAt: now,
+	}
+}
+
+// UpdateOrder records the cumulative fill of the order and returns the new fill since the last update as a
+// TradeEvent. The order topic doesn't tell the liquidity, so the fill takes the maker flag of the last execution of
+// the order if it's received, the post-only order fill is maker, and the other fills are treated as taker. The fee of
+// the fill is the difference of the cumExecFee.
+func (t *fillTracker) UpdateOrder(order bybitapi.Order, category bybitapi.Category) (TradeEvent, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.evictExpired(now)
+
+	if isTerminalOrderStatus(order.OrderStatus) {
+		defer t.crossCheck(order)
+	}
+
+	last := t.orderFills[order.OrderId]
+	qty := order.CumExecQty.Sub(last.cumExecQty)
+	if qty.Sign() <= 0 {
+		return TradeEvent{}, false
+	}
+
+	t.orderFills[order.OrderId] = orderFill{
+		cumExecQty:   order.CumExecQty,
+		cumExecValue: order.CumExecValue,
+		cumExecFee:   order.CumExecFee,
+		updatedAt:    now,
+	}
+
+	isMaker := order.TimeInForce == bybitapi.TimeInForcePostOnly
+	if execution, ok := t.executedQty[order.OrderId]; ok {
+		isMaker = execution.isMaker
+	}
+
+	price := order.AvgPrice
+	if value := order.CumExecValue.Sub(last.cumExecValue); value.Sign() > 0 {
+		price = value.Div(qty)
+	}
+
+	return TradeEvent{
+		OrderId:     order.OrderId,
+		OrderLinkId: order.OrderLinkId,
+		Category:    category,
+		Symbol:      order.Symbol,
+		// the order topic has no execution id, so derive an unique one from the cumulative fill.
+		ExecId:    strconv.FormatUint(hashStringID(order.OrderId+"-"+order.CumExecQty.String()), 10),
+		ExecPrice: price,
+		ExecQty:   qty,
+		ExecFee:   order.CumExecFee.Sub(last.cumExecFee),
+		IsMaker:   isMaker,
+		OrderType: order.OrderType,
+		Side:      order.Side,
+		ExecTime:  order.UpdatedTime,
+	}, true
+}
+
+// crossCheck logs the discrepancy between the execution topic and the order topic, and then cleans up the order.
+// It must be called with the lock held.
+func (t *fillTracker) crossCheck(order bybitapi.Order) {
+	execution, ok := t.executedQty[order.OrderId]
+	if ok && execution.qty.Compare(order.CumExecQty) != 0 && fillDiscrepancyLogLimiter.Allow() {
+		log.Warnf("the fill discrepancy found, order id: %s, symbol: %s, executed qty: %s, cumExecQty: %s",
+			order.OrderId, order.Symbol, execution.qty.String(), order.CumExecQty.String())
+	}
+
+	delete(t.executedQty, order.OrderId)
+	delete(t.orderFills, order.OrderId)
+}
+
+// evictExpired deletes the fills not updated within fillTrackerTTL, at most once per fillTrackerEvictInterval. It must
+// be called with the lock held.
+func (t *fillTracker) evictExpired(now time.Time) {
+	if now.Sub(t.evictedAt) < fillTrackerEvictInterval {
+		return
+	}
+	t.evictedAt = now
+
+	for orderID, execution := range t.executedQty {
+		if now.Sub(execution.updatedAt) > fillTrackerTTL {
+			delete(t.executedQty, orderID)
+		}
+	}
+
+	for orderID, fill := range t.orderFills {
+		if now.Sub(fill.updatedAt) > fillTrackerTTL {
+			delete(t.orderFills, orderID)
+		}
+	}
+}
+
+func isTerminalOrderStatus(status bybitapi.OrderStatus) bool {
+	switch status {
+	case bybitapi.OrderStatusRejected, bybitapi.OrderStatusPartiallyFilledCanceled,
+		bybitapi.OrderStatusFilled, bybitapi.OrderStatusCancelled, bybitapi.OrderStatusDeactivated:
+		return true
+	}
+	return false
+}
+
+func hashStringID(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
@@ -0,0 +1,70 @@
+package bybit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestFillTracker_UpdateOrder_isMaker(t *testing.T) {
+	order := func(id string, timeInForce bybitapi.TimeInForce) bybitapi.Order {
+		return bybitapi.Order{
+			OrderId:      id,
+			Symbol:       "BTCUSDT",
+			Side:         bybitapi.SideBuy,
+			OrderStatus:  bybitapi.OrderStatusPartiallyFilled,
+			OrderType:    bybitapi.OrderTypeLimit,
+			TimeInForce:  timeInForce,
+			CumExecQty:   fixedpoint.One,
+			CumExecValue: fixedpoint.NewFromInt(100),
+		}
+	}
+
+	tracker := newFillTracker()
+
+	// the limit order may cross the book, so it's taker without the execution
+	fill, ok := tracker.UpdateOrder(order("1", bybitapi.TimeInForceGTC), bybitapi.CategorySpot)
+	assert.True(t, ok)
+	assert.False(t, fill.IsMaker)
+
+	fill, ok = tracker.UpdateOrder(order("2", bybitapi.TimeInForcePostOnly), bybitapi.CategorySpot)
+	assert.True(t, ok)
+	assert.True(t, fill.IsMaker)
+
+	// the maker flag of the execution takes precedence
+	tracker.AddExecution(TradeEvent{OrderId: "3", ExecQty: fixedpoint.One, IsMaker: true})
+	fill, ok = tracker.UpdateOrder(order("3", bybitapi.TimeInForceGTC), bybitapi.CategorySpot)
+	assert.True(t, ok)
+	assert.True(t, fill.IsMaker)
+}
+
+func TestFillTracker_evictExpired(t *testing.T) {
+	tracker := newFillTracker()
+	tracker.AddExecution(TradeEvent{OrderId: "1", ExecQty: fixedpoint.One})
+	tracker.UpdateOrder(bybitapi.Order{
+		OrderId:     "1",
+		Symbol:      "BTCUSDT",
+		OrderStatus: bybitapi.OrderStatusPartiallyFilled,
+		CumExecQty:  fixedpoint.One,
+	}, bybitapi.CategorySpot)
+
+	now := time.Now()
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	expired := now.Add(fillTrackerTTL + time.Second)
+	tracker.evictExpired(expired)
+	assert.Empty(t, tracker.executedQty)
+	assert.Empty(t, tracker.orderFills)
+
+	// evicted at most once per interval
+	tracker.executedQty["2"] = executedFill{qty: fixedpoint.One, updatedAt: now}
+	tracker.evictExpired(expired.Add(fillTrackerEvictInterval / 2))
+	assert.Len(t, tracker.executedQty, 1)
+	tracker.evictExpired(expired.Add(fillTrackerEvictInterval))
+	assert.Empty(t, tracker.executedQty)
+}
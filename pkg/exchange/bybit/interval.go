@@ -0,0 +1,97 @@
+package bybit
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// IntervalFloor returns the start time of the kline of the interval which contains t, in UTC. It aligns the klines like
+// bybit does, the minute and the hour intervals align to the multiples of the interval since the Unix epoch, the daily
+// kline aligns to the UTC midnight, the weekly kline aligns to the Monday UTC midnight and the monthly kline aligns to
+// the first day of the month.
+func IntervalFloor(t time.Time, interval types.Interval) time.Time {
+	t = t.UTC()
+
+	switch interval {
+	case types.Interval1w:
+		day := t.Truncate(24 * time.Hour)
+		// time.Weekday starts from Sunday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+
+	case types.Interval1mo:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	default:
+		// the intervals up to a day evenly divide the days since the Unix epoch, and time.Truncate works on the
+		// absolute time, so it aligns to the epoch as well.
+		return t.Truncate(interval.Duration())
+	}
+}
+
+// IntervalCeil returns the start time of the next kline of the interval if t isn't aligned to the interval, otherwise
+// t itself, in UTC.
+func IntervalCeil(t time.Time, interval types.Interval) time.Time {
+	floor := IntervalFloor(t, interval)
+	if floor.Equal(t) {
+		return floor
+	}
+
+	return nextIntervalStart(floor, interval)
+}
+
+// nextIntervalStart returns the start time of the kline next to the kline starting at start.
+func nextIntervalStart(start time.Time, interval types.Interval) time.Time {
+	switch interval {
+	case types.Interval1w:
+		return start.AddDate(0, 0, 7)
+
+	case types.Interval1mo:
+		return start.AddDate(0, 1, 0)
+
+	default:
+		return start.Add(interval.Duration())
+	}
+}
+
+// IsIntervalAligned returns true if t is the start time of a kline of the interval, it validates the kline start
+// times of the aggregation and the backfill.
+func IsIntervalAligned(t time.Time, interval types.Interval) bool {
+	return IntervalFloor(t, interval).Equal(t)
+}
+
+// FillKLineGaps returns the klines with the missing klines between them filled, bybit skips the klines without any
+// trade of the illiquid symbols. The klines must be of the same symbol and sorted by the start time in ascending order.
+// The synthetic kline is flat at the close price of the previous kline with zero volume and zero trades, the zero
+// volume tells it from the real klines. The given klines are not modified.
+func FillKLineGaps(klines []types.KLine, interval types.Interval) []types.KLine {
+	if len(klines) < 2 {
+		return klines
+	}
+
+	filled := make([]types.KLine, 0, len(klines))
+	filled = append(filled, klines[0])
+	for _, kline := range klines[1:] {
+		prev := filled[len(filled)-1]
+		for start := nextIntervalStart(prev.StartTime.Time(), interval); start.Before(kline.StartTime.Time()); {
+			next := nextIntervalStart(start, interval)
+			filled = append(filled, types.KLine{
+				Exchange:  prev.Exchange,
+				Symbol:    prev.Symbol,
+				StartTime: types.Time(start),
+				EndTime:   types.Time(next.Add(-time.Millisecond)),
+				Interval:  interval,
+				Open:      prev.Close,
+				Close:     prev.Close,
+				High:      prev.Close,
+				Low:       prev.Close,
+				Closed:    prev.Closed,
+			})
+			start = next
+		}
+
+		filled = append(filled, kline)
+	}
+
+	return filled
+}
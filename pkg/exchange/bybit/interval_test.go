@@ -0,0 +1,134 @@
+package bybit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestIntervalFloor(t *testing.T) {
+	// Wednesday
+	ts := time.Date(2024, 3, 13, 17, 47, 31, 500, time.UTC)
+
+	tests := []struct {
+		interval types.Interval
+		floor    time.Time
+		ceil     time.Time
+	}{
+		{types.Interval1m, time.Date(2024, 3, 13, 17, 47, 0, 0, time.UTC), time.Date(2024, 3, 13, 17, 48, 0, 0, time.UTC)},
+		{types.Interval3m, time.Date(2024, 3, 13, 17, 45, 0, 0, time.UTC), time.Date(2024, 3, 13, 17, 48, 0, 0, time.UTC)},
+		{types.Interval5m, time.Date(2024, 3, 13, 17, 45, 0, 0, time.UTC), time.Date(2024, 3, 13, 17, 50, 0, 0, time.UTC)},
+		{types.Interval15m, time.Date(2024, 3, 13, 17, 45, 0, 0, time.UTC), time.Date(2024, 3, 13, 18, 0, 0, 0, time.UTC)},
+		{types.Interval30m, time.Date(2024, 3, 13, 17, 30, 0, 0, time.UTC), time.Date(2024, 3, 13, 18, 0, 0, 0, time.UTC)},
+		{types.Interval1h, time.Date(2024, 3, 13, 17, 0, 0, 0, time.UTC), time.Date(2024, 3, 13, 18, 0, 0, 0, time.UTC)},
+		{types.Interval2h, time.Date(2024, 3, 13, 16, 0, 0, 0, time.UTC), time.Date(2024, 3, 13, 18, 0, 0, 0, time.UTC)},
+		{types.Interval4h, time.Date(2024, 3, 13, 16, 0, 0, 0, time.UTC), time.Date(2024, 3, 13, 20, 0, 0, 0, time.UTC)},
+		{types.Interval6h, time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC), time.Date(2024, 3, 13, 18, 0, 0, 0, time.UTC)},
+		{types.Interval12h, time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC), time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{types.Interval1d, time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{types.Interval1w, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{types.Interval1mo, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	assert.Len(t, tests, len(bybitapi.SupportedIntervals))
+	for _, test := range tests {
+		t.Run(test.interval.String(), func(t *testing.T) {
+			assert.Equal(t, test.floor, IntervalFloor(ts, test.interval))
+			assert.Equal(t, test.ceil, IntervalCeil(ts, test.interval))
+
+			assert.True(t, IsIntervalAligned(test.floor, test.interval))
+			assert.False(t, IsIntervalAligned(ts, test.interval))
+			assert.Equal(t, test.floor, IntervalCeil(test.floor, test.interval))
+		})
+	}
+
+	t.Run("non-UTC location", func(t *testing.T) {
+		// 2024-03-11 07:00 in UTC+8 is 2024-03-10 23:00 UTC, which is in the week starting on 2024-03-04
+		loc := time.FixedZone("UTC+8", 8*60*60)
+		assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), IntervalFloor(time.Date(2024, 3, 11, 7, 0, 0, 0, loc), types.Interval1w))
+		assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), IntervalFloor(time.Date(2024, 3, 11, 7, 0, 0, 0, loc), types.Interval1d))
+	})
+
+	t.Run("week on Monday and Sunday", func(t *testing.T) {
+		monday := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, monday, IntervalFloor(monday, types.Interval1w))
+		assert.Equal(t, monday, IntervalFloor(time.Date(2024, 1, 7, 23, 59, 59, 0, time.UTC), types.Interval1w))
+	})
+
+	t.Run("month end", func(t *testing.T) {
+		assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), IntervalCeil(time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), types.Interval1mo))
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), IntervalCeil(time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC), types.Interval1mo))
+	})
+}
+
+func TestFillKLineGaps(t *testing.T) {
+	newKLine := func(start time.Time, interval types.Interval, close float64) types.KLine {
+		return types.KLine{
+			Exchange:  types.ExchangeBybit,
+			Symbol:    "BTCUSDT",
+			StartTime: types.Time(start),
+			EndTime:   types.Time(nextIntervalStart(start, interval).Add(-time.Millisecond)),
+			Interval:  interval,
+			Open:      fixedpoint.NewFromFloat(close - 1),
+			Close:     fixedpoint.NewFromFloat(close),
+			High:      fixedpoint.NewFromFloat(close + 1),
+			Low:       fixedpoint.NewFromFloat(close - 2),
+			Volume:    fixedpoint.One,
+		}
+	}
+
+	t.Run("minutes", func(t *testing.T) {
+		start := time.Date(2024, 3, 13, 17, 0, 0, 0, time.UTC)
+		klines := []types.KLine{
+			newKLine(start, types.Interval1m, 100),
+			newKLine(start.Add(3*time.Minute), types.Interval1m, 105),
+			newKLine(start.Add(4*time.Minute), types.Interval1m, 106),
+		}
+
+		filled := FillKLineGaps(klines, types.Interval1m)
+		if assert.Len(t, filled, 5) {
+			assert.Equal(t, klines[0], filled[0])
+			for i, k := range filled[1:3] {
+				assert.Equal(t, start.Add(time.Duration(i+1)*time.Minute), k.StartTime.Time())
+				assert.Equal(t, start.Add(time.Duration(i+2)*time.Minute-time.Millisecond), k.EndTime.Time())
+				assert.Equal(t, fixedpoint.NewFromFloat(100), k.Open)
+				assert.Equal(t, fixedpoint.NewFromFloat(100), k.High)
+				assert.Equal(t, fixedpoint.NewFromFloat(100), k.Low)
+				assert.Equal(t, fixedpoint.NewFromFloat(100), k.Close)
+				assert.True(t, k.Volume.IsZero())
+				assert.Equal(t, "BTCUSDT", k.Symbol)
+			}
+			assert.Equal(t, klines[1], filled[3])
+			assert.Equal(t, klines[2], filled[4])
+		}
+	})
+
+	t.Run("months", func(t *testing.T) {
+		klines := []types.KLine{
+			newKLine(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), types.Interval1mo, 100),
+			newKLine(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), types.Interval1mo, 105),
+		}
+
+		filled := FillKLineGaps(klines, types.Interval1mo)
+		if assert.Len(t, filled, 4) {
+			assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), filled[1].StartTime.Time())
+			assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Add(-time.Millisecond), filled[1].EndTime.Time())
+			assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), filled[2].StartTime.Time())
+		}
+	})
+
+	t.Run("no gap", func(t *testing.T) {
+		start := time.Date(2024, 3, 13, 17, 0, 0, 0, time.UTC)
+		klines := []types.KLine{
+			newKLine(start, types.Interval1h, 100),
+			newKLine(start.Add(time.Hour), types.Interval1h, 101),
+		}
+		assert.Equal(t, klines, FillKLineGaps(klines, types.Interval1h))
+		assert.Empty(t, FillKLineGaps(nil, types.Interval1h))
+	})
+}
@@ -0,0 +1,35 @@
+package bybit
+
+import (
+	"encoding/json"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// JSONDecoder decodes the websocket messages and the data of the topics, see Stream.SetJSONDecoder.
+type JSONDecoder interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type stdJSONDecoder struct{}
+
+func (stdJSONDecoder) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+var (
+	// StdJSONDecoder decodes by encoding/json, it's the default decoder.
+	StdJSONDecoder JSONDecoder = stdJSONDecoder{}
+
+	// JSONIterDecoder decodes by jsoniter with the configuration compatible with encoding/json, so the custom
+	// json.Unmarshaler like fixedpoint.Value works as is.
+	JSONIterDecoder JSONDecoder = jsoniter.ConfigCompatibleWithStandardLibrary
+)
+
+// orStdJSONDecoder returns the decoder, or StdJSONDecoder if it's nil.
+func orStdJSONDecoder(decoder JSONDecoder) JSONDecoder {
+	if decoder == nil {
+		return StdJSONDecoder
+	}
+	return decoder
+}
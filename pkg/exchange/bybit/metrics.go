@@ -0,0 +1,27 @@
+package bybit
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	metricsDroppedDecodeErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "bbgo_bybit_stream_dropped_decode_errors_total",
+			Help: "the decode errors dropped since the decode error channel is full",
+		},
+	)
+
+	metricsDroppedTopicEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_bybit_stream_dropped_topic_events_total",
+			Help: "the events dropped since the topic queue is full",
+		},
+		[]string{"topic", "policy"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricsDroppedDecodeErrors,
+		metricsDroppedTopicEvents,
+	)
+}
@@ -0,0 +1,312 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PaperTrader simulates the spot orders against the full depth books maintained by the stream, so a strategy can be
+// dry run with the live market data. It has no API client and it's not an Exchange, so it never sends a real order.
+//
+// The simulated orders and fills are emitted to the OnOrderUpdate and the OnTradeUpdate callbacks of the stream, like
+// the private stream does. The market order walks the book by the VWAP of its quantity, and the rest is cancelled if
+// the book is too thin. The limit order crossing the book fills the crossed levels as the taker, and the rest of it
+// rests until the book crosses its price, then it's filled at its price as the maker up to the crossed volume. The
+// fills don't consume the liquidity of the book, so the same levels can fill many orders before the next book update.
+type PaperTrader struct {
+	stream *Stream
+
+	makerFeeRate, takerFeeRate fixedpoint.Value
+
+	mu sync.Mutex
+	// books are the latest books by the symbol.
+	books map[string]types.SliceOrderBook
+	// openOrders are the resting limit orders in the submitted order.
+	openOrders []*types.Order
+	orderId    uint64
+	tradeId    uint64
+}
+
+// NewPaperTrader creates the paper trader of the books of the stream, the book channels of the symbols must be
+// subscribed. The default fee rates are 0.1%.
+func NewPaperTrader(stream *Stream) *PaperTrader {
+	t := &PaperTrader{
+		stream:       stream,
+		makerFeeRate: defaultMakerFee,
+		takerFeeRate: defaultTakerFee,
+		books:        make(map[string]types.SliceOrderBook),
+	}
+
+	// the stream maintains the full depth books for the OnOrderBook callbacks
+	stream.OnOrderBook(t.handleOrderBook)
+	return t
+}
+
+// SetFeeRates sets the fee rates of the maker and the taker fills. The fee of the buy fill is charged in the base
+// currency, and the fee of the sell fill is charged in the quote currency, like the bybit spot trading.
+func (t *PaperTrader) SetFeeRates(maker, taker fixedpoint.Value) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.makerFeeRate = maker
+	t.takerFeeRate = taker
+}
+
+// SubmitOrder simulates the market, the limit and the limit maker orders against the latest book of the symbol. The
+// limit maker order crossing the book is rejected.
+func (t *PaperTrader) SubmitOrder(ctx context.Context, submitOrder types.SubmitOrder) (*types.Order, error) {
+	switch submitOrder.Type {
+	case types.OrderTypeMarket, types.OrderTypeLimit, types.OrderTypeLimitMaker:
+	default:
+		return nil, fmt.Errorf("the %s order is not supported by the paper trader", submitOrder.Type)
+	}
+
+	if submitOrder.Quantity.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid quantity of the paper order: %s", submitOrder.Quantity.String())
+	}
+
+	if submitOrder.Type != types.OrderTypeMarket && submitOrder.Price.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid price of the paper order: %s", submitOrder.Price.String())
+	}
+
+	t.mu.Lock()
+	book, ok := t.books[submitOrder.Symbol]
+	if !ok {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no order book of %s, the book channel must be subscribed", submitOrder.Symbol)
+	}
+
+	now := time.Now()
+	t.orderId++
+	order := &types.Order{
+		SubmitOrder:      submitOrder,
+		Exchange:         types.ExchangeBybit,
+		OrderID:          t.orderId,
+		Status:           types.OrderStatusNew,
+		ExecutedQuantity: fixedpoint.Zero,
+		IsWorking:        true,
+		CreationTime:     types.Time(now),
+		UpdateTime:       types.Time(now),
+	}
+
+	var orders []types.Order
+	var trades []types.Trade
+	switch submitOrder.Type {
+	case types.OrderTypeMarket:
+		price, filled := book.VWAP(submitOrder.Side, submitOrder.Quantity)
+		if filled.Sign() > 0 {
+			trades = append(trades, t.fill(order, price, filled, false, now))
+		}
+		if order.Status != types.OrderStatusFilled {
+			order.Status = types.OrderStatusCanceled
+			order.IsWorking = false
+		}
+		orders = append(orders, *order)
+
+	case types.OrderTypeLimitMaker:
+		if crossedVolume(book, submitOrder.Side, submitOrder.Price).Sign() > 0 {
+			order.Status = types.OrderStatusRejected
+			order.IsWorking = false
+			orders = append(orders, *order)
+			break
+		}
+		orders = append(orders, *order)
+		t.openOrders = append(t.openOrders, order)
+
+	case types.OrderTypeLimit:
+		orders = append(orders, *order)
+		if price, filled := takerFill(book, submitOrder.Side, submitOrder.Quantity, submitOrder.Price); filled.Sign() > 0 {
+			trades = append(trades, t.fill(order, price, filled, false, now))
+			orders = append(orders, *order)
+		}
+		if order.IsWorking {
+			t.openOrders = append(t.openOrders, order)
+		}
+	}
+
+	created := *order
+	t.mu.Unlock()
+
+	t.emit(orders, trades)
+	return &created, nil
+}
+
+// CancelOrders cancels the open orders by the order id, it returns an error if any of them is not open.
+func (t *PaperTrader) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	t.mu.Lock()
+
+	var canceled []types.Order
+	var missing []uint64
+	for _, o := range orders {
+		i := t.indexOpenOrder(o.OrderID)
+		if i < 0 {
+			missing = append(missing, o.OrderID)
+			continue
+		}
+
+		order := t.openOrders[i]
+		t.openOrders = append(t.openOrders[:i], t.openOrders[i+1:]...)
+
+		order.Status = types.OrderStatusCanceled
+		order.IsWorking = false
+		order.UpdateTime = types.Time(time.Now())
+		canceled = append(canceled, *order)
+	}
+	t.mu.Unlock()
+
+	t.emit(canceled, nil)
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the paper orders %v are not open", missing)
+	}
+	return nil
+}
+
+// OpenOrders returns the open orders of the symbol.
+func (t *PaperTrader) OpenOrders(symbol string) (orders []types.Order) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, order := range t.openOrders {
+		if order.Symbol == symbol {
+			orders = append(orders, *order)
+		}
+	}
+	return orders
+}
+
+func (t *PaperTrader) indexOpenOrder(orderId uint64) int {
+	for i, order := range t.openOrders {
+		if order.OrderID == orderId {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleOrderBook fills the open orders of the symbol crossed by the book at their prices as the maker.
+func (t *PaperTrader) handleOrderBook(book types.SliceOrderBook) {
+	t.mu.Lock()
+
+	t.books[book.Symbol] = book
+
+	now := time.Now()
+	var orders []types.Order
+	var trades []types.Trade
+	openOrders := t.openOrders[:0]
+	for _, order := range t.openOrders {
+		if order.Symbol == book.Symbol {
+			remaining := order.Quantity.Sub(order.ExecutedQuantity)
+			if volume := crossedVolume(book, order.Side, order.Price); volume.Sign() > 0 {
+				trades = append(trades, t.fill(order, order.Price, fixedpoint.Min(volume, remaining), true, now))
+				orders = append(orders, *order)
+			}
+		}
+
+		if order.IsWorking {
+			openOrders = append(openOrders, order)
+		}
+	}
+	t.openOrders = openOrders
+	t.mu.Unlock()
+
+	t.emit(orders, trades)
+}
+
+// fill updates the order with the fill and returns the trade, the caller must hold the lock.
+func (t *PaperTrader) fill(order *types.Order, price, quantity fixedpoint.Value, isMaker bool, now time.Time) types.Trade {
+	order.ExecutedQuantity = order.ExecutedQuantity.Add(quantity)
+	order.Status = types.OrderStatusPartiallyFilled
+	if order.ExecutedQuantity.Compare(order.Quantity) >= 0 {
+		order.Status = types.OrderStatusFilled
+		order.IsWorking = false
+	}
+	order.UpdateTime = types.Time(now)
+
+	feeRate := t.takerFeeRate
+	if isMaker {
+		feeRate = t.makerFeeRate
+	}
+
+	// the fee of the buy fill is charged in the base currency, and the fee of the sell fill is in the quote currency.
+	quoteQuantity := price.Mul(quantity)
+	fee, feeCurrency := quantity.Mul(feeRate), order.Market.BaseCurrency
+	if order.Side == types.SideTypeSell {
+		fee, feeCurrency = quoteQuantity.Mul(feeRate), order.Market.QuoteCurrency
+	}
+
+	t.tradeId++
+	return types.Trade{
+		ID:            t.tradeId,
+		OrderID:       order.OrderID,
+		Exchange:      types.ExchangeBybit,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          types.Time(now),
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+	}
+}
+
+// emit emits the order updates and then the trades to the callbacks of the stream, it must be called without the
+// lock, so the callbacks can submit or cancel the orders.
+func (t *PaperTrader) emit(orders []types.Order, trades []types.Trade) {
+	for _, order := range orders {
+		t.stream.EmitOrderUpdate(order)
+	}
+
+	for _, trade := range trades {
+		t.stream.EmitTradeUpdate(trade)
+	}
+}
+
+// crossedVolume returns the volume of the opposite side of the book at the price or better for the order of the side.
+func crossedVolume(book types.SliceOrderBook, side types.SideType, price fixedpoint.Value) fixedpoint.Value {
+	volume := fixedpoint.Zero
+	for _, pv := range book.SideBook(side.Reverse()) {
+		if !crosses(side, price, pv.Price) {
+			break
+		}
+		volume = volume.Add(pv.Volume)
+	}
+	return volume
+}
+
+// takerFill returns the average price and the filled quantity of the limit order walking the opposite side of the book
+// up to its price.
+func takerFill(book types.SliceOrderBook, side types.SideType, quantity, price fixedpoint.Value) (fixedpoint.Value, fixedpoint.Value) {
+	quote, filled := fixedpoint.Zero, fixedpoint.Zero
+	for _, pv := range book.SideBook(side.Reverse()) {
+		remaining := quantity.Sub(filled)
+		if remaining.Sign() <= 0 || !crosses(side, price, pv.Price) {
+			break
+		}
+
+		volume := fixedpoint.Min(pv.Volume, remaining)
+		quote = quote.Add(pv.Price.Mul(volume))
+		filled = filled.Add(volume)
+	}
+
+	if filled.IsZero() {
+		return fixedpoint.Zero, fixedpoint.Zero
+	}
+	return quote.Div(filled), filled
+}
+
+// crosses returns true if the order of the side at the price matches the level price of the opposite side.
+func crosses(side types.SideType, price, levelPrice fixedpoint.Value) bool {
+	if side == types.SideTypeBuy {
+		return levelPrice.Compare(price) <= 0
+	}
+	return levelPrice.Compare(price) >= 0
+}
@@ -0,0 +1,141 @@
+package bybit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestPaperTrader(t *testing.T) {
+	s := NewStream("", "", nil)
+	trader := NewPaperTrader(s)
+
+	var orders []types.Order
+	var trades []types.Trade
+	s.OnOrderUpdate(func(order types.Order) {
+		orders = append(orders, order)
+	})
+	s.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	newSubmitOrder := func(side types.SideType, orderType types.OrderType, price, quantity float64) types.SubmitOrder {
+		return types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     side,
+			Type:     orderType,
+			Price:    fixedpoint.NewFromFloat(price),
+			Quantity: fixedpoint.NewFromFloat(quantity),
+			Market:   market,
+		}
+	}
+
+	newBookEvent := func(dataType DataType, updateId int64, bids, asks types.PriceVolumeSlice) BookEvent {
+		return BookEvent{
+			Symbol:     "BTCUSDT",
+			Bids:       bids,
+			Asks:       asks,
+			UpdateId:   fixedpoint.NewFromInt(updateId),
+			SequenceId: fixedpoint.NewFromInt(updateId),
+			Type:       dataType,
+		}
+	}
+
+	ctx := context.Background()
+	_, err := trader.SubmitOrder(ctx, newSubmitOrder(types.SideTypeBuy, types.OrderTypeMarket, 0, 1))
+	assert.ErrorContains(t, err, "no order book of BTCUSDT")
+
+	s.EmitBookEvent(newBookEvent(DataTypeSnapshot, 1, types.PriceVolumeSlice{
+		{Price: fixedpoint.NewFromInt(29990), Volume: fixedpoint.One},
+		{Price: fixedpoint.NewFromInt(29980), Volume: fixedpoint.One},
+	}, types.PriceVolumeSlice{
+		{Price: fixedpoint.NewFromInt(30010), Volume: fixedpoint.One},
+		{Price: fixedpoint.NewFromInt(30020), Volume: fixedpoint.One},
+	}))
+
+	t.Run("market order walks the book", func(t *testing.T) {
+		orders, trades = nil, nil
+
+		order, err := trader.SubmitOrder(ctx, newSubmitOrder(types.SideTypeBuy, types.OrderTypeMarket, 0, 1.5))
+		require.NoError(t, err)
+		assert.Equal(t, types.OrderStatusFilled, order.Status)
+		assert.False(t, order.IsWorking)
+
+		if assert.Len(t, trades, 1) {
+			assert.Equal(t, "30013.33333333", trades[0].Price.String())
+			assert.Equal(t, "1.5", trades[0].Quantity.String())
+			assert.False(t, trades[0].IsMaker)
+			assert.True(t, trades[0].IsBuyer)
+			assert.Equal(t, "0.0015", trades[0].Fee.String())
+			assert.Equal(t, "BTC", trades[0].FeeCurrency)
+		}
+
+		// the rest of the market order beyond the book is cancelled
+		order, err = trader.SubmitOrder(ctx, newSubmitOrder(types.SideTypeSell, types.OrderTypeMarket, 0, 3))
+		require.NoError(t, err)
+		assert.Equal(t, types.OrderStatusCanceled, order.Status)
+		assert.Equal(t, "2", order.ExecutedQuantity.String())
+		if assert.Len(t, trades, 2) {
+			assert.Equal(t, "USDT", trades[1].FeeCurrency)
+		}
+	})
+
+	t.Run("limit maker crossing the book is rejected", func(t *testing.T) {
+		order, err := trader.SubmitOrder(ctx, newSubmitOrder(types.SideTypeBuy, types.OrderTypeLimitMaker, 30010, 1))
+		require.NoError(t, err)
+		assert.Equal(t, types.OrderStatusRejected, order.Status)
+		assert.Empty(t, trader.OpenOrders("BTCUSDT"))
+	})
+
+	t.Run("limit order takes the crossed levels and rests", func(t *testing.T) {
+		orders, trades = nil, nil
+
+		order, err := trader.SubmitOrder(ctx, newSubmitOrder(types.SideTypeBuy, types.OrderTypeLimit, 30010, 2))
+		require.NoError(t, err)
+		assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+		assert.Equal(t, "1", order.ExecutedQuantity.String())
+		if assert.Len(t, trades, 1) {
+			assert.Equal(t, "30010", trades[0].Price.String())
+			assert.False(t, trades[0].IsMaker)
+		}
+		assert.Len(t, orders, 2)
+		assert.Len(t, trader.OpenOrders("BTCUSDT"), 1)
+
+		// the ask drops to the order price, the rest is filled as the maker at the order price
+		s.EmitBookEvent(newBookEvent(DataTypeDelta, 2, nil, types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(30000), Volume: fixedpoint.NewFromInt(5)},
+		}))
+		if assert.Len(t, trades, 2) {
+			assert.Equal(t, "30010", trades[1].Price.String())
+			assert.Equal(t, "1", trades[1].Quantity.String())
+			assert.True(t, trades[1].IsMaker)
+			assert.Equal(t, order.OrderID, trades[1].OrderID)
+		}
+		assert.Equal(t, types.OrderStatusFilled, orders[len(orders)-1].Status)
+		assert.Empty(t, trader.OpenOrders("BTCUSDT"))
+	})
+
+	t.Run("cancel the resting order", func(t *testing.T) {
+		orders, trades = nil, nil
+
+		order, err := trader.SubmitOrder(ctx, newSubmitOrder(types.SideTypeSell, types.OrderTypeLimit, 31000, 1))
+		require.NoError(t, err)
+		assert.Equal(t, types.OrderStatusNew, order.Status)
+		assert.Empty(t, trades)
+
+		require.NoError(t, trader.CancelOrders(ctx, *order))
+		assert.Equal(t, types.OrderStatusCanceled, orders[len(orders)-1].Status)
+		assert.Empty(t, trader.OpenOrders("BTCUSDT"))
+
+		assert.ErrorContains(t, trader.CancelOrders(ctx, *order), "are not open")
+	})
+
+	_, err = trader.SubmitOrder(ctx, newSubmitOrder(types.SideTypeBuy, types.OrderTypeStopLimit, 30000, 1))
+	assert.ErrorContains(t, err, "not supported")
+}
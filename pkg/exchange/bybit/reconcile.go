@@ -0,0 +1,106 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// defaultReconcileSettleCoins are the settle coins of the linear positions queried by Reconcile.
+var defaultReconcileSettleCoins = []string{"USDT", "USDC"}
+
+// ReconcileResult is the snapshot of the account state queried by Reconcile.
+type ReconcileResult struct {
+	// Time is the time the queries started, the events of the private stream after it are newer than the snapshot.
+	Time time.Time
+
+	Balances types.BalanceMap
+	// OpenOrders are the spot open orders.
+	OpenOrders []types.Order
+	// Positions are the non-empty linear positions settled in the reconcile settle coins.
+	Positions []bybitapi.Position
+}
+
+// DiffOpenOrders compares the open orders of the snapshot with the local open orders by the order id, it returns the
+// open orders unknown to the local state and the local orders which are no longer open on the exchange.
+func (r ReconcileResult) DiffOpenOrders(local []types.Order) (unknown, closed []types.Order) {
+	exchangeOrders := make(map[uint64]struct{}, len(r.OpenOrders))
+	for _, order := range r.OpenOrders {
+		exchangeOrders[order.OrderID] = struct{}{}
+	}
+
+	localOrders := make(map[uint64]struct{}, len(local))
+	for _, order := range local {
+		localOrders[order.OrderID] = struct{}{}
+		if _, ok := exchangeOrders[order.OrderID]; !ok {
+			closed = append(closed, order)
+		}
+	}
+
+	for _, order := range r.OpenOrders {
+		if _, ok := localOrders[order.OrderID]; !ok {
+			unknown = append(unknown, order)
+		}
+	}
+	return unknown, closed
+}
+
+// SetReconcileSettleCoins sets the settle coins of the linear positions queried by Reconcile, the default ones are USDT
+// and USDC. No position is queried if it's empty, e.g. for the spot only account.
+func (e *Exchange) SetReconcileSettleCoins(coins ...string) {
+	e.reconcileSettleCoins = coins
+}
+
+// Reconcile queries the wallet balances, the spot open orders and the linear positions, so a strategy can load the
+// state before processing the events of the private stream. It only reads the account, so it's safe to call it after
+// a reconnection to detect the drift between the local state and the exchange, see ReconcileResult.DiffOpenOrders.
+func (e *Exchange) Reconcile(ctx context.Context) (ReconcileResult, error) {
+	result := ReconcileResult{Time: time.Now()}
+
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return ReconcileResult{}, fmt.Errorf("failed to query the balances: %w", err)
+	}
+	result.Balances = balances
+
+	if err := queryOrderTradeRateLimiter.Wait(ctx); err != nil {
+		return ReconcileResult{}, fmt.Errorf("query open orders rate limiter wait error: %w", err)
+	}
+
+	// the symbol is omitted to query the open orders of all the symbols
+	openOrders, err := e.client.QueryAllOpenOrders(ctx, bybitapi.CategorySpot, "")
+	if err != nil {
+		return ReconcileResult{}, fmt.Errorf("failed to query the open orders: %w", err)
+	}
+
+	for _, openOrder := range openOrders {
+		order, err := toGlobalOrder(openOrder)
+		if err != nil {
+			return ReconcileResult{}, fmt.Errorf("failed to convert order, err: %w", err)
+		}
+		result.OpenOrders = append(result.OpenOrders, *order)
+	}
+
+	for _, coin := range e.reconcileSettleCoins {
+		if err := sharedRateLimiter.Wait(ctx); err != nil {
+			return ReconcileResult{}, fmt.Errorf("query positions rate limiter wait error: %w", err)
+		}
+
+		positions, err := e.client.QuerySettleCoinPositions(ctx, bybitapi.CategoryLinear, coin)
+		if err != nil {
+			return ReconcileResult{}, fmt.Errorf("failed to query the positions settled in %s: %w", coin, err)
+		}
+
+		for _, position := range positions {
+			if position.Size.IsZero() {
+				continue
+			}
+			result.Positions = append(result.Positions, position)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package bybit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchange_Reconcile(t *testing.T) {
+	ex, err := New("key", "secret")
+	require.NoError(t, err)
+
+	transport := &httptesting.MockTransport{}
+	ex.client.HttpClient.Transport = transport
+
+	transport.GET("/v5/account/wallet-balance", func(req *http.Request) (*http.Response, error) {
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {"list": [{
+			"accountType": "SPOT", "coin": [{"coin": "USDT", "free": "1000", "locked": "28"}]
+		}]}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	transport.GET("/v5/order/realtime", func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "spot", req.URL.Query().Get("category"))
+		assert.False(t, req.URL.Query().Has("symbol"))
+		return httptesting.BuildResponseString(http.StatusOK, safeSubmitOrderList), nil
+	})
+
+	var settleCoins []string
+	transport.GET("/v5/position/list", func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		assert.Equal(t, "linear", query.Get("category"))
+		settleCoins = append(settleCoins, query.Get("settleCoin"))
+		if query.Get("settleCoin") == "USDC" {
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {
+				"category": "linear", "list": [], "nextPageCursor": ""
+			}, "retExtInfo": {}, "time": 1700000000000}`), nil
+		}
+
+		return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 0, "retMsg": "OK", "result": {
+			"category": "linear", "nextPageCursor": "", "list": [
+				{"symbol": "BTCUSDT", "positionIdx": 0, "side": "Buy", "size": "0.01", "avgPrice": "30000"},
+				{"symbol": "ETHUSDT", "positionIdx": 0, "side": "", "size": "0", "avgPrice": "0"}
+			]
+		}, "retExtInfo": {}, "time": 1700000000000}`), nil
+	})
+
+	result, err := ex.Reconcile(context.Background())
+	require.NoError(t, err)
+	assert.False(t, result.Time.IsZero())
+	assert.Equal(t, "1000", result.Balances["USDT"].Available.String())
+	assert.Equal(t, "28", result.Balances["USDT"].Locked.String())
+	assert.Equal(t, []string{"USDT", "USDC"}, settleCoins)
+
+	if assert.Len(t, result.OpenOrders, 1) {
+		assert.Equal(t, uint64(1468264727470772736), result.OpenOrders[0].OrderID)
+		assert.Equal(t, "BTCUSDT", result.OpenOrders[0].Symbol)
+	}
+
+	// the empty positions are dropped
+	if assert.Len(t, result.Positions, 1) {
+		assert.Equal(t, "BTCUSDT", result.Positions[0].Symbol)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.01), result.Positions[0].Size)
+	}
+
+	// no position is queried for the spot only account
+	settleCoins = nil
+	ex.SetReconcileSettleCoins()
+	result, err = ex.Reconcile(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, settleCoins)
+	assert.Empty(t, result.Positions)
+}
+
+func TestReconcileResult_DiffOpenOrders(t *testing.T) {
+	newOrder := func(orderID uint64) types.Order {
+		return types.Order{OrderID: orderID}
+	}
+
+	result := ReconcileResult{OpenOrders: []types.Order{newOrder(1), newOrder(2), newOrder(3)}}
+
+	unknown, closed := result.DiffOpenOrders([]types.Order{newOrder(2), newOrder(3), newOrder(4)})
+	assert.Equal(t, []types.Order{newOrder(1)}, unknown)
+	assert.Equal(t, []types.Order{newOrder(4)}, closed)
+
+	unknown, closed = result.DiffOpenOrders(result.OpenOrders)
+	assert.Empty(t, unknown)
+	assert.Empty(t, closed)
+}
@@ -0,0 +1,153 @@
+package bybit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// submittedOrderTTL is how long the client order id is remembered after it's submitted.
+const submittedOrderTTL = 10 * time.Minute
+
+// SubmitResult is the outcome of SafeSubmitOrder.
+type SubmitResult string
+
+const (
+	// SubmitResultCreated means the order is created by this submission.
+	SubmitResultCreated SubmitResult = "created"
+	// SubmitResultAlreadyExists means the order of the client order id was created by a previous submission.
+	SubmitResultAlreadyExists SubmitResult = "already-exists"
+	// SubmitResultRejected means the order is rejected by the exchange, it's safe to submit it again after fixing
+	// the error.
+	SubmitResultRejected SubmitResult = "rejected"
+	// SubmitResultUnknown means the order can't be confirmed, e.g. the request timed out and the order isn't visible
+	// yet. Submit it again with the same client order id, it will never create a duplicated order.
+	SubmitResultUnknown SubmitResult = "unknown"
+)
+
+// submittedOrders remembers the recently submitted client order ids.
+type submittedOrders struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func newSubmittedOrders() *submittedOrders {
+	return &submittedOrders{
+		entries: make(map[string]time.Time),
+	}
+}
+
+// add remembers the client order id and returns true if it was submitted within the ttl.
+func (s *submittedOrders) add(clientOrderID string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, submittedAt := range s.entries {
+		if now.Sub(submittedAt) >= submittedOrderTTL {
+			delete(s.entries, id)
+		}
+	}
+
+	_, ok := s.entries[clientOrderID]
+	s.entries[clientOrderID] = now
+	return ok
+}
+
+func (s *submittedOrders) remove(clientOrderID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, clientOrderID)
+}
+
+// SafeSubmitOrder submits the order idempotently by the client order id, which is required. The order of the client
+// order id submitted recently is looked up before it's submitted again. If the submission fails without a response
+// from the exchange, e.g. timed out, the order is looked up by the client order id to tell whether it was created.
+// The returned order is nil unless the result is SubmitResultCreated or SubmitResultAlreadyExists.
+func (e *Exchange) SafeSubmitOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, SubmitResult, error) {
+	if len(order.ClientOrderID) == 0 {
+		return nil, SubmitResultRejected, errors.New("client order id is required for the safe submission")
+	}
+
+	if e.submittedOrders.add(order.ClientOrderID, time.Now()) {
+		existing, err := e.queryOrderByClientOrderID(ctx, order.Market.Symbol, order.ClientOrderID)
+		if err != nil {
+			return nil, SubmitResultUnknown, err
+		}
+
+		if existing != nil {
+			return existing, SubmitResultAlreadyExists, nil
+		}
+	}
+
+	created, err := e.SubmitOrder(ctx, order)
+	if err == nil {
+		return created, SubmitResultCreated, nil
+	}
+
+	var apiErr *bybitapi.APIError
+	if errors.As(err, &apiErr) {
+		if !apiErr.IsDuplicateOrderLinkId() {
+			e.submittedOrders.remove(order.ClientOrderID)
+			return nil, SubmitResultRejected, err
+		}
+
+		existing, queryErr := e.queryOrderByClientOrderID(ctx, order.Market.Symbol, order.ClientOrderID)
+		if queryErr != nil || existing == nil {
+			return nil, SubmitResultUnknown, err
+		}
+		return existing, SubmitResultAlreadyExists, nil
+	}
+
+	// the request may reach the exchange even if the response is lost.
+	existing, queryErr := e.queryOrderByClientOrderID(ctx, order.Market.Symbol, order.ClientOrderID)
+	if queryErr != nil || existing == nil {
+		return nil, SubmitResultUnknown, err
+	}
+	return existing, SubmitResultCreated, nil
+}
+
+// queryOrderByClientOrderID looks up the order in the open orders and then the order history, it returns nil if the
+// order isn't found.
+func (e *Exchange) queryOrderByClientOrderID(ctx context.Context, symbol, clientOrderID string) (*types.Order, error) {
+	if err := queryOrderTradeRateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("query order rate limiter wait error: %w", err)
+	}
+
+	req := e.client.NewGetOpenOrderRequest().OrderLinkId(clientOrderID)
+	if len(symbol) > 0 {
+		req.Symbol(toLocalSymbol(symbol, bybitapi.CategorySpot))
+	}
+
+	res, err := req.Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open orders, client order id: %s, err: %w", clientOrderID, err)
+	}
+
+	if len(res.List) == 0 {
+		if err := queryOrderTradeRateLimiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("query order rate limiter wait error: %w", err)
+		}
+
+		historyReq := e.client.NewGetOrderHistoriesRequest().OrderLinkId(clientOrderID)
+		if len(symbol) > 0 {
+			historyReq.Symbol(toLocalSymbol(symbol, bybitapi.CategorySpot))
+		}
+
+		res, err = historyReq.Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query order histories, client order id: %s, err: %w", clientOrderID, err)
+		}
+	}
+
+	if len(res.List) == 0 {
+		return nil, nil
+	}
+
+	return toGlobalOrder(res.List[0])
+}
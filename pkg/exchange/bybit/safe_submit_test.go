@@ -0,0 +1,161 @@
+package bybit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/testing/httptesting"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	safeSubmitOrderResponse = `{"retCode": 0, "retMsg": "OK", "result": {"orderId": "1468264727470772736", "orderLinkId": "my-order"}, "retExtInfo": {}, "time": 1700000000000}`
+	safeSubmitOrderList     = `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "nextPageCursor": "", "list": [{
+		"orderId": "1468264727470772736", "orderLinkId": "my-order", "symbol": "BTCUSDT", "price": "28000", "qty": "0.001",
+		"side": "Buy", "orderType": "Limit", "timeInForce": "GTC", "orderStatus": "New", "cumExecQty": "0",
+		"createdTime": "1700000000000", "updatedTime": "1700000000000"
+	}]}, "retExtInfo": {}, "time": 1700000000000}`
+	safeSubmitEmptyList = `{"retCode": 0, "retMsg": "OK", "result": {"category": "spot", "nextPageCursor": "", "list": []}, "retExtInfo": {}, "time": 1700000000000}`
+)
+
+func TestExchange_SafeSubmitOrder(t *testing.T) {
+	order := types.SubmitOrder{
+		ClientOrderID: "my-order",
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		Type:          types.OrderTypeLimit,
+		Price:         fixedpoint.NewFromInt(28000),
+		Quantity:      fixedpoint.NewFromFloat(0.001),
+		Market: types.Market{
+			Symbol:          "BTCUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 4,
+			TickSize:        fixedpoint.NewFromFloat(0.01),
+			StepSize:        fixedpoint.NewFromFloat(0.0001),
+		},
+	}
+
+	newExchange := func(t *testing.T) (*Exchange, *httptesting.MockTransport) {
+		ex, err := New("key", "secret")
+		assert.NoError(t, err)
+
+		transport := &httptesting.MockTransport{}
+		ex.client.HttpClient.Transport = transport
+		return ex, transport
+	}
+
+	t.Run("created and already exists", func(t *testing.T) {
+		ex, transport := newExchange(t)
+
+		creates := 0
+		transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+			creates++
+			return httptesting.BuildResponseString(http.StatusOK, safeSubmitOrderResponse), nil
+		})
+		transport.GET("/v5/order/realtime", func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "my-order", req.URL.Query().Get("orderLinkId"))
+			return httptesting.BuildResponseString(http.StatusOK, safeSubmitOrderList), nil
+		})
+
+		created, result, err := ex.SafeSubmitOrder(context.Background(), order)
+		assert.NoError(t, err)
+		assert.Equal(t, SubmitResultCreated, result)
+		assert.Equal(t, uint64(1468264727470772736), created.OrderID)
+
+		// the submitted order is looked up instead of being submitted again
+		existing, result, err := ex.SafeSubmitOrder(context.Background(), order)
+		assert.NoError(t, err)
+		assert.Equal(t, SubmitResultAlreadyExists, result)
+		assert.Equal(t, "my-order", existing.ClientOrderID)
+		assert.Equal(t, 1, creates)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ex, transport := newExchange(t)
+
+		transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("i/o timeout")
+		})
+		transport.GET("/v5/order/realtime", func(req *http.Request) (*http.Response, error) {
+			return httptesting.BuildResponseString(http.StatusOK, safeSubmitOrderList), nil
+		})
+
+		created, result, err := ex.SafeSubmitOrder(context.Background(), order)
+		assert.NoError(t, err)
+		assert.Equal(t, SubmitResultCreated, result)
+		assert.Equal(t, "my-order", created.ClientOrderID)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		ex, transport := newExchange(t)
+
+		transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("i/o timeout")
+		})
+		transport.GET("/v5/order/realtime", func(req *http.Request) (*http.Response, error) {
+			return httptesting.BuildResponseString(http.StatusOK, safeSubmitEmptyList), nil
+		})
+		transport.GET("/v5/order/history", func(req *http.Request) (*http.Response, error) {
+			return httptesting.BuildResponseString(http.StatusOK, safeSubmitEmptyList), nil
+		})
+
+		created, result, err := ex.SafeSubmitOrder(context.Background(), order)
+		assert.ErrorContains(t, err, "i/o timeout")
+		assert.Equal(t, SubmitResultUnknown, result)
+		assert.Nil(t, created)
+	})
+
+	t.Run("duplicate order link id", func(t *testing.T) {
+		ex, transport := newExchange(t)
+
+		transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 170141, "retMsg": "Duplicate clientOrderId.", "result": {}, "retExtInfo": {}, "time": 1700000000000}`), nil
+		})
+		transport.GET("/v5/order/realtime", func(req *http.Request) (*http.Response, error) {
+			return httptesting.BuildResponseString(http.StatusOK, safeSubmitEmptyList), nil
+		})
+		transport.GET("/v5/order/history", func(req *http.Request) (*http.Response, error) {
+			return httptesting.BuildResponseString(http.StatusOK, safeSubmitOrderList), nil
+		})
+
+		existing, result, err := ex.SafeSubmitOrder(context.Background(), order)
+		assert.NoError(t, err)
+		assert.Equal(t, SubmitResultAlreadyExists, result)
+		assert.Equal(t, "my-order", existing.ClientOrderID)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		ex, transport := newExchange(t)
+
+		transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+			return httptesting.BuildResponseString(http.StatusOK, `{"retCode": 170131, "retMsg": "Insufficient balance.", "result": {}, "retExtInfo": {}, "time": 1700000000000}`), nil
+		})
+
+		_, result, err := ex.SafeSubmitOrder(context.Background(), order)
+		assert.Error(t, err)
+		assert.Equal(t, SubmitResultRejected, result)
+
+		// the rejected order can be submitted again without the lookup
+		transport.POST("/v5/order/create", func(req *http.Request) (*http.Response, error) {
+			return httptesting.BuildResponseString(http.StatusOK, safeSubmitOrderResponse), nil
+		})
+		_, result, err = ex.SafeSubmitOrder(context.Background(), order)
+		assert.NoError(t, err)
+		assert.Equal(t, SubmitResultCreated, result)
+	})
+
+	t.Run("client order id is required", func(t *testing.T) {
+		ex, _ := newExchange(t)
+
+		o := order
+		o.ClientOrderID = ""
+		_, result, err := ex.SafeSubmitOrder(context.Background(), o)
+		assert.ErrorContains(t, err, "client order id is required")
+		assert.Equal(t, SubmitResultRejected, result)
+	})
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 
 	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
@@ -19,10 +23,27 @@ import (
 const (
 	// spotArgsLimit can input up to 10 args for each subscription request sent to one connection.
 	spotArgsLimit = 10
+	// maxArgsLength is the max total length of the args subscribed on one connection, the server rejects the
+	// subscription beyond it.
+	maxArgsLength = 21000
+
+	// reconnectBackoff is the initial cool down period of the reconnection, it's multiplied by reconnectBackoffFactor
+	// on every failed attempt up to maxReconnectBackoff, and the cool down period is fully jittered.
+	reconnectBackoff       = time.Second
+	maxReconnectBackoff    = time.Minute
+	reconnectBackoffFactor = 2.0
+	// reconnectResetAfter is how long a connection must last to reset the reconnection backoff, so a connection
+	// dropped right after connecting keeps backing off.
+	reconnectResetAfter = time.Minute
+
+	// bookResubscribeCooldown is the min interval of resubscribing the orderbook topic of a symbol on the update id
+	// gap, so a server keeping sending the gaps isn't flooded by the ops.
+	bookResubscribeCooldown = 5 * time.Second
 )
 
 var (
-	// wsAuthRequest specifies the duration for which a websocket request's authentication is valid.
+	// wsAuthRequest specifies the default duration for which a websocket request's authentication is valid, see
+	// SetAuthExpiry.
 	wsAuthRequest = 10 * time.Second
 	// The default taker/maker fees can help us in estimating trading fees in the SPOT market, because trade fees are not
 	// provided for traditional accounts on Bybit.
@@ -34,6 +55,9 @@ var (
 	tradeLogLimiter       = rate.NewLimiter(rate.Every(time.Minute), 1)
 	orderLogLimiter       = rate.NewLimiter(rate.Every(time.Minute), 1)
 	kLineLogLimiter       = rate.NewLimiter(rate.Every(time.Minute), 1)
+	decodeErrorLogLimiter = rate.NewLimiter(rate.Every(time.Minute), 1)
+	bookLogLimiter        = rate.NewLimiter(rate.Every(time.Minute), 1)
+	liquidationLogLimiter = rate.NewLimiter(rate.Every(time.Minute), 1)
 )
 
 // MarketInfoProvider calculates trade fees since trading fees are not supported by streaming.
@@ -62,12 +86,114 @@ type Stream struct {
 	feeRateProvider    *feeRatePoller
 	marketsInfo        types.MarketMap
 
-	bookEventCallbacks        []func(e BookEvent)
-	marketTradeEventCallbacks []func(e []MarketTradeEvent)
-	walletEventCallbacks      []func(e []bybitapi.WalletBalances)
-	kLineEventCallbacks       []func(e KLineEvent)
-	orderEventCallbacks       []func(e []OrderEvent)
-	tradeEventCallbacks       []func(e []TradeEvent)
+	// dropZeroVolumeTrades drops the public trades with zero quantity (e.g. index prints) before emitting them.
+	dropZeroVolumeTrades bool
+
+	// bookBucketSize aggregates the emitted books into the price buckets of the size, see SetBookBucketSize.
+	bookBucketSize fixedpoint.Value
+	// books are the full depth books maintained for the bucketing, they're never modified by the bucketing.
+	books      map[string]*types.SliceOrderBook
+	booksMutex sync.Mutex
+	// importedBooks are the symbols of the books imported by ImportBooks and not replaced by a snapshot yet.
+	importedBooks map[string]struct{}
+	// bookResubscribedAt is the last time the orderbook topic of the symbol was resubscribed on the update id gap.
+	bookResubscribedAt map[string]time.Time
+
+	// bookChecksumDepth enables the book checksum over the top N levels of the emitted book, see SetBookChecksumDepth.
+	bookChecksumDepth int
+
+	// validateBook validates the local book after every merge, see SetBookValidation.
+	validateBook bool
+
+	// fillTracker selects the authoritative fill source per symbol, see SetFillSource.
+	fillTracker *fillTracker
+
+	// deduper drops the order, execution and book events re-delivered after the reconnection.
+	deduper *eventDeduper
+
+	// connected is true after the first connection, it distinguishes the reconnection from the first connection.
+	connected bool
+
+	// decodeErrorC receives the decode errors if it's enabled, see EnableDecodeErrors.
+	decodeErrorC chan DecodeError
+
+	// strictDecode rejects the unknown fields of the topic data, see SetStrictDecode.
+	strictDecode bool
+
+	// jsonDecoder decodes the websocket messages, see SetJSONDecoder.
+	jsonDecoder JSONDecoder
+
+	// greeksEnabled subscribes the greeks topic of the private stream, see EnableGreeks.
+	greeksEnabled bool
+
+	// demoTrading connects the private stream to the demo trading host, see EnableDemoTrading.
+	demoTrading bool
+
+	// testnet connects the streams to the testnet, see EnableTestnet.
+	testnet bool
+
+	// category selects the public url and the symbols of the public topics, see SetCategory.
+	category bybitapi.Category
+
+	// stats counts the received messages and the reconnections, see Stats.
+	stats streamStats
+
+	// topicQueues are the bounded queues of the topic types, see SetTopicQueue.
+	topicQueues map[TopicType]*topicQueue
+
+	// authExpiry is how long the auth request is valid, see SetAuthExpiry.
+	authExpiry time.Duration
+	// clockOffset is the offset of the server clock to the local clock, see SetClockOffset.
+	clockOffset time.Duration
+
+	// pendingOps are the ops waiting for the responses by the req id, see UnsubscribeChannel.
+	pendingOpsMu sync.Mutex
+	pendingOps   map[string]*pendingOp
+	reqIdSeq     uint64
+
+	// subscribeTimeout fails the subscribe op which isn't acknowledged in time after the retries, see
+	// SetSubscribeTimeout.
+	subscribeTimeout time.Duration
+	subscribeRetries int
+
+	// writeMu serializes the writes of the connection, since the subscribe timeout retries from the timer goroutine.
+	writeMu sync.Mutex
+
+	// kLineBackfill emits the last closed k lines before the k line subscriptions, see SetKLineBackfill.
+	kLineBackfill *kLineBackfill
+
+	// topicWatchdog alerts the silent topics, see SetTopicWatchdog.
+	topicWatchdog *topicWatchdog
+
+	// orderPoller polls the orders while the private stream is degraded, see SetOrderPoller.
+	orderPoller *orderPoller
+
+	// tickers are the latest tickers by the symbol, the deltas of the tickers topic are merged into them.
+	tickers *tickerState
+
+	// logger logs with the exchange field and the fields given by SetLogger.
+	logger *logrus.Entry
+
+	bookEventCallbacks            []func(e BookEvent)
+	marketTradeEventCallbacks     []func(e []MarketTradeEvent)
+	walletEventCallbacks          []func(e WalletEvent)
+	kLineEventCallbacks           []func(e KLineEvent)
+	orderEventCallbacks           []func(e []OrderEvent)
+	tradeEventCallbacks           []func(e []TradeEvent)
+	connectionStateEventCallbacks []func(e ConnectionStateEvent)
+	bookChecksumEventCallbacks    []func(e BookChecksumEvent)
+	liquidationEventCallbacks     []func(e LiquidationEvent)
+	rawTopicMessageCallbacks      []func(topic string, data json.RawMessage)
+	greeksEventCallbacks          []func(e GreeksEvent)
+	tickerEventCallbacks          []func(e TickerEvent)
+	subscriptionErrorCallbacks    []func(e SubscriptionErrorEvent)
+	// orderBookCallbacks receive the full depth book merged from the snapshot and the deltas after every book event,
+	// so the consumer doesn't deal with the data types of the book events, see OnOrderBook.
+	orderBookCallbacks []func(book types.SliceOrderBook)
+	// conditionalOrderUpdateCallbacks receive the conditional orders with the trigger state after every order update,
+	// along with the global orders of the OnOrderUpdate callbacks. The stop orders of the derivatives are received
+	// once the stream is in their category, see SetCategory.
+	conditionalOrderUpdateCallbacks []func(order ConditionalOrder)
 }
 
 func NewStream(key, secret string, userDataProvider StreamDataProvider) *Stream {
@@ -78,29 +204,49 @@ func NewStream(key, secret string, userDataProvider StreamDataProvider) *Stream
 		secret:             secret,
 		streamDataProvider: userDataProvider,
 		feeRateProvider:    newFeeRatePoller(userDataProvider),
+		books:              make(map[string]*types.SliceOrderBook),
+		importedBooks:      make(map[string]struct{}),
+		bookResubscribedAt: make(map[string]time.Time),
+		fillTracker:        newFillTracker(),
+		deduper:            newEventDeduper(),
+		tickers:            newTickerState(),
+		authExpiry:         wsAuthRequest,
+		category:           bybitapi.CategorySpot,
+		logger:             log,
 	}
 
 	stream.SetEndpointCreator(stream.createEndpoint)
-	stream.SetParser(stream.parseWebSocketEvent)
+	stream.SetParser(stream.parse)
 	stream.SetDispatcher(stream.dispatchEvent)
 	stream.SetHeartBeat(stream.ping)
+	// the subscriptions are re-sent by handlerConnect after every reconnection.
+	stream.SetReconnectBackoff(reconnectBackoff, maxReconnectBackoff, reconnectBackoffFactor)
+	stream.SetReconnectJitter(true)
+	stream.SetReconnectResetAfter(reconnectResetAfter)
+	// no event is dispatched after Close returns, so Close must not be called by the event handlers.
+	stream.SetWaitOnClose(true)
 	stream.SetBeforeConnect(func(ctx context.Context) (err error) {
 		if stream.PublicOnly {
-			// we don't need the fee rate in the public stream.
-			return
+			// reject the subscriptions beyond the limit before connecting, we don't need the fee rate in the public
+			// stream.
+			_, err = stream.buildSubscriptionOps(WsOpTypeSubscribe)
+			return err
 		}
 
 		// get account fee rate
 		go stream.feeRateProvider.Start(ctx)
+		stream.startOrderPoller(ctx)
 
 		stream.marketsInfo, err = stream.streamDataProvider.QueryMarkets(ctx)
 		if err != nil {
-			log.WithError(err).Error("failed to query market info before to connect stream")
+			stream.logger.WithError(err).Error("failed to query market info before to connect stream")
 			return err
 		}
 		return nil
 	})
 	stream.OnConnect(stream.handlerConnect)
+	stream.OnConnect(stream.handleConnected)
+	stream.OnDisconnect(stream.handleDisconnected)
 	stream.OnAuth(stream.handleAuthEvent)
 
 	stream.OnBookEvent(stream.handleBookEvent)
@@ -109,38 +255,176 @@ func NewStream(key, secret string, userDataProvider StreamDataProvider) *Stream
 	stream.OnWalletEvent(stream.handleWalletEvent)
 	stream.OnOrderEvent(stream.handleOrderEvent)
 	stream.OnTradeEvent(stream.handleTradeEvent)
+	stream.OnLiquidationEvent(stream.handleLiquidationEvent)
 	return stream
 }
 
-func (s *Stream) syncSubscriptions(opType WsOpType) error {
+// SetDropZeroVolumeTrades drops the zero-size public trades before they reach the market trade handlers.
+// It's disabled by default.
+func (s *Stream) SetDropZeroVolumeTrades(enabled bool) {
+	s.dropZeroVolumeTrades = enabled
+}
+
+// SetBookBucketSize aggregates the price levels of the emitted books into the price buckets of the given size, the
+// volumes in the same bucket are summed up. Since the bucketed delta can't be applied to a book, the stream keeps the
+// full depth book and emits the bucketed book as a snapshot on every book event. Zero disables the bucketing.
+func (s *Stream) SetBookBucketSize(size fixedpoint.Value) {
+	s.bookBucketSize = size
+}
+
+// SetBookChecksumDepth emits the BookChecksumEvent with the checksum of the top depth levels after every book emit, so
+// the consumer can verify the book it reconstructed, see bookChecksum for the checksum input format. The stream keeps
+// the full depth book to calculate the checksum. Zero disables the checksum.
+func (s *Stream) SetBookChecksumDepth(depth int) {
+	s.bookChecksumDepth = depth
+}
+
+// SetBookValidation validates the full depth book maintained by the stream after every book event and logs the
+// inconsistency, it's for debugging the delta merge. It's disabled by default.
+func (s *Stream) SetBookValidation(enabled bool) {
+	s.validateBook = enabled
+}
+
+// SetFillSource selects the topic which drives the trade updates of the symbol, the other one is only used for
+// cross-checking and logging the discrepancies. The execution topic is used by default.
+func (s *Stream) SetFillSource(symbol string, source FillSource) {
+	s.fillTracker.SetSource(symbol, source)
+}
+
+// EnableDecodeErrors makes the stream send the decode errors to the returned channel with the given buffer size, so
+// the caller can react to them. The errors are dropped if the channel is full. It must be called before Connect.
+func (s *Stream) EnableDecodeErrors(bufferSize int) <-chan DecodeError {
+	s.decodeErrorC = make(chan DecodeError, bufferSize)
+	return s.decodeErrorC
+}
+
+// SetStrictDecode rejects the topic messages of which the data has the fields unknown to the event structs, so the
+// changes of the wire format are noticed instead of dropping the new fields silently. The rejected message is
+// reported as the DecodeError, see EnableDecodeErrors, and it's not emitted. The envelope of the message, e.g. the
+// topic and the ts, is not checked. It's meant for the testing, so it's disabled by default.
+func (s *Stream) SetStrictDecode(enabled bool) {
+	s.strictDecode = enabled
+}
+
+// SetJSONDecoder decodes the websocket messages and the data of the topics by the decoder instead of encoding/json,
+// e.g. JSONIterDecoder, which saves the CPU of decoding the book deltas of many symbols, see BenchmarkParseBookEvent.
+// The strict decode set by SetStrictDecode always uses encoding/json. It must be called before Connect.
+func (s *Stream) SetJSONDecoder(decoder JSONDecoder) {
+	s.jsonDecoder = decoder
+}
+
+// EnableGreeks subscribes the greeks topic of the options positions on the private stream, the greeks are emitted to
+// the OnGreeksEvent callbacks. It's disabled by default since only the options accounts have the greeks. It must be
+// called before Connect.
+func (s *Stream) EnableGreeks() {
+	s.greeksEnabled = true
+}
+
+// SetLogger logs with the given entry, so the logs can be filtered by its fields, e.g. the strategy. The exchange field
+// is added to it. It must be called before Connect.
+func (s *Stream) SetLogger(logger *logrus.Entry) {
+	s.logger = logger.WithField("exchange", "bybit")
+}
+
+// SetAuthExpiry sets how long the auth request of the private stream is valid after it's sent, bybit rejects the auth
+// request which arrives after the expiry. The default is 10 seconds. It must be called before Connect.
+func (s *Stream) SetAuthExpiry(window time.Duration) {
+	s.authExpiry = window
+}
+
+// SetClockOffset sets the offset of the server clock to the local clock, i.e. the server time minus the local time.
+// The expiry of the auth request is based on the server time, so the auth request isn't rejected if the local clock
+// drifts. It must be called before Connect.
+func (s *Stream) SetClockOffset(offset time.Duration) {
+	s.clockOffset = offset
+}
+
+// Stats returns the counters of the received messages by the topic type and the reconnections, which tell the health
+// of the connection, e.g. a stalled topic. The counters are accumulated across the reconnections. The latency of the
+// book updates is measured against the clock offset set by SetClockOffset.
+func (s *Stream) Stats() StreamStats {
+	return s.stats.snapshot()
+}
+
+// EnableDemoTrading connects the private stream to the demo trading host, the public stream still connects to the
+// mainnet. It must be called before Connect.
+func (s *Stream) EnableDemoTrading() {
+	s.demoTrading = true
+}
+
+// EnableTestnet connects both the public and the private streams to the testnet. It must be called before Connect.
+func (s *Stream) EnableTestnet() {
+	s.testnet = true
+}
+
+// SetCategory connects the public stream to the public url of the category, the default is spot. The derivatives only
+// topics, e.g. the liquidation, are only available in the linear and the inverse categories. The private stream emits
+// the order updates of the category, since the order topic pushes the orders of all the categories. It must be called
+// before Connect.
+func (s *Stream) SetCategory(category bybitapi.Category) error {
+	switch category {
+	case bybitapi.CategorySpot, bybitapi.CategoryLinear, bybitapi.CategoryInverse:
+		s.category = category
+		return nil
+	}
+
+	return fmt.Errorf("the %s category is not supported by the public stream", category)
+}
+
+// buildSubscriptionOps converts the subscriptions to the topics and chunks them into the ops of at most spotArgsLimit
+// args. It returns an error if the topics exceed the limit of one connection, so nothing is sent instead of a partial
+// subscription.
+func (s *Stream) buildSubscriptionOps(opType WsOpType) ([]WebsocketOp, error) {
 	if opType != WsOpTypeUnsubscribe && opType != WsOpTypeSubscribe {
-		return fmt.Errorf("unexpected subscription type: %v", opType)
+		return nil, fmt.Errorf("unexpected subscription type: %v", opType)
 	}
 
-	logger := log.WithField("opType", opType)
-	lens := len(s.Subscriptions)
-	for begin := 0; begin < lens; begin += spotArgsLimit {
-		end := begin + spotArgsLimit
-		if end > lens {
-			end = lens
+	var topics []string
+	argsLength := 0
+	for _, subscription := range s.Subscriptions {
+		topic, err := s.convertSubscription(subscription)
+		if err != nil {
+			return nil, fmt.Errorf("convert error, subscription: %+v, err: %w", subscription, err)
 		}
 
-		topics := []string{}
-		for _, subscription := range s.Subscriptions[begin:end] {
-			topic, err := s.convertSubscription(subscription)
-			if err != nil {
-				logger.WithError(err).Errorf("convert error, subscription: %+v", subscription)
-				return err
-			}
+		topics = append(topics, topic)
+		argsLength += len(topic)
+	}
+
+	if argsLength > maxArgsLength {
+		return nil, fmt.Errorf("%d topics of %d characters exceed the limit of %d characters per connection, "+
+			"please split the subscriptions into multiple streams", len(topics), argsLength, maxArgsLength)
+	}
 
-			topics = append(topics, topic)
+	var ops []WebsocketOp
+	for begin := 0; begin < len(topics); begin += spotArgsLimit {
+		end := begin + spotArgsLimit
+		if end > len(topics) {
+			end = len(topics)
 		}
 
-		logger.Infof("%s channels: %+v", opType, topics)
-		if err := s.Conn.WriteJSON(WebsocketOp{
+		ops = append(ops, WebsocketOp{
 			Op:   opType,
-			Args: topics,
-		}); err != nil {
+			Args: topics[begin:end],
+		})
+	}
+
+	return ops, nil
+}
+
+func (s *Stream) syncSubscriptions(opType WsOpType) error {
+	logger := s.logger.WithField("opType", opType)
+
+	ops, err := s.buildSubscriptionOps(opType)
+	if err != nil {
+		logger.WithError(err).Error("failed to build the subscription requests")
+		return err
+	}
+
+	for _, op := range ops {
+		op.ReqId = s.newReqId(opType)
+		logger.Infof("%s channels: %+v, req id: %s", opType, op.Args, op.ReqId)
+		if err := s.writeOp(s.Conn, op, 0); err != nil {
 			logger.WithError(err).Error("failed to send request")
 			return err
 		}
@@ -158,10 +442,310 @@ func (s *Stream) Unsubscribe() {
 	})
 }
 
+// UnsubscribeChannel unsubscribes the topic of the subscription without dropping the connection, e.g. to rotate the
+// watched symbols, and removes the matched subscriptions so the topic isn't subscribed again after the reconnection.
+// The subscription is matched by its topic, so the options must be the same as the subscribed ones. The op is sent if
+// the stream is connected, the response is correlated by the req id and the failure is logged.
+func (s *Stream) UnsubscribeChannel(channel types.Channel, symbol string, options types.SubscribeOptions) error {
+	topic, err := s.convertSubscription(types.Subscription{Channel: channel, Symbol: symbol, Options: options})
+	if err != nil {
+		return fmt.Errorf("convert error, channel: %s, symbol: %s, err: %w", channel, symbol, err)
+	}
+
+	found := false
+	if err := s.UpdateSubscriptions(func(old []types.Subscription) (subs []types.Subscription, err error) {
+		for _, sub := range old {
+			if t, err := s.convertSubscription(sub); err == nil && t == topic {
+				found = true
+				continue
+			}
+			subs = append(subs, sub)
+		}
+		return subs, nil
+	}); err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("topic %s is not subscribed", topic)
+	}
+
+	return s.sendTopicOps(WsOpTypeUnsubscribe, []string{topic})
+}
+
+// SetSubscriptions replaces the subscriptions with the given ones without dropping the connection, e.g. when the
+// universe of the strategy changes from {A, B, C} to {B, C, D}. The subscriptions are compared by their topics, which
+// cover the channel, the symbol, the interval and the depth, and only the removed topics are unsubscribed and only the
+// added topics are subscribed, so the remaining topics have no gap. The ops are sent if the stream is connected, the
+// responses are correlated by the req ids and the failures are logged. Nothing is changed if a subscription can't be
+// converted or the topics exceed the limit of one connection.
+func (s *Stream) SetSubscriptions(subs []types.Subscription) error {
+	var newSubs []types.Subscription
+	var topics []string
+	newTopics := make(map[string]struct{}, len(subs))
+	argsLength := 0
+	for _, sub := range subs {
+		topic, err := s.convertSubscription(sub)
+		if err != nil {
+			return fmt.Errorf("convert error, subscription: %+v, err: %w", sub, err)
+		}
+
+		if _, ok := newTopics[topic]; ok {
+			continue
+		}
+
+		newTopics[topic] = struct{}{}
+		newSubs = append(newSubs, sub)
+		topics = append(topics, topic)
+		argsLength += len(topic)
+	}
+
+	if argsLength > maxArgsLength {
+		return fmt.Errorf("%d topics of %d characters exceed the limit of %d characters per connection, "+
+			"please split the subscriptions into multiple streams", len(newTopics), argsLength, maxArgsLength)
+	}
+
+	var subscribed, unsubscribed []string
+	if err := s.UpdateSubscriptions(func(old []types.Subscription) ([]types.Subscription, error) {
+		subscribed, unsubscribed = diffTopics(s.subscriptionTopics(old), topics)
+		return newSubs, nil
+	}); err != nil {
+		return err
+	}
+
+	// unsubscribe first, so the removed topics don't count against the limit of the connection
+	if err := s.sendTopicOps(WsOpTypeUnsubscribe, unsubscribed); err != nil {
+		return err
+	}
+	return s.sendTopicOps(WsOpTypeSubscribe, subscribed)
+}
+
+// subscriptionTopics converts the subscriptions to the topics, the subscription failed to convert is skipped since it
+// was never subscribed.
+func (s *Stream) subscriptionTopics(subs []types.Subscription) map[string]struct{} {
+	topics := make(map[string]struct{}, len(subs))
+	for _, sub := range subs {
+		if topic, err := s.convertSubscription(sub); err == nil {
+			topics[topic] = struct{}{}
+		}
+	}
+	return topics
+}
+
+// diffTopics returns the new topics which are not in the old topics in the given order, and the old topics which are
+// not in the new topics in the sorted order.
+func diffTopics(oldTopics map[string]struct{}, newTopics []string) (added, removed []string) {
+	newTopicSet := make(map[string]struct{}, len(newTopics))
+	for _, topic := range newTopics {
+		newTopicSet[topic] = struct{}{}
+		if _, ok := oldTopics[topic]; !ok {
+			added = append(added, topic)
+		}
+	}
+
+	for topic := range oldTopics {
+		if _, ok := newTopicSet[topic]; !ok {
+			removed = append(removed, topic)
+		}
+	}
+	sort.Strings(removed)
+
+	return added, removed
+}
+
+// sendTopicOps sends the op of the topics through the current connection in the chunks of at most spotArgsLimit args,
+// nothing is sent if the stream is not connected since the subscriptions are sent after connecting. The responses are
+// correlated by the req ids, see handleOpResponse.
+func (s *Stream) sendTopicOps(opType WsOpType, topics []string) error {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	s.ConnLock.Lock()
+	conn := s.Conn
+	s.ConnLock.Unlock()
+	if conn == nil {
+		return nil
+	}
+
+	for begin := 0; begin < len(topics); begin += spotArgsLimit {
+		end := begin + spotArgsLimit
+		if end > len(topics) {
+			end = len(topics)
+		}
+
+		op := WebsocketOp{
+			ReqId: s.newReqId(opType),
+			Op:    opType,
+			Args:  topics[begin:end],
+		}
+		if err := s.writeOp(conn, op, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Stream) newReqId(opType WsOpType) string {
+	return fmt.Sprintf("%s-%d", opType, atomic.AddUint64(&s.reqIdSeq, 1))
+}
+
+// writeOp sends the op with the req id and waits for the response as a pending op, retries is the number of the
+// retries already done for the subscribe timeout.
+func (s *Stream) writeOp(conn *websocket.Conn, op WebsocketOp, retries int) error {
+	s.addPendingOp(op, retries)
+	if err := s.writeJSON(conn, op); err != nil {
+		s.removePendingOp(op.ReqId)
+		return fmt.Errorf("failed to send the %s request, topics: %v, err: %w", op.Op, op.Args, err)
+	}
+	return nil
+}
+
+func (s *Stream) writeJSON(conn *websocket.Conn, v interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return conn.WriteJSON(v)
+}
+
+func (s *Stream) addPendingOp(op WebsocketOp, retries int) {
+	s.pendingOpsMu.Lock()
+	defer s.pendingOpsMu.Unlock()
+
+	if s.pendingOps == nil {
+		s.pendingOps = make(map[string]*pendingOp)
+	}
+
+	p := &pendingOp{op: op, retries: retries}
+	if op.Op == WsOpTypeSubscribe && s.subscribeTimeout > 0 {
+		p.timer = time.AfterFunc(s.subscribeTimeout, func() {
+			s.handleSubscribeTimeout(op.ReqId)
+		})
+	}
+	s.pendingOps[op.ReqId] = p
+}
+
+func (s *Stream) removePendingOp(reqId string) (*pendingOp, bool) {
+	s.pendingOpsMu.Lock()
+	defer s.pendingOpsMu.Unlock()
+
+	p, ok := s.pendingOps[reqId]
+	if ok {
+		p.stop()
+		delete(s.pendingOps, reqId)
+	}
+	return p, ok
+}
+
+// clearPendingOps drops the pending ops of the closed connection, the subscriptions are sent again after the
+// reconnection.
+func (s *Stream) clearPendingOps() {
+	s.pendingOpsMu.Lock()
+	defer s.pendingOpsMu.Unlock()
+
+	for reqId, p := range s.pendingOps {
+		p.stop()
+		delete(s.pendingOps, reqId)
+	}
+}
+
+// handleOpResponse correlates the response to the pending op by the req id, and logs the result. The failed subscribe
+// op is emitted to the subscription error callbacks.
+func (s *Stream) handleOpResponse(e *WebSocketOpEvent) {
+	if len(e.ReqId) == 0 {
+		return
+	}
+
+	p, ok := s.removePendingOp(e.ReqId)
+	if !ok {
+		return
+	}
+
+	op := p.op
+	if !e.Success {
+		s.logger.Errorf("failed to %s topics: %v, req id: %s, ret msg: %s", op.Op, op.Args, e.ReqId, e.RetMsg)
+		if op.Op == WsOpTypeSubscribe {
+			s.EmitSubscriptionError(SubscriptionErrorEvent{
+				ReqId:  e.ReqId,
+				Topics: op.Args,
+				Err:    fmt.Errorf("subscription rejected: %s", e.RetMsg),
+			})
+		}
+		return
+	}
+
+	s.logger.Infof("%s topics: %v, req id: %s", op.Op, op.Args, e.ReqId)
+}
+
+func (s *Stream) handleConnected() {
+	state := ConnectionStateConnected
+	if s.connected {
+		state = ConnectionStateReconnected
+		s.stats.recordReconnect()
+	}
+	s.connected = true
+	s.startTopicWatchdog()
+
+	s.emitConnectionState(state)
+}
+
+func (s *Stream) handleDisconnected() {
+	s.clearPendingOps()
+	if s.topicWatchdog != nil {
+		s.topicWatchdog.disconnect()
+	}
+	if s.orderPoller != nil {
+		s.orderPoller.setAuthenticated(false)
+	}
+	s.emitConnectionState(ConnectionStateDisconnected)
+}
+
+func (s *Stream) emitConnectionState(state ConnectionState) {
+	s.EmitConnectionStateEvent(ConnectionStateEvent{
+		State:   state,
+		Symbols: s.subscribedSymbols(),
+		Time:    time.Now(),
+	})
+}
+
+// subscribedSymbols returns the unique symbols of the subscriptions.
+func (s *Stream) subscribedSymbols() (symbols []string) {
+	seen := map[string]struct{}{}
+	for _, sub := range s.Subscriptions {
+		if _, ok := seen[sub.Symbol]; ok || len(sub.Symbol) == 0 {
+			continue
+		}
+
+		seen[sub.Symbol] = struct{}{}
+		symbols = append(symbols, sub.Symbol)
+	}
+	return symbols
+}
+
 func (s *Stream) createEndpoint(_ context.Context) (string, error) {
 	var url string
 	if s.PublicOnly {
-		url = bybitapi.WsSpotPublicSpotUrl
+		switch s.category {
+		case bybitapi.CategoryLinear:
+			url = bybitapi.WsPublicLinearUrl
+			if s.testnet {
+				url = bybitapi.WsTestnetPublicLinearUrl
+			}
+		case bybitapi.CategoryInverse:
+			url = bybitapi.WsPublicInverseUrl
+			if s.testnet {
+				url = bybitapi.WsTestnetPublicInverseUrl
+			}
+		default:
+			url = bybitapi.WsSpotPublicSpotUrl
+			if s.testnet {
+				url = bybitapi.WsTestnetPublicSpotUrl
+			}
+		}
+	} else if s.testnet {
+		url = bybitapi.WsTestnetPrivateUrl
+	} else if s.demoTrading {
+		url = bybitapi.WsDemoTradingPrivateUrl
 	} else {
 		url = bybitapi.WsSpotPrivateUrl
 	}
@@ -169,6 +753,14 @@ func (s *Stream) createEndpoint(_ context.Context) (string, error) {
 }
 
 func (s *Stream) dispatchEvent(event interface{}) {
+	if s.enqueueEvent(event) {
+		return
+	}
+
+	s.emitEvent(event)
+}
+
+func (s *Stream) emitEvent(event interface{}) {
 	switch e := event.(type) {
 	case *WebSocketOpEvent:
 		if e.IsAuthenticated() {
@@ -181,8 +773,8 @@ func (s *Stream) dispatchEvent(event interface{}) {
 	case []MarketTradeEvent:
 		s.EmitMarketTradeEvent(e)
 
-	case []bybitapi.WalletBalances:
-		s.EmitWalletEvent(e)
+	case *WalletEvent:
+		s.EmitWalletEvent(*e)
 
 	case *KLineEvent:
 		s.EmitKLineEvent(*e)
@@ -193,21 +785,62 @@ func (s *Stream) dispatchEvent(event interface{}) {
 	case []TradeEvent:
 		s.EmitTradeEvent(e)
 
+	case *LiquidationEvent:
+		s.EmitLiquidationEvent(*e)
+
+	case *GreeksEvent:
+		s.EmitGreeksEvent(*e)
+
+	case *TickerEvent:
+		if ticker, ok := s.tickers.merge(*e); ok {
+			s.EmitTickerEvent(ticker)
+		}
+
+	case *rawTopicMessage:
+		s.EmitRawTopicMessage(e.Topic, e.Data)
+
+	}
+}
+
+func (s *Stream) parse(in []byte) (interface{}, error) {
+	s.stats.recordMessage(len(in), time.Now())
+
+	e, err := s.parseWebSocketEvent(in)
+	if err != nil {
+		s.sendDecodeError(newDecodeError(in, err))
+	}
+	return e, err
+}
+
+func (s *Stream) sendDecodeError(err DecodeError) {
+	if s.decodeErrorC == nil {
+		return
+	}
+
+	select {
+	case s.decodeErrorC <- err:
+	default:
+		metricsDroppedDecodeErrors.Inc()
+		if decodeErrorLogLimiter.Allow() {
+			s.logger.WithError(err).Warn("the decode error channel is full, drop the error")
+		}
 	}
 }
 
 func (s *Stream) parseWebSocketEvent(in []byte) (interface{}, error) {
 	var e WsEvent
 
-	err := json.Unmarshal(in, &e)
+	err := orStdJSONDecoder(s.jsonDecoder).Unmarshal(in, &e)
 	if err != nil {
 		return nil, err
 	}
 
 	switch {
 	case e.IsOp():
+		s.handleOpResponse(e.WebSocketOpEvent)
+
 		if err = e.IsValid(); err != nil {
-			log.Errorf("invalid event: %+v, err: %s", e, err)
+			s.logger.Errorf("invalid event: %+v, err: %s", e, err)
 			return nil, err
 		}
 
@@ -218,56 +851,16 @@ func (s *Stream) parseWebSocketEvent(in []byte) (interface{}, error) {
 		return e.WebSocketOpEvent, nil
 
 	case e.IsTopic():
-		switch getTopicType(e.Topic) {
-
-		case TopicTypeOrderBook:
-			var book BookEvent
-			err = json.Unmarshal(e.WebSocketTopicEvent.Data, &book)
-			if err != nil {
-				return nil, fmt.Errorf("failed to unmarshal data into BookEvent: %+v, err: %w", string(e.WebSocketTopicEvent.Data), err)
-			}
-
-			book.Type = e.WebSocketTopicEvent.Type
-			book.ServerTime = e.WebSocketTopicEvent.Ts.Time()
-			return &book, nil
-
-		case TopicTypeMarketTrade:
-			// snapshot only
-			var trade []MarketTradeEvent
-			err = json.Unmarshal(e.WebSocketTopicEvent.Data, &trade)
-			if err != nil {
-				return nil, fmt.Errorf("failed to unmarshal data into MarketTradeEvent: %+v, err: %w", string(e.WebSocketTopicEvent.Data), err)
-			}
-
-			return trade, nil
-
-		case TopicTypeKLine:
-			var kLines []KLine
-			err = json.Unmarshal(e.WebSocketTopicEvent.Data, &kLines)
-			if err != nil {
-				return nil, fmt.Errorf("failed to unmarshal data into KLine: %+v, err: %w", string(e.WebSocketTopicEvent.Data), err)
-			}
-
-			symbol, err := getSymbolFromTopic(e.Topic)
-			if err != nil {
-				return nil, err
-			}
-
-			return &KLineEvent{KLines: kLines, Symbol: symbol, Type: e.WebSocketTopicEvent.Type}, nil
-
-		case TopicTypeWallet:
-			var wallets []bybitapi.WalletBalances
-			return wallets, json.Unmarshal(e.WebSocketTopicEvent.Data, &wallets)
-
-		case TopicTypeOrder:
-			var orders []OrderEvent
-			return orders, json.Unmarshal(e.WebSocketTopicEvent.Data, &orders)
-
-		case TopicTypeTrade:
-			var trades []TradeEvent
-			return trades, json.Unmarshal(e.WebSocketTopicEvent.Data, &trades)
-
+		now := time.Now()
+		s.stats.recordTopicMessage(getTopicType(e.Topic), len(in), now)
+
+		e.WebSocketTopicEvent.strict = s.strictDecode
+		e.WebSocketTopicEvent.decoder = s.jsonDecoder
+		event, err := parseTopicEvent(e.WebSocketTopicEvent)
+		if book, ok := event.(*BookEvent); ok {
+			book.ReceivedTime = now
 		}
+		return event, err
 	}
 
 	return nil, fmt.Errorf("unhandled websocket event: %+v", string(in))
@@ -275,13 +868,13 @@ func (s *Stream) parseWebSocketEvent(in []byte) (interface{}, error) {
 
 // ping implements the Bybit text message of WebSocket PingPong.
 func (s *Stream) ping(conn *websocket.Conn) error {
-	err := conn.WriteJSON(struct {
+	err := s.writeJSON(conn, struct {
 		Op WsOpType `json:"op"`
 	}{
 		Op: WsOpTypePing,
 	})
 	if err != nil {
-		log.WithError(err).Error("ping error")
+		s.logger.WithError(err).Error("ping error")
 		return err
 	}
 
@@ -290,38 +883,62 @@ func (s *Stream) ping(conn *websocket.Conn) error {
 
 func (s *Stream) handlerConnect() {
 	if s.PublicOnly {
+		// the backfilled k lines are emitted before the live ones since the subscriptions are sent after it.
+		s.backfillKLines()
+
 		// errors are handled in the syncSubscriptions, so they are skipped here.
 		_ = s.syncSubscriptions(WsOpTypeSubscribe)
 	} else {
-		expires := strconv.FormatInt(time.Now().Add(wsAuthRequest).In(time.UTC).UnixMilli(), 10)
-
-		if err := s.Conn.WriteJSON(WebsocketOp{
-			Op: WsOpTypeAuth,
-			Args: []string{
-				s.key,
-				expires,
-				bybitapi.Sign(fmt.Sprintf("GET/realtime%s", expires), s.secret),
-			},
-		}); err != nil {
-			log.WithError(err).Error("failed to auth request")
+		if err := s.writeJSON(s.Conn, s.buildAuthOp(time.Now())); err != nil {
+			s.logger.WithError(err).Error("failed to auth request")
 			return
 		}
 
-		if err := s.Conn.WriteJSON(WebsocketOp{
-			Op: WsOpTypeSubscribe,
-			Args: []string{
-				string(TopicTypeWallet),
-				string(TopicTypeOrder),
-				string(TopicTypeTrade),
-			},
-		}); err != nil {
-			log.WithError(err).Error("failed to send subscription request")
+		topics := []string{
+			string(TopicTypeWallet),
+			string(TopicTypeOrder),
+			string(TopicTypeTrade),
+		}
+		if s.greeksEnabled {
+			topics = append(topics, string(TopicTypeGreeks))
+		}
+
+		if err := s.writeOp(s.Conn, WebsocketOp{
+			ReqId: s.newReqId(WsOpTypeSubscribe),
+			Op:    WsOpTypeSubscribe,
+			Args:  topics,
+		}, 0); err != nil {
+			s.logger.WithError(err).Error("failed to send subscription request")
 			return
 		}
 	}
 }
 
+// buildAuthOp builds the auth op of the private stream, the signature is the HMAC of "GET/realtime" and the expiry
+// timestamp in milliseconds.
+func (s *Stream) buildAuthOp(now time.Time) WebsocketOp {
+	window := s.authExpiry
+	if window <= 0 {
+		window = wsAuthRequest
+	}
+
+	expires := strconv.FormatInt(now.Add(s.clockOffset).Add(window).UnixMilli(), 10)
+	return WebsocketOp{
+		Op: WsOpTypeAuth,
+		Args: []string{
+			s.key,
+			expires,
+			bybitapi.Sign(fmt.Sprintf("GET/realtime%s", expires), s.secret),
+		},
+	}
+}
+
 func (s *Stream) convertSubscription(sub types.Subscription) (string, error) {
+	category := s.category
+	if len(category) == 0 {
+		category = bybitapi.CategorySpot
+	}
+
 	switch sub.Channel {
 
 	case types.BookChannel:
@@ -333,10 +950,16 @@ func (s *Stream) convertSubscription(sub types.Subscription) (string, error) {
 		case types.DepthLevel200:
 			depth = sub.Options.Depth
 		}
-		return genTopic(TopicTypeOrderBook, depth, sub.Symbol), nil
+		return genTopic(TopicTypeOrderBook, depth, toLocalSymbol(sub.Symbol, category)), nil
 
 	case types.MarketTradeChannel:
-		return genTopic(TopicTypeMarketTrade, sub.Symbol), nil
+		return genTopic(TopicTypeMarketTrade, toLocalSymbol(sub.Symbol, category)), nil
+
+	case types.ForceOrderChannel:
+		if category == bybitapi.CategorySpot {
+			return "", fmt.Errorf("the %s channel is not available in the spot category, please set the linear or the inverse category", sub.Channel)
+		}
+		return genTopic(TopicTypeLiquidation, toLocalSymbol(sub.Symbol, category)), nil
 
 	case types.KLineChannel:
 		interval, err := toLocalInterval(sub.Options.Interval)
@@ -344,7 +967,26 @@ func (s *Stream) convertSubscription(sub types.Subscription) (string, error) {
 			return "", err
 		}
 
-		return genTopic(TopicTypeKLine, interval, sub.Symbol), nil
+		return genTopic(TopicTypeKLine, interval, toLocalSymbol(sub.Symbol, category)), nil
+
+	case MarkPriceKLineChannel, IndexPriceKLineChannel:
+		if category == bybitapi.CategorySpot {
+			return "", fmt.Errorf("the %s channel is not available in the spot category, please set the linear or the inverse category", sub.Channel)
+		}
+
+		interval, err := toLocalInterval(sub.Options.Interval)
+		if err != nil {
+			return "", err
+		}
+
+		topicType := TopicTypeMarkPriceKLine
+		if sub.Channel == IndexPriceKLineChannel {
+			topicType = TopicTypeIndexPriceKLine
+		}
+		return genTopic(topicType, interval, toLocalSymbol(sub.Symbol, category)), nil
+
+	case TickerChannel:
+		return genTopic(TopicTypeTicker, toLocalSymbol(sub.Symbol, category)), nil
 
 	}
 
@@ -352,6 +994,10 @@ func (s *Stream) convertSubscription(sub types.Subscription) (string, error) {
 }
 
 func (s *Stream) handleAuthEvent() {
+	if s.orderPoller != nil {
+		s.orderPoller.setAuthenticated(true)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
@@ -362,7 +1008,7 @@ func (s *Stream) handleAuthEvent() {
 		return err
 	})
 	if err != nil {
-		log.WithError(err).Error("no more attempts to retrieve balances")
+		s.logger.WithError(err).Error("no more attempts to retrieve balances")
 		return
 	}
 
@@ -370,6 +1016,52 @@ func (s *Stream) handleAuthEvent() {
 }
 
 func (s *Stream) handleBookEvent(e BookEvent) {
+	if s.deduper.IsDuplicateBook(e) {
+		return
+	}
+
+	if !e.ReceivedTime.IsZero() && !e.ServerTime.IsZero() {
+		// the server time is in the server clock, so the offset of the clock is corrected
+		s.stats.recordBookLatency(e.ReceivedTime.Add(s.clockOffset).Sub(e.ServerTime))
+	}
+
+	if s.bookBucketSize.Sign() <= 0 && s.bookChecksumDepth <= 0 && !s.validateBook && len(s.orderBookCallbacks) == 0 {
+		s.emitBook(e)
+		return
+	}
+
+	book, ok := s.updateLocalBook(e)
+	if !ok {
+		return
+	}
+
+	if s.validateBook {
+		if err := book.Validate(); err != nil && bookLogLimiter.Allow() {
+			s.logger.WithError(err).WithField("symbol", e.Symbol).Errorf("the local book is inconsistent, symbol: %s, update id: %s", e.Symbol, e.UpdateId.String())
+		}
+	}
+
+	s.EmitOrderBook(book)
+
+	if s.bookBucketSize.Sign() > 0 {
+		book.Bids = book.Bids.Bucket(s.bookBucketSize, true)
+		book.Asks = book.Asks.Bucket(s.bookBucketSize, false)
+		s.EmitBookSnapshot(book)
+	} else {
+		s.emitBook(e)
+	}
+
+	if s.bookChecksumDepth > 0 {
+		s.EmitBookChecksumEvent(BookChecksumEvent{
+			Symbol:   book.Symbol,
+			Checksum: bookChecksum(book, s.bookChecksumDepth),
+			UpdateId: e.UpdateId,
+			Time:     e.ServerTime,
+		})
+	}
+}
+
+func (s *Stream) emitBook(e BookEvent) {
 	orderBook := e.OrderBook()
 	switch {
 	// Occasionally, you'll receive "UpdateId"=1, which is a snapshot data due to the restart of
@@ -382,12 +1074,103 @@ func (s *Stream) handleBookEvent(e BookEvent) {
 	}
 }
 
+// updateLocalBook applies the book event to the full depth book of its symbol and returns a copy of it. It returns
+// false if the delta is received before the snapshot, or if the update id of the delta isn't consecutive. The book is
+// dropped on the gap since it's no longer consistent, and the orderbook topic of the symbol is resubscribed since bybit
+// only sends the snapshot on the subscription. The books, the update ids and the gaps are kept by the symbol, so the
+// interleaved events of the symbols don't affect each other.
+func (s *Stream) updateLocalBook(e BookEvent) (types.SliceOrderBook, bool) {
+	s.booksMutex.Lock()
+	defer s.booksMutex.Unlock()
+
+	book, ok := s.books[e.Symbol]
+	switch {
+	case e.Type == DataTypeSnapshot || e.UpdateId.Int() == 1:
+		if !ok {
+			book = types.NewSliceOrderBook(e.Symbol)
+			s.books[e.Symbol] = book
+		}
+		s.reconcileImportedBook(book, e)
+		book.Load(e.OrderBook())
+		book.LastUpdateId = e.UpdateId.Int64()
+		book.SequenceId = e.SequenceId.Int64()
+
+	case e.Type == DataTypeDelta:
+		if !ok {
+			s.logger.WithField("symbol", e.Symbol).Warnf("received the book delta before the snapshot, symbol: %s", e.Symbol)
+			return types.SliceOrderBook{}, false
+		}
+
+		if updateId := e.UpdateId.Int64(); updateId != book.LastUpdateId+1 {
+			s.logger.WithField("symbol", e.Symbol).Warnf("detected the book update id gap, symbol: %s, last update id: %d, update id: %d, resubscribing the orderbook topic",
+				e.Symbol, book.LastUpdateId, updateId)
+			delete(s.books, e.Symbol)
+			delete(s.importedBooks, e.Symbol)
+
+			now := time.Now()
+			if now.Sub(s.bookResubscribedAt[e.Symbol]) >= bookResubscribeCooldown {
+				s.bookResubscribedAt[e.Symbol] = now
+				// the ops are sent by another goroutine, so the reader isn't blocked by the writes
+				go s.resubscribeBook(e.Symbol)
+			}
+			return types.SliceOrderBook{}, false
+		}
+
+		book.Update(e.OrderBook())
+		book.LastUpdateId = e.UpdateId.Int64()
+		book.SequenceId = e.SequenceId.Int64()
+
+	default:
+		return types.SliceOrderBook{}, false
+	}
+
+	return types.SliceOrderBook{
+		Symbol:       book.Symbol,
+		Bids:         book.Bids.Copy(),
+		Asks:         book.Asks.Copy(),
+		Time:         e.ServerTime,
+		LastUpdateId: book.LastUpdateId,
+		SequenceId:   book.SequenceId,
+	}, true
+}
+
+// resubscribeBook unsubscribes and subscribes the orderbook topics of the symbol again, so bybit sends a new snapshot
+// to rebuild the book dropped on the update id gap.
+func (s *Stream) resubscribeBook(symbol string) {
+	var topics []string
+	for _, sub := range s.GetSubscriptions() {
+		if sub.Channel != types.BookChannel || sub.Symbol != symbol {
+			continue
+		}
+
+		topic, err := s.convertSubscription(sub)
+		if err != nil {
+			s.logger.WithError(err).Errorf("failed to convert the book subscription of %s", symbol)
+			return
+		}
+		topics = append(topics, topic)
+	}
+
+	if err := s.sendTopicOps(WsOpTypeUnsubscribe, topics); err != nil {
+		s.logger.WithError(err).Errorf("failed to unsubscribe the orderbook topics of %s", symbol)
+		return
+	}
+
+	if err := s.sendTopicOps(WsOpTypeSubscribe, topics); err != nil {
+		s.logger.WithError(err).Errorf("failed to resubscribe the orderbook topics of %s", symbol)
+	}
+}
+
 func (s *Stream) handleMarketTradeEvent(events []MarketTradeEvent) {
 	for _, event := range events {
+		if s.dropZeroVolumeTrades && event.Quantity.IsZero() {
+			continue
+		}
+
 		trade, err := event.toGlobalTrade()
 		if err != nil {
 			if marketTradeLogLimiter.Allow() {
-				log.WithError(err).Error("failed to convert to market trade")
+				s.logger.WithError(err).Error("failed to convert to market trade")
 			}
 			continue
 		}
@@ -396,87 +1179,134 @@ func (s *Stream) handleMarketTradeEvent(events []MarketTradeEvent) {
 	}
 }
 
-func (s *Stream) handleWalletEvent(events []bybitapi.WalletBalances) {
-	s.StandardStream.EmitBalanceUpdate(toGlobalBalanceMap(events))
+func (s *Stream) handleLiquidationEvent(event LiquidationEvent) {
+	info, err := event.toGlobalLiquidationInfo()
+	if err != nil {
+		if liquidationLogLimiter.Allow() {
+			s.logger.WithError(err).Error("failed to convert to liquidation info")
+		}
+		return
+	}
+
+	s.StandardStream.EmitForceOrder(info)
+}
+
+// handleWalletEvent emits the changed balances as the balance update, which is merged into the balances.
+func (s *Stream) handleWalletEvent(e WalletEvent) {
+	s.StandardStream.EmitBalanceUpdate(e.toGlobalBalanceMap())
 }
 
 func (s *Stream) handleOrderEvent(events []OrderEvent) {
 	for _, event := range events {
-		if event.Category != bybitapi.CategorySpot {
-			return
+		if event.Category != s.category {
+			continue
+		}
+
+		if s.deduper.IsDuplicateOrder(event.Order) {
+			continue
 		}
 
 		gOrder, err := toGlobalOrder(event.Order)
 		if err != nil {
 			if orderLogLimiter.Allow() {
-				log.WithError(err).Error("failed to convert to global order")
+				s.logger.WithError(err).Error("failed to convert to global order")
 			}
 			continue
 		}
 		s.StandardStream.EmitOrderUpdate(*gOrder)
+
+		if event.Order.IsConditional() && len(s.conditionalOrderUpdateCallbacks) > 0 {
+			if order, err := toConditionalOrder(event.Order); err == nil {
+				s.EmitConditionalOrderUpdate(*order)
+			}
+		}
+
+		fill, ok := s.fillTracker.UpdateOrder(event.Order, event.Category)
+		if ok && s.fillTracker.Source(event.Symbol) == FillSourceOrder {
+			s.emitTradeEvent(fill)
+		}
 	}
 }
 
+// handleKLineEvent emits every update of the k line to the OnKLine callbacks, including the closing one, and emits the
+// closed k line to the OnKLineClosed callbacks once, so the consumer of the closed k lines doesn't check the Closed.
 func (s *Stream) handleKLineEvent(klineEvent KLineEvent) {
-	if klineEvent.Type != DataTypeSnapshot {
+	// the mark price and the index price k lines are only emitted by the KLineEvent, so they won't be mixed up with
+	// the trade price k lines.
+	if klineEvent.Type != DataTypeSnapshot || klineEvent.PriceSource != KLinePriceSourceTrade {
 		return
 	}
 
-	for _, event := range klineEvent.KLines {
-		kline, err := event.toGlobalKLine(klineEvent.Symbol)
-		if err != nil {
-			if kLineLogLimiter.Allow() {
-				log.WithError(err).Error("failed to convert to global k line")
-			}
+	klines, err := klineEvent.toGlobalKLines()
+	if err != nil && kLineLogLimiter.Allow() {
+		s.logger.WithError(err).Error("failed to convert to global k line")
+	}
+
+	for _, kline := range klines {
+		s.EmitKLine(kline)
+		if !kline.Closed {
 			continue
 		}
 
-		if kline.Closed {
-			s.EmitKLineClosed(kline)
-		} else {
-			s.EmitKLine(kline)
+		// the closed k line may be emitted by the backfill already
+		if s.kLineBackfill != nil && !s.kLineBackfill.advance(kline) {
+			continue
 		}
+		s.EmitKLineClosed(kline)
 	}
 }
 
 func (s *Stream) handleTradeEvent(events []TradeEvent) {
 	for _, event := range events {
-		feeRate, found := s.feeRateProvider.Get(event.Symbol)
-		if !found {
-			feeRate = symbolFeeDetail{
-				FeeRate: bybitapi.FeeRate{
-					Symbol:       event.Symbol,
-					TakerFeeRate: defaultTakerFee,
-					MakerFeeRate: defaultMakerFee,
-				},
-				BaseCoin:  "",
-				QuoteCoin: "",
-			}
+		if s.deduper.IsDuplicateExecution(event.ExecId) {
+			continue
+		}
 
-			if market, ok := s.marketsInfo[event.Symbol]; ok {
-				feeRate.BaseCoin = market.BaseCurrency
-				feeRate.QuoteCoin = market.QuoteCurrency
-			}
+		s.fillTracker.AddExecution(event)
+		if s.fillTracker.Source(event.Symbol) != FillSourceExecution {
+			continue
+		}
 
-			if tradeLogLimiter.Allow() {
-				// The error log level was utilized due to a detected discrepancy in the fee calculations.
-				log.Errorf("failed to get %s fee rate, use default taker fee %f, maker fee %f, base coin: %s, quote coin: %s",
-					event.Symbol,
-					feeRate.TakerFeeRate.Float64(),
-					feeRate.MakerFeeRate.Float64(),
-					feeRate.BaseCoin,
-					feeRate.QuoteCoin,
-				)
-			}
+		s.emitTradeEvent(event)
+	}
+}
+
+func (s *Stream) emitTradeEvent(event TradeEvent) {
+	feeRate, found := s.feeRateProvider.Get(event.Symbol)
+	if !found {
+		feeRate = symbolFeeDetail{
+			FeeRate: bybitapi.FeeRate{
+				Symbol:       event.Symbol,
+				TakerFeeRate: defaultTakerFee,
+				MakerFeeRate: defaultMakerFee,
+			},
+			BaseCoin:  "",
+			QuoteCoin: "",
 		}
 
-		gTrade, err := event.toGlobalTrade(feeRate)
-		if err != nil {
-			if tradeLogLimiter.Allow() {
-				log.WithError(err).Errorf("unable to convert: %+v", event)
-			}
-			continue
+		if market, ok := s.marketsInfo[event.Symbol]; ok {
+			feeRate.BaseCoin = market.BaseCurrency
+			feeRate.QuoteCoin = market.QuoteCurrency
+		}
+
+		if tradeLogLimiter.Allow() {
+			// The error log level was utilized due to a detected discrepancy in the fee calculations.
+			s.logger.Errorf("failed to get %s fee rate, use default taker fee %f, maker fee %f, base coin: %s, quote coin: %s",
+				event.Symbol,
+				feeRate.TakerFeeRate.Float64(),
+				feeRate.MakerFeeRate.Float64(),
+				feeRate.BaseCoin,
+				feeRate.QuoteCoin,
+			)
 		}
-		s.StandardStream.EmitTradeUpdate(*gTrade)
 	}
+
+	gTrade, err := event.toGlobalTrade(feeRate)
+	if err != nil {
+		if tradeLogLimiter.Allow() {
+			s.logger.WithError(err).Errorf("unable to convert: %+v", event)
+		}
+		return
+	}
+	s.StandardStream.EmitTradeUpdate(*gTrade)
 }
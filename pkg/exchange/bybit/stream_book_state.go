@@ -0,0 +1,115 @@
+package bybit
+
+import (
+	"fmt"
+
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ExportBooks returns the copies of the full depth books maintained by the stream by the symbol, with the update id and
+// the sequence id of the last applied book event, so they can be persisted and imported by ImportBooks after the
+// restart. The books are maintained only if the book bucketing, the book checksum, the book validation or the
+// OnOrderBook callbacks are enabled.
+func (s *Stream) ExportBooks() map[string]types.SliceOrderBook {
+	s.booksMutex.Lock()
+	defer s.booksMutex.Unlock()
+
+	books := make(map[string]types.SliceOrderBook, len(s.books))
+	for symbol, book := range s.books {
+		books[symbol] = types.SliceOrderBook{
+			Symbol:       book.Symbol,
+			Bids:         book.Bids.Copy(),
+			Asks:         book.Asks.Copy(),
+			Time:         book.LastUpdateTime(),
+			LastUpdateId: book.LastUpdateId,
+			SequenceId:   book.SequenceId,
+		}
+	}
+
+	return books
+}
+
+// ImportBooks restores the books exported by ExportBooks, so the book deltas can be applied and the OnOrderBook
+// callbacks receive the deep book before the first snapshot. The imported book is replaced by the first snapshot of
+// its symbol, and it's dropped if the update id of the delta isn't consecutive, like the book from the snapshot. Like
+// ExportBooks, the imported books are used only if the books are maintained by the stream.
+//
+// Every book is validated before it's imported: the symbol must match the key, the book must not be empty, crossed
+// or out of order, and the update id must be set. The invalid books are skipped and returned in the error, the valid
+// ones are imported anyway.
+func (s *Stream) ImportBooks(books map[string]types.SliceOrderBook) error {
+	var err error
+	var imported []types.SliceOrderBook
+
+	s.booksMutex.Lock()
+	for symbol, book := range books {
+		if validateErr := validateImportedBook(symbol, book); validateErr != nil {
+			err = multierr.Append(err, validateErr)
+			continue
+		}
+
+		local := types.NewSliceOrderBook(symbol)
+		local.Load(book)
+		local.LastUpdateId = book.LastUpdateId
+		local.SequenceId = book.SequenceId
+		s.books[symbol] = local
+		s.importedBooks[symbol] = struct{}{}
+
+		imported = append(imported, types.SliceOrderBook{
+			Symbol:       symbol,
+			Bids:         local.Bids.Copy(),
+			Asks:         local.Asks.Copy(),
+			Time:         book.Time,
+			LastUpdateId: local.LastUpdateId,
+			SequenceId:   local.SequenceId,
+		})
+	}
+	s.booksMutex.Unlock()
+
+	for _, book := range imported {
+		s.EmitOrderBook(book)
+	}
+
+	return err
+}
+
+func validateImportedBook(symbol string, book types.SliceOrderBook) error {
+	if book.Symbol != symbol {
+		return fmt.Errorf("invalid imported book of %s: unexpected symbol %q", symbol, book.Symbol)
+	}
+
+	if len(book.Bids) == 0 && len(book.Asks) == 0 {
+		return fmt.Errorf("invalid imported book of %s: empty book", symbol)
+	}
+
+	if book.LastUpdateId <= 0 {
+		return fmt.Errorf("invalid imported book of %s: missing update id", symbol)
+	}
+
+	if err := book.Validate(); err != nil {
+		return fmt.Errorf("invalid imported book of %s: %w", symbol, err)
+	}
+
+	return nil
+}
+
+// reconcileImportedBook logs the difference between the imported book and the first snapshot replacing it, the caller
+// must hold the booksMutex.
+func (s *Stream) reconcileImportedBook(book *types.SliceOrderBook, e BookEvent) {
+	if _, ok := s.importedBooks[e.Symbol]; !ok {
+		return
+	}
+	delete(s.importedBooks, e.Symbol)
+
+	logger := s.logger.WithField("symbol", e.Symbol)
+	if sequenceId := e.SequenceId.Int64(); sequenceId < book.SequenceId {
+		logger.Warnf("the imported book of %s is newer than the snapshot, imported sequence id: %d, snapshot sequence id: %d",
+			e.Symbol, book.SequenceId, sequenceId)
+		return
+	}
+
+	logger.Infof("the imported book of %s is replaced by the snapshot, imported sequence id: %d, snapshot sequence id: %d",
+		e.Symbol, book.SequenceId, e.SequenceId.Int64())
+}
@@ -3,7 +3,9 @@
 package bybit
 
 import (
-	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"encoding/json"
+
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func (s *Stream) OnBookEvent(cb func(e BookEvent)) {
@@ -26,11 +28,11 @@ func (s *Stream) EmitMarketTradeEvent(e []MarketTradeEvent) {
 	}
 }
 
-func (s *Stream) OnWalletEvent(cb func(e []bybitapi.WalletBalances)) {
+func (s *Stream) OnWalletEvent(cb func(e WalletEvent)) {
 	s.walletEventCallbacks = append(s.walletEventCallbacks, cb)
 }
 
-func (s *Stream) EmitWalletEvent(e []bybitapi.WalletBalances) {
+func (s *Stream) EmitWalletEvent(e WalletEvent) {
 	for _, cb := range s.walletEventCallbacks {
 		cb(e)
 	}
@@ -65,3 +67,93 @@ func (s *Stream) EmitTradeEvent(e []TradeEvent) {
 		cb(e)
 	}
 }
+
+func (s *Stream) OnConnectionStateEvent(cb func(e ConnectionStateEvent)) {
+	s.connectionStateEventCallbacks = append(s.connectionStateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitConnectionStateEvent(e ConnectionStateEvent) {
+	for _, cb := range s.connectionStateEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnBookChecksumEvent(cb func(e BookChecksumEvent)) {
+	s.bookChecksumEventCallbacks = append(s.bookChecksumEventCallbacks, cb)
+}
+
+func (s *Stream) EmitBookChecksumEvent(e BookChecksumEvent) {
+	for _, cb := range s.bookChecksumEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnLiquidationEvent(cb func(e LiquidationEvent)) {
+	s.liquidationEventCallbacks = append(s.liquidationEventCallbacks, cb)
+}
+
+func (s *Stream) EmitLiquidationEvent(e LiquidationEvent) {
+	for _, cb := range s.liquidationEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnTickerEvent(cb func(e TickerEvent)) {
+	s.tickerEventCallbacks = append(s.tickerEventCallbacks, cb)
+}
+
+func (s *Stream) EmitTickerEvent(e TickerEvent) {
+	for _, cb := range s.tickerEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnRawTopicMessage(cb func(topic string, data json.RawMessage)) {
+	s.rawTopicMessageCallbacks = append(s.rawTopicMessageCallbacks, cb)
+}
+
+func (s *Stream) EmitRawTopicMessage(topic string, data json.RawMessage) {
+	for _, cb := range s.rawTopicMessageCallbacks {
+		cb(topic, data)
+	}
+}
+
+func (s *Stream) OnOrderBook(cb func(book types.SliceOrderBook)) {
+	s.orderBookCallbacks = append(s.orderBookCallbacks, cb)
+}
+
+func (s *Stream) EmitOrderBook(book types.SliceOrderBook) {
+	for _, cb := range s.orderBookCallbacks {
+		cb(book)
+	}
+}
+
+func (s *Stream) OnGreeksEvent(cb func(e GreeksEvent)) {
+	s.greeksEventCallbacks = append(s.greeksEventCallbacks, cb)
+}
+
+func (s *Stream) EmitGreeksEvent(e GreeksEvent) {
+	for _, cb := range s.greeksEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnConditionalOrderUpdate(cb func(order ConditionalOrder)) {
+	s.conditionalOrderUpdateCallbacks = append(s.conditionalOrderUpdateCallbacks, cb)
+}
+
+func (s *Stream) EmitConditionalOrderUpdate(order ConditionalOrder) {
+	for _, cb := range s.conditionalOrderUpdateCallbacks {
+		cb(order)
+	}
+}
+
+func (s *Stream) OnSubscriptionError(cb func(e SubscriptionErrorEvent)) {
+	s.subscriptionErrorCallbacks = append(s.subscriptionErrorCallbacks, cb)
+}
+
+func (s *Stream) EmitSubscriptionError(e SubscriptionErrorEvent) {
+	for _, cb := range s.subscriptionErrorCallbacks {
+		cb(e)
+	}
+}
@@ -0,0 +1,131 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// kLineBackfillTimeout bounds the backfill of all the k line subscriptions, the subscriptions are sent after it.
+const kLineBackfillTimeout = 30 * time.Second
+
+// KLineProvider queries the k lines in ascending order, it's implemented by the Exchange.
+type KLineProvider interface {
+	QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error)
+}
+
+// kLineBackfill emits the last closed k lines before the k line subscriptions are sent, and remembers the start time
+// of the last closed k line of every symbol and interval, so the closed k line is emitted once whether it comes from
+// the backfill or the stream.
+type kLineBackfill struct {
+	provider KLineProvider
+	limit    int
+
+	mu sync.Mutex
+	// lastClosed is the start time of the last emitted closed k line by the symbol and the interval.
+	lastClosed map[string]time.Time
+}
+
+func kLineBackfillKey(symbol string, interval types.Interval) string {
+	return symbol + "." + string(interval)
+}
+
+// advance returns true if the closed k line is newer than the last emitted one, and records it as the last one.
+func (b *kLineBackfill) advance(kline types.KLine) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := kLineBackfillKey(kline.Symbol, kline.Interval)
+	startTime := kline.StartTime.Time()
+	if last, ok := b.lastClosed[key]; ok && !startTime.After(last) {
+		return false
+	}
+
+	b.lastClosed[key] = startTime
+	return true
+}
+
+// query returns the last closed k lines of the symbol, the open k line at the given time is excluded.
+func (b *kLineBackfill) query(ctx context.Context, symbol string, interval types.Interval, now time.Time) ([]types.KLine, error) {
+	// one more k line is queried since the last one is usually still open
+	limit := b.limit + 1
+	if limit > defaultKLineLimit {
+		limit = defaultKLineLimit
+	}
+
+	kLines, err := b.provider.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
+		Limit:   limit,
+		EndTime: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var closed []types.KLine
+	for _, kline := range kLines {
+		if !kline.EndTime.Time().Before(now) {
+			continue
+		}
+
+		kline.Closed = true
+		closed = append(closed, kline)
+	}
+
+	if len(closed) > b.limit {
+		closed = closed[len(closed)-b.limit:]
+	}
+	return closed, nil
+}
+
+// SetKLineBackfill emits the last closed k lines of the k line subscriptions by the OnKLineClosed callbacks before the
+// subscriptions are sent, so the indicators are warmed up before the live k lines. It's also done after every
+// reconnection to fill the gap. The closed k lines are emitted in ascending order and once, the live closed k line
+// which is backfilled already is not emitted again. The limit is at most 1000. It must be called before Connect.
+func (s *Stream) SetKLineBackfill(provider KLineProvider, limit int) error {
+	if provider == nil {
+		return fmt.Errorf("the k line provider of the backfill is nil")
+	}
+
+	if limit <= 0 || limit > defaultKLineLimit {
+		return fmt.Errorf("invalid k line backfill limit: %d, it must be between 1 and %d", limit, defaultKLineLimit)
+	}
+
+	s.kLineBackfill = &kLineBackfill{
+		provider:   provider,
+		limit:      limit,
+		lastClosed: make(map[string]time.Time),
+	}
+	return nil
+}
+
+// backfillKLines emits the last closed k lines of the trade price k line subscriptions, the failure is logged and the
+// subscription goes on.
+func (s *Stream) backfillKLines() {
+	if s.kLineBackfill == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kLineBackfillTimeout)
+	defer cancel()
+
+	for _, sub := range s.Subscriptions {
+		if sub.Channel != types.KLineChannel {
+			continue
+		}
+
+		kLines, err := s.kLineBackfill.query(ctx, sub.Symbol, sub.Options.Interval, time.Now())
+		if err != nil {
+			s.logger.WithError(err).WithField("symbol", sub.Symbol).Errorf("failed to backfill the %s k lines of %s", sub.Options.Interval, sub.Symbol)
+			continue
+		}
+
+		for _, kline := range kLines {
+			if s.kLineBackfill.advance(kline) {
+				s.EmitKLineClosed(kline)
+			}
+		}
+	}
+}
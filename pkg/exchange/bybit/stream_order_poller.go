@@ -0,0 +1,214 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+)
+
+const (
+	// orderPollStaleAfter is how long the private stream may receive nothing, not even the pong, before it's treated
+	// as degraded. It's twice the ping interval.
+	orderPollStaleAfter = time.Minute
+	// orderPollOverlap is queried again before the last poll, so the executions delayed by the server aren't missed,
+	// the duplicate ones are dropped by the deduper.
+	orderPollOverlap = time.Minute
+	// orderPollHistoryLimit is the number of the latest orders queried, it covers the orders closed since the last
+	// poll.
+	orderPollHistoryLimit = 50
+	// maxExecutionQueryRange is the max time range of the execution list request.
+	maxExecutionQueryRange = 7 * 24 * time.Hour
+)
+
+// OrderStatusProvider queries the orders and the executions by the REST api, it's implemented by the
+// bybitapi.RestClient.
+type OrderStatusProvider interface {
+	QueryAllOpenOrders(ctx context.Context, category bybitapi.Category, symbol string) ([]bybitapi.Order, error)
+	QueryOrderHistories(ctx context.Context, category bybitapi.Category, symbol string, limit int) ([]bybitapi.Order, error)
+	QueryExecutions(ctx context.Context, category bybitapi.Category, symbol string, startTime, endTime time.Time) ([]bybitapi.Execution, error)
+}
+
+var _ OrderStatusProvider = &bybitapi.RestClient{}
+
+// orderPoller polls the orders and the executions while the private stream is degraded.
+type orderPoller struct {
+	provider OrderStatusProvider
+	interval time.Duration
+
+	once sync.Once
+
+	mu sync.Mutex
+	// authenticated is true after the private stream is authenticated, and false after it's disconnected.
+	authenticated bool
+	// since is the start time of the next execution query, it's the time the stream was healthy or the last poll.
+	since time.Time
+}
+
+func (p *orderPoller) setAuthenticated(authenticated bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.authenticated = authenticated
+}
+
+// healthy returns true if the stream is authenticated and the last message is fresh, the execution query starts from
+// the last healthy time.
+func (p *orderPoller) healthy(stats StreamStats, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.authenticated || stats.LastMessageTime.IsZero() || now.Sub(stats.LastMessageTime) > orderPollStaleAfter {
+		return false
+	}
+
+	p.since = now
+	return true
+}
+
+// executionRange returns the time range of the execution query, which is within maxExecutionQueryRange.
+func (p *orderPoller) executionRange(now time.Time) (time.Time, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	startTime := p.since.Add(-orderPollOverlap)
+	if now.Sub(startTime) > maxExecutionQueryRange {
+		startTime = now.Add(-maxExecutionQueryRange)
+	}
+	return startTime, now
+}
+
+func (p *orderPoller) advance(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.since = now
+}
+
+// SetOrderPoller polls the spot open orders, the latest order histories and the executions by the REST api every
+// interval while the private stream is degraded, i.e. it's not authenticated or it receives nothing for a minute, and
+// emits them as the order and the trade events of the stream. The events delivered by the stream already are dropped
+// by the order id with the update time and by the execution id, so every update is emitted once whichever delivers it
+// first. The polling pauses while the stream is healthy. It must be called before Connect.
+func (s *Stream) SetOrderPoller(provider OrderStatusProvider, interval time.Duration) error {
+	if provider == nil {
+		return fmt.Errorf("the order status provider of the order poller is nil")
+	}
+
+	if interval <= 0 {
+		return fmt.Errorf("invalid order poll interval: %s, it must be positive", interval)
+	}
+
+	s.orderPoller = &orderPoller{
+		provider: provider,
+		interval: interval,
+		since:    time.Now(),
+	}
+	return nil
+}
+
+// startOrderPoller starts the order poller of the private stream once, it runs until the context is done or the
+// stream is closed.
+func (s *Stream) startOrderPoller(ctx context.Context) {
+	p := s.orderPoller
+	if p == nil || s.PublicOnly {
+		return
+	}
+
+	p.once.Do(func() {
+		go func() {
+			ticker := time.NewTicker(p.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+
+				case <-s.CloseC:
+					return
+
+				case now := <-ticker.C:
+					if p.healthy(s.Stats(), now) {
+						continue
+					}
+
+					if err := s.pollOrders(ctx, now); err != nil {
+						s.logger.WithError(err).Warn("failed to poll the orders")
+					}
+				}
+			}
+		}()
+	})
+}
+
+// pollOrders emits the orders and the executions queried by the REST api in ascending order by the update time and
+// the execution time, the execution query starts from the last poll or the last healthy time.
+func (s *Stream) pollOrders(ctx context.Context, now time.Time) error {
+	p := s.orderPoller
+
+	openOrders, err := p.provider.QueryAllOpenOrders(ctx, bybitapi.CategorySpot, "")
+	if err != nil {
+		return fmt.Errorf("failed to query the open orders: %w", err)
+	}
+
+	histories, err := p.provider.QueryOrderHistories(ctx, bybitapi.CategorySpot, "", orderPollHistoryLimit)
+	if err != nil {
+		return fmt.Errorf("failed to query the order histories: %w", err)
+	}
+
+	startTime, endTime := p.executionRange(now)
+	executions, err := p.provider.QueryExecutions(ctx, bybitapi.CategorySpot, "", startTime, endTime)
+	if err != nil {
+		return fmt.Errorf("failed to query the executions: %w", err)
+	}
+
+	var orderEvents []OrderEvent
+	for _, order := range append(openOrders, histories...) {
+		orderEvents = append(orderEvents, OrderEvent{Order: order, Category: bybitapi.CategorySpot})
+	}
+	sort.SliceStable(orderEvents, func(i, j int) bool {
+		return orderEvents[i].UpdatedTime.Time().Before(orderEvents[j].UpdatedTime.Time())
+	})
+
+	var tradeEvents []TradeEvent
+	for _, execution := range executions {
+		tradeEvents = append(tradeEvents, toTradeEvent(bybitapi.CategorySpot, execution))
+	}
+	sort.SliceStable(tradeEvents, func(i, j int) bool {
+		return tradeEvents[i].ExecTime.Time().Before(tradeEvents[j].ExecTime.Time())
+	})
+
+	s.handleOrderEvent(orderEvents)
+	s.handleTradeEvent(tradeEvents)
+
+	p.advance(now)
+	return nil
+}
+
+// toTradeEvent converts the execution of the execution list to the TradeEvent of the execution topic.
+func toTradeEvent(category bybitapi.Category, execution bybitapi.Execution) TradeEvent {
+	return TradeEvent{
+		OrderId:     execution.OrderId,
+		OrderLinkId: execution.OrderLinkId,
+		Category:    category,
+		Symbol:      execution.Symbol,
+		ExecId:      execution.ExecId,
+		ExecPrice:   execution.ExecPrice,
+		ExecQty:     execution.ExecQty,
+		IsMaker:     execution.IsMaker,
+		OrderType:   execution.OrderType,
+		Side:        execution.Side,
+		ExecTime:    execution.ExecTime,
+		ExecFee:     execution.ExecFee,
+		ExecType:    execution.ExecType,
+		ExecValue:   execution.ExecValue,
+		FeeRate:     execution.FeeRate,
+		FeeCurrency: execution.FeeCurrency,
+		OrderPrice:  execution.OrderPrice,
+		OrderQty:    execution.OrderQty,
+	}
+}
@@ -0,0 +1,200 @@
+package bybit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+)
+
+// QueuePolicy decides what to do with a new event when the topic queue is full, see Stream.SetTopicQueue.
+type QueuePolicy int
+
+const (
+	// QueuePolicyBlock blocks the reader until the queue has room, so nothing is dropped but all the topics are
+	// stalled by the full queue.
+	QueuePolicyBlock QueuePolicy = iota
+	// QueuePolicyDropOldest drops the oldest queued event to make room for the new one, it fits the topics that only
+	// the latest event matters, e.g. the tickers.
+	QueuePolicyDropOldest
+	// QueuePolicyDropNewest drops the new event and keeps the queued ones.
+	QueuePolicyDropNewest
+)
+
+func (p QueuePolicy) String() string {
+	switch p {
+	case QueuePolicyBlock:
+		return "block"
+	case QueuePolicyDropOldest:
+		return "drop-oldest"
+	case QueuePolicyDropNewest:
+		return "drop-newest"
+	default:
+		return fmt.Sprintf("QueuePolicy(%d)", int(p))
+	}
+}
+
+// topicQueue is the bounded queue of the events of a topic type, the events are emitted by its own goroutine, so a
+// slow handler of the topic doesn't stall the reader and the other topics.
+type topicQueue struct {
+	topicType TopicType
+	policy    QueuePolicy
+	events    chan interface{}
+}
+
+// SetTopicQueue dispatches the events of the topic type through a bounded queue of the given size, the events are
+// emitted to the handlers by a dedicated goroutine in order, and the policy decides what to do when the handlers fall
+// behind. The dropped events are counted in TopicStats.Dropped. The events of the topic types without a queue are
+// emitted by the reader goroutine as before.
+//
+// Note that the events of the different topic types are no longer emitted in the order they are received, e.g. the
+// order and the execution topics. It must be called before Connect.
+func (s *Stream) SetTopicQueue(topicType TopicType, size int, policy QueuePolicy) error {
+	if size <= 0 {
+		return fmt.Errorf("the queue size of the topic %s must be positive, got %d", topicType, size)
+	}
+
+	switch policy {
+	case QueuePolicyBlock, QueuePolicyDropOldest, QueuePolicyDropNewest:
+	default:
+		return fmt.Errorf("unknown queue policy of the topic %s: %s", topicType, policy)
+	}
+
+	if _, ok := s.topicQueues[topicType]; ok {
+		return fmt.Errorf("the queue of the topic %s is already set", topicType)
+	}
+
+	if s.topicQueues == nil {
+		s.topicQueues = make(map[TopicType]*topicQueue)
+	}
+
+	q := &topicQueue{
+		topicType: topicType,
+		policy:    policy,
+		events:    make(chan interface{}, size),
+	}
+	s.topicQueues[topicType] = q
+
+	go s.runTopicQueue(q)
+	return nil
+}
+
+// enqueueEvent puts the event into the queue of its topic type, it returns false if the topic type has no queue, so
+// the caller emits the event directly.
+func (s *Stream) enqueueEvent(event interface{}) bool {
+	if len(s.topicQueues) == 0 {
+		return false
+	}
+
+	topicType, ok := eventTopicType(event)
+	if !ok {
+		return false
+	}
+
+	q, ok := s.topicQueues[topicType]
+	if !ok {
+		return false
+	}
+
+	switch q.policy {
+	case QueuePolicyBlock:
+		select {
+		case q.events <- event:
+		case <-s.CloseC:
+		}
+
+	case QueuePolicyDropNewest:
+		select {
+		case q.events <- event:
+		default:
+			s.dropEvent(q)
+		}
+
+	case QueuePolicyDropOldest:
+		for {
+			select {
+			case q.events <- event:
+				return true
+			default:
+			}
+
+			// the consumer may take the oldest one first, then the next push succeeds without dropping.
+			select {
+			case <-q.events:
+				s.dropEvent(q)
+			default:
+			}
+		}
+	}
+
+	return true
+}
+
+func (s *Stream) dropEvent(q *topicQueue) {
+	s.stats.recordDrop(q.topicType, time.Now())
+	metricsDroppedTopicEvents.WithLabelValues(string(q.topicType), q.policy.String()).Inc()
+}
+
+func (s *Stream) runTopicQueue(q *topicQueue) {
+	for {
+		select {
+		case <-s.CloseC:
+			return
+
+		case event := <-q.events:
+			// don't emit any event after closing, the same as the reader.
+			select {
+			case <-s.CloseC:
+				return
+			default:
+			}
+
+			s.emitEvent(event)
+		}
+	}
+}
+
+// eventTopicType returns the topic type of the parsed event, the op events have no topic type.
+func eventTopicType(event interface{}) (TopicType, bool) {
+	switch e := event.(type) {
+	case *BookEvent:
+		return TopicTypeOrderBook, true
+
+	case []MarketTradeEvent:
+		return TopicTypeMarketTrade, true
+
+	case []bybitapi.WalletBalances:
+		return TopicTypeWallet, true
+
+	case *KLineEvent:
+		switch e.PriceSource {
+		case KLinePriceSourceMark:
+			return TopicTypeMarkPriceKLine, true
+		case KLinePriceSourceIndex:
+			return TopicTypeIndexPriceKLine, true
+		default:
+			return TopicTypeKLine, true
+		}
+
+	case []OrderEvent:
+		return TopicTypeOrder, true
+
+	case []TradeEvent:
+		return TopicTypeTrade, true
+
+	case *LiquidationEvent:
+		return TopicTypeLiquidation, true
+
+	case *GreeksEvent:
+		return TopicTypeGreeks, true
+
+	case *TickerEvent:
+		return TopicTypeTicker, true
+
+	case *rawTopicMessage:
+		return getTopicType(e.Topic), true
+
+	}
+
+	return "", false
+}
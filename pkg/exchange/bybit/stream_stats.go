@@ -0,0 +1,161 @@
+package bybit
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// bookLatencyWindow is the number of the latest book updates the latency percentiles are computed over.
+const bookLatencyWindow = 1000
+
+// TopicStats is the counters of the messages of a topic type.
+type TopicStats struct {
+	Messages uint64
+	Bytes    uint64
+	// LastMessageTime is the local time the last message of the topic type was received.
+	LastMessageTime time.Time
+	// Dropped is the number of the events dropped by the topic queue, see Stream.SetTopicQueue.
+	Dropped uint64
+	// LastDropTime is the local time the last event of the topic type was dropped.
+	LastDropTime time.Time
+}
+
+// LatencyStats is the latency percentiles over the latest updates, the percentiles are zero if there is no update.
+type LatencyStats struct {
+	// Samples is the number of the updates the percentiles are computed over, it's at most bookLatencyWindow.
+	Samples int
+	Last    time.Duration
+	P50     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// StreamStats is the snapshot of the connection health of the stream, see Stream.Stats.
+type StreamStats struct {
+	// Messages and Bytes count all the received messages, including the op messages like pong and the messages
+	// failed to decode.
+	Messages uint64
+	Bytes    uint64
+	// LastMessageTime is the local time the last message was received, it's zero if nothing was received.
+	LastMessageTime time.Time
+	// Reconnects is the number of the reconnections, the first connection is not counted.
+	Reconnects uint64
+	// Topics is the counters by the topic type, e.g. a stalled orderbook topic can be told from a fresh kline topic.
+	Topics map[TopicType]TopicStats
+	// BookLatency is the delay of the book updates from the generation time, the ts of the message, to the local
+	// receive time. It's negative if the local clock is ahead of the server clock beyond the clock offset.
+	BookLatency LatencyStats
+}
+
+// SinceLastMessage returns the duration since the last message, it's zero if nothing was received.
+func (s StreamStats) SinceLastMessage() time.Duration {
+	if s.LastMessageTime.IsZero() {
+		return 0
+	}
+	return time.Since(s.LastMessageTime)
+}
+
+// streamStats collects the StreamStats, the messages are recorded by the reader goroutine while Stats may be called
+// from anywhere. The zero value is ready to use.
+type streamStats struct {
+	mu sync.Mutex
+
+	stats StreamStats
+
+	// bookLatencies is the ring buffer of the latest book latencies, next is the index of the next sample.
+	bookLatencies []time.Duration
+	next          int
+}
+
+func (s *streamStats) recordMessage(size int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Messages++
+	s.stats.Bytes += uint64(size)
+	s.stats.LastMessageTime = now
+}
+
+func (s *streamStats) recordTopicMessage(topicType TopicType, size int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stats.Topics == nil {
+		s.stats.Topics = make(map[TopicType]TopicStats)
+	}
+
+	topic := s.stats.Topics[topicType]
+	topic.Messages++
+	topic.Bytes += uint64(size)
+	topic.LastMessageTime = now
+	s.stats.Topics[topicType] = topic
+}
+
+func (s *streamStats) recordDrop(topicType TopicType, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stats.Topics == nil {
+		s.stats.Topics = make(map[TopicType]TopicStats)
+	}
+
+	topic := s.stats.Topics[topicType]
+	topic.Dropped++
+	topic.LastDropTime = now
+	s.stats.Topics[topicType] = topic
+}
+
+func (s *streamStats) recordBookLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.BookLatency.Last = latency
+	if len(s.bookLatencies) < bookLatencyWindow {
+		s.bookLatencies = append(s.bookLatencies, latency)
+		return
+	}
+
+	s.bookLatencies[s.next] = latency
+	s.next = (s.next + 1) % bookLatencyWindow
+}
+
+func (s *streamStats) recordReconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Reconnects++
+}
+
+// snapshot returns a copy of the stats, so the caller can't race with the recording.
+func (s *streamStats) snapshot() StreamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Topics = make(map[TopicType]TopicStats, len(s.stats.Topics))
+	for topicType, topic := range s.stats.Topics {
+		stats.Topics[topicType] = topic
+	}
+
+	if n := len(s.bookLatencies); n > 0 {
+		latencies := append([]time.Duration{}, s.bookLatencies...)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		stats.BookLatency.Samples = n
+		stats.BookLatency.P50 = percentile(latencies, 0.5)
+		stats.BookLatency.P99 = percentile(latencies, 0.99)
+		stats.BookLatency.Max = latencies[n-1]
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
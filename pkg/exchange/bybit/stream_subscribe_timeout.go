@@ -0,0 +1,82 @@
+package bybit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSubscribeTimeout is the error of the subscribe op which isn't acknowledged in the subscribe timeout, e.g. the
+// topic of an unknown symbol.
+var ErrSubscribeTimeout = errors.New("the subscription is not acknowledged in time")
+
+// SubscriptionErrorEvent is emitted if the subscribe op is rejected by the server or isn't acknowledged in the
+// subscribe timeout after the retries, the topics won't receive any data.
+type SubscriptionErrorEvent struct {
+	// ReqId is the req id of the last sent op.
+	ReqId  string
+	Topics []string
+	Err    error
+}
+
+// pendingOp is the op waiting for the response, the timer fails the subscribe op if it's not acknowledged in time.
+type pendingOp struct {
+	op      WebsocketOp
+	retries int
+	timer   *time.Timer
+}
+
+func (p *pendingOp) stop() {
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// SetSubscribeTimeout fails the subscribe op if it isn't acknowledged in the timeout, the op is sent again with a new
+// req id up to retries times before it's failed. The failed subscriptions, either timed out or rejected by the server,
+// are emitted to OnSubscriptionError. The timeout is disabled by default, and it must be called before Connect.
+func (s *Stream) SetSubscribeTimeout(timeout time.Duration, retries int) error {
+	if timeout < 0 {
+		return fmt.Errorf("the subscribe timeout must not be negative, got %s", timeout)
+	}
+	if retries < 0 {
+		return fmt.Errorf("the subscribe retries must not be negative, got %d", retries)
+	}
+
+	s.subscribeTimeout = timeout
+	s.subscribeRetries = retries
+	return nil
+}
+
+func (s *Stream) handleSubscribeTimeout(reqId string) {
+	p, ok := s.removePendingOp(reqId)
+	if !ok {
+		// the response is received
+		return
+	}
+
+	if p.retries < s.subscribeRetries {
+		s.ConnLock.Lock()
+		conn := s.Conn
+		s.ConnLock.Unlock()
+
+		if conn != nil {
+			op := p.op
+			op.ReqId = s.newReqId(op.Op)
+			s.logger.Warnf("the subscription of topics %v is not acknowledged in %s, retry with req id: %s", op.Args, s.subscribeTimeout, op.ReqId)
+
+			err := s.writeOp(conn, op, p.retries+1)
+			if err == nil {
+				return
+			}
+			s.logger.WithError(err).Error("failed to retry the subscription")
+		}
+	}
+
+	s.logger.Errorf("failed to subscribe topics: %v, req id: %s, err: %v", p.op.Args, reqId, ErrSubscribeTimeout)
+	s.EmitSubscriptionError(SubscriptionErrorEvent{
+		ReqId:  reqId,
+		Topics: p.op.Args,
+		Err:    ErrSubscribeTimeout,
+	})
+}
@@ -2,17 +2,29 @@ package bybit
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
 
 	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/notifier"
 	"github.com/c9s/bbgo/pkg/testutil"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -234,6 +246,9 @@ func TestStream_parseWebSocketEvent(t *testing.T) {
 		assert.NoError(t, err)
 		book, ok := res.(*BookEvent)
 		assert.True(t, ok)
+		// the receive time is set by the stream
+		assert.False(t, book.ReceivedTime.IsZero())
+		book.ReceivedTime = time.Time{}
 		assert.Equal(t, BookEvent{
 			Symbol: "BTCUSDT",
 			Bids:   nil,
@@ -290,6 +305,79 @@ func TestStream_parseWebSocketEvent(t *testing.T) {
 		}, book)
 	})
 
+	t.Run("TopicTypeGreeks", func(t *testing.T) {
+		input := `{
+    "id": "592324fa945a30-2603-49a5-b865-21668c29f2a6",
+    "topic": "greeks",
+    "creationTime": 1672364262482,
+    "data": [
+        {
+            "baseCoin": "ETH",
+            "totalDelta": "0.06999986",
+            "totalGamma": "-0.00000001",
+            "totalVega": "-0.00000024",
+            "totalTheta": "0.00001314"
+        }
+    ]
+}`
+
+		res, err := s.parseWebSocketEvent([]byte(input))
+		assert.NoError(t, err)
+		greeks, ok := res.(*GreeksEvent)
+		if assert.True(t, ok) {
+			eth := Greeks{
+				BaseCoin:   "ETH",
+				TotalDelta: fixedpoint.MustNewFromString("0.06999986"),
+				TotalGamma: fixedpoint.MustNewFromString("-0.00000001"),
+				TotalVega:  fixedpoint.MustNewFromString("-0.00000024"),
+				TotalTheta: fixedpoint.MustNewFromString("0.00001314"),
+			}
+			assert.Equal(t, GreeksEvent{
+				Greeks: []Greeks{eth},
+				Time:   types.NewMillisecondTimestampFromInt(1672364262482).Time(),
+			}, *greeks)
+			assert.Equal(t, map[string]Greeks{"ETH": eth}, greeks.ByBaseCoin())
+		}
+	})
+
+	t.Run("TopicTypeLiquidation", func(t *testing.T) {
+		input := `{
+   "topic":"liquidation.BTCUSDT",
+   "type":"snapshot",
+   "ts":1673251091822,
+   "data":{
+      "updatedTime":1673251091822,
+      "symbol":"BTCUSDT",
+      "side":"Sell",
+      "size":"0.003",
+      "price":"16787.5"
+   }
+}`
+
+		res, err := s.parseWebSocketEvent([]byte(input))
+		assert.NoError(t, err)
+		liquidation, ok := res.(*LiquidationEvent)
+		assert.True(t, ok)
+		assert.Equal(t, LiquidationEvent{
+			UpdatedTime: types.NewMillisecondTimestampFromInt(1673251091822),
+			Symbol:      "BTCUSDT",
+			Side:        bybitapi.SideSell,
+			Size:        fixedpoint.NewFromFloat(0.003),
+			Price:       fixedpoint.NewFromFloat(16787.5),
+		}, *liquidation)
+
+		info, err := liquidation.toGlobalLiquidationInfo()
+		assert.NoError(t, err)
+		assert.Equal(t, types.LiquidationInfo{
+			Symbol:       "BTCUSDT",
+			Side:         types.SideTypeSell,
+			Quantity:     fixedpoint.NewFromFloat(0.003),
+			Price:        fixedpoint.NewFromFloat(16787.5),
+			AveragePrice: fixedpoint.NewFromFloat(16787.5),
+			TradeTime:    types.Time(types.NewMillisecondTimestampFromInt(1673251091822).Time()),
+		}, info)
+	})
+
 	t.Run("TopicTypeKLine with snapshot", func(t *testing.T) {
 		input := `{
     "topic": "kline.5.BTCUSDT",
@@ -337,6 +425,38 @@ func TestStream_parseWebSocketEvent(t *testing.T) {
 		}, *book)
 	})
 
+	t.Run("TopicTypeMarkPriceKLine with snapshot", func(t *testing.T) {
+		input := `{
+    "topic": "kline_mark.5.BTCUSDT",
+    "data": [
+        {
+            "start": 1672324800000,
+            "end": 1672325099999,
+            "interval": "5",
+            "open": "16649.5",
+            "close": "16677",
+            "high": "16677",
+            "low": "16608",
+            "confirm": true,
+            "timestamp": 1672325099999
+        }
+    ],
+    "ts": 1672325099999,
+    "type": "snapshot"
+}`
+
+		res, err := s.parseWebSocketEvent([]byte(input))
+		assert.NoError(t, err)
+		kLineEvent, ok := res.(*KLineEvent)
+		assert.True(t, ok)
+		assert.Equal(t, KLinePriceSourceMark, kLineEvent.PriceSource)
+		assert.Equal(t, "BTCUSDT", kLineEvent.Symbol)
+		assert.Len(t, kLineEvent.KLines, 1)
+		assert.Equal(t, fixedpoint.NewFromFloat(16677), kLineEvent.KLines[0].ClosePrice)
+		assert.True(t, kLineEvent.KLines[0].Volume.IsZero())
+		assert.True(t, kLineEvent.KLines[0].Turnover.IsZero())
+	})
+
 	t.Run("TopicTypeKLine with invalid topic", func(t *testing.T) {
 		input := `{
     "topic": "kline.5",
@@ -464,4 +584,1922 @@ func Test_convertSubscription(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, genTopic(TopicTypeMarketTrade, "BTCUSDT"), res)
 	})
+
+	t.Run("IndexPriceKLineChannel", func(t *testing.T) {
+		// the mark and the index price k lines are only available in the derivatives categories
+		for _, channel := range []types.Channel{MarkPriceKLineChannel, IndexPriceKLineChannel} {
+			_, err := s.convertSubscription(types.Subscription{
+				Symbol:  "BTCUSDT",
+				Channel: channel,
+				Options: types.SubscribeOptions{Interval: types.Interval5m},
+			})
+			assert.ErrorContains(t, err, "not available in the spot category")
+		}
+
+		inverse := NewStream("", "", nil)
+		assert.NoError(t, inverse.SetCategory(bybitapi.CategoryInverse))
+		res, err := inverse.convertSubscription(types.Subscription{
+			Symbol:  "BTCUSD",
+			Channel: IndexPriceKLineChannel,
+			Options: types.SubscribeOptions{Interval: types.Interval5m},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, genTopic(TopicTypeIndexPriceKLine, "5", "BTCUSD"), res)
+	})
+
+	t.Run("ForceOrderChannel", func(t *testing.T) {
+		// the liquidation topic is only available in the derivatives categories
+		_, err := s.convertSubscription(types.Subscription{
+			Symbol:  "BTCUSDT",
+			Channel: types.ForceOrderChannel,
+		})
+		assert.ErrorContains(t, err, "not available in the spot category")
+
+		linear := NewStream("", "", nil)
+		assert.NoError(t, linear.SetCategory(bybitapi.CategoryLinear))
+		res, err := linear.convertSubscription(types.Subscription{
+			Symbol:  "BTCUSDT",
+			Channel: types.ForceOrderChannel,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, genTopic(TopicTypeLiquidation, "BTCUSDT"), res)
+	})
+
+	t.Run("TickerChannel", func(t *testing.T) {
+		res, err := s.convertSubscription(types.Subscription{
+			Symbol:  "BTCUSDT",
+			Channel: TickerChannel,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, genTopic(TopicTypeTicker, "BTCUSDT"), res)
+	})
+}
+
+func TestStream_handleMarketTradeEvent(t *testing.T) {
+	events := []MarketTradeEvent{
+		{
+			Timestamp: types.NewMillisecondTimestampFromInt(1691486100000),
+			Symbol:    "BTCUSDT",
+			Side:      bybitapi.SideBuy,
+			Quantity:  fixedpoint.Zero,
+			Price:     fixedpoint.NewFromFloat(28829.76),
+			TradeId:   "2290000000068683542",
+		},
+		{
+			Timestamp: types.NewMillisecondTimestampFromInt(1691486100001),
+			Symbol:    "BTCUSDT",
+			Side:      bybitapi.SideSell,
+			Quantity:  fixedpoint.NewFromFloat(0.002289),
+			Price:     fixedpoint.NewFromFloat(28829.76),
+			TradeId:   "2290000000068683543",
+		},
+	}
+
+	t.Run("keep zero volume trades by default", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		var trades []types.Trade
+		s.OnMarketTrade(func(trade types.Trade) {
+			trades = append(trades, trade)
+		})
+
+		s.handleMarketTradeEvent(events)
+		assert.Len(t, trades, 2)
+	})
+
+	t.Run("drop zero volume trades", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		s.SetDropZeroVolumeTrades(true)
+		var trades []types.Trade
+		s.OnMarketTrade(func(trade types.Trade) {
+			trades = append(trades, trade)
+		})
+
+		s.handleMarketTradeEvent(events)
+		if assert.Len(t, trades, 1) {
+			assert.Equal(t, uint64(2290000000068683543), trades[0].ID)
+		}
+	})
+}
+
+func TestStream_handleBookEvent_bucket(t *testing.T) {
+	s := NewStream("", "", nil)
+	s.SetBookBucketSize(fixedpoint.One)
+
+	var books []types.SliceOrderBook
+	s.OnBookSnapshot(func(book types.SliceOrderBook) {
+		books = append(books, book)
+	})
+	s.OnBookUpdate(func(book types.SliceOrderBook) {
+		assert.Fail(t, "the bucketed book should be emitted as snapshot")
+	})
+
+	s.handleBookEvent(BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("100.9"), Volume: fixedpoint.MustNewFromString("1")},
+			{Price: fixedpoint.MustNewFromString("100.2"), Volume: fixedpoint.MustNewFromString("2")},
+			{Price: fixedpoint.MustNewFromString("99.8"), Volume: fixedpoint.MustNewFromString("3")},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("101.1"), Volume: fixedpoint.MustNewFromString("1")},
+			{Price: fixedpoint.MustNewFromString("101.5"), Volume: fixedpoint.MustNewFromString("2")},
+		},
+		UpdateId: fixedpoint.NewFromInt(10),
+		Type:     DataTypeSnapshot,
+	})
+
+	// remove the 100.2 bid level
+	s.handleBookEvent(BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("100.2"), Volume: fixedpoint.Zero},
+		},
+		UpdateId: fixedpoint.NewFromInt(11),
+		Type:     DataTypeDelta,
+	})
+
+	if assert.Len(t, books, 2) {
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.NewFromInt(3)},
+			{Price: fixedpoint.NewFromInt(99), Volume: fixedpoint.NewFromInt(3)},
+		}, books[0].Bids)
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(102), Volume: fixedpoint.NewFromInt(3)},
+		}, books[0].Asks)
+
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.NewFromInt(1)},
+			{Price: fixedpoint.NewFromInt(99), Volume: fixedpoint.NewFromInt(3)},
+		}, books[1].Bids)
+	}
+
+	// the full depth book keeps the original price levels
+	assert.Len(t, s.books["BTCUSDT"].Bids, 2)
+	assert.Equal(t, fixedpoint.MustNewFromString("100.9"), s.books["BTCUSDT"].Bids[0].Price)
+}
+
+func TestStream_decodeErrors(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		_, err := s.parse([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","data":"bad"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed frame", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		errC := s.EnableDecodeErrors(1)
+
+		_, err := s.parse([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1691130685111,"data":"bad"}`))
+		assert.Error(t, err)
+
+		select {
+		case decodeErr := <-errC:
+			assert.Equal(t, "orderbook.50.BTCUSDT", decodeErr.Topic)
+			assert.Contains(t, string(decodeErr.Raw), `"data":"bad"`)
+			assert.Error(t, decodeErr.Err)
+		default:
+			assert.Fail(t, "expected a decode error")
+		}
+	})
+
+	t.Run("strict decode", func(t *testing.T) {
+		known := []byte(`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1691130685111,"cts":1691130685100,"data":{"s":"BTCUSDT","b":[["100","1"]],"a":[],"u":1,"seq":10}}`)
+		unknown := []byte(`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1691130685111,"data":{"s":"BTCUSDT","b":[],"a":[],"u":1,"seq":10,"newField":"1"}}`)
+
+		// the unknown fields are dropped by default
+		s := NewStream("", "", nil)
+		_, err := s.parse(unknown)
+		assert.NoError(t, err)
+
+		s.SetStrictDecode(true)
+		errC := s.EnableDecodeErrors(1)
+
+		// the unknown fields of the envelope are not checked
+		e, err := s.parse(known)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "BTCUSDT", e.(*BookEvent).Symbol)
+		}
+
+		_, err = s.parse(unknown)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), `unknown field "newField"`)
+		}
+		if assert.Len(t, errC, 1) {
+			assert.Equal(t, "orderbook.50.BTCUSDT", (<-errC).Topic)
+		}
+	})
+
+	t.Run("drop on overflow", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		errC := s.EnableDecodeErrors(1)
+
+		for i := 0; i < 3; i++ {
+			_, err := s.parse([]byte(`{`))
+			assert.Error(t, err)
+		}
+		assert.Len(t, errC, 1)
+	})
+}
+
+func TestStream_fillSource(t *testing.T) {
+	orderEvents := []OrderEvent{
+		{
+			Order: bybitapi.Order{
+				OrderId:      "1468264727470772736",
+				Symbol:       "BTCUSDT",
+				Side:         bybitapi.SideBuy,
+				OrderStatus:  bybitapi.OrderStatusPartiallyFilled,
+				OrderType:    bybitapi.OrderTypeLimit,
+				TimeInForce:  bybitapi.TimeInForceGTC,
+				Price:        fixedpoint.NewFromInt(102),
+				Qty:          fixedpoint.One,
+				CumExecQty:   fixedpoint.NewFromFloat(0.5),
+				CumExecValue: fixedpoint.NewFromInt(50),
+				UpdatedTime:  types.NewMillisecondTimestampFromInt(1691486100000),
+			},
+			Category: bybitapi.CategorySpot,
+		},
+		{
+			Order: bybitapi.Order{
+				OrderId:      "1468264727470772736",
+				Symbol:       "BTCUSDT",
+				Side:         bybitapi.SideBuy,
+				OrderStatus:  bybitapi.OrderStatusFilled,
+				OrderType:    bybitapi.OrderTypeLimit,
+				TimeInForce:  bybitapi.TimeInForceGTC,
+				Price:        fixedpoint.NewFromInt(102),
+				Qty:          fixedpoint.One,
+				CumExecQty:   fixedpoint.One,
+				CumExecValue: fixedpoint.NewFromInt(101),
+				UpdatedTime:  types.NewMillisecondTimestampFromInt(1691486100001),
+			},
+			Category: bybitapi.CategorySpot,
+		},
+	}
+	tradeEvents := []TradeEvent{
+		{
+			OrderId:   "1468264727470772736",
+			Category:  bybitapi.CategorySpot,
+			Symbol:    "BTCUSDT",
+			ExecId:    "2100000000007764263",
+			ExecPrice: fixedpoint.NewFromInt(100),
+			ExecQty:   fixedpoint.NewFromFloat(0.5),
+			IsMaker:   true,
+			OrderType: bybitapi.OrderTypeLimit,
+			Side:      bybitapi.SideBuy,
+			ExecTime:  types.NewMillisecondTimestampFromInt(1691486100000),
+		},
+		{
+			OrderId:   "1468264727470772736",
+			Category:  bybitapi.CategorySpot,
+			Symbol:    "BTCUSDT",
+			ExecId:    "2100000000007764264",
+			ExecPrice: fixedpoint.NewFromInt(102),
+			ExecQty:   fixedpoint.NewFromFloat(0.5),
+			IsMaker:   true,
+			OrderType: bybitapi.OrderTypeLimit,
+			Side:      bybitapi.SideBuy,
+			ExecTime:  types.NewMillisecondTimestampFromInt(1691486100001),
+		},
+	}
+
+	run := func(s *Stream) (trades []types.Trade) {
+		s.OnTradeUpdate(func(trade types.Trade) {
+			trades = append(trades, trade)
+		})
+
+		for i := range orderEvents {
+			s.handleTradeEvent(tradeEvents[i : i+1])
+			s.handleOrderEvent(orderEvents[i : i+1])
+		}
+		return trades
+	}
+
+	t.Run("execution", func(t *testing.T) {
+		trades := run(NewStream("", "", nil))
+		if assert.Len(t, trades, 2) {
+			assert.Equal(t, uint64(2100000000007764263), trades[0].ID)
+			assert.Equal(t, uint64(2100000000007764264), trades[1].ID)
+		}
+	})
+
+	t.Run("order", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		s.SetFillSource("BTCUSDT", FillSourceOrder)
+
+		trades := run(s)
+		if assert.Len(t, trades, 2) {
+			assert.NotEqual(t, trades[0].ID, trades[1].ID)
+			assert.Equal(t, uint64(1468264727470772736), trades[0].OrderID)
+			assert.Equal(t, fixedpoint.NewFromFloat(0.5), trades[0].Quantity)
+			assert.Equal(t, fixedpoint.NewFromInt(100), trades[0].Price)
+			assert.Equal(t, fixedpoint.NewFromFloat(0.5), trades[1].Quantity)
+			assert.Equal(t, fixedpoint.NewFromInt(102), trades[1].Price)
+		}
+
+		// the order is cleaned up after it's filled
+		assert.Empty(t, s.fillTracker.orderFills)
+		assert.Empty(t, s.fillTracker.executedQty)
+	})
+}
+
+func TestStream_reconnectOnlyTheDroppedConnection(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip the reconnect test which waits for the reconnect cool down")
+	}
+
+	type subscribeOp struct {
+		connID int
+		args   []string
+	}
+
+	var mu sync.Mutex
+	var conns []*websocket.Conn
+	var ops []subscribeOp
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		connID := len(conns)
+		conns = append(conns, conn)
+		mu.Unlock()
+
+		for {
+			var op WebsocketOp
+			if err := conn.ReadJSON(&op); err != nil {
+				return
+			}
+
+			if op.Op == WsOpTypeSubscribe {
+				mu.Lock()
+				ops = append(ops, subscribeOp{connID: connID, args: op.Args})
+				mu.Unlock()
+			}
+		}
+	}))
+	defer server.Close()
+
+	newPublicStream := func(symbol string) (*Stream, *int32) {
+		stream := NewStream("", "", nil)
+		stream.SetPublicOnly()
+		stream.SetEndpointCreator(func(context.Context) (string, error) {
+			return "ws" + strings.TrimPrefix(server.URL, "http"), nil
+		})
+		stream.Subscribe(types.BookChannel, symbol, types.SubscribeOptions{Depth: types.DepthLevel50})
+
+		var numOfConnects int32
+		stream.OnConnect(func() {
+			atomic.AddInt32(&numOfConnects, 1)
+		})
+		return stream, &numOfConnects
+	}
+
+	btcStream, btcConnects := newPublicStream("BTCUSDT")
+	ethStream, ethConnects := newPublicStream("ETHUSDT")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, btcStream.Connect(ctx))
+	defer btcStream.Close()
+	assert.NoError(t, ethStream.Connect(ctx))
+	defer ethStream.Close()
+
+	numOfOps := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(ops)
+	}
+
+	if !assert.Eventually(t, func() bool { return numOfOps() == 2 }, time.Second, 10*time.Millisecond) {
+		return
+	}
+
+	// drop the connection of the BTCUSDT stream from the server side
+	mu.Lock()
+	for _, op := range ops {
+		if op.args[0] == "orderbook.50.BTCUSDT" {
+			_ = conns[op.connID].Close()
+		}
+	}
+	mu.Unlock()
+
+	if !assert.Eventually(t, func() bool { return numOfOps() == 3 }, 20*time.Second, 100*time.Millisecond) {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// only the dropped connection is reconnected, and only its symbols are resubscribed on the new connection
+	assert.Len(t, conns, 3)
+	assert.Equal(t, subscribeOp{connID: 2, args: []string{"orderbook.50.BTCUSDT"}}, ops[2])
+	assert.Equal(t, int32(2), atomic.LoadInt32(btcConnects))
+	assert.Equal(t, int32(1), atomic.LoadInt32(ethConnects))
+}
+
+func TestStream_connectionStateEvent(t *testing.T) {
+	s := NewStream("", "", nil)
+	s.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	s.Subscribe(types.MarketTradeChannel, "BTCUSDT", types.SubscribeOptions{})
+	s.Subscribe(types.BookChannel, "ETHUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+
+	var events []ConnectionStateEvent
+	s.OnConnectionStateEvent(func(e ConnectionStateEvent) {
+		events = append(events, e)
+	})
+
+	s.handleConnected()
+	s.EmitDisconnect()
+	s.handleConnected()
+
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, ConnectionStateConnected, events[0].State)
+		assert.Equal(t, ConnectionStateDisconnected, events[1].State)
+		assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, events[1].Symbols)
+		assert.Equal(t, ConnectionStateReconnected, events[2].State)
+	}
+}
+
+func TestStream_Stats(t *testing.T) {
+	s := NewStream("", "", nil)
+	assert.Empty(t, s.Stats().Topics)
+	assert.Zero(t, s.Stats().SinceLastMessage())
+
+	book := `{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1691130685111,"data":{"s":"BTCUSDT","b":[],"a":[],"u":1,"seq":1}}`
+	kline := `{"topic":"kline.1.BTCUSDT","type":"snapshot","ts":1691130685111,"data":[]}`
+	pong := `{"success":true,"ret_msg":"pong","conn_id":"a806f6c4-3608-4b6d-a225-9f5da975bc44","op":"ping"}`
+
+	for _, msg := range []string{book, kline, kline, pong} {
+		_, err := s.parse([]byte(msg))
+		assert.NoError(t, err)
+	}
+	_, err := s.parse([]byte(`{`))
+	assert.Error(t, err)
+
+	s.handleConnected()
+	s.handleConnected()
+
+	stats := s.Stats()
+	assert.Equal(t, uint64(5), stats.Messages)
+	assert.Equal(t, uint64(len(book)+2*len(kline)+len(pong)+1), stats.Bytes)
+	assert.Equal(t, uint64(1), stats.Reconnects)
+	assert.False(t, stats.LastMessageTime.IsZero())
+
+	if assert.Len(t, stats.Topics, 2) {
+		assert.Equal(t, uint64(1), stats.Topics[TopicTypeOrderBook].Messages)
+		assert.Equal(t, uint64(len(book)), stats.Topics[TopicTypeOrderBook].Bytes)
+		assert.Equal(t, uint64(2), stats.Topics[TopicTypeKLine].Messages)
+		assert.False(t, stats.Topics[TopicTypeKLine].LastMessageTime.Before(stats.Topics[TopicTypeOrderBook].LastMessageTime))
+	}
+
+	// the snapshot is not affected by the later messages
+	_, err = s.parse([]byte(book))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.Topics[TopicTypeOrderBook].Messages)
+	assert.Equal(t, uint64(2), s.Stats().Topics[TopicTypeOrderBook].Messages)
+}
+
+func TestStream_orderFillFee(t *testing.T) {
+	s := NewStream("", "", nil)
+	s.marketsInfo = types.MarketMap{
+		"BTCUSDT": types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+	}
+	s.SetFillSource("BTCUSDT", FillSourceOrder)
+
+	var trades []types.Trade
+	s.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	order := bybitapi.Order{
+		OrderId:     "1468264727470772736",
+		Symbol:      "BTCUSDT",
+		Side:        bybitapi.SideSell,
+		OrderType:   bybitapi.OrderTypeMarket,
+		TimeInForce: bybitapi.TimeInForceGTC,
+		Qty:         fixedpoint.One,
+	}
+
+	for _, fill := range []struct {
+		status                           bybitapi.OrderStatus
+		cumExecQty, cumExecValue, cumFee string
+	}{
+		{bybitapi.OrderStatusPartiallyFilled, "0.3", "30", "0.03"},
+		{bybitapi.OrderStatusPartiallyFilled, "0.5", "50", "0.05"},
+		{bybitapi.OrderStatusFilled, "1", "100", "0.1"},
+	} {
+		order.OrderStatus = fill.status
+		order.CumExecQty = fixedpoint.MustNewFromString(fill.cumExecQty)
+		order.CumExecValue = fixedpoint.MustNewFromString(fill.cumExecValue)
+		order.CumExecFee = fixedpoint.MustNewFromString(fill.cumFee)
+		s.handleOrderEvent([]OrderEvent{{Order: order, Category: bybitapi.CategorySpot}})
+	}
+
+	if assert.Len(t, trades, 3) {
+		totalFee := fixedpoint.Zero
+		for _, trade := range trades {
+			// the taker sell order pays the fee in the quote coin
+			assert.Equal(t, "USDT", trade.FeeCurrency)
+			totalFee = totalFee.Add(trade.Fee)
+		}
+		assert.Equal(t, fixedpoint.MustNewFromString("0.03"), trades[0].Fee)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.02"), trades[1].Fee)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.1"), totalFee)
+	}
+}
+
+func TestStream_bookChecksum(t *testing.T) {
+	snapshot := BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.One},
+			{Price: fixedpoint.NewFromInt(99), Volume: fixedpoint.NewFromInt(3)},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(101), Volume: fixedpoint.NewFromInt(2)},
+		},
+		UpdateId: fixedpoint.NewFromInt(10),
+		Type:     DataTypeSnapshot,
+	}
+
+	t.Run("checksum input", func(t *testing.T) {
+		assert.Equal(t, crc32.ChecksumIEEE([]byte("100:1:101:2:99:3")), bookChecksum(snapshot.OrderBook(), 25))
+		assert.Equal(t, crc32.ChecksumIEEE([]byte("100:1:101:2")), bookChecksum(snapshot.OrderBook(), 1))
+	})
+
+	t.Run("emit", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		s.SetBookChecksumDepth(25)
+
+		var snapshots, updates int
+		s.OnBookSnapshot(func(book types.SliceOrderBook) { snapshots++ })
+		s.OnBookUpdate(func(book types.SliceOrderBook) { updates++ })
+
+		var checksums []uint32
+		s.OnBookChecksumEvent(func(e BookChecksumEvent) {
+			assert.Equal(t, "BTCUSDT", e.Symbol)
+			checksums = append(checksums, e.Checksum)
+		})
+
+		s.handleBookEvent(snapshot)
+		// the same snapshot gives the same checksum
+		s.handleBookEvent(snapshot)
+		s.handleBookEvent(BookEvent{
+			Symbol: "BTCUSDT",
+			Asks: types.PriceVolumeSlice{
+				{Price: fixedpoint.NewFromInt(101), Volume: fixedpoint.NewFromInt(5)},
+			},
+			UpdateId: fixedpoint.NewFromInt(11),
+			Type:     DataTypeDelta,
+		})
+
+		assert.Equal(t, 2, snapshots)
+		assert.Equal(t, 1, updates)
+		if assert.Len(t, checksums, 3) {
+			assert.Equal(t, checksums[0], checksums[1])
+			assert.NotEqual(t, checksums[1], checksums[2])
+			assert.Equal(t, crc32.ChecksumIEEE([]byte("100:1:101:5:99:3")), checksums[2])
+		}
+	})
+}
+
+func TestStream_OnOrderBook(t *testing.T) {
+	s := NewStream("", "", nil)
+
+	var books []types.SliceOrderBook
+	s.OnOrderBook(func(book types.SliceOrderBook) {
+		books = append(books, book)
+	})
+
+	var events int
+	s.OnBookEvent(func(e BookEvent) {
+		events++
+	})
+
+	snapshot := BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.NewFromInt(1)},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(101), Volume: fixedpoint.NewFromInt(2)},
+		},
+		UpdateId:   fixedpoint.NewFromInt(10),
+		SequenceId: fixedpoint.NewFromInt(1000),
+		Type:       DataTypeSnapshot,
+	}
+	s.EmitBookEvent(snapshot)
+	s.EmitBookEvent(BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(99), Volume: fixedpoint.NewFromInt(3)},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(101), Volume: fixedpoint.Zero},
+		},
+		UpdateId:   fixedpoint.NewFromInt(11),
+		SequenceId: fixedpoint.NewFromInt(1001),
+		Type:       DataTypeDelta,
+	})
+
+	if assert.Len(t, books, 2) {
+		assert.Equal(t, int64(11), books[1].LastUpdateId)
+		assert.Equal(t, int64(1001), books[1].SequenceId)
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.NewFromInt(1)},
+			{Price: fixedpoint.NewFromInt(99), Volume: fixedpoint.NewFromInt(3)},
+		}, books[1].Bids)
+		assert.Empty(t, books[1].Asks)
+	}
+
+	// the book is dropped on the gap until the next snapshot
+	s.EmitBookEvent(BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(98), Volume: fixedpoint.NewFromInt(1)},
+		},
+		UpdateId:   fixedpoint.NewFromInt(13),
+		SequenceId: fixedpoint.NewFromInt(1003),
+		Type:       DataTypeDelta,
+	})
+	s.EmitBookEvent(BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(97), Volume: fixedpoint.NewFromInt(1)},
+		},
+		UpdateId:   fixedpoint.NewFromInt(14),
+		SequenceId: fixedpoint.NewFromInt(1004),
+		Type:       DataTypeDelta,
+	})
+	assert.Len(t, books, 2)
+
+	snapshot.UpdateId = fixedpoint.NewFromInt(20)
+	snapshot.SequenceId = fixedpoint.NewFromInt(1010)
+	s.EmitBookEvent(snapshot)
+	if assert.Len(t, books, 3) {
+		assert.Equal(t, snapshot.Bids, books[2].Bids)
+		assert.Equal(t, snapshot.Asks, books[2].Asks)
+	}
+
+	// the low-level book events are still available
+	assert.Equal(t, 5, events)
+}
+
+func TestStream_ExportBooks(t *testing.T) {
+	s := NewStream("", "", nil)
+	s.OnOrderBook(func(book types.SliceOrderBook) {})
+	assert.Empty(t, s.ExportBooks())
+
+	s.EmitBookEvent(BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.NewFromInt(1)},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(101), Volume: fixedpoint.NewFromInt(2)},
+		},
+		UpdateId:   fixedpoint.NewFromInt(10),
+		SequenceId: fixedpoint.NewFromInt(1000),
+		ServerTime: time.UnixMilli(1691130685111),
+		Type:       DataTypeSnapshot,
+	})
+
+	exported := s.ExportBooks()
+	if assert.Len(t, exported, 1) {
+		book := exported["BTCUSDT"]
+		assert.Equal(t, "BTCUSDT", book.Symbol)
+		assert.Equal(t, int64(10), book.LastUpdateId)
+		assert.Equal(t, int64(1000), book.SequenceId)
+		assert.Equal(t, time.UnixMilli(1691130685111), book.Time)
+		assert.Len(t, book.Bids, 1)
+		assert.Len(t, book.Asks, 1)
+	}
+
+	// the exported book is a copy
+	exported["BTCUSDT"].Bids[0].Volume = fixedpoint.NewFromInt(5)
+	assert.Equal(t, fixedpoint.NewFromInt(1), s.ExportBooks()["BTCUSDT"].Bids[0].Volume)
+
+	// the exported books are restored by another stream
+	restored := NewStream("", "", nil)
+	var books []types.SliceOrderBook
+	restored.OnOrderBook(func(book types.SliceOrderBook) {
+		books = append(books, book)
+	})
+	assert.NoError(t, restored.ImportBooks(s.ExportBooks()))
+	assert.Equal(t, s.ExportBooks(), restored.ExportBooks())
+	if assert.Len(t, books, 1) {
+		assert.Equal(t, int64(10), books[0].LastUpdateId)
+	}
+
+	// the consecutive delta is applied to the imported book
+	restored.EmitBookEvent(BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(99), Volume: fixedpoint.NewFromInt(3)},
+		},
+		UpdateId:   fixedpoint.NewFromInt(11),
+		SequenceId: fixedpoint.NewFromInt(1001),
+		Type:       DataTypeDelta,
+	})
+	if assert.Len(t, books, 2) {
+		assert.Len(t, books[1].Bids, 2)
+		assert.Equal(t, int64(11), books[1].LastUpdateId)
+	}
+
+	// the imported book is replaced by the snapshot
+	restored.EmitBookEvent(BookEvent{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(98), Volume: fixedpoint.NewFromInt(1)},
+		},
+		UpdateId:   fixedpoint.NewFromInt(20),
+		SequenceId: fixedpoint.NewFromInt(1010),
+		Type:       DataTypeSnapshot,
+	})
+	if assert.Len(t, books, 3) {
+		assert.Equal(t, types.PriceVolumeSlice{{Price: fixedpoint.NewFromInt(98), Volume: fixedpoint.NewFromInt(1)}}, books[2].Bids)
+		assert.Empty(t, books[2].Asks)
+	}
+	restored.booksMutex.Lock()
+	assert.Empty(t, restored.importedBooks)
+	restored.booksMutex.Unlock()
+}
+
+func TestStream_ImportBooks_invalid(t *testing.T) {
+	s := NewStream("", "", nil)
+
+	valid := types.SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.NewFromInt(1)},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(101), Volume: fixedpoint.NewFromInt(2)},
+		},
+		LastUpdateId: 10,
+	}
+	crossed := valid
+	crossed.Symbol = "ETHUSDT"
+	crossed.Bids = types.PriceVolumeSlice{{Price: fixedpoint.NewFromInt(102), Volume: fixedpoint.NewFromInt(1)}}
+	noUpdateId := valid
+	noUpdateId.Symbol = "SOLUSDT"
+	noUpdateId.LastUpdateId = 0
+
+	err := s.ImportBooks(map[string]types.SliceOrderBook{
+		"BTCUSDT": valid,
+		"ETHUSDT": crossed,
+		"SOLUSDT": noUpdateId,
+		"XRPUSDT": {Symbol: "XRPUSDT", LastUpdateId: 1},
+		"DOTUSDT": valid,
+	})
+	assert.Len(t, multierr.Errors(err), 4)
+
+	// only the valid book is imported
+	books := s.ExportBooks()
+	assert.Len(t, books, 1)
+	assert.Contains(t, books, "BTCUSDT")
+}
+
+func TestStream_OnOrderBook_multipleSymbols(t *testing.T) {
+	s := NewStream("", "", nil)
+
+	books := map[string][]types.SliceOrderBook{}
+	s.OnOrderBook(func(book types.SliceOrderBook) {
+		books[book.Symbol] = append(books[book.Symbol], book)
+	})
+
+	newBookEvent := func(symbol string, dataType DataType, updateId int64, bid int64) BookEvent {
+		return BookEvent{
+			Symbol: symbol,
+			Bids: types.PriceVolumeSlice{
+				{Price: fixedpoint.NewFromInt(bid), Volume: fixedpoint.One},
+			},
+			UpdateId:   fixedpoint.NewFromInt(updateId),
+			SequenceId: fixedpoint.NewFromInt(updateId),
+			Type:       dataType,
+		}
+	}
+
+	// the events of the symbols interleave, and their update ids overlap
+	s.EmitBookEvent(newBookEvent("BTCUSDT", DataTypeSnapshot, 10, 30000))
+	s.EmitBookEvent(newBookEvent("ETHUSDT", DataTypeSnapshot, 10, 2000))
+	s.EmitBookEvent(newBookEvent("BTCUSDT", DataTypeDelta, 11, 29999))
+	s.EmitBookEvent(newBookEvent("ETHUSDT", DataTypeDelta, 11, 1999))
+	// the gap of ETHUSDT doesn't affect BTCUSDT
+	s.EmitBookEvent(newBookEvent("ETHUSDT", DataTypeDelta, 13, 1998))
+	s.EmitBookEvent(newBookEvent("BTCUSDT", DataTypeDelta, 12, 29998))
+	// the delta of the symbol without the snapshot is dropped
+	s.EmitBookEvent(newBookEvent("SOLUSDT", DataTypeDelta, 12, 100))
+
+	if assert.Len(t, books["BTCUSDT"], 3) {
+		book := books["BTCUSDT"][2]
+		assert.Equal(t, int64(12), book.LastUpdateId)
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(30000), Volume: fixedpoint.One},
+			{Price: fixedpoint.NewFromInt(29999), Volume: fixedpoint.One},
+			{Price: fixedpoint.NewFromInt(29998), Volume: fixedpoint.One},
+		}, book.Bids)
+	}
+
+	if assert.Len(t, books["ETHUSDT"], 2) {
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(2000), Volume: fixedpoint.One},
+			{Price: fixedpoint.NewFromInt(1999), Volume: fixedpoint.One},
+		}, books["ETHUSDT"][1].Bids)
+	}
+	assert.Empty(t, books["SOLUSDT"])
+
+	// ETHUSDT is rebuilt from its next snapshot
+	s.EmitBookEvent(newBookEvent("ETHUSDT", DataTypeSnapshot, 20, 1990))
+	if assert.Len(t, books["ETHUSDT"], 3) {
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(1990), Volume: fixedpoint.One},
+		}, books["ETHUSDT"][2].Bids)
+	}
+	assert.Len(t, s.books, 2)
+}
+
+func TestStream_buildAuthOp(t *testing.T) {
+	s := NewStream("key", "secret", nil)
+	now := time.UnixMilli(1700000000000)
+
+	op := s.buildAuthOp(now)
+	assert.Equal(t, WsOpTypeAuth, op.Op)
+	assert.Equal(t, []string{
+		"key",
+		"1700000010000",
+		bybitapi.Sign("GET/realtime1700000010000", "secret"),
+	}, op.Args)
+
+	// the expiry is based on the server clock
+	s.SetAuthExpiry(30 * time.Second)
+	s.SetClockOffset(-2 * time.Second)
+	op = s.buildAuthOp(now)
+	assert.Equal(t, "1700000028000", op.Args[1])
+	assert.Equal(t, bybitapi.Sign("GET/realtime1700000028000", "secret"), op.Args[2])
+}
+
+func TestStream_UnsubscribeChannel(t *testing.T) {
+	received := make(chan WebsocketOp, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		var op WebsocketOp
+		if assert.NoError(t, conn.ReadJSON(&op)) {
+			received <- op
+		}
+	}))
+	defer server.Close()
+
+	s := NewStream("", "", nil)
+	s.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	s.Subscribe(types.BookChannel, "ETHUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	s.Subscribe(types.MarketTradeChannel, "BTCUSDT", types.SubscribeOptions{})
+
+	// the subscriptions are removed before the connection
+	assert.NoError(t, s.UnsubscribeChannel(types.MarketTradeChannel, "BTCUSDT", types.SubscribeOptions{}))
+	assert.Len(t, s.GetSubscriptions(), 2)
+
+	err := s.UnsubscribeChannel(types.MarketTradeChannel, "BTCUSDT", types.SubscribeOptions{})
+	assert.ErrorContains(t, err, "topic publicTrade.BTCUSDT is not subscribed")
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	s.Conn = conn
+
+	assert.NoError(t, s.UnsubscribeChannel(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50}))
+	assert.Equal(t, []types.Subscription{
+		{Channel: types.BookChannel, Symbol: "ETHUSDT", Options: types.SubscribeOptions{Depth: types.DepthLevel50}},
+	}, s.GetSubscriptions())
+
+	select {
+	case op := <-received:
+		assert.Equal(t, WsOpTypeUnsubscribe, op.Op)
+		assert.Equal(t, []string{"orderbook.50.BTCUSDT"}, op.Args)
+		assert.Equal(t, "unsubscribe-1", op.ReqId)
+
+	case <-time.After(time.Second):
+		t.Fatal("the unsubscribe op is not received")
+	}
+
+	// the response is correlated by the req id
+	assert.Len(t, s.pendingOps, 1)
+	_, err = s.parse([]byte(`{"success":true,"ret_msg":"","conn_id":"cm","req_id":"unsubscribe-1","op":"unsubscribe"}`))
+	assert.NoError(t, err)
+	assert.Empty(t, s.pendingOps)
+}
+
+func TestStream_SetLogger(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+
+	s := NewStream("", "", nil)
+	s.SetLogger(logger.WithField("strategy", "xmaker"))
+
+	_, ok := s.updateLocalBook(BookEvent{Symbol: "BTCUSDT", UpdateId: fixedpoint.NewFromInt(2), Type: DataTypeDelta})
+	assert.False(t, ok)
+
+	if entry := hook.LastEntry(); assert.NotNil(t, entry) {
+		assert.Equal(t, "xmaker", entry.Data["strategy"])
+		assert.Equal(t, "bybit", entry.Data["exchange"])
+		assert.Equal(t, "BTCUSDT", entry.Data["symbol"])
+	}
+}
+
+func TestStream_buildSubscriptionOps(t *testing.T) {
+	t.Run("chunk args", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		for i := 0; i < 25; i++ {
+			s.Subscribe(types.MarketTradeChannel, fmt.Sprintf("COIN%dUSDT", i), types.SubscribeOptions{})
+		}
+
+		ops, err := s.buildSubscriptionOps(WsOpTypeSubscribe)
+		assert.NoError(t, err)
+		if assert.Len(t, ops, 3) {
+			assert.Len(t, ops[0].Args, spotArgsLimit)
+			assert.Len(t, ops[1].Args, spotArgsLimit)
+			assert.Len(t, ops[2].Args, 5)
+			assert.Equal(t, "publicTrade.COIN24USDT", ops[2].Args[4])
+		}
+	})
+
+	t.Run("exceed the connection limit", func(t *testing.T) {
+		s := NewStream("", "", nil)
+		for i := 0; i < 2000; i++ {
+			s.Subscribe(types.MarketTradeChannel, fmt.Sprintf("COIN%dUSDT", i), types.SubscribeOptions{})
+		}
+
+		ops, err := s.buildSubscriptionOps(WsOpTypeSubscribe)
+		assert.ErrorContains(t, err, "exceed the limit")
+		assert.Empty(t, ops)
+	})
+}
+
+func TestStream_rawTopicMessage(t *testing.T) {
+	s := NewStream("", "", nil)
+
+	var topics []string
+	var payloads []string
+	s.OnRawTopicMessage(func(topic string, data json.RawMessage) {
+		topics = append(topics, topic)
+		payloads = append(payloads, string(data))
+	})
+
+	input := `{"topic":"newTopic.BTCUSDT","type":"snapshot","ts":1700000000000,"data":{"foo":"bar"}}`
+	event, err := s.parseWebSocketEvent([]byte(input))
+	assert.NoError(t, err)
+	s.dispatchEvent(event)
+
+	assert.Equal(t, []string{"newTopic.BTCUSDT"}, topics)
+	assert.Equal(t, []string{`{"foo":"bar"}`}, payloads)
+}
+
+func TestStream_perTopicCallbacks(t *testing.T) {
+	s := NewStream("", "", nil)
+
+	// multiple handlers can be attached to the same topic
+	var klineEvents []string
+	s.OnKLineEvent(func(e KLineEvent) {
+		klineEvents = append(klineEvents, "first "+e.Symbol)
+	})
+	s.OnKLineEvent(func(e KLineEvent) {
+		klineEvents = append(klineEvents, "second "+e.Symbol)
+	})
+
+	var numOfOtherEvents int
+	s.OnBookEvent(func(e BookEvent) { numOfOtherEvents++ })
+	s.OnMarketTradeEvent(func(e []MarketTradeEvent) { numOfOtherEvents++ })
+	s.OnOrderEvent(func(e []OrderEvent) { numOfOtherEvents++ })
+
+	event, err := s.parse([]byte(`{
+    "topic": "kline.5.BTCUSDT",
+    "data": [
+        {
+            "start": 1672324800000,
+            "end": 1672325099999,
+            "interval": "5",
+            "open": "16649.5",
+            "close": "16677",
+            "high": "16677",
+            "low": "16608",
+            "volume": "2.081",
+            "turnover": "34666.4005",
+            "confirm": false,
+            "timestamp": 1672324988882
+        }
+    ],
+    "ts": 1672324988882,
+    "type": "snapshot"
+}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the event is only dispatched to the handlers of its topic
+	s.dispatchEvent(event)
+	assert.Equal(t, []string{"first BTCUSDT", "second BTCUSDT"}, klineEvents)
+	assert.Equal(t, 0, numOfOtherEvents)
+}
+
+func TestStream_OnTickerEvent(t *testing.T) {
+	s := NewStream("", "", nil)
+
+	var tickers []TickerEvent
+	s.OnTickerEvent(func(e TickerEvent) {
+		tickers = append(tickers, e)
+	})
+
+	for _, input := range []string{
+		// the delta before the snapshot is dropped
+		`{"topic":"tickers.BTCUSDT","type":"delta","ts":1700000000000,"data":{"symbol":"BTCUSDT","lastPrice":"30000"}}`,
+		`{"topic":"tickers.BTCUSDT","type":"snapshot","ts":1700000001000,"data":{"symbol":"BTCUSDT","lastPrice":"30000","markPrice":"30001","fundingRate":"0.0001","bid1Price":"29999","ask1Price":"30000.5"}}`,
+		`{"topic":"tickers.BTCUSDT","type":"delta","ts":1700000002000,"data":{"symbol":"BTCUSDT","markPrice":"30002","fundingRate":"0"}}`,
+	} {
+		event, err := s.parseWebSocketEvent([]byte(input))
+		assert.NoError(t, err)
+		s.dispatchEvent(event)
+	}
+
+	if assert.Len(t, tickers, 2) {
+		assert.Equal(t, DataTypeSnapshot, tickers[0].Type)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.0001), tickers[0].FundingRate)
+
+		// the fields missing from the delta keep the values of the snapshot
+		delta := tickers[1]
+		assert.Equal(t, DataTypeDelta, delta.Type)
+		assert.Equal(t, int64(1700000002000), delta.ServerTime.UnixMilli())
+		assert.Equal(t, fixedpoint.NewFromInt(30000), delta.LastPrice)
+		assert.Equal(t, fixedpoint.NewFromInt(30002), delta.MarkPrice)
+		assert.Equal(t, fixedpoint.Zero, delta.FundingRate)
+		assert.Equal(t, fixedpoint.NewFromInt(29999), delta.Bid1Price)
+		assert.Equal(t, fixedpoint.NewFromFloat(30000.5), delta.Ask1Price)
+	}
+}
+
+func TestStream_EnableDemoTrading(t *testing.T) {
+	s := NewStream("key", "secret", nil)
+	s.EnableDemoTrading()
+
+	endpoint, err := s.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsDemoTradingPrivateUrl, endpoint)
+
+	s.SetPublicOnly()
+	endpoint, err = s.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsSpotPublicSpotUrl, endpoint)
+}
+
+func TestStream_EnableTestnet(t *testing.T) {
+	s := NewStream("key", "secret", nil)
+	s.EnableTestnet()
+
+	endpoint, err := s.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsTestnetPrivateUrl, endpoint)
+
+	s.SetPublicOnly()
+	endpoint, err = s.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsTestnetPublicSpotUrl, endpoint)
+
+	assert.NoError(t, s.SetCategory(bybitapi.CategoryLinear))
+	endpoint, err = s.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsTestnetPublicLinearUrl, endpoint)
+}
+
+func TestStream_SetTopicQueue(t *testing.T) {
+	s := NewStream("", "", nil)
+	assert.Error(t, s.SetTopicQueue(TopicTypeKLine, 0, QueuePolicyBlock))
+	assert.Error(t, s.SetTopicQueue(TopicTypeKLine, 1, QueuePolicy(99)))
+	assert.NoError(t, s.SetTopicQueue(TopicTypeKLine, 1, QueuePolicyDropNewest))
+	assert.Error(t, s.SetTopicQueue(TopicTypeKLine, 1, QueuePolicyBlock))
+	assert.NoError(t, s.Close())
+}
+
+func TestStream_topicQueue(t *testing.T) {
+	newKLineEvent := func(startTime int64) *KLineEvent {
+		return &KLineEvent{
+			Symbol: "BTCUSDT",
+			KLines: []KLine{{StartTime: types.NewMillisecondTimestampFromInt(startTime)}},
+		}
+	}
+
+	run := func(t *testing.T, policy QueuePolicy) ([]int64, StreamStats) {
+		s := NewStream("", "", nil)
+		defer s.Close()
+		assert.NoError(t, s.SetTopicQueue(TopicTypeKLine, 2, policy))
+
+		release := make(chan struct{})
+		started := make(chan struct{}, 10)
+		var mu sync.Mutex
+		var startTimes []int64
+		s.OnKLineEvent(func(e KLineEvent) {
+			started <- struct{}{}
+			<-release
+
+			mu.Lock()
+			startTimes = append(startTimes, e.KLines[0].StartTime.Time().UnixMilli())
+			mu.Unlock()
+		})
+
+		var books int
+		s.OnBookEvent(func(e BookEvent) {
+			books++
+		})
+
+		// the first event is taken by the slow handler, then the queue is full after the next two events.
+		s.dispatchEvent(newKLineEvent(1))
+		<-started
+		for i := int64(2); i <= 5; i++ {
+			s.dispatchEvent(newKLineEvent(i))
+		}
+
+		// the slow kline handler doesn't stall the other topics
+		s.dispatchEvent(&BookEvent{Symbol: "BTCUSDT"})
+		assert.Equal(t, 1, books)
+
+		close(release)
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(startTimes) == 3
+		}, time.Second, time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		return startTimes, s.Stats()
+	}
+
+	t.Run("drop newest", func(t *testing.T) {
+		startTimes, stats := run(t, QueuePolicyDropNewest)
+		assert.Equal(t, []int64{1, 2, 3}, startTimes)
+		assert.Equal(t, uint64(2), stats.Topics[TopicTypeKLine].Dropped)
+		assert.Zero(t, stats.Topics[TopicTypeOrderBook].Dropped)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		startTimes, stats := run(t, QueuePolicyDropOldest)
+		assert.Equal(t, []int64{1, 4, 5}, startTimes)
+		assert.Equal(t, uint64(2), stats.Topics[TopicTypeKLine].Dropped)
+	})
+}
+
+func TestStream_topicQueue_block(t *testing.T) {
+	s := NewStream("", "", nil)
+	defer s.Close()
+	assert.NoError(t, s.SetTopicQueue(TopicTypeOrderBook, 1, QueuePolicyBlock))
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	var count int64
+	s.OnBookEvent(func(e BookEvent) {
+		started <- struct{}{}
+		<-release
+		atomic.AddInt64(&count, 1)
+	})
+
+	s.dispatchEvent(&BookEvent{Symbol: "BTCUSDT"})
+	<-started
+	s.dispatchEvent(&BookEvent{Symbol: "BTCUSDT"})
+
+	dispatched := make(chan struct{})
+	go func() {
+		s.dispatchEvent(&BookEvent{Symbol: "BTCUSDT"})
+		close(dispatched)
+	}()
+
+	select {
+	case <-dispatched:
+		t.Fatal("the dispatch should be blocked by the full queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-dispatched
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&count) == 3
+	}, time.Second, time.Millisecond)
+	assert.Zero(t, s.Stats().Topics[TopicTypeOrderBook].Dropped)
+}
+
+func TestStream_SetSubscriptions(t *testing.T) {
+	received := make(chan WebsocketOp, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var op WebsocketOp
+			if err := conn.ReadJSON(&op); err != nil {
+				return
+			}
+			received <- op
+		}
+	}))
+	defer server.Close()
+
+	book := func(symbol string) types.Subscription {
+		return types.Subscription{Channel: types.BookChannel, Symbol: symbol, Options: types.SubscribeOptions{Depth: types.DepthLevel50}}
+	}
+
+	s := NewStream("", "", nil)
+	s.Subscribe(types.BookChannel, "AAAUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	s.Subscribe(types.BookChannel, "BBBUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	s.Subscribe(types.BookChannel, "CCCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+
+	// nothing is changed if a subscription is invalid
+	err := s.SetSubscriptions([]types.Subscription{book("BBBUSDT"), {Channel: types.KLineChannel, Symbol: "BBBUSDT", Options: types.SubscribeOptions{Interval: "3h"}}})
+	assert.ErrorContains(t, err, "interval not supported")
+	assert.Len(t, s.GetSubscriptions(), 3)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	s.Conn = conn
+
+	// the duplicated subscription is dropped
+	newSubs := []types.Subscription{book("BBBUSDT"), book("CCCUSDT"), book("DDDUSDT"), book("DDDUSDT")}
+	assert.NoError(t, s.SetSubscriptions(newSubs))
+	assert.Equal(t, newSubs[:3], s.GetSubscriptions())
+
+	var ops []WebsocketOp
+	for len(ops) < 2 {
+		select {
+		case op := <-received:
+			ops = append(ops, op)
+		case <-time.After(time.Second):
+			t.Fatalf("the ops are not received, got %+v", ops)
+		}
+	}
+
+	// only the diff is sent, the removed topic first
+	assert.Equal(t, []WebsocketOp{
+		{ReqId: "unsubscribe-1", Op: WsOpTypeUnsubscribe, Args: []string{"orderbook.50.AAAUSDT"}},
+		{ReqId: "subscribe-2", Op: WsOpTypeSubscribe, Args: []string{"orderbook.50.DDDUSDT"}},
+	}, ops)
+
+	// the same subscriptions send nothing
+	assert.NoError(t, s.SetSubscriptions(newSubs))
+	select {
+	case op := <-received:
+		t.Fatalf("unexpected op: %+v", op)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestStream_handleKLineEvent(t *testing.T) {
+	s := NewStream("", "", nil)
+
+	var updates, closed []types.KLine
+	s.OnKLine(func(kline types.KLine) {
+		updates = append(updates, kline)
+	})
+	s.OnKLineClosed(func(kline types.KLine) {
+		closed = append(closed, kline)
+	})
+
+	newKLine := func(close float64, confirm bool) KLine {
+		return KLine{
+			StartTime:  types.NewMillisecondTimestampFromInt(1672324800000),
+			EndTime:    types.NewMillisecondTimestampFromInt(1672325099999),
+			Interval:   "5",
+			ClosePrice: fixedpoint.NewFromFloat(close),
+			Confirm:    confirm,
+		}
+	}
+
+	s.handleKLineEvent(KLineEvent{Symbol: "BTCUSDT", Type: DataTypeSnapshot, KLines: []KLine{newKLine(100, false)}})
+	s.handleKLineEvent(KLineEvent{Symbol: "BTCUSDT", Type: DataTypeSnapshot, KLines: []KLine{newKLine(101, false)}})
+	s.handleKLineEvent(KLineEvent{Symbol: "BTCUSDT", Type: DataTypeSnapshot, KLines: []KLine{newKLine(102, true)}})
+
+	// every update including the closing one fires OnKLine, only the closing one fires OnKLineClosed
+	if assert.Len(t, updates, 3) {
+		assert.False(t, updates[0].Closed)
+		assert.True(t, updates[2].Closed)
+	}
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, "BTCUSDT", closed[0].Symbol)
+		assert.Equal(t, types.Interval5m, closed[0].Interval)
+		assert.Equal(t, fixedpoint.NewFromFloat(102), closed[0].Close)
+		assert.True(t, closed[0].Closed)
+	}
+
+	// the mark price k line is not emitted to the trade price k line callbacks
+	s.handleKLineEvent(KLineEvent{Symbol: "BTCUSDT", Type: DataTypeSnapshot, PriceSource: KLinePriceSourceMark, KLines: []KLine{newKLine(103, true)}})
+	assert.Len(t, updates, 3)
+	assert.Len(t, closed, 1)
+}
+
+func TestStream_SetSubscribeTimeout(t *testing.T) {
+	received := make(chan WebsocketOp, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		// never acknowledge
+		for {
+			var op WebsocketOp
+			if err := conn.ReadJSON(&op); err != nil {
+				return
+			}
+			received <- op
+		}
+	}))
+	defer server.Close()
+
+	s := NewStream("", "", nil)
+	assert.Error(t, s.SetSubscribeTimeout(-time.Second, 0))
+	assert.Error(t, s.SetSubscribeTimeout(time.Second, -1))
+	assert.NoError(t, s.SetSubscribeTimeout(50*time.Millisecond, 1))
+
+	errC := make(chan SubscriptionErrorEvent, 10)
+	s.OnSubscriptionError(func(e SubscriptionErrorEvent) {
+		errC <- e
+	})
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	s.Conn = conn
+
+	s.Subscribe(types.BookChannel, "BADSYMBOL", types.SubscribeOptions{Depth: types.DepthLevel50})
+	assert.NoError(t, s.syncSubscriptions(WsOpTypeSubscribe))
+
+	// the op is retried once with a new req id, then it's failed
+	for _, reqId := range []string{"subscribe-1", "subscribe-2"} {
+		select {
+		case op := <-received:
+			assert.Equal(t, WebsocketOp{ReqId: reqId, Op: WsOpTypeSubscribe, Args: []string{"orderbook.50.BADSYMBOL"}}, op)
+		case <-time.After(time.Second):
+			t.Fatalf("the op %s is not received", reqId)
+		}
+	}
+
+	select {
+	case e := <-errC:
+		assert.Equal(t, "subscribe-2", e.ReqId)
+		assert.Equal(t, []string{"orderbook.50.BADSYMBOL"}, e.Topics)
+		assert.ErrorIs(t, e.Err, ErrSubscribeTimeout)
+	case <-time.After(time.Second):
+		t.Fatal("the subscription error is not emitted")
+	}
+
+	// the acknowledged op is neither retried nor failed
+	assert.NoError(t, s.sendTopicOps(WsOpTypeSubscribe, []string{"orderbook.50.BTCUSDT"}))
+	_, err = s.parse([]byte(`{"success":true,"ret_msg":"","conn_id":"cm","req_id":"subscribe-3","op":"subscribe"}`))
+	assert.NoError(t, err)
+
+	// the rejected op is failed without waiting for the timeout
+	assert.NoError(t, s.sendTopicOps(WsOpTypeSubscribe, []string{"orderbook.50.UNKNOWN"}))
+	_, err = s.parse([]byte(`{"success":false,"ret_msg":"error:handler not found,topic:orderbook.50.UNKNOWN","conn_id":"cm","req_id":"subscribe-4","op":"subscribe"}`))
+	assert.ErrorContains(t, err, "unexpected response result")
+
+	select {
+	case e := <-errC:
+		assert.Equal(t, "subscribe-4", e.ReqId)
+		assert.ErrorContains(t, e.Err, "handler not found")
+	case <-time.After(time.Second):
+		t.Fatal("the subscription error is not emitted")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, errC)
+	s.pendingOpsMu.Lock()
+	assert.Empty(t, s.pendingOps)
+	s.pendingOpsMu.Unlock()
+}
+
+func TestStream_bookLatency(t *testing.T) {
+	s := NewStream("", "", nil)
+	assert.Zero(t, s.Stats().BookLatency)
+
+	serverTime := time.UnixMilli(1691130685111)
+	for i := 1; i <= bookLatencyWindow+100; i++ {
+		s.handleBookEvent(BookEvent{
+			Symbol:       "BTCUSDT",
+			Type:         DataTypeDelta,
+			UpdateId:     fixedpoint.NewFromInt(int64(i)),
+			SequenceId:   fixedpoint.NewFromInt(int64(i)),
+			ServerTime:   serverTime,
+			ReceivedTime: serverTime.Add(time.Duration(i) * time.Millisecond),
+		})
+	}
+
+	// only the latest updates are in the window
+	latency := s.Stats().BookLatency
+	assert.Equal(t, LatencyStats{
+		Samples: bookLatencyWindow,
+		Last:    1100 * time.Millisecond,
+		P50:     600 * time.Millisecond,
+		P99:     1090 * time.Millisecond,
+		Max:     1100 * time.Millisecond,
+	}, latency)
+
+	// the clock offset is corrected, and the event not received by the stream is skipped
+	s = NewStream("", "", nil)
+	s.SetClockOffset(-20 * time.Millisecond)
+	s.handleBookEvent(BookEvent{Symbol: "BTCUSDT", UpdateId: fixedpoint.One, ServerTime: serverTime, ReceivedTime: serverTime.Add(50 * time.Millisecond)})
+	s.handleBookEvent(BookEvent{Symbol: "BTCUSDT", UpdateId: fixedpoint.NewFromInt(2), ServerTime: serverTime})
+	assert.Equal(t, LatencyStats{Samples: 1, Last: 30 * time.Millisecond, P50: 30 * time.Millisecond, P99: 30 * time.Millisecond, Max: 30 * time.Millisecond}, s.Stats().BookLatency)
+}
+
+type kLineProviderFunc func(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error)
+
+func (f kLineProviderFunc) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	return f(ctx, symbol, interval, options)
+}
+
+func TestStream_SetKLineBackfill(t *testing.T) {
+	s := NewStream("", "", nil)
+	assert.Error(t, s.SetKLineBackfill(nil, 10))
+
+	now := time.Now().Truncate(5 * time.Minute)
+	newKLine := func(start time.Time) types.KLine {
+		return types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval5m,
+			StartTime: types.Time(start),
+			EndTime:   types.Time(start.Add(5*time.Minute - time.Millisecond)),
+		}
+	}
+
+	var queried []types.KLineQueryOptions
+	provider := kLineProviderFunc(func(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+		queried = append(queried, options)
+		if symbol == "ETHUSDT" {
+			return nil, errors.New("query failed")
+		}
+
+		// the last k line is still open
+		var kLines []types.KLine
+		for i := options.Limit - 1; i >= 0; i-- {
+			kLines = append(kLines, newKLine(now.Add(-time.Duration(i)*5*time.Minute)))
+		}
+		return kLines, nil
+	})
+	assert.Error(t, s.SetKLineBackfill(provider, 0))
+	assert.Error(t, s.SetKLineBackfill(provider, 1001))
+	assert.NoError(t, s.SetKLineBackfill(provider, 2))
+
+	var closed []types.KLine
+	s.OnKLineClosed(func(kline types.KLine) {
+		closed = append(closed, kline)
+	})
+
+	s.Subscribe(types.KLineChannel, "ETHUSDT", types.SubscribeOptions{Interval: types.Interval5m})
+	s.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: types.Interval5m})
+	s.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	s.backfillKLines()
+
+	// the failed query is skipped, and the open k line is not backfilled
+	if assert.Len(t, queried, 2) {
+		assert.Equal(t, 3, queried[1].Limit)
+		assert.NotNil(t, queried[1].EndTime)
+	}
+	if assert.Len(t, closed, 2) {
+		assert.Equal(t, now.Add(-10*time.Minute), closed[0].StartTime.Time())
+		assert.Equal(t, now.Add(-5*time.Minute), closed[1].StartTime.Time())
+		assert.True(t, closed[1].Closed)
+	}
+
+	// the backfilled k lines are not emitted again by the reconnection
+	s.backfillKLines()
+	assert.Len(t, closed, 2)
+
+	// the live closed k line which is backfilled already is skipped, and the next one is emitted
+	newLiveKLine := func(start time.Time) KLine {
+		return KLine{
+			StartTime: types.NewMillisecondTimestampFromInt(start.UnixMilli()),
+			EndTime:   types.NewMillisecondTimestampFromInt(start.Add(5*time.Minute - time.Millisecond).UnixMilli()),
+			Interval:  "5",
+			Confirm:   true,
+		}
+	}
+	s.handleKLineEvent(KLineEvent{Symbol: "BTCUSDT", Type: DataTypeSnapshot, KLines: []KLine{newLiveKLine(now.Add(-5 * time.Minute))}})
+	assert.Len(t, closed, 2)
+	s.handleKLineEvent(KLineEvent{Symbol: "BTCUSDT", Type: DataTypeSnapshot, KLines: []KLine{newLiveKLine(now)}})
+	if assert.Len(t, closed, 3) {
+		assert.Equal(t, now, closed[2].StartTime.Time())
+	}
+}
+
+func TestStream_SetTopicWatchdog(t *testing.T) {
+	s := NewStream("", "", nil)
+	s.SetPublicOnly()
+
+	var alerts []string
+	alerter := notifier.NotifierFunc(func(channel, format string, args ...interface{}) error {
+		assert.Empty(t, channel)
+		alerts = append(alerts, fmt.Sprintf(format, args...))
+		return nil
+	})
+	assert.Error(t, s.SetTopicWatchdog(nil, map[TopicType]time.Duration{TopicTypeOrderBook: time.Second}, true))
+	assert.Error(t, s.SetTopicWatchdog(alerter, nil, true))
+	assert.Error(t, s.SetTopicWatchdog(alerter, map[TopicType]time.Duration{TopicTypeOrderBook: 0}, true))
+	assert.NoError(t, s.SetTopicWatchdog(alerter, map[TopicType]time.Duration{
+		TopicTypeOrderBook: 2 * time.Second,
+		TopicTypeWallet:    time.Hour,
+	}, true))
+	assert.Equal(t, time.Second, s.topicWatchdog.interval())
+
+	s.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevel50})
+	s.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: types.Interval1m})
+
+	// nothing is checked before the connection
+	now := time.Now()
+	s.checkTopics(now.Add(time.Hour))
+	assert.Empty(t, alerts)
+
+	s.topicWatchdog.connect(s.subscribedTopicTypes(), now)
+	s.stats.recordTopicMessage(TopicTypeOrderBook, 10, now.Add(time.Second))
+	s.checkTopics(now.Add(3 * time.Second))
+	assert.Empty(t, alerts)
+
+	// the kline topic has no threshold, and the wallet topic is not subscribed
+	s.checkTopics(now.Add(4 * time.Second))
+	assert.Equal(t, []string{"bybit: the orderbook topic is silent for 3s, threshold: 2s"}, alerts)
+	select {
+	case <-s.ReconnectC:
+	default:
+		t.Fatal("the stream is not reconnected")
+	}
+
+	// the same silence is alerted once
+	s.checkTopics(now.Add(time.Minute))
+	assert.Len(t, alerts, 1)
+
+	s.stats.recordTopicMessage(TopicTypeOrderBook, 10, now.Add(time.Minute))
+	s.checkTopics(now.Add(time.Minute + 3*time.Second))
+	assert.Len(t, alerts, 2)
+
+	// the silence is counted from the reconnection
+	s.topicWatchdog.disconnect()
+	s.checkTopics(now.Add(time.Hour))
+	assert.Len(t, alerts, 2)
+	s.topicWatchdog.connect(s.subscribedTopicTypes(), now.Add(time.Hour))
+	s.checkTopics(now.Add(time.Hour + time.Second))
+	assert.Len(t, alerts, 2)
+	s.checkTopics(now.Add(time.Hour + 3*time.Second))
+	assert.Len(t, alerts, 3)
+}
+
+type fakeOrderStatusProvider struct {
+	openOrders, histories []bybitapi.Order
+	executions            []bybitapi.Execution
+	startTimes            []time.Time
+}
+
+func (p *fakeOrderStatusProvider) QueryAllOpenOrders(ctx context.Context, category bybitapi.Category, symbol string) ([]bybitapi.Order, error) {
+	return p.openOrders, nil
+}
+
+func (p *fakeOrderStatusProvider) QueryOrderHistories(ctx context.Context, category bybitapi.Category, symbol string, limit int) ([]bybitapi.Order, error) {
+	return p.histories, nil
+}
+
+func (p *fakeOrderStatusProvider) QueryExecutions(ctx context.Context, category bybitapi.Category, symbol string, startTime, endTime time.Time) ([]bybitapi.Execution, error) {
+	p.startTimes = append(p.startTimes, startTime)
+	return p.executions, nil
+}
+
+func TestStream_SetOrderPoller(t *testing.T) {
+	s := NewStream("", "", nil)
+	provider := &fakeOrderStatusProvider{}
+	assert.Error(t, s.SetOrderPoller(nil, time.Second))
+	assert.Error(t, s.SetOrderPoller(provider, 0))
+	assert.NoError(t, s.SetOrderPoller(provider, time.Second))
+
+	var orders []types.Order
+	s.OnOrderUpdate(func(order types.Order) {
+		orders = append(orders, order)
+	})
+	var trades []types.Trade
+	s.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	now := time.Now()
+	newOrder := func(id string, status bybitapi.OrderStatus, cumExecQty fixedpoint.Value, updatedTime time.Time) bybitapi.Order {
+		return bybitapi.Order{
+			OrderId:     id,
+			Symbol:      "BTCUSDT",
+			Side:        bybitapi.SideBuy,
+			OrderType:   bybitapi.OrderTypeLimit,
+			TimeInForce: bybitapi.TimeInForceGTC,
+			OrderStatus: status,
+			Qty:         fixedpoint.One,
+			CumExecQty:  cumExecQty,
+			CreatedTime: types.MillisecondTimestamp(now.Add(-time.Hour)),
+			UpdatedTime: types.MillisecondTimestamp(updatedTime),
+		}
+	}
+
+	// the order delivered by the stream already is not emitted again
+	delivered := newOrder("1", bybitapi.OrderStatusNew, fixedpoint.Zero, now.Add(-2*time.Second))
+	s.handleOrderEvent([]OrderEvent{{Order: delivered, Category: bybitapi.CategorySpot}})
+	assert.Len(t, orders, 1)
+
+	provider.openOrders = []bybitapi.Order{delivered}
+	provider.histories = []bybitapi.Order{
+		newOrder("2", bybitapi.OrderStatusFilled, fixedpoint.One, now.Add(-time.Second)),
+		newOrder("3", bybitapi.OrderStatusCancelled, fixedpoint.Zero, now.Add(-3*time.Second)),
+	}
+	provider.executions = []bybitapi.Execution{{
+		Symbol:    "BTCUSDT",
+		OrderId:   "2",
+		Side:      bybitapi.SideBuy,
+		OrderType: bybitapi.OrderTypeLimit,
+		ExecId:    "100",
+		ExecPrice: fixedpoint.NewFromInt(30000),
+		ExecQty:   fixedpoint.One,
+		IsMaker:   true,
+		ExecTime:  types.MillisecondTimestamp(now.Add(-time.Second)),
+	}}
+
+	// the polling pauses while the stream is healthy
+	s.orderPoller.setAuthenticated(true)
+	s.stats.recordMessage(10, now)
+	assert.True(t, s.orderPoller.healthy(s.Stats(), now.Add(time.Second)))
+	assert.False(t, s.orderPoller.healthy(s.Stats(), now.Add(2*time.Minute)))
+	s.orderPoller.setAuthenticated(false)
+	assert.False(t, s.orderPoller.healthy(s.Stats(), now.Add(time.Second)))
+
+	assert.NoError(t, s.pollOrders(context.Background(), now.Add(2*time.Minute)))
+	// in ascending order by the update time
+	if assert.Len(t, orders, 3) {
+		assert.Equal(t, uint64(3), orders[1].OrderID)
+		assert.Equal(t, uint64(2), orders[2].OrderID)
+	}
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, uint64(100), trades[0].ID)
+	}
+	// the executions are queried from the last healthy time
+	if assert.Len(t, provider.startTimes, 1) {
+		assert.Equal(t, now.Add(time.Second-orderPollOverlap), provider.startTimes[0])
+	}
+
+	// nothing is emitted twice, and the next query starts from the last poll
+	assert.NoError(t, s.pollOrders(context.Background(), now.Add(3*time.Minute)))
+	assert.Len(t, orders, 3)
+	assert.Len(t, trades, 1)
+	if assert.Len(t, provider.startTimes, 2) {
+		assert.Equal(t, now.Add(2*time.Minute-orderPollOverlap), provider.startTimes[1])
+	}
+}
+
+func TestStream_OnConditionalOrderUpdate(t *testing.T) {
+	s := NewStream("", "", nil)
+
+	var orders []ConditionalOrder
+	s.OnConditionalOrderUpdate(func(order ConditionalOrder) {
+		orders = append(orders, order)
+	})
+
+	order := bybitapi.Order{
+		OrderId:     "1",
+		Symbol:      "BTCUSDT",
+		Side:        bybitapi.SideSell,
+		OrderType:   bybitapi.OrderTypeMarket,
+		TimeInForce: bybitapi.TimeInForceGTC,
+		OrderStatus: bybitapi.OrderStatusUntriggered,
+		Qty:         fixedpoint.One,
+		UpdatedTime: types.MillisecondTimestamp(time.UnixMilli(1700000000000)),
+	}
+	// the plain order is not emitted
+	s.handleOrderEvent([]OrderEvent{{Order: order, Category: bybitapi.CategorySpot}})
+	assert.Empty(t, orders)
+
+	order.OrderId = "2"
+	order.TriggerPrice = fixedpoint.NewFromInt(29500)
+	order.TriggerBy = bybitapi.TriggerByLastPrice
+	s.handleOrderEvent([]OrderEvent{{Order: order, Category: bybitapi.CategorySpot}})
+	order.OrderStatus = bybitapi.OrderStatusTriggered
+	order.UpdatedTime = types.MillisecondTimestamp(time.UnixMilli(1700000001000))
+	s.handleOrderEvent([]OrderEvent{{Order: order, Category: bybitapi.CategorySpot}})
+
+	if assert.Len(t, orders, 2) {
+		assert.True(t, orders[0].IsUntriggered())
+		assert.Equal(t, types.OrderTypeStopMarket, orders[0].Type)
+		assert.Equal(t, TriggerStateTriggered, orders[1].TriggerState)
+		assert.Equal(t, bybitapi.TriggerByLastPrice, orders[1].TriggerBy)
+	}
+}
+
+func TestStream_handleOrderEvent_category(t *testing.T) {
+	order := bybitapi.Order{
+		OrderId:     "1",
+		Symbol:      "BTCUSDT",
+		Side:        bybitapi.SideBuy,
+		OrderType:   bybitapi.OrderTypeLimit,
+		TimeInForce: bybitapi.TimeInForceGTC,
+		OrderStatus: bybitapi.OrderStatusNew,
+		Qty:         fixedpoint.One,
+		Price:       fixedpoint.NewFromInt(30000),
+		UpdatedTime: types.MillisecondTimestamp(time.UnixMilli(1700000000000)),
+	}
+	linearOrder := order
+	linearOrder.OrderId = "2"
+
+	run := func(s *Stream) (orderIDs []string) {
+		s.OnOrderUpdate(func(order types.Order) {
+			orderIDs = append(orderIDs, order.UUID)
+		})
+		// the orders of the other categories are skipped, not the rest of the event
+		s.handleOrderEvent([]OrderEvent{
+			{Order: linearOrder, Category: bybitapi.CategoryLinear},
+			{Order: order, Category: bybitapi.CategorySpot},
+		})
+		return orderIDs
+	}
+
+	assert.Equal(t, []string{"1"}, run(NewStream("", "", nil)))
+
+	s := NewStream("", "", nil)
+	assert.NoError(t, s.SetCategory(bybitapi.CategoryLinear))
+	assert.Equal(t, []string{"2"}, run(s))
+}
+
+func TestStream_OnConditionalOrderUpdate_linear(t *testing.T) {
+	message := func(orderStatus bybitapi.OrderStatus, updatedTime int64) []byte {
+		return []byte(fmt.Sprintf(`{
+  "id": "5923240c6880ab-c59f-420b-9adb-3639adc9dd90",
+  "topic": "order",
+  "creationTime": 1700000000000,
+  "data": [
+    {
+      "category": "linear",
+      "symbol": "BTCUSDT",
+      "orderId": "1",
+      "orderLinkId": "stop-loss",
+      "side": "Sell",
+      "orderType": "Market",
+      "price": "0",
+      "qty": "0.01",
+      "timeInForce": "IOC",
+      "orderStatus": "%s",
+      "cumExecQty": "0",
+      "cumExecValue": "0",
+      "avgPrice": "",
+      "cumExecFee": "0",
+      "reduceOnly": true,
+      "closeOnTrigger": true,
+      "stopOrderType": "StopLoss",
+      "triggerPrice": "29500",
+      "triggerBy": "MarkPrice",
+      "triggerDirection": 2,
+      "createdTime": "1700000000000",
+      "updatedTime": "%d"
+    }
+  ]
+}`, orderStatus, updatedTime))
+	}
+
+	s := NewStream("", "", nil)
+	assert.NoError(t, s.SetCategory(bybitapi.CategoryLinear))
+
+	var orders []ConditionalOrder
+	s.OnConditionalOrderUpdate(func(order ConditionalOrder) {
+		orders = append(orders, order)
+	})
+
+	for i, status := range []bybitapi.OrderStatus{
+		bybitapi.OrderStatusUntriggered,
+		bybitapi.OrderStatusTriggered,
+		bybitapi.OrderStatusDeactivated,
+	} {
+		event, err := s.parse(message(status, 1700000000000+int64(i)*1000))
+		if !assert.NoError(t, err) {
+			return
+		}
+		s.dispatchEvent(event)
+	}
+
+	if assert.Len(t, orders, 3) {
+		assert.Equal(t, []TriggerState{TriggerStateUntriggered, TriggerStateTriggered, TriggerStateDeactivated},
+			[]TriggerState{orders[0].TriggerState, orders[1].TriggerState, orders[2].TriggerState})
+		assert.Equal(t, "BTCUSDT", orders[0].Symbol)
+		assert.Equal(t, types.OrderTypeStopMarket, orders[0].Type)
+		assert.Equal(t, fixedpoint.NewFromInt(29500), orders[0].StopPrice)
+		assert.Equal(t, bybitapi.StopOrderType("StopLoss"), orders[0].StopOrderType)
+		assert.Equal(t, bybitapi.TriggerBy("MarkPrice"), orders[0].TriggerBy)
+		assert.Equal(t, bybitapi.TriggerDirectionFall, orders[0].TriggerDirection)
+	}
+}
+
+func TestStream_SetJSONDecoder(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "ws_*.json"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	std := NewStream("", "", nil)
+	iter := NewStream("", "", nil)
+	iter.SetJSONDecoder(JSONIterDecoder)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+
+		expected, err := std.parseWebSocketEvent(data)
+		assert.NoError(t, err, file)
+		actual, err := iter.parseWebSocketEvent(data)
+		assert.NoError(t, err, file)
+
+		// the receive time differs
+		if book, ok := actual.(*BookEvent); ok {
+			book.ReceivedTime = expected.(*BookEvent).ReceivedTime
+		}
+		assert.Equal(t, expected, actual, file)
+	}
+
+	_, err = iter.parseWebSocketEvent([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"delta","data":{"b":[["x"]]}}`))
+	assert.Error(t, err)
+}
+
+// newBookDeltaMessage returns the book delta message of the given depth on both sides.
+func newBookDeltaMessage(depth int) []byte {
+	levels := func(base float64, step float64) string {
+		var pvs []string
+		for i := 0; i < depth; i++ {
+			pvs = append(pvs, fmt.Sprintf(`["%.2f","%.6f"]`, base+step*float64(i), 0.001*float64(i+1)))
+		}
+		return "[" + strings.Join(pvs, ",") + "]"
+	}
+
+	return []byte(fmt.Sprintf(`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":1687940967466,"data":{"s":"BTCUSDT","b":%s,"a":%s,"u":177400507,"seq":66544703342},"cts":1687940967464}`,
+		levels(30000, -0.5), levels(30000.5, 0.5)))
+}
+
+func BenchmarkParseBookEvent(b *testing.B) {
+	msg := newBookDeltaMessage(50)
+	for _, c := range []struct {
+		name    string
+		decoder JSONDecoder
+	}{
+		{"encoding/json", StdJSONDecoder},
+		{"jsoniter", JSONIterDecoder},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			s := NewStream("", "", nil)
+			s.SetJSONDecoder(c.decoder)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(msg)))
+			for i := 0; i < b.N; i++ {
+				if _, err := s.parseWebSocketEvent(msg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStream_SetCategory(t *testing.T) {
+	s := NewStream("", "", nil)
+	s.SetPublicOnly()
+
+	url, err := s.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsSpotPublicSpotUrl, url)
+
+	assert.NoError(t, s.SetCategory(bybitapi.CategoryLinear))
+	url, err = s.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsPublicLinearUrl, url)
+
+	assert.NoError(t, s.SetCategory(bybitapi.CategoryInverse))
+	url, err = s.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsPublicInverseUrl, url)
+
+	assert.Error(t, s.SetCategory(bybitapi.CategoryOption))
+
+	// the private stream isn't affected
+	private := NewStream("", "", nil)
+	assert.NoError(t, private.SetCategory(bybitapi.CategoryLinear))
+	url, err = private.createEndpoint(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, bybitapi.WsSpotPrivateUrl, url)
 }
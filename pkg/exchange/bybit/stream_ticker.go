@@ -0,0 +1,45 @@
+package bybit
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// tickerState keeps the latest ticker of every symbol of the tickers topic, the deltas of the linear and the inverse
+// categories only carry the changed fields and are merged into it.
+type tickerState struct {
+	mu      sync.Mutex
+	tickers map[string]TickerEvent
+}
+
+func newTickerState() *tickerState {
+	return &tickerState{tickers: make(map[string]TickerEvent)}
+}
+
+// merge replaces the ticker of the symbol with the snapshot, or decodes the delta onto it, so the fields missing from
+// the delta keep their values. It returns false if the delta arrives before the snapshot of the symbol.
+func (s *tickerState) merge(e TickerEvent) (TickerEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e.Type != DataTypeDelta {
+		s.tickers[e.Symbol] = e
+		return e, true
+	}
+
+	ticker, ok := s.tickers[e.Symbol]
+	if !ok {
+		return TickerEvent{}, false
+	}
+
+	// the delta has been decoded by the parser, so the data is valid.
+	if err := json.Unmarshal(e.data, &ticker); err != nil {
+		return TickerEvent{}, false
+	}
+
+	ticker.Type = e.Type
+	ticker.ServerTime = e.ServerTime
+	ticker.data = e.data
+	s.tickers[e.Symbol] = ticker
+	return ticker, true
+}
@@ -0,0 +1,224 @@
+package bybit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/notifier"
+)
+
+// minTopicWatchdogInterval bounds how often the topic watchdog checks the topics.
+const minTopicWatchdogInterval = 100 * time.Millisecond
+
+// TopicStall is a subscribed topic type which receives no message beyond its threshold.
+type TopicStall struct {
+	Type      TopicType
+	Silence   time.Duration
+	Threshold time.Duration
+}
+
+// topicWatchdog alerts the subscribed topic types which go silent beyond their thresholds. The silence is counted
+// from the last message of the topic type or the connection, whichever is later, and every silence is alerted once
+// until the topic type receives a message again or the stream reconnects.
+type topicWatchdog struct {
+	notifier   notifier.Notifier
+	thresholds map[TopicType]time.Duration
+	reconnect  bool
+
+	mu sync.Mutex
+	// connectedAt is the time of the current connection, it's zero while disconnected.
+	connectedAt time.Time
+	// subscribed is the topic types subscribed on the current connection.
+	subscribed []TopicType
+	// alerted is the last message time of the alerted topic types, so the same silence is not alerted again.
+	alerted map[TopicType]time.Time
+	started bool
+}
+
+// interval returns the check interval, which is half of the smallest threshold.
+func (w *topicWatchdog) interval() time.Duration {
+	var interval time.Duration
+	for _, threshold := range w.thresholds {
+		if interval == 0 || threshold/2 < interval {
+			interval = threshold / 2
+		}
+	}
+
+	if interval < minTopicWatchdogInterval {
+		interval = minTopicWatchdogInterval
+	}
+	return interval
+}
+
+func (w *topicWatchdog) connect(subscribed []TopicType, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.connectedAt = now
+	w.subscribed = subscribed
+	w.alerted = make(map[TopicType]time.Time)
+}
+
+func (w *topicWatchdog) disconnect() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.connectedAt = time.Time{}
+}
+
+// check returns the newly stalled topic types of the subscribed ones in the order of the type.
+func (w *topicWatchdog) check(stats StreamStats, now time.Time) (stalls []TopicStall) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.connectedAt.IsZero() {
+		return nil
+	}
+
+	for _, topicType := range w.subscribed {
+		threshold, ok := w.thresholds[topicType]
+		if !ok {
+			continue
+		}
+
+		last := stats.Topics[topicType].LastMessageTime
+		since := last
+		if since.Before(w.connectedAt) {
+			since = w.connectedAt
+		}
+
+		silence := now.Sub(since)
+		if silence <= threshold {
+			continue
+		}
+
+		if alertedAt, ok := w.alerted[topicType]; ok && alertedAt.Equal(last) {
+			continue
+		}
+
+		w.alerted[topicType] = last
+		stalls = append(stalls, TopicStall{Type: topicType, Silence: silence, Threshold: threshold})
+	}
+
+	sort.Slice(stalls, func(i, j int) bool { return stalls[i].Type < stalls[j].Type })
+	return stalls
+}
+
+// SetTopicWatchdog alerts the subscribed topic types which receive no message beyond their thresholds to the default
+// channel of the notifier, e.g. the slack notifier, e.g. a short threshold for the frequent orderbook updates and a long one for the sparse wallet updates.
+// The topic types without the threshold are not watched. The silence is counted from the connection if the topic
+// type receives nothing after it, and it's alerted once until the topic type receives a message again. If reconnect
+// is true, the stream reconnects after the alert, which usually resumes the stalled subscriptions. It must be called
+// before Connect.
+func (s *Stream) SetTopicWatchdog(alerter notifier.Notifier, thresholds map[TopicType]time.Duration, reconnect bool) error {
+	if alerter == nil {
+		return fmt.Errorf("the notifier of the topic watchdog is nil")
+	}
+
+	if len(thresholds) == 0 {
+		return fmt.Errorf("the topic watchdog has no threshold")
+	}
+
+	copied := make(map[TopicType]time.Duration, len(thresholds))
+	for topicType, threshold := range thresholds {
+		if threshold <= 0 {
+			return fmt.Errorf("invalid threshold of the %s topic: %s, it must be positive", topicType, threshold)
+		}
+		copied[topicType] = threshold
+	}
+
+	s.topicWatchdog = &topicWatchdog{
+		notifier:   alerter,
+		thresholds: copied,
+		reconnect:  reconnect,
+		alerted:    make(map[TopicType]time.Time),
+	}
+	return nil
+}
+
+// subscribedTopicTypes returns the topic types subscribed by the stream.
+func (s *Stream) subscribedTopicTypes() []TopicType {
+	if !s.PublicOnly {
+		topicTypes := []TopicType{TopicTypeWallet, TopicTypeOrder, TopicTypeTrade}
+		if s.greeksEnabled {
+			topicTypes = append(topicTypes, TopicTypeGreeks)
+		}
+		return topicTypes
+	}
+
+	seen := map[TopicType]struct{}{}
+	var topicTypes []TopicType
+	for _, sub := range s.Subscriptions {
+		topic, err := s.convertSubscription(sub)
+		if err != nil {
+			continue
+		}
+
+		topicType := getTopicType(topic)
+		if _, ok := seen[topicType]; ok {
+			continue
+		}
+
+		seen[topicType] = struct{}{}
+		topicTypes = append(topicTypes, topicType)
+	}
+	return topicTypes
+}
+
+// startTopicWatchdog starts the watchdog on the first connection, it runs until the stream is closed. The subscribed
+// topic types are taken on every connection, since the subscriptions are re-sent by the reconnection.
+func (s *Stream) startTopicWatchdog() {
+	w := s.topicWatchdog
+	if w == nil {
+		return
+	}
+
+	w.connect(s.subscribedTopicTypes(), time.Now())
+
+	w.mu.Lock()
+	started := w.started
+	w.started = true
+	w.mu.Unlock()
+	if started {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.interval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.CloseC:
+				return
+
+			case now := <-ticker.C:
+				s.checkTopics(now)
+			}
+		}
+	}()
+}
+
+// checkTopics alerts the newly stalled topic types, and reconnects if it's enabled.
+func (s *Stream) checkTopics(now time.Time) {
+	w := s.topicWatchdog
+	stalls := w.check(s.Stats(), now)
+	if len(stalls) == 0 {
+		return
+	}
+
+	for _, stall := range stalls {
+		s.logger.Warnf("the %s topic is silent for %s, threshold: %s", stall.Type, stall.Silence, stall.Threshold)
+		if err := w.notifier.NotifyChannel("", "bybit: the %s topic is silent for %s, threshold: %s",
+			stall.Type, stall.Silence.Truncate(time.Second), stall.Threshold); err != nil {
+			s.logger.WithError(err).Errorf("failed to notify the silent %s topic", stall.Type)
+		}
+	}
+
+	if w.reconnect {
+		s.logger.Warn("reconnecting since the topics are silent")
+		s.Reconnect()
+	}
+}
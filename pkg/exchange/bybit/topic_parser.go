@@ -0,0 +1,179 @@
+package bybit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+)
+
+// topicParser converts the data of a topic event to the typed event.
+type topicParser func(e *WebSocketTopicEvent) (interface{}, error)
+
+// topicParsers are the parsers by the topic type, the topics without a parser are returned as the rawTopicMessage.
+var topicParsers = map[TopicType]topicParser{
+	TopicTypeOrderBook:       parseBookEvent,
+	TopicTypeMarketTrade:     parseMarketTradeEvent,
+	TopicTypeKLine:           parseKLineEvent,
+	TopicTypeMarkPriceKLine:  parseKLineEvent,
+	TopicTypeIndexPriceKLine: parseKLineEvent,
+	TopicTypeLiquidation:     parseLiquidationEvent,
+	TopicTypeGreeks:          parseGreeksEvent,
+	TopicTypeTicker:          parseTickerEvent,
+	TopicTypeWallet:          parseWalletEvent,
+	TopicTypeOrder:           parseOrderEvent,
+	TopicTypeTrade:           parseTradeEvent,
+}
+
+// ParseWebSocketTopicEvent parses a topic message of the websocket into the typed event by the topic, e.g. *BookEvent
+// for the orderbook topic and []OrderEvent for the order topic. The message of an unsupported topic is returned as is,
+// and the op messages are rejected.
+func ParseWebSocketTopicEvent(data []byte) (interface{}, error) {
+	var e WebSocketTopicEvent
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	if len(e.Topic) == 0 {
+		return nil, errors.New("the message is not a topic event")
+	}
+
+	return parseTopicEvent(&e)
+}
+
+func parseTopicEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	parser, ok := topicParsers[getTopicType(e.Topic)]
+	if !ok {
+		// the topic is not supported yet, pass it to the raw topic message callbacks.
+		return &rawTopicMessage{
+			Topic: e.Topic,
+			Data:  e.Data,
+		}, nil
+	}
+
+	return parser(e)
+}
+
+// unmarshalTopicData decodes the data of the topic event into v, the error has the data and the name of the event.
+// The unknown fields of the data are rejected if the event is strict.
+func unmarshalTopicData(e *WebSocketTopicEvent, name string, v interface{}) error {
+	if err := decodeTopicData(e.decoder, e.Data, v, e.strict); err != nil {
+		return fmt.Errorf("failed to unmarshal data into %s: %+v, err: %w", name, string(e.Data), err)
+	}
+	return nil
+}
+
+// decodeTopicData decodes the data by the decoder, the strict mode always decodes by encoding/json since the unknown
+// fields can't be rejected by the JSONDecoder.
+func decodeTopicData(decoder JSONDecoder, data []byte, v interface{}, strict bool) error {
+	if !strict {
+		return orStdJSONDecoder(decoder).Unmarshal(data, v)
+	}
+
+	strictDecoder := json.NewDecoder(bytes.NewReader(data))
+	strictDecoder.DisallowUnknownFields()
+	return strictDecoder.Decode(v)
+}
+
+func parseBookEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	var book BookEvent
+	if err := unmarshalTopicData(e, "BookEvent", &book); err != nil {
+		return nil, err
+	}
+
+	book.Type = e.Type
+	book.ServerTime = e.Ts.Time()
+	return &book, nil
+}
+
+func parseMarketTradeEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	// snapshot only
+	var trades []MarketTradeEvent
+	if err := unmarshalTopicData(e, "MarketTradeEvent", &trades); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+func parseKLineEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	var kLines []KLine
+	if err := unmarshalTopicData(e, "KLine", &kLines); err != nil {
+		return nil, err
+	}
+
+	topic, err := ParseTopic(e.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KLineEvent{
+		KLines:      kLines,
+		PriceSource: toKLinePriceSource(topic.Type),
+		Symbol:      topic.Symbol,
+		Type:        e.Type,
+	}, nil
+}
+
+func parseLiquidationEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	var liquidation LiquidationEvent
+	if err := unmarshalTopicData(e, "LiquidationEvent", &liquidation); err != nil {
+		return nil, err
+	}
+
+	return &liquidation, nil
+}
+
+func parseTickerEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	var ticker TickerEvent
+	if err := unmarshalTopicData(e, "TickerEvent", &ticker); err != nil {
+		return nil, err
+	}
+
+	ticker.Type = e.Type
+	ticker.ServerTime = e.Ts.Time()
+	ticker.data = e.Data
+	return &ticker, nil
+}
+
+func parseGreeksEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	// snapshot only
+	var greeks []Greeks
+	if err := unmarshalTopicData(e, "GreeksEvent", &greeks); err != nil {
+		return nil, err
+	}
+
+	return &GreeksEvent{
+		Greeks: greeks,
+		Time:   e.CreationTime.Time(),
+	}, nil
+}
+
+func parseWalletEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	var wallets []bybitapi.WalletBalances
+	if err := unmarshalTopicData(e, "WalletBalances", &wallets); err != nil {
+		return nil, err
+	}
+
+	return newWalletEvent(wallets, e.CreationTime.Time()), nil
+}
+
+func parseOrderEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	var orders []OrderEvent
+	if err := unmarshalTopicData(e, "OrderEvent", &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func parseTradeEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	var trades []TradeEvent
+	if err := unmarshalTopicData(e, "TradeEvent", &trades); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
@@ -0,0 +1,297 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// topicPayload returns the part of the parsed event decoded from the data of the topic event, so it can be compared
+// with the wire data.
+func topicPayload(event interface{}) interface{} {
+	switch e := event.(type) {
+	case *BookEvent:
+		// the type and the server time are from the envelope of the topic event
+		return struct {
+			Symbol     string                 `json:"s"`
+			Bids       types.PriceVolumeSlice `json:"b"`
+			Asks       types.PriceVolumeSlice `json:"a"`
+			UpdateId   fixedpoint.Value       `json:"u"`
+			SequenceId fixedpoint.Value       `json:"seq"`
+		}{e.Symbol, e.Bids, e.Asks, e.UpdateId, e.SequenceId}
+
+	case *KLineEvent:
+		return e.KLines
+
+	case *GreeksEvent:
+		return e.Greeks
+
+	case *LiquidationEvent:
+		return *e
+
+	case *WalletEvent:
+		// the balances are flattened from the coins of the accounts, so only the keys of the account are checked
+		return struct {
+			AccountType bybitapi.AccountType `json:"accountType"`
+			Coins       []WalletCoinBalance  `json:"coin"`
+		}{e.Balances[0].AccountType, e.Balances}
+
+	default:
+		return e
+	}
+}
+
+// jsonKeys returns the keys of the json object, or the keys of the first object of the json array.
+func jsonKeys(t *testing.T, data []byte) map[string]struct{} {
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &objects); err != nil {
+		var object map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &object))
+		objects = append(objects, object)
+	}
+
+	require.NotEmpty(t, objects)
+	keys := make(map[string]struct{})
+	for key := range objects[0] {
+		keys[key] = struct{}{}
+	}
+	return keys
+}
+
+// assertTopicRoundTrip encodes the typed payload back to json and checks every field of it is in the wire data, so a
+// misspelled json tag, which is silently decoded to the zero value, is caught. The values are not compared since the
+// timestamps and the price volumes are not encoded in the wire format.
+func assertTopicRoundTrip(t *testing.T, data json.RawMessage, event interface{}) {
+	encoded, err := json.Marshal(topicPayload(event))
+	require.NoError(t, err)
+
+	wireKeys := jsonKeys(t, data)
+	for key := range jsonKeys(t, encoded) {
+		_, ok := wireKeys[key]
+		assert.True(t, ok, "the field %q is not in the wire data", key)
+	}
+}
+
+func TestParseWebSocketTopicEvent(t *testing.T) {
+	testCases := []struct {
+		file  string
+		check func(t *testing.T, event interface{})
+	}{
+		{
+			file: "ws_orderbook.json",
+			check: func(t *testing.T, event interface{}) {
+				book, ok := event.(*BookEvent)
+				require.True(t, ok)
+				assert.Equal(t, "BTCUSDT", book.Symbol)
+				assert.Equal(t, DataTypeSnapshot, book.Type)
+				assert.Len(t, book.Bids, 2)
+				assert.Len(t, book.Asks, 2)
+				assert.Equal(t, fixedpoint.MustNewFromString("16493.5"), book.Bids[0].Price)
+				assert.Equal(t, fixedpoint.NewFromInt(7961638724), book.SequenceId)
+				assert.Equal(t, int64(1672304484978), book.ServerTime.UnixMilli())
+			},
+		},
+		{
+			file: "ws_public_trade.json",
+			check: func(t *testing.T, event interface{}) {
+				trades, ok := event.([]MarketTradeEvent)
+				require.True(t, ok)
+				require.Len(t, trades, 1)
+				assert.Equal(t, bybitapi.SideBuy, trades[0].Side)
+				assert.Equal(t, fixedpoint.MustNewFromString("16578.5"), trades[0].Price)
+				assert.Equal(t, "PlusTick", trades[0].Direction)
+			},
+		},
+		{
+			file: "ws_kline.json",
+			check: func(t *testing.T, event interface{}) {
+				kLineEvent, ok := event.(*KLineEvent)
+				require.True(t, ok)
+				assert.Equal(t, "BTCUSDT", kLineEvent.Symbol)
+				assert.Equal(t, KLinePriceSourceTrade, kLineEvent.PriceSource)
+				require.Len(t, kLineEvent.KLines, 1)
+				assert.Equal(t, fixedpoint.MustNewFromString("2.081"), kLineEvent.KLines[0].Volume)
+			},
+		},
+		{
+			file: "ws_liquidation.json",
+			check: func(t *testing.T, event interface{}) {
+				liquidation, ok := event.(*LiquidationEvent)
+				require.True(t, ok)
+				assert.Equal(t, bybitapi.SideSell, liquidation.Side)
+				assert.Equal(t, fixedpoint.MustNewFromString("0.003"), liquidation.Size)
+			},
+		},
+		{
+			file: "ws_greeks.json",
+			check: func(t *testing.T, event interface{}) {
+				greeks, ok := event.(*GreeksEvent)
+				require.True(t, ok)
+				require.Len(t, greeks.Greeks, 1)
+				assert.Equal(t, "ETH", greeks.Greeks[0].BaseCoin)
+				assert.Equal(t, int64(1672364262482), greeks.Time.UnixMilli())
+			},
+		},
+		{
+			file: "ws_wallet.json",
+			check: func(t *testing.T, event interface{}) {
+				wallet, ok := event.(*WalletEvent)
+				require.True(t, ok)
+				assert.Equal(t, int64(1700034722104), wallet.Time.UnixMilli())
+				assert.Equal(t, []WalletCoinBalance{{
+					AccountType:   bybitapi.AccountTypeUnified,
+					Coin:          "BTC",
+					WalletBalance: fixedpoint.MustNewFromString("0.00102964"),
+					Available:     fixedpoint.MustNewFromString("0.00102964"),
+					Locked:        fixedpoint.Zero,
+					Equity:        fixedpoint.MustNewFromString("0.00102964"),
+					UnrealizedPnL: fixedpoint.Zero,
+				}}, wallet.Balances)
+			},
+		},
+		{
+			file: "ws_order.json",
+			check: func(t *testing.T, event interface{}) {
+				orders, ok := event.([]OrderEvent)
+				require.True(t, ok)
+				require.Len(t, orders, 1)
+				assert.Equal(t, bybitapi.CategoryOption, orders[0].Category)
+				assert.Equal(t, bybitapi.OrderStatusFilled, orders[0].OrderStatus)
+				assert.Equal(t, fixedpoint.MustNewFromString("75"), orders[0].AvgPrice)
+				assert.Equal(t, int64(1672364262457), orders[0].UpdatedTime.Time().UnixMilli())
+			},
+		},
+		{
+			file: "ws_execution.json",
+			check: func(t *testing.T, event interface{}) {
+				trades, ok := event.([]TradeEvent)
+				require.True(t, ok)
+				require.Len(t, trades, 1)
+				assert.Equal(t, "7e2ae69c-4edf-5800-a352-893d52b446aa", trades[0].ExecId)
+				assert.Equal(t, fixedpoint.MustNewFromString("0.005061"), trades[0].ExecFee)
+				assert.False(t, trades[0].IsMaker)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tc.file))
+			require.NoError(t, err)
+
+			event, err := ParseWebSocketTopicEvent(data)
+			require.NoError(t, err)
+			tc.check(t, event)
+
+			var envelope WebSocketTopicEvent
+			require.NoError(t, json.Unmarshal(data, &envelope))
+			assertTopicRoundTrip(t, envelope.Data, event)
+		})
+	}
+}
+
+func TestParseWebSocketTopicEvent_errors(t *testing.T) {
+	_, err := ParseWebSocketTopicEvent([]byte(`{`))
+	assert.Error(t, err)
+
+	// the op message is not a topic event
+	_, err = ParseWebSocketTopicEvent([]byte(`{"success":true,"ret_msg":"pong","op":"ping"}`))
+	assert.Error(t, err)
+
+	_, err = ParseWebSocketTopicEvent([]byte(`{"topic":"order","data":{"orderId":"1"}}`))
+	assert.ErrorContains(t, err, "OrderEvent")
+
+	event, err := ParseWebSocketTopicEvent([]byte(`{"topic":"newTopic.BTCUSDT","data":{"foo":"bar"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &rawTopicMessage{Topic: "newTopic.BTCUSDT", Data: json.RawMessage(`{"foo":"bar"}`)}, event)
+}
+
+// replaceNumerics replaces every numeric string of the json with the value, and every number with null since the
+// numeric fields sent as the numbers are never the strings.
+func replaceNumerics(v interface{}, value interface{}) interface{} {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for key, elem := range vt {
+			vt[key] = replaceNumerics(elem, value)
+		}
+		return vt
+
+	case []interface{}:
+		for i, elem := range vt {
+			vt[i] = replaceNumerics(elem, value)
+		}
+		return vt
+
+	case float64:
+		return nil
+
+	case string:
+		if _, err := strconv.ParseFloat(vt, 64); err == nil {
+			return value
+		}
+	}
+
+	return v
+}
+
+func TestParseWebSocketTopicEvent_emptyNumerics(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "ws_*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		for _, value := range []interface{}{"", "0", nil} {
+			t.Run(fmt.Sprintf("%s %v", filepath.Base(file), value), func(t *testing.T) {
+				data, err := os.ReadFile(file)
+				require.NoError(t, err)
+
+				var message map[string]interface{}
+				require.NoError(t, json.Unmarshal(data, &message))
+				message["data"] = replaceNumerics(message["data"], value)
+
+				data, err = json.Marshal(message)
+				require.NoError(t, err)
+
+				event, err := ParseWebSocketTopicEvent(data)
+				assert.NoError(t, err)
+				assert.NotNil(t, event)
+			})
+		}
+	}
+}
+
+func TestParseWebSocketTopicEvent_precision(t *testing.T) {
+	// the values have 16 significant digits and they're above 2^53 once scaled by 1e8, so the last digit would be lost
+	// if they're parsed through float64.
+	const turnover, volume = "98765432.10987653", "90071992.54740993"
+
+	event, err := ParseWebSocketTopicEvent([]byte(`{"topic":"kline.5.BTCUSDT","type":"snapshot","ts":1672324988882,"data":[` +
+		`{"start":1672324800000,"end":1672325099999,"interval":"5","open":"16649.5","close":"16677","high":"16677","low":"16608",` +
+		`"volume":"` + volume + `","turnover":"` + turnover + `","confirm":true,"timestamp":1672324988882}]}`))
+	require.NoError(t, err)
+	kLines := event.(*KLineEvent).KLines
+	require.Len(t, kLines, 1)
+	assert.Equal(t, turnover, kLines[0].Turnover.String())
+	assert.Equal(t, volume, kLines[0].Volume.String())
+
+	kLine, err := kLines[0].toGlobalKLine("BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, turnover, kLine.QuoteVolume.String())
+
+	event, err = ParseWebSocketTopicEvent([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1672304484978,"data":` +
+		`{"s":"BTCUSDT","b":[["16493.5","` + volume + `"]],"a":[["16611","` + turnover + `"]],"u":18521288,"seq":7961638724}}`))
+	require.NoError(t, err)
+	book := event.(*BookEvent)
+	assert.Equal(t, volume, book.Bids[0].Volume.String())
+	assert.Equal(t, turnover, book.Asks[0].Volume.String())
+}
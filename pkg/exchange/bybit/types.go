@@ -3,10 +3,13 @@ package bybit
 import (
 	"encoding/json"
 	"fmt"
+	"hash/crc32"
 	"strconv"
 	"strings"
 	"time"
 
+	"go.uber.org/multierr"
+
 	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -37,8 +40,10 @@ const (
 )
 
 type WebsocketOp struct {
-	Op   WsOpType `json:"op"`
-	Args []string `json:"args"`
+	// ReqId is echoed back in the response, so the response can be correlated to the request.
+	ReqId string   `json:"req_id,omitempty"`
+	Op    WsOpType `json:"op"`
+	Args  []string `json:"args"`
 }
 
 type WebSocketOpEvent struct {
@@ -76,7 +81,7 @@ func (w *WebSocketOpEvent) IsValid() error {
 		return nil
 
 	case WsOpTypeUnsubscribe:
-		// in the public channel, you can get RetMsg = 'subscribe', but in the private channel, you cannot.
+		// in the public channel, you can get RetMsg = 'unsubscribe', but in the private channel, you cannot.
 		// so, we only verify that success is true.
 		if !w.Success {
 			return fmt.Errorf("unexpected response result: %+v", w)
@@ -108,6 +113,26 @@ const (
 	TopicTypeOrder       TopicType = "order"
 	TopicTypeKLine       TopicType = "kline"
 	TopicTypeTrade       TopicType = "execution"
+	TopicTypeLiquidation TopicType = "liquidation"
+	// TopicTypeGreeks is the private topic of the greeks of the options positions, see Stream.EnableGreeks.
+	TopicTypeGreeks TopicType = "greeks"
+	// TopicTypeMarkPriceKLine and TopicTypeIndexPriceKLine are the k lines of the mark price and the index price, they
+	// have no volume and turnover. They're only available in the linear and the inverse categories, see
+	// Stream.SetCategory.
+	TopicTypeMarkPriceKLine  TopicType = "kline_mark"
+	TopicTypeIndexPriceKLine TopicType = "kline_index"
+	// TopicTypeTicker is the 24h ticker topic. The spot category pushes the snapshots only, while the linear and the
+	// inverse categories push a snapshot followed by the deltas, see TickerEvent.
+	TopicTypeTicker TopicType = "tickers"
+)
+
+const (
+	// MarkPriceKLineChannel subscribes the TopicTypeMarkPriceKLine with the interval option.
+	MarkPriceKLineChannel = types.Channel("markPriceKLine")
+	// IndexPriceKLineChannel subscribes the TopicTypeIndexPriceKLine with the interval option.
+	IndexPriceKLineChannel = types.Channel("indexPriceKLine")
+	// TickerChannel subscribes the TopicTypeTicker of the symbol, see Stream.OnTickerEvent.
+	TickerChannel = types.Channel("ticker")
 )
 
 type DataType string
@@ -121,8 +146,74 @@ type WebSocketTopicEvent struct {
 	Topic string   `json:"topic"`
 	Type  DataType `json:"type"`
 	// The timestamp (ms) that the system generates the data
-	Ts   types.MillisecondTimestamp `json:"ts"`
-	Data json.RawMessage            `json:"data"`
+	Ts types.MillisecondTimestamp `json:"ts"`
+	// CreationTime is the timestamp (ms) of the private topics, which don't have the ts.
+	CreationTime types.MillisecondTimestamp `json:"creationTime"`
+	Data         json.RawMessage            `json:"data"`
+
+	// strict rejects the unknown fields of the data, see Stream.SetStrictDecode.
+	strict bool
+	// decoder decodes the data, encoding/json is used if it's nil, see Stream.SetJSONDecoder.
+	decoder JSONDecoder
+}
+
+// rawTopicMessage is the message of the topic which is not supported by the stream, see Stream.OnRawTopicMessage.
+type rawTopicMessage struct {
+	Topic string
+	Data  json.RawMessage
+}
+
+type ConnectionState string
+
+const (
+	ConnectionStateConnected    ConnectionState = "connected"
+	ConnectionStateDisconnected ConnectionState = "disconnected"
+	ConnectionStateReconnected  ConnectionState = "reconnected"
+)
+
+// ConnectionStateEvent is the synthetic event emitted when the websocket connection state changes, so the strategies
+// can react to the stale feed.
+type ConnectionStateEvent struct {
+	State ConnectionState
+	// Symbols are the subscribed symbols affected by the state change, it's empty for the private stream.
+	Symbols []string
+	Time    time.Time
+}
+
+// decodeErrorSampleLimit is the max length of the raw message kept in the DecodeError.
+const decodeErrorSampleLimit = 512
+
+// DecodeError is the error occurs while decoding or validating the websocket message.
+type DecodeError struct {
+	// Topic is the topic of the message, it's empty if the message isn't a topic message or can't be decoded.
+	Topic string
+	// Raw is the raw message, it's truncated to decodeErrorSampleLimit bytes.
+	Raw []byte
+	Err error
+}
+
+func newDecodeError(raw []byte, err error) DecodeError {
+	var topicEvent WebSocketTopicEvent
+	// ignore the error, the message may be malformed.
+	_ = json.Unmarshal(raw, &topicEvent)
+
+	if len(raw) > decodeErrorSampleLimit {
+		raw = raw[:decodeErrorSampleLimit]
+	}
+
+	return DecodeError{
+		Topic: topicEvent.Topic,
+		Raw:   append([]byte{}, raw...),
+		Err:   err,
+	}
+}
+
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode the message, topic: %s, raw: %s, err: %v", e.Topic, e.Raw, e.Err)
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
 }
 
 type BookEvent struct {
@@ -145,6 +236,9 @@ type BookEvent struct {
 	Type DataType
 	// ServerTime using the websocket timestamp as server time. Since the event not provide server time information.
 	ServerTime time.Time
+	// ReceivedTime is the local time the message was received by the stream, it's zero if the event is parsed by
+	// ParseWebSocketTopicEvent.
+	ReceivedTime time.Time
 }
 
 func (e *BookEvent) OrderBook() (snapshot types.SliceOrderBook) {
@@ -152,9 +246,41 @@ func (e *BookEvent) OrderBook() (snapshot types.SliceOrderBook) {
 	snapshot.Bids = e.Bids
 	snapshot.Asks = e.Asks
 	snapshot.Time = e.ServerTime
+	snapshot.LastUpdateId = e.UpdateId.Int64()
+	snapshot.SequenceId = e.SequenceId.Int64()
 	return snapshot
 }
 
+// BookChecksumEvent is emitted after the book emit if the book checksum is enabled.
+type BookChecksumEvent struct {
+	Symbol   string
+	Checksum uint32
+	UpdateId fixedpoint.Value
+	Time     time.Time
+}
+
+// bookChecksum calculates the CRC32 (IEEE) checksum of the top depth levels of the book. The checksum input is the
+// price and the volume pairs of the bids and the asks interleaved by level and joined by colons:
+//
+//	bid1Price:bid1Volume:ask1Price:ask1Volume:bid2Price:bid2Volume:ask2Price:ask2Volume...
+//
+// The numbers are formatted by fixedpoint.Value.String, and the levels of the shorter side are skipped once it runs
+// out, e.g. "100:1:101:2:99:3" for 2 bids and 1 ask.
+func bookChecksum(book types.SliceOrderBook, depth int) uint32 {
+	var parts []string
+	for i := 0; i < depth; i++ {
+		if i < len(book.Bids) {
+			parts = append(parts, book.Bids[i].Price.String(), book.Bids[i].Volume.String())
+		}
+
+		if i < len(book.Asks) {
+			parts = append(parts, book.Asks[i].Price.String(), book.Asks[i].Volume.String())
+		}
+	}
+
+	return crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))
+}
+
 type MarketTradeEvent struct {
 	// Timestamp is the timestamp (ms) that the order is filled
 	Timestamp types.MillisecondTimestamp `json:"T"`
@@ -211,20 +337,261 @@ func genTopic(in ...interface{}) string {
 	return strings.Join(out, topicSeparator)
 }
 
-func getTopicType(topic string) TopicType {
+// ParsedTopic is the parts of a topic, the layout of the topic depends on the topic type:
+//
+//	orderbook.{depth}.{symbol}
+//	kline.{interval}.{symbol}, kline_mark.{interval}.{symbol}, kline_index.{interval}.{symbol}
+//	publicTrade.{symbol}, liquidation.{symbol}, tickers.{symbol}
+//	wallet, order, execution, greeks, optionally with the category, e.g. order.spot
+type ParsedTopic struct {
+	Type TopicType
+	// Depth is the depth of the orderbook topic.
+	Depth int
+	// Interval is the interval of the k line topics, e.g. 1, 60 and D.
+	Interval string
+	// Category is the category of the private topics, it's empty for the all-in-one topics.
+	Category bybitapi.Category
+	// Symbol is the global symbol of the public topics.
+	Symbol string
+}
+
+// topicLayout is the layout of the topic type, parts is the number of the parts including the type itself, and the
+// private topics may have the category as the second part.
+type topicLayout struct {
+	parts   int
+	private bool
+}
+
+var topicLayouts = map[TopicType]topicLayout{
+	TopicTypeOrderBook:       {parts: 3},
+	TopicTypeKLine:           {parts: 3},
+	TopicTypeMarkPriceKLine:  {parts: 3},
+	TopicTypeIndexPriceKLine: {parts: 3},
+	TopicTypeMarketTrade:     {parts: 2},
+	TopicTypeLiquidation:     {parts: 2},
+	TopicTypeTicker:          {parts: 2},
+	TopicTypeWallet:          {parts: 1, private: true},
+	TopicTypeOrder:           {parts: 1, private: true},
+	TopicTypeTrade:           {parts: 1, private: true},
+	TopicTypeGreeks:          {parts: 1, private: true},
+}
+
+// ParseTopic splits the topic into the parts by the layout of the topic type. The type of the unknown topics is still
+// parsed, and the last part is the symbol if there are more than one part. The type is always returned even if the
+// topic doesn't match the layout, so the caller can tell which topic is malformed.
+func ParseTopic(topic string) (ParsedTopic, error) {
 	slice := strings.Split(topic, topicSeparator)
-	if len(slice) == 0 {
-		return ""
+	parsed := ParsedTopic{Type: TopicType(slice[0])}
+	if len(parsed.Type) == 0 {
+		return parsed, fmt.Errorf("unexpected topic: %s", topic)
 	}
-	return TopicType(slice[0])
+
+	layout, ok := topicLayouts[parsed.Type]
+	if !ok {
+		if len(slice) > 1 {
+			parsed.Symbol = toGlobalSymbol(slice[len(slice)-1], bybitapi.CategorySpot)
+		}
+		return parsed, nil
+	}
+
+	if layout.private {
+		switch len(slice) {
+		case 1:
+			return parsed, nil
+		case 2:
+			parsed.Category = bybitapi.Category(slice[1])
+			if err := parsed.Category.Validate(); err != nil {
+				return parsed, fmt.Errorf("unexpected topic: %s, err: %w", topic, err)
+			}
+			return parsed, nil
+		}
+		return parsed, fmt.Errorf("unexpected topic: %s", topic)
+	}
+
+	if len(slice) != layout.parts || len(slice[len(slice)-1]) == 0 {
+		return parsed, fmt.Errorf("unexpected topic: %s", topic)
+	}
+	parsed.Symbol = toGlobalSymbol(slice[len(slice)-1], bybitapi.CategorySpot)
+
+	if layout.parts == 3 {
+		if parsed.Type == TopicTypeOrderBook {
+			depth, err := strconv.Atoi(slice[1])
+			if err != nil {
+				return parsed, fmt.Errorf("unexpected depth of the topic: %s, err: %w", topic, err)
+			}
+			parsed.Depth = depth
+		} else {
+			parsed.Interval = slice[1]
+		}
+	}
+	return parsed, nil
 }
 
-func getSymbolFromTopic(topic string) (string, error) {
-	slice := strings.Split(topic, topicSeparator)
-	if len(slice) != 3 {
-		return "", fmt.Errorf("unexpected topic: %s", topic)
+// getTopicType returns the type of the topic, it's empty if the topic is empty.
+func getTopicType(topic string) TopicType {
+	parsed, _ := ParseTopic(topic)
+	return parsed.Type
+}
+
+// LiquidationEvent is the liquidated order of the liquidation topic, it's pushed one by one without the snapshot.
+type LiquidationEvent struct {
+	UpdatedTime types.MillisecondTimestamp `json:"updatedTime"`
+	Symbol      string                     `json:"symbol"`
+	// Side is the side of the liquidated position, Buy means the long position is liquidated.
+	Side  bybitapi.Side    `json:"side"`
+	Size  fixedpoint.Value `json:"size"`
+	Price fixedpoint.Value `json:"price"`
+}
+
+func (e *LiquidationEvent) toGlobalLiquidationInfo() (types.LiquidationInfo, error) {
+	side, err := toGlobalSideType(e.Side)
+	if err != nil {
+		return types.LiquidationInfo{}, err
+	}
+
+	return types.LiquidationInfo{
+		Symbol:       e.Symbol,
+		Side:         side,
+		Quantity:     e.Size,
+		Price:        e.Price,
+		AveragePrice: e.Price,
+		TradeTime:    types.Time(e.UpdatedTime.Time()),
+	}, nil
+}
+
+// TickerEvent is the ticker of the tickers topic. The fields of the derivatives only are empty in the spot category.
+//
+// The delta of the linear and the inverse categories only carries the changed fields, the stream merges it into the
+// latest ticker of the symbol, so the callbacks of Stream.OnTickerEvent always receive the full ticker.
+type TickerEvent struct {
+	Symbol        string           `json:"symbol"`
+	TickDirection string           `json:"tickDirection"`
+	LastPrice     fixedpoint.Value `json:"lastPrice"`
+	PrevPrice24h  fixedpoint.Value `json:"prevPrice24h"`
+	Price24hPcnt  fixedpoint.Value `json:"price24hPcnt"`
+	HighPrice24h  fixedpoint.Value `json:"highPrice24h"`
+	LowPrice24h   fixedpoint.Value `json:"lowPrice24h"`
+	PrevPrice1h   fixedpoint.Value `json:"prevPrice1h"`
+	Volume24h     fixedpoint.Value `json:"volume24h"`
+	Turnover24h   fixedpoint.Value `json:"turnover24h"`
+	UsdIndexPrice fixedpoint.Value `json:"usdIndexPrice"`
+
+	MarkPrice         fixedpoint.Value           `json:"markPrice"`
+	IndexPrice        fixedpoint.Value           `json:"indexPrice"`
+	OpenInterest      fixedpoint.Value           `json:"openInterest"`
+	OpenInterestValue fixedpoint.Value           `json:"openInterestValue"`
+	FundingRate       fixedpoint.Value           `json:"fundingRate"`
+	NextFundingTime   types.MillisecondTimestamp `json:"nextFundingTime"`
+	Bid1Price         fixedpoint.Value           `json:"bid1Price"`
+	Bid1Size          fixedpoint.Value           `json:"bid1Size"`
+	Ask1Price         fixedpoint.Value           `json:"ask1Price"`
+	Ask1Size          fixedpoint.Value           `json:"ask1Size"`
+
+	Type       DataType  `json:"-"`
+	ServerTime time.Time `json:"-"`
+
+	// data is the raw data of the event, the delta is decoded onto the latest ticker to keep the unchanged fields.
+	data json.RawMessage
+}
+
+// Greeks is the greeks of all the options positions of the base coin.
+type Greeks struct {
+	BaseCoin   string           `json:"baseCoin"`
+	TotalDelta fixedpoint.Value `json:"totalDelta"`
+	TotalGamma fixedpoint.Value `json:"totalGamma"`
+	TotalVega  fixedpoint.Value `json:"totalVega"`
+	TotalTheta fixedpoint.Value `json:"totalTheta"`
+}
+
+// GreeksEvent is the event of the greeks topic. The topic only pushes the snapshots, every event carries the greeks
+// of all the base coins with the options positions and replaces the previous one, the base coin missing from the
+// event has no options position anymore.
+type GreeksEvent struct {
+	Greeks []Greeks
+	Time   time.Time
+}
+
+// ByBaseCoin returns the greeks keyed by the base coin.
+func (e *GreeksEvent) ByBaseCoin() map[string]Greeks {
+	m := make(map[string]Greeks, len(e.Greeks))
+	for _, greeks := range e.Greeks {
+		m[greeks.BaseCoin] = greeks
+	}
+	return m
+}
+
+// WalletCoinBalance is the balance of a coin in an account of the wallet event.
+type WalletCoinBalance struct {
+	AccountType   bybitapi.AccountType
+	Coin          string
+	WalletBalance fixedpoint.Value
+	// Available is the free balance of the spot account, or the wallet balance minus the locked balance of the
+	// unified account, which doesn't push the free balance.
+	Available     fixedpoint.Value
+	Locked        fixedpoint.Value
+	Equity        fixedpoint.Value
+	UnrealizedPnL fixedpoint.Value
+}
+
+// WalletEvent is the event of the wallet topic. Unlike the greeks topic, bybit only pushes the accounts and the coins
+// of which the balance changed, the coin missing from the event is not changed, so the event must be merged into the
+// balances instead of replacing them, see Currencies.
+type WalletEvent struct {
+	Balances []WalletCoinBalance
+	Time     time.Time
+}
+
+func newWalletEvent(accounts []bybitapi.WalletBalances, t time.Time) *WalletEvent {
+	e := &WalletEvent{Time: t}
+	for _, account := range accounts {
+		for _, coin := range account.Coins {
+			available := coin.Free
+			if account.AccountType != bybitapi.AccountTypeSpot {
+				available = coin.WalletBalance.Sub(coin.Locked)
+			}
+
+			e.Balances = append(e.Balances, WalletCoinBalance{
+				AccountType:   account.AccountType,
+				Coin:          coin.Coin,
+				WalletBalance: coin.WalletBalance,
+				Available:     available,
+				Locked:        coin.Locked,
+				Equity:        coin.Equity,
+				UnrealizedPnL: coin.UnrealisedPnl,
+			})
+		}
+	}
+	return e
+}
+
+// Currencies returns the coins of the spot account in the event in the order of the event, they're the only balances
+// changed by the balance map of toGlobalBalanceMap.
+func (e *WalletEvent) Currencies() []string {
+	var currencies []string
+	for _, balance := range e.Balances {
+		if balance.AccountType == bybitapi.AccountTypeSpot {
+			currencies = append(currencies, balance.Coin)
+		}
+	}
+	return currencies
+}
+
+// toGlobalBalanceMap converts the balances of the spot account like the balances queried by the exchange, the other
+// accounts are skipped. The map only has the changed coins, see Currencies.
+func (e *WalletEvent) toGlobalBalanceMap() types.BalanceMap {
+	bm := types.BalanceMap{}
+	for _, balance := range e.Balances {
+		if balance.AccountType != bybitapi.AccountTypeSpot {
+			continue
+		}
+
+		bm[balance.Coin] = types.Balance{
+			Currency:  balance.Coin,
+			Available: balance.Available,
+			Locked:    balance.Locked,
+		}
 	}
-	return slice[2], nil
+	return bm
 }
 
 type OrderEvent struct {
@@ -233,9 +600,21 @@ type OrderEvent struct {
 	Category bybitapi.Category `json:"category"`
 }
 
+// KLinePriceSource is the price the k line is built from.
+type KLinePriceSource int
+
+const (
+	KLinePriceSourceTrade KLinePriceSource = iota
+	KLinePriceSourceMark
+	KLinePriceSourceIndex
+)
+
 type KLineEvent struct {
 	KLines []KLine
 
+	// PriceSource is the price source of the k lines, the mark price and the index price k lines have no volume.
+	PriceSource KLinePriceSource
+
 	// internal use
 	// Type can be one of snapshot or delta. Copied from WebSocketTopicEvent.Type
 	Type DataType
@@ -243,6 +622,44 @@ type KLineEvent struct {
 	Symbol string
 }
 
+// ClosedKLines returns the confirmed k lines of the event, for the consumers which only act on the closed candles.
+func (e *KLineEvent) ClosedKLines() (kLines []KLine) {
+	for _, k := range e.KLines {
+		if k.Confirm {
+			kLines = append(kLines, k)
+		}
+	}
+	return kLines
+}
+
+// InProgressKLine returns the latest unconfirmed k line of the event for the live updates, it returns false if all
+// the k lines of the event are confirmed.
+func (e *KLineEvent) InProgressKLine() (KLine, bool) {
+	for i := len(e.KLines) - 1; i >= 0; i-- {
+		if !e.KLines[i].Confirm {
+			return e.KLines[i], true
+		}
+	}
+	return KLine{}, false
+}
+
+// toGlobalKLines converts all the k lines of the event. It doesn't fail fast, the k line which fails to convert, e.g.
+// of an unknown interval, is skipped and its error is appended to the returned error, so the converted k lines are
+// returned even if the error is not nil.
+func (e *KLineEvent) toGlobalKLines() (kLines []types.KLine, err error) {
+	for i := range e.KLines {
+		kLine, convertErr := e.KLines[i].toGlobalKLine(e.Symbol)
+		if convertErr != nil {
+			err = multierr.Append(err, convertErr)
+			continue
+		}
+
+		kLines = append(kLines, kLine)
+	}
+
+	return kLines, err
+}
+
 type KLine struct {
 	// The start timestamp (ms)
 	StartTime types.MillisecondTimestamp `json:"start"`
@@ -315,11 +732,13 @@ type TradeEvent struct {
 	// Executed trading fee. You can get spot fee currency instruction here. Normal spot is not supported
 	ExecFee fixedpoint.Value `json:"execFee"`
 	// Executed type. Normal spot is not supported
-	ExecType string `json:"execType"`
+	ExecType bybitapi.ExecType `json:"execType"`
 	// Executed order value. Normal spot is not supported
 	ExecValue fixedpoint.Value `json:"execValue"`
 	// Trading fee rate. Normal spot is not supported
 	FeeRate fixedpoint.Value `json:"feeRate"`
+	// The currency of the executed trading fee, it's empty if the exchange doesn't provide it.
+	FeeCurrency string `json:"feeCurrency"`
 	// The remaining qty not executed. Normal spot is not supported
 	LeavesQty fixedpoint.Value `json:"leavesQty"`
 	// Order price. Normal spot is not supported
@@ -352,6 +771,10 @@ func (t *TradeEvent) toGlobalTrade(symbolFee symbolFeeDetail) (*types.Trade, err
 		return nil, err
 	}
 
+	// the fee is calculated with the classified liquidity, so the event is copied rather than modified.
+	event := *t
+	event.IsMaker = isMakerExecution(t.IsMaker, t.ExecType)
+
 	orderIdNum, err := strconv.ParseUint(t.OrderId, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("unexpected order id: %s, err: %w", t.OrderId, err)
@@ -372,12 +795,30 @@ func (t *TradeEvent) toGlobalTrade(symbolFee symbolFeeDetail) (*types.Trade, err
 		Symbol:        t.Symbol,
 		Side:          side,
 		IsBuyer:       side == types.SideTypeBuy,
-		IsMaker:       t.IsMaker,
+		IsMaker:       event.IsMaker,
 		Time:          types.Time(t.ExecTime),
 		Fee:           fixedpoint.Zero,
 		FeeCurrency:   "",
 	}
-	trade.FeeCurrency, trade.Fee = calculateFee(*t, symbolFee)
+	// the fee rate applied by the exchange is more accurate than the polled one.
+	if !t.FeeRate.IsZero() {
+		if event.IsMaker {
+			symbolFee.MakerFeeRate = t.FeeRate
+		} else {
+			symbolFee.TakerFeeRate = t.FeeRate
+		}
+	}
+
+	trade.FeeCurrency, trade.Fee = calculateFee(event, symbolFee)
+
+	// use the fee charged by the exchange if it's provided, the currency still follows the fee currency rules unless
+	// the exchange provides it as well.
+	if !t.ExecFee.IsZero() {
+		trade.Fee = t.ExecFee
+	}
+	if len(t.FeeCurrency) > 0 {
+		trade.FeeCurrency = t.FeeCurrency
+	}
 	return trade, nil
 }
 
@@ -428,3 +869,14 @@ func quoteCoinAsFee(t TradeEvent, feeDetail symbolFeeDetail) fixedpoint.Value {
 	}
 	return feeDetail.TakerFeeRate.Mul(baseFee)
 }
+
+func toKLinePriceSource(topicType TopicType) KLinePriceSource {
+	switch topicType {
+	case TopicTypeMarkPriceKLine:
+		return KLinePriceSourceMark
+	case TopicTypeIndexPriceKLine:
+		return KLinePriceSourceIndex
+	default:
+		return KLinePriceSourceTrade
+	}
+}
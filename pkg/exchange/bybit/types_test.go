@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
 
 	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -304,9 +305,11 @@ func TestBookEvent_OrderBook(t *testing.T) {
 		}
 
 		expSliceOrderBook := types.SliceOrderBook{
-			Symbol: event.Symbol,
-			Bids:   event.Bids,
-			Asks:   event.Asks,
+			Symbol:       event.Symbol,
+			Bids:         event.Bids,
+			Asks:         event.Asks,
+			LastUpdateId: 1841364,
+			SequenceId:   10558648910,
 		}
 
 		assert.Equal(t, expSliceOrderBook, event.OrderBook())
@@ -356,9 +359,11 @@ func TestBookEvent_OrderBook(t *testing.T) {
 		}
 
 		expSliceOrderBook := types.SliceOrderBook{
-			Symbol: event.Symbol,
-			Bids:   types.PriceVolumeSlice{},
-			Asks:   event.Asks,
+			Symbol:       event.Symbol,
+			Bids:         types.PriceVolumeSlice{},
+			Asks:         event.Asks,
+			LastUpdateId: 1854104,
+			SequenceId:   10559247733,
 		}
 
 		assert.Equal(t, expSliceOrderBook, event.OrderBook())
@@ -425,21 +430,59 @@ func Test_getTopicName(t *testing.T) {
 	assert.Equal(t, exp, getTopicType("orderbook.50.BTCUSDT"))
 }
 
-func Test_getSymbolFromTopic(t *testing.T) {
-	t.Run("succeeds", func(t *testing.T) {
-		exp := "BTCUSDT"
-		res, err := getSymbolFromTopic("kline.1.BTCUSDT")
-		assert.NoError(t, err)
-		assert.Equal(t, exp, res)
-	})
+func TestParseTopic(t *testing.T) {
+	for _, c := range []struct {
+		topic string
+		exp   ParsedTopic
+	}{
+		{"orderbook.50.BTCUSDT", ParsedTopic{Type: TopicTypeOrderBook, Depth: 50, Symbol: "BTCUSDT"}},
+		{"kline.1.BTCUSDT", ParsedTopic{Type: TopicTypeKLine, Interval: "1", Symbol: "BTCUSDT"}},
+		{"kline_mark.D.BTCUSDT", ParsedTopic{Type: TopicTypeMarkPriceKLine, Interval: "D", Symbol: "BTCUSDT"}},
+		{"publicTrade.BTCUSDT", ParsedTopic{Type: TopicTypeMarketTrade, Symbol: "BTCUSDT"}},
+		{"tickers.BTCUSDT", ParsedTopic{Type: TopicTypeTicker, Symbol: "BTCUSDT"}},
+		{"order", ParsedTopic{Type: TopicTypeOrder}},
+		{"execution.spot", ParsedTopic{Type: TopicTypeTrade, Category: bybitapi.CategorySpot}},
+		// the unknown topics have the type and the symbol
+		{"insurance.USDT", ParsedTopic{Type: "insurance", Symbol: "USDT"}},
+	} {
+		res, err := ParseTopic(c.topic)
+		assert.NoError(t, err, c.topic)
+		assert.Equal(t, c.exp, res, c.topic)
+	}
 
 	t.Run("unexpected topic", func(t *testing.T) {
-		res, err := getSymbolFromTopic("kline.1")
-		assert.Empty(t, res)
-		assert.Equal(t, err, fmt.Errorf("unexpected topic: kline.1"))
+		res, err := ParseTopic("kline.1")
+		assert.Equal(t, fmt.Errorf("unexpected topic: kline.1"), err)
+		// the type is still parsed
+		assert.Equal(t, TopicTypeKLine, res.Type)
+		assert.Empty(t, res.Symbol)
+
+		for _, topic := range []string{"", "publicTrade.1.BTCUSDT", "publicTrade.", "orderbook.x.BTCUSDT", "order.futures", "wallet.spot.BTCUSDT"} {
+			_, err = ParseTopic(topic)
+			assert.Error(t, err, topic)
+		}
 	})
 }
 
+func TestKLineEvent_ClosedKLines(t *testing.T) {
+	closed := KLine{StartTime: types.NewMillisecondTimestampFromInt(1700000000000), Interval: "1", Confirm: true}
+	open := KLine{StartTime: types.NewMillisecondTimestampFromInt(1700000060000), Interval: "1"}
+
+	e := KLineEvent{KLines: []KLine{closed, open}}
+	assert.Equal(t, []KLine{closed}, e.ClosedKLines())
+
+	k, ok := e.InProgressKLine()
+	assert.True(t, ok)
+	assert.Equal(t, open, k)
+
+	e = KLineEvent{KLines: []KLine{closed}}
+	_, ok = e.InProgressKLine()
+	assert.False(t, ok)
+
+	e = KLineEvent{KLines: []KLine{open}}
+	assert.Empty(t, e.ClosedKLines())
+}
+
 func TestKLine_toGlobalKLine(t *testing.T) {
 	t.Run("succeeds", func(t *testing.T) {
 		k := KLine{
@@ -496,6 +539,35 @@ func TestKLine_toGlobalKLine(t *testing.T) {
 	})
 }
 
+func TestKLineEvent_toGlobalKLines(t *testing.T) {
+	e := KLineEvent{
+		Symbol: "BTCUSDT",
+		KLines: []KLine{
+			{StartTime: types.NewMillisecondTimestampFromInt(1700000000000), Interval: "1", Confirm: true},
+			{StartTime: types.NewMillisecondTimestampFromInt(1700000000000), Interval: "2"},
+			{StartTime: types.NewMillisecondTimestampFromInt(1700000060000), Interval: "1"},
+			{StartTime: types.NewMillisecondTimestampFromInt(1700000060000), Interval: "7"},
+		},
+	}
+
+	kLines, err := e.toGlobalKLines()
+	assert.Len(t, multierr.Errors(err), 2)
+	assert.ErrorContains(t, err, "unexpected k line interval")
+
+	// the bad k lines don't discard the good ones
+	if assert.Len(t, kLines, 2) {
+		assert.Equal(t, "BTCUSDT", kLines[0].Symbol)
+		assert.True(t, kLines[0].Closed)
+		assert.Equal(t, types.Interval1m, kLines[1].Interval)
+		assert.Equal(t, time.UnixMilli(1700000060000), kLines[1].StartTime.Time())
+	}
+
+	e.KLines = e.KLines[:1]
+	kLines, err = e.toGlobalKLines()
+	assert.NoError(t, err)
+	assert.Len(t, kLines, 1)
+}
+
 func TestTradeEvent_toGlobalTrade(t *testing.T) {
 	/*
 		{
@@ -590,6 +662,94 @@ func TestTradeEvent_toGlobalTrade(t *testing.T) {
 		assert.Equal(t, expTrade, actualTrade)
 	})
 
+	t.Run("exec fee and fee rate", func(t *testing.T) {
+		symbolFee := symbolFeeDetail{
+			FeeRate: bybitapi.FeeRate{
+				Symbol:       "BTCUSDT",
+				TakerFeeRate: fixedpoint.NewFromFloat(0.001),
+				MakerFeeRate: fixedpoint.NewFromFloat(0.001),
+			},
+			BaseCoin:  "BTC",
+			QuoteCoin: "USDT",
+		}
+		tradeEvent := TradeEvent{
+			OrderId:   "1482125285219500288",
+			Category:  "spot",
+			Symbol:    "BTCUSDT",
+			ExecId:    "2100000000032905730",
+			ExecPrice: fixedpoint.NewFromInt(28000),
+			ExecQty:   fixedpoint.NewFromFloat(0.01),
+			IsMaker:   true,
+			Side:      bybitapi.SideSell,
+		}
+
+		// the maker rebate is paid in the base coin for the sell order
+		tradeEvent.FeeRate = fixedpoint.NewFromFloat(-0.0002)
+		actualTrade, err := tradeEvent.toGlobalTrade(symbolFee)
+		assert.NoError(t, err)
+		assert.Equal(t, "BTC", actualTrade.FeeCurrency)
+		assert.Equal(t, fixedpoint.NewFromFloat(-0.0002).Mul(tradeEvent.ExecQty), actualTrade.Fee)
+
+		// the exec fee overrides the calculated fee
+		tradeEvent.FeeRate = fixedpoint.NewFromFloat(0.0002)
+		tradeEvent.ExecFee = fixedpoint.NewFromFloat(0.056)
+		actualTrade, err = tradeEvent.toGlobalTrade(symbolFee)
+		assert.NoError(t, err)
+		assert.Equal(t, "USDT", actualTrade.FeeCurrency)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.056), actualTrade.Fee)
+	})
+
+	t.Run("maker classification and fee currency", func(t *testing.T) {
+		symbolFee := symbolFeeDetail{
+			FeeRate: bybitapi.FeeRate{
+				Symbol:       "BTCUSDT",
+				TakerFeeRate: fixedpoint.NewFromFloat(0.001),
+				MakerFeeRate: fixedpoint.NewFromFloat(0.001),
+			},
+			BaseCoin:  "BTC",
+			QuoteCoin: "USDT",
+		}
+		tradeEvent := TradeEvent{
+			OrderId:   "1482125285219500288",
+			Category:  "spot",
+			Symbol:    "BTCUSDT",
+			ExecId:    "2100000000032905730",
+			ExecPrice: fixedpoint.NewFromInt(28000),
+			ExecQty:   fixedpoint.NewFromFloat(0.01),
+			IsMaker:   true,
+			ExecType:  bybitapi.ExecTypeTrade,
+			Side:      bybitapi.SideBuy,
+		}
+
+		// the spot buy pays the fee in the base coin
+		actualTrade, err := tradeEvent.toGlobalTrade(symbolFee)
+		assert.NoError(t, err)
+		assert.True(t, actualTrade.IsMaker)
+		assert.Equal(t, "BTC", actualTrade.FeeCurrency)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.001).Mul(tradeEvent.ExecQty), actualTrade.Fee)
+
+		// the spot sell pays the fee in the quote coin
+		tradeEvent.Side = bybitapi.SideSell
+		actualTrade, err = tradeEvent.toGlobalTrade(symbolFee)
+		assert.NoError(t, err)
+		assert.Equal(t, "USDT", actualTrade.FeeCurrency)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.001).Mul(tradeEvent.ExecPrice.Mul(tradeEvent.ExecQty)), actualTrade.Fee)
+
+		// the liquidation is always taker
+		tradeEvent.ExecType = bybitapi.ExecTypeBustTrade
+		actualTrade, err = tradeEvent.toGlobalTrade(symbolFee)
+		assert.NoError(t, err)
+		assert.False(t, actualTrade.IsMaker)
+		assert.True(t, tradeEvent.IsMaker)
+
+		// the fee currency provided by the exchange is preferred
+		tradeEvent.ExecType = bybitapi.ExecTypeTrade
+		tradeEvent.FeeCurrency = "MNT"
+		actualTrade, err = tradeEvent.toGlobalTrade(symbolFee)
+		assert.NoError(t, err)
+		assert.Equal(t, "MNT", actualTrade.FeeCurrency)
+	})
+
 	t.Run("unexpected category", func(t *testing.T) {
 		tradeEvent := TradeEvent{
 			Category: "test-spot",
@@ -887,3 +1047,49 @@ func TestTradeEvent_quoteCoinAsFee(t *testing.T) {
 	trade.IsMaker = true
 	assert.Equal(t, symbolFee.FeeRate.MakerFeeRate.Mul(qty.Mul(price)), quoteCoinAsFee(*trade, symbolFee))
 }
+
+func TestWalletEvent(t *testing.T) {
+	e := newWalletEvent([]bybitapi.WalletBalances{
+		{
+			AccountType: bybitapi.AccountTypeSpot,
+			Coins: []bybitapi.WalletCoin{
+				{
+					Coin:          "USDT",
+					WalletBalance: fixedpoint.NewFromInt(100),
+					Free:          fixedpoint.NewFromInt(70),
+					Locked:        fixedpoint.NewFromInt(30),
+					Equity:        fixedpoint.NewFromInt(100),
+				},
+			},
+		},
+		{
+			AccountType: bybitapi.AccountTypeUnified,
+			Coins: []bybitapi.WalletCoin{
+				{
+					Coin:          "BTC",
+					WalletBalance: fixedpoint.NewFromInt(2),
+					Locked:        fixedpoint.NewFromFloat(0.5),
+					Equity:        fixedpoint.NewFromFloat(2.1),
+					UnrealisedPnl: fixedpoint.NewFromFloat(0.1),
+				},
+			},
+		},
+	}, time.UnixMilli(1700034722104))
+
+	if assert.Len(t, e.Balances, 2) {
+		assert.Equal(t, fixedpoint.NewFromInt(70), e.Balances[0].Available)
+		// the unified account doesn't push the free balance
+		assert.Equal(t, fixedpoint.NewFromFloat(1.5), e.Balances[1].Available)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.1), e.Balances[1].UnrealizedPnL)
+	}
+
+	// only the changed coins of the spot account are converted
+	assert.Equal(t, []string{"USDT"}, e.Currencies())
+	assert.Equal(t, types.BalanceMap{
+		"USDT": {
+			Currency:  "USDT",
+			Available: fixedpoint.NewFromInt(70),
+			Locked:    fixedpoint.NewFromInt(30),
+		},
+	}, e.toGlobalBalanceMap())
+}
@@ -106,7 +106,10 @@ func (v Value) String() string {
 	} else if v == NegInf {
 		return "-inf"
 	}
-	return strconv.FormatFloat(float64(v)/DefaultPow, 'f', -1, 64)
+
+	// the digits are formatted from the integer instead of the float64, which only keeps ~16 significant digits.
+	str := v.FormatString(DefaultPrecision)
+	return strings.TrimSuffix(strings.TrimRight(str, "0"), ".")
 }
 
 func (v Value) FormatString(prec int) string {
@@ -422,6 +425,10 @@ func NewFromString(input string) (Value, error) {
 			after = after[0:decimalCount] + strings.Repeat("0", 8-decimalCount) + after[decimalCount:]
 		}
 		input = input[0:dotIndex] + after
+		if v, ok := parseScaledInt(input, isPercentage); ok {
+			return v, nil
+		}
+
 		v, err := strconv.ParseFloat(input, 64)
 		if err != nil {
 			return 0, err
@@ -467,6 +474,31 @@ func NewFromString(input string) (Value, error) {
 	}
 }
 
+// parseScaledInt parses the input which is already scaled by DefaultPow as an integer, the digits after the dot are
+// truncated like math.Trunc. The float64 only has 53 bits of mantissa, so parsing the scaled input as a float loses the
+// last digits of the values above ~9e7, e.g. the turnover. It returns false if the input isn't a plain integer, e.g.
+// it has the exponent or it's out of the int64 range, then the float parsing is used.
+func parseScaledInt(input string, isPercentage bool) (Value, bool) {
+	if dot := strings.IndexByte(input, '.'); dot >= 0 {
+		for _, c := range input[dot+1:] {
+			if c < '0' || c > '9' {
+				return 0, false
+			}
+		}
+		input = input[:dot]
+	}
+
+	v, err := strconv.ParseInt(input, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if isPercentage {
+		v = v / 100
+	}
+	return Value(v), true
+}
+
 func MustNewFromString(input string) Value {
 	v, err := NewFromString(input)
 	if err != nil {
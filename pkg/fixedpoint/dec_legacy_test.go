@@ -3,7 +3,10 @@
 package fixedpoint
 
 import (
+	"encoding/json"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNumFractionalDigitsLegacy(t *testing.T) {
@@ -31,3 +34,24 @@ func TestNumFractionalDigitsLegacy(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFromString_precision(t *testing.T) {
+	// the scaled values are above 2^53, they lose the last digits if they're parsed or formatted as float64, the dnum
+	// build keeps 16 significant digits only.
+	for _, s := range []string{"123456789.12345678", "-98765432.87654321", "90071992.54740993"} {
+		f, err := NewFromString(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, f.String())
+	}
+
+	var v struct {
+		Turnover Value `json:"turnover"`
+		Volume   Value `json:"volume"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"turnover":"987654321.98765432","volume":123456789.12345678}`), &v))
+	assert.Equal(t, "987654321.98765432", v.Turnover.String())
+	assert.Equal(t, "123456789.12345678", v.Volume.String())
+
+	f := MustNewFromString("1234567890.123456%")
+	assert.Equal(t, "12345678.90123456", f.String())
+}
@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+// Notifier posts the formatted message to the channel and returns the error of the post, the notifier decides the
+// default channel if the channel is empty. The slack notifier implements it, and the other sinks can be adapted by
+// NotifierFunc.
+type Notifier interface {
+	NotifyChannel(channel, format string, args ...interface{}) error
+}
+
+// NotifierFunc adapts the function to the Notifier, e.g. a log sink.
+type NotifierFunc func(channel, format string, args ...interface{}) error
+
+func (f NotifierFunc) NotifyChannel(channel, format string, args ...interface{}) error {
+	return f(channel, format, args...)
+}
+
+// MultiNotifier fans out the message to all the notifiers in order, so the strategy only holds one notifier. It's a
+// Notifier as well, so it can be nested.
+type MultiNotifier struct {
+	notifiers []Notifier
+}
+
+func NewMultiNotifier(notifiers ...Notifier) *MultiNotifier {
+	return &MultiNotifier{notifiers: notifiers}
+}
+
+// Add adds the notifier, it's not safe to call it concurrently with NotifyChannel.
+func (m *MultiNotifier) Add(notifier Notifier) {
+	m.notifiers = append(m.notifiers, notifier)
+}
+
+// NotifyChannel posts the message to every notifier even if some of them fail, the errors are combined with the index
+// and the type of the failed notifiers, multierr.Errors splits them.
+func (m *MultiNotifier) NotifyChannel(channel, format string, args ...interface{}) (err error) {
+	for i, notifier := range m.notifiers {
+		if err2 := notifier.NotifyChannel(channel, format, args...); err2 != nil {
+			err = multierr.Append(err, fmt.Errorf("notifier #%d (%T): %w", i, notifier, err2))
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/notifier/slacknotifier"
+)
+
+var _ Notifier = &slacknotifier.Notifier{}
+
+func TestMultiNotifier(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = append(posted, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	slack := slacknotifier.NewWebhook(server.URL+"/default", slacknotifier.WithChannelWebhook("#alerts", server.URL+"/alerts"))
+	defer slack.Close()
+
+	var logs []string
+	logSink := NotifierFunc(func(channel, format string, args ...interface{}) error {
+		logs = append(logs, channel+": "+fmt.Sprintf(format, args...))
+		return nil
+	})
+
+	errFailed := errors.New("failed")
+	failed := NotifierFunc(func(channel, format string, args ...interface{}) error {
+		return errFailed
+	})
+
+	multi := NewMultiNotifier(failed, slack)
+	multi.Add(logSink)
+	multi.Add(NewMultiNotifier(failed))
+
+	err := multi.NotifyChannel("#alerts", "position %s liquidated", "BTCUSDT")
+	// the message is posted to all the notifiers despite the failed ones
+	assert.Equal(t, []string{"/alerts"}, posted)
+	assert.Equal(t, []string{"#alerts: position BTCUSDT liquidated"}, logs)
+
+	errs := multierr.Errors(err)
+	if assert.Len(t, errs, 2) {
+		assert.ErrorIs(t, errs[0], errFailed)
+		assert.ErrorContains(t, errs[0], "notifier #0")
+		assert.ErrorIs(t, errs[1], errFailed)
+		assert.ErrorContains(t, errs[1], "notifier #3")
+	}
+
+	assert.NoError(t, NewMultiNotifier(logSink).NotifyChannel("", "ok"))
+}
@@ -0,0 +1,195 @@
+package slacknotifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// defaultMaxAttachmentFields is the max number of the fields of an attachment, slack doesn't render the fields
+	// beyond it reliably.
+	defaultMaxAttachmentFields = 20
+
+	// defaultMaxFieldValueLength is the max number of characters of a field value, slack truncates the longer value
+	// without any notice.
+	defaultMaxFieldValueLength = 2000
+
+	continuedSuffix = " (continued)"
+)
+
+// attachmentLimits guards the attachments against the slack limits, see WithAttachmentLimits.
+type attachmentLimits struct {
+	maxFields           int
+	maxFieldValueLength int
+}
+
+// WithAttachmentLimits sets the max number of the fields of an attachment and the max number of characters of a field
+// value. The attachment with more fields is split into the continued attachments, and the longer value is trimmed with
+// an ellipsis. Both of them are logged as warnings. Zero disables the corresponding guard.
+func WithAttachmentLimits(maxFields, maxFieldValueLength int) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.attachmentLimits = attachmentLimits{
+			maxFields:           maxFields,
+			maxFieldValueLength: maxFieldValueLength,
+		}
+	}
+}
+
+// attachmentFooter is the footer of every posted attachment, see WithFooter.
+type attachmentFooter struct {
+	text    string
+	iconURL string
+}
+
+// WithFooter sets the footer text and the footer icon of every attachment of the posted messages, e.g. the bot name,
+// along with the timestamp of the post time, so the messages have the same auditable format. It applies to the
+// messages of PostMessage, Update and Schedule as well, the scheduled message is stamped with its post time. A minimal attachment with
+// only the footer is added to the message without any attachment. The footer of the trace id, see NotifyContext, is
+// kept after the footer text.
+func WithFooter(text, iconURL string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.footer = attachmentFooter{text: text, iconURL: iconURL}
+	}
+}
+
+func (f attachmentFooter) enabled() bool {
+	return len(f.text) > 0 || len(f.iconURL) > 0
+}
+
+// apply returns the attachments with the footer and the timestamp of the given time, the given attachments are not
+// modified.
+func (f attachmentFooter) apply(attachments []slack.Attachment, now time.Time) []slack.Attachment {
+	if !f.enabled() {
+		return attachments
+	}
+
+	if len(attachments) == 0 {
+		attachments = []slack.Attachment{{}}
+	} else {
+		attachments = append([]slack.Attachment(nil), attachments...)
+	}
+
+	ts := json.Number(strconv.FormatInt(now.Unix(), 10))
+	for i := range attachments {
+		a := &attachments[i]
+		switch {
+		case len(a.Footer) == 0:
+			a.Footer = f.text
+		case len(f.text) > 0:
+			a.Footer = f.text + " | " + a.Footer
+		}
+
+		if len(f.iconURL) > 0 {
+			a.FooterIcon = f.iconURL
+		}
+		a.Ts = ts
+	}
+
+	return attachments
+}
+
+// apply returns the attachments within the limits, the given attachments are not modified.
+func (l attachmentLimits) apply(logger *log.Entry, channel string, attachments []slack.Attachment) []slack.Attachment {
+	if len(attachments) == 0 || (l.maxFields <= 0 && l.maxFieldValueLength <= 0) {
+		return attachments
+	}
+
+	var result []slack.Attachment
+	for _, attachment := range attachments {
+		attachment.Fields = l.trimFields(logger, channel, attachment)
+
+		if l.maxFields <= 0 || len(attachment.Fields) <= l.maxFields {
+			result = append(result, attachment)
+			continue
+		}
+
+		logger.WithField("channel", channel).Warnf("slack attachment %q to channel %s has %d fields, split it into the attachments of %d fields",
+			attachmentName(attachment), channel, len(attachment.Fields), l.maxFields)
+
+		fields := attachment.Fields
+		attachment.Fields = fields[:l.maxFields]
+		result = append(result, attachment)
+
+		for fields = fields[l.maxFields:]; len(fields) > 0; {
+			n := l.maxFields
+			if n > len(fields) {
+				n = len(fields)
+			}
+
+			// the continued attachment only keeps the appearance of the original one
+			result = append(result, slack.Attachment{
+				Color:  attachment.Color,
+				Title:  attachment.Title + continuedSuffix,
+				Fields: fields[:n],
+			})
+			fields = fields[n:]
+		}
+	}
+
+	return result
+}
+
+// trimFields returns the fields of which the values are trimmed to the max length.
+func (l attachmentLimits) trimFields(logger *log.Entry, channel string, attachment slack.Attachment) []slack.AttachmentField {
+	if l.maxFieldValueLength <= 0 {
+		return attachment.Fields
+	}
+
+	var fields []slack.AttachmentField
+	for i, field := range attachment.Fields {
+		value := truncateText(field.Value, l.maxFieldValueLength)
+		if value == field.Value {
+			continue
+		}
+
+		if fields == nil {
+			fields = make([]slack.AttachmentField, len(attachment.Fields))
+			copy(fields, attachment.Fields)
+		}
+
+		logger.WithField("channel", channel).Warnf("slack attachment %q to channel %s has the field %q of %d characters, trim it to %d characters",
+			attachmentName(attachment), channel, field.Title, len([]rune(field.Value)), l.maxFieldValueLength)
+		fields[i].Value = value
+	}
+
+	if fields == nil {
+		return attachment.Fields
+	}
+
+	return fields
+}
+
+func attachmentName(attachment slack.Attachment) string {
+	if len(attachment.Title) > 0 {
+		return attachment.Title
+	}
+
+	if len(attachment.Fallback) > 0 {
+		return attachment.Fallback
+	}
+
+	return fmt.Sprintf("%.20s", attachment.Text)
+}
+
+// fallbackText returns the plain text summary of the attachments for the attachment-only message, since the slack
+// notifications and the search only show the message text. Each attachment is summarized by its fallback, pretext or
+// title, whichever is set first. The attachments without any of them are skipped.
+func fallbackText(attachments []slack.Attachment) string {
+	var lines []string
+	for _, attachment := range attachments {
+		for _, text := range []string{attachment.Fallback, attachment.Pretext, attachment.Title} {
+			if len(text) > 0 {
+				lines = append(lines, text)
+				break
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
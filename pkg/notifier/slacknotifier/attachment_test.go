@@ -0,0 +1,107 @@
+package slacknotifier
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLogger = log.WithField("notifier", "slack")
+
+func newTestFields(n int) []slack.AttachmentField {
+	var fields []slack.AttachmentField
+	for i := 0; i < n; i++ {
+		fields = append(fields, slack.AttachmentField{
+			Title: fmt.Sprintf("field %d", i),
+			Value: fmt.Sprintf("%d", i),
+		})
+	}
+	return fields
+}
+
+func TestAttachmentLimits_apply(t *testing.T) {
+	t.Run("split fields", func(t *testing.T) {
+		limits := attachmentLimits{maxFields: 2}
+		attachments := limits.apply(testLogger, "#pnl", []slack.Attachment{
+			{Color: "good", Title: "PnL", Text: "report", Fields: newTestFields(5)},
+			{Title: "other", Fields: newTestFields(1)},
+		})
+
+		if assert.Len(t, attachments, 4) {
+			assert.Equal(t, "PnL", attachments[0].Title)
+			assert.Equal(t, "report", attachments[0].Text)
+			assert.Equal(t, newTestFields(2), attachments[0].Fields)
+
+			assert.Equal(t, "PnL (continued)", attachments[1].Title)
+			assert.Equal(t, "good", attachments[1].Color)
+			assert.Empty(t, attachments[1].Text)
+			assert.Equal(t, newTestFields(4)[2:], attachments[1].Fields)
+
+			assert.Equal(t, newTestFields(5)[4:], attachments[2].Fields)
+			assert.Equal(t, "other", attachments[3].Title)
+		}
+	})
+
+	t.Run("trim values", func(t *testing.T) {
+		limits := attachmentLimits{maxFieldValueLength: 10}
+		fields := []slack.AttachmentField{
+			{Title: "short", Value: "ok"},
+			{Title: "long", Value: strings.Repeat("x", 20)},
+		}
+
+		attachments := limits.apply(testLogger, "#pnl", []slack.Attachment{{Title: "PnL", Fields: fields}})
+		if assert.Len(t, attachments, 1) {
+			assert.Equal(t, "ok", attachments[0].Fields[0].Value)
+			assert.Equal(t, "xxxxxxx...", attachments[0].Fields[1].Value)
+		}
+
+		// the given fields are not modified
+		assert.Equal(t, strings.Repeat("x", 20), fields[1].Value)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		limits := attachmentLimits{}
+		attachments := []slack.Attachment{{Fields: newTestFields(100)}}
+		assert.Equal(t, attachments, limits.apply(testLogger, "#pnl", attachments))
+	})
+}
+
+func TestFallbackText(t *testing.T) {
+	assert.Equal(t, "BTCUSDT PnL\nprofit: 10\nTrade", fallbackText([]slack.Attachment{
+		{Fallback: "BTCUSDT PnL", Pretext: "pretext", Title: "PnL"},
+		{Pretext: "profit: 10", Title: "profit"},
+		{Text: "no summary"},
+		{Title: "Trade"},
+	}))
+	assert.Empty(t, fallbackText(nil))
+
+	n := New(nil, "#general", WithAttachmentLimits(1, 0))
+	defer n.Close()
+
+	task := n.newTask("", slack.Attachment{Title: "PnL", Fields: newTestFields(3)})
+	assert.Equal(t, "PnL", task.Text)
+	assert.Len(t, task.Attachments, 3)
+
+	// the explicit text is kept
+	task = n.newTask("", "report %s", "BTCUSDT", slack.Attachment{Title: "PnL"})
+	assert.Equal(t, "report BTCUSDT", task.Text)
+}
+
+func TestAttachmentFooter_apply(t *testing.T) {
+	now := time.Unix(1710374340, 0)
+
+	attachments := []slack.Attachment{{Text: "fill"}}
+	assert.Equal(t, attachments, attachmentFooter{}.apply(attachments, now))
+
+	footer := attachmentFooter{text: "bbgo"}
+	assert.Equal(t, []slack.Attachment{{Footer: "bbgo", Ts: "1710374340"}}, footer.apply(nil, now))
+
+	// the given attachments are not modified
+	assert.Equal(t, []slack.Attachment{{Text: "fill", Footer: "bbgo", Ts: "1710374340"}}, footer.apply(attachments, now))
+	assert.Equal(t, []slack.Attachment{{Text: "fill"}}, attachments)
+}
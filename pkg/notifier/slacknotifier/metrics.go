@@ -0,0 +1,19 @@
+package slacknotifier
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	metricsNotifications = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_slack_notifications_total",
+			Help: "the slack notifications by the channel and the result: sent, error, rate_limited, retry, dry_run, dropped or throttled",
+		},
+		[]string{"channel", "result"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricsNotifications,
+	)
+}
@@ -3,38 +3,464 @@ package slacknotifier
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
 
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/style"
 	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util/traceid"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackutilsx"
 )
 
 var limiter = rate.NewLimiter(rate.Every(1*time.Second), 3)
 
+// defaultMaxMessageLength is the max length of the message text, slack rejects the text longer than ~4000 characters.
+const defaultMaxMessageLength = 4000
+
+const ellipsis = "..."
+
+const (
+	// maxPostRetries is the max number of the retries of a message rejected by the slack rate limit.
+	maxPostRetries = 2
+	// maxPostRetryAfter is the max wait before retrying, the message which must wait longer is not retried.
+	maxPostRetryAfter = 10 * time.Second
+)
+
+// ErrWebhookNotSupported is returned by the operations the incoming webhook can't do, e.g. updating a message.
+var ErrWebhookNotSupported = errors.New("the operation is not supported by the incoming webhook")
+
+// ErrThrottled is returned by NotifyTrade and NotifyPnL if the message exceeds the rate limit of the channel, see
+// WithChannelRateLimit.
+var ErrThrottled = errors.New("the message exceeds the rate limit of the channel")
+
+// ErrNotEnqueued is returned by Broadcast in the async mode if the message is throttled, or dropped since the queue
+// is full or the notifier is closed.
+var ErrNotEnqueued = errors.New("the message is not enqueued")
+
+// Severity is the severity of the message, see NotifyWithSeverity.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// severityStyle is the channel and the formatting of the messages of a severity, see NotifyWithSeverity.
+type severityStyle struct {
+	// channel overrides the routed channel if it's not empty.
+	channel string
+	// emoji is prepended to the text, e.g. :warning:.
+	emoji string
+	// color is set to the attachments without a color.
+	color string
+}
+
+var defaultSeverityStyles = map[Severity]severityStyle{
+	SeverityInfo:     {emoji: ":information_source:", color: "#439FE0"},
+	SeverityWarning:  {emoji: ":warning:", color: "warning"},
+	SeverityCritical: {emoji: ":rotating_light:", color: "danger"},
+}
+
+// MentionUser returns the mrkdwn which mentions the user of the given id, e.g. <@U123>. The mention only works in the
+// text sent by NotifyMarkdown, since the plain text is escaped.
+func MentionUser(id string) string {
+	return "<@" + id + ">"
+}
+
+// MentionGroup returns the mrkdwn which mentions the user group of the given id, e.g. <!subteam^S123>. Like
+// MentionUser, it only works in the text sent by NotifyMarkdown.
+func MentionGroup(id string) string {
+	return "<!subteam^" + id + ">"
+}
+
 type notifyTask struct {
-	Channel string
-	Opts    []slack.MsgOption
+	Channel     string
+	Text        string
+	Attachments []slack.Attachment
+	Blocks      []slack.Block
+
+	// Markdown sends the text as is, so the mrkdwn mentions like <@U123> and the links like <https://...|text> work,
+	// otherwise the &, < and > in the text are escaped, see NotifyMarkdown.
+	Markdown bool
+
+	// Options are the extra message options appended after the options of the notifier, see NotifyWithOptions.
+	Options []slack.MsgOption
+
+	// TraceID is the trace id of the context, it's logged and shown in the footer, see NotifyContext.
+	TraceID string
+
+	// ThreadSymbol groups the message into the rolling thread of the symbol, see WithSymbolThreads.
+	ThreadSymbol string
+}
+
+// setTraceID sets the trace id and shows it in the footer of the last attachment, a new attachment is added if there
+// is no attachment or the footer is used already.
+func (t *notifyTask) setTraceID(id string) {
+	if len(id) == 0 {
+		return
+	}
+
+	t.TraceID = id
+	footer := traceid.LogField + ": " + id
+	if n := len(t.Attachments); n > 0 && len(t.Attachments[n-1].Footer) == 0 {
+		t.Attachments = append([]slack.Attachment(nil), t.Attachments...)
+		t.Attachments[n-1].Footer = footer
+		return
+	}
+	t.Attachments = append(t.Attachments, slack.Attachment{Footer: footer})
+}
+
+// escapedText returns the text to send, it's escaped unless the task is in the markdown mode.
+func (t notifyTask) escapedText() string {
+	if t.Markdown {
+		return t.Text
+	}
+	return slackutilsx.EscapeMessage(t.Text)
+}
+
+// renderText returns the task with the final text to send: the text is escaped unless it's in the markdown mode, then
+// truncated to maxMessageLength as the last step, so the escaped characters and the prefixes like the severity emoji
+// and the mention can't exceed the limit. The rendered task is in the markdown mode, rendering it again is a no-op.
+func (n *Notifier) renderText(task notifyTask) notifyTask {
+	task.Text = truncateEscapedText(task.escapedText(), n.maxMessageLength)
+	task.Markdown = true
+	return task
+}
+
+func (t notifyTask) msgOptions() []slack.MsgOption {
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(t.escapedText(), false),
+		slack.MsgOptionAttachments(t.Attachments...),
+	}
+
+	if len(t.Blocks) > 0 {
+		opts = append(opts, slack.MsgOptionBlocks(t.Blocks...))
+	}
+
+	return opts
+}
+
+func (t notifyTask) webhookMessage() *slack.WebhookMessage {
+	msg := &slack.WebhookMessage{
+		Text:        t.escapedText(),
+		Attachments: t.Attachments,
+	}
+
+	if len(t.Blocks) > 0 {
+		msg.Blocks = &slack.Blocks{BlockSet: t.Blocks}
+	}
+
+	return msg
 }
 
 type Notifier struct {
 	client  *slack.Client
 	channel string
 
+	// webhookURL is the incoming webhook url, the notifier posts messages via the webhook instead of the client if
+	// it's set.
+	webhookURL string
+
+	// channelWebhookURLs maps the channel to its incoming webhook url, since one webhook can only post to one channel.
+	channelWebhookURLs map[string]string
+
+	// username, iconEmoji and iconURL override the identity of the bot, see WithUsername.
+	username  string
+	iconEmoji string
+	iconURL   string
+
+	// maxMessageLength is the max number of characters of the message text, the longer text is truncated.
+	maxMessageLength int
+
+	// attachmentLimits splits or trims the attachments beyond the slack limits, see WithAttachmentLimits.
+	attachmentLimits attachmentLimits
+
+	// footer is added to every posted attachment with the post time, see WithFooter.
+	footer attachmentFooter
+
+	// signValue extracts the value for coloring the attachment, see WithSignColoring.
+	signValue func(obj interface{}) (fixedpoint.Value, bool)
+
+	// tradeChannel is the channel for NotifyTrade, the default channel is used if it's empty.
+	tradeChannel string
+
+	// pnlChannel is the channel for NotifyPnL, the default channel is used if it's empty.
+	pnlChannel string
+
+	// channelRouter picks the channel by the notified object, see WithChannelRouter.
+	channelRouter func(obj interface{}) string
+
+	// severityMention is prepended to the messages of which the severity is at least minMentionSeverity.
+	severityMention    string
+	minMentionSeverity Severity
+
+	// severityStyles are the channels and the formatting of the severities, see NotifyWithSeverity.
+	severityStyles map[Severity]severityStyle
+
+	// dryRun logs the messages instead of calling the slack api, see WithDryRun.
+	dryRun bool
+
+	// asyncBroadcast makes Broadcast enqueue the messages instead of posting them, see WithAsyncBroadcast.
+	asyncBroadcast bool
+
+	// throttler suppresses the notification storms, see WithDeduplication and WithChannelRateLimit.
+	throttler messageThrottler
+
+	// symbolThreads groups the messages of the same symbol into the rolling threads, see WithSymbolThreads.
+	symbolThreads symbolThreads
+
+	// logger logs with the notifier field and the fields given by WithLogger.
+	logger *log.Entry
+
 	taskC chan notifyTask
+
+	// pendingTasks counts the enqueued tasks which are not posted yet, it's used by Flush.
+	pendingTasks pendingCounter
+	// droppedTasks counts the tasks dropped since the queue is full.
+	droppedTasks uint64
+
+	stats notifyStats
+
+	// closeMutex guards taskC from being sent after it's closed.
+	closeMutex sync.RWMutex
+	closed     bool
+	done       chan struct{}
+}
+
+// pendingCounter counts the pending tasks. Unlike sync.WaitGroup, the tasks can be added while Flush is waiting.
+type pendingCounter struct {
+	mu    sync.Mutex
+	count int
+	// idleC is closed when the count drops to zero, it's nil while no task is pending.
+	idleC chan struct{}
+}
+
+func (c *pendingCounter) add(delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.count == 0 && delta > 0 {
+		c.idleC = make(chan struct{})
+	}
+
+	c.count += delta
+	if c.count == 0 && c.idleC != nil {
+		close(c.idleC)
+		c.idleC = nil
+	}
+}
+
+// idle returns the channel closed when no task is pending.
+func (c *pendingCounter) idle() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.idleC == nil {
+		idleC := make(chan struct{})
+		close(idleC)
+		return idleC
+	}
+	return c.idleC
 }
 
 type NotifyOption func(notifier *Notifier)
 
+// WithChannelWebhook adds an incoming webhook url for the given channel.
+// It only takes effect with the notifier created by NewWebhook.
+func WithChannelWebhook(channel, webhookURL string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.channelWebhookURLs[channel] = webhookURL
+	}
+}
+
+// WithUsername posts the messages under the given username, so the bots posting to the same channel can be told
+// apart. The override requires the bot token with the chat:write.customize scope, it's ignored by the incoming
+// webhooks created after the app directory deprecation.
+func WithUsername(username string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.username = username
+	}
+}
+
+// WithIconEmoji posts the messages with the given emoji icon, e.g. ":robot_face:".
+// Like WithUsername, it requires the chat:write.customize scope.
+func WithIconEmoji(iconEmoji string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.iconEmoji = iconEmoji
+	}
+}
+
+// WithIconURL posts the messages with the icon image of the given url.
+// Like WithUsername, it requires the chat:write.customize scope.
+func WithIconURL(iconURL string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.iconURL = iconURL
+	}
+}
+
+// WithTradeChannel sets the channel for NotifyTrade.
+func WithTradeChannel(channel string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.tradeChannel = channel
+	}
+}
+
+// WithPnLChannel sets the channel for NotifyPnL.
+func WithPnLChannel(channel string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.pnlChannel = channel
+	}
+}
+
+// WithMaxMessageLength sets the max number of characters of the message text, the longer text is truncated with an
+// ellipsis. The limit applies to the final text, after the escaping and the prefixes like the severity emoji and the
+// mention. The attachments are not affected. Zero disables the truncation.
+func WithMaxMessageLength(n int) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.maxMessageLength = n
+	}
+}
+
+// WithSignColoring colors the attachment of the SlackAttachmentCreator by the sign of the value extracted from the
+// creator, green for the positive value, red for the negative value and gray for zero. The attachment with a color is
+// not changed, and so is the creator which the extractor returns false for.
+func WithSignColoring(extractor func(obj interface{}) (fixedpoint.Value, bool)) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.signValue = extractor
+	}
+}
+
+// WithChannelRouter sets the router which picks the channel for Notify by the notified object. The router is called
+// with the object first and then the arguments, the first non-empty channel is used. Notify falls back to the default
+// channel if the router returns empty channels for all of them.
+func WithChannelRouter(router func(obj interface{}) string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.channelRouter = router
+	}
+}
+
+// WithSeverityMention prepends the mention, e.g. MentionGroup("S123"), to the messages sent by NotifyWithSeverity,
+// NotifyInfo, NotifyWarn and NotifyError of which the severity is at least minSeverity.
+func WithSeverityMention(minSeverity Severity, mention string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.minMentionSeverity = minSeverity
+		notifier.severityMention = mention
+	}
+}
+
+// WithSeverityChannel posts the messages of the severity sent by NotifyWithSeverity to the channel instead of the
+// routed channel, e.g. the errors to #alerts.
+func WithSeverityChannel(severity Severity, channel string) NotifyOption {
+	return func(notifier *Notifier) {
+		style := notifier.severityStyles[severity]
+		style.channel = channel
+		notifier.severityStyles[severity] = style
+	}
+}
+
+// WithSeverityStyle overrides the emoji prepended to the text and the color of the attachments of the severity, the
+// empty value disables it. The defaults are :information_source:, :warning: and :rotating_light: with blue, yellow
+// and red.
+func WithSeverityStyle(severity Severity, emoji, color string) NotifyOption {
+	return func(notifier *Notifier) {
+		style := notifier.severityStyles[severity]
+		style.emoji = emoji
+		style.color = color
+		notifier.severityStyles[severity] = style
+	}
+}
+
+// WithDryRun logs the rendered messages and the attachment summaries at info level instead of calling the slack api,
+// it's useful for the backtests and the local development where no token or network is available.
+func WithDryRun(dryRun bool) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.dryRun = dryRun
+	}
+}
+
+// WithLogger logs with the given entry, so the logs can be filtered by its fields, e.g. the strategy. The notifier
+// field is added to it.
+func WithLogger(logger *log.Entry) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.logger = logger.WithField("notifier", "slack")
+	}
+}
+
+// WithAsyncBroadcast makes Broadcast enqueue the message to every channel like Notify instead of posting it
+// synchronously.
+func WithAsyncBroadcast(async bool) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.asyncBroadcast = async
+	}
+}
+
+// WithDeduplication coalesces the identical messages of a channel within the window, the first message after the window
+// carries the "repeated N times in last <window>" suffix. The rendered text is the dedup key, so the messages without
+// text are not deduplicated.
+func WithDeduplication(window time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.throttler.dedupWindow = window
+	}
+}
+
+// WithChannelRateLimit limits the messages of every channel to one per interval with the burst, the messages beyond
+// the limit are dropped.
+func WithChannelRateLimit(interval time.Duration, burst int) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.throttler.rateInterval = interval
+		notifier.throttler.rateBurst = burst
+	}
+}
+
+// WithSymbolThreads groups the async messages given with a ThreadSymbol arg into one thread per channel and symbol.
+// The first message of the symbol in the window is posted as the parent, and the following ones are replied in its
+// thread until the window ends, e.g. 24 * time.Hour starts a new thread every day at the midnight in UTC. The
+// messages posted by the webhook are not threaded since the webhook doesn't return the timestamp of the message.
+func WithSymbolThreads(window time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.symbolThreads.window = window
+	}
+}
+
 func New(client *slack.Client, channel string, options ...NotifyOption) *Notifier {
 	notifier := &Notifier{
-		channel: channel,
-		client:  client,
-		taskC:   make(chan notifyTask, 100),
+		channel:            channel,
+		client:             client,
+		channelWebhookURLs: map[string]string{},
+		maxMessageLength:   defaultMaxMessageLength,
+		attachmentLimits: attachmentLimits{
+			maxFields:           defaultMaxAttachmentFields,
+			maxFieldValueLength: defaultMaxFieldValueLength,
+		},
+		throttler: messageThrottler{
+			entries:  map[string]map[string]*dedupEntry{},
+			limiters: map[string]*rate.Limiter{},
+		},
+		symbolThreads: symbolThreads{
+			threads: map[string]symbolThread{},
+		},
+		severityStyles: map[Severity]severityStyle{},
+		logger:         log.WithField("notifier", "slack"),
+		taskC:          make(chan notifyTask, 100),
+		done:           make(chan struct{}),
+	}
+
+	for severity, style := range defaultSeverityStyles {
+		notifier.severityStyles[severity] = style
 	}
 
 	for _, o := range options {
@@ -46,31 +472,378 @@ func New(client *slack.Client, channel string, options ...NotifyOption) *Notifie
 	return notifier
 }
 
+// NewWebhook creates the notifier which posts messages via the incoming webhook. This is useful when you can't create
+// a bot token. The webhook has a fixed channel, so the channel argument is only used to select the webhook added by
+// WithChannelWebhook, otherwise the message is posted to the default webhook.
+func NewWebhook(webhookURL string, options ...NotifyOption) *Notifier {
+	return New(nil, "", append([]NotifyOption{withWebhookURL(webhookURL)}, options...)...)
+}
+
+func withWebhookURL(webhookURL string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.webhookURL = webhookURL
+	}
+}
+
+func (n *Notifier) isWebhook() bool {
+	return len(n.webhookURL) > 0
+}
+
+func (n *Notifier) getWebhookURL(channel string) string {
+	if url, ok := n.channelWebhookURLs[channel]; ok {
+		return url
+	}
+
+	return n.webhookURL
+}
+
+// post posts the task and returns the timestamp of the posted message, the timestamp is empty if the message is
+// posted by the webhook or in the dry run mode.
+func (n *Notifier) post(ctx context.Context, task notifyTask) (string, error) {
+	return n.send(ctx, task, time.Now(), n.doPost)
+}
+
+// send adds the footer of the post time to the task and sends it by the given function, so the messages posted,
+// updated and scheduled have the same format. The message rejected by the slack rate limit is retried after the
+// retry-after duration up to maxPostRetries times.
+func (n *Notifier) send(ctx context.Context, task notifyTask, postTime time.Time,
+	do func(ctx context.Context, task notifyTask) (string, error)) (string, error) {
+	task = n.renderText(task)
+	task.Attachments = n.footer.apply(task.Attachments, postTime)
+	if n.dryRun {
+		n.logDryRun(task)
+		n.stats.dryRun(task.Channel)
+		return "", nil
+	}
+
+	for retries := 0; ; retries++ {
+		ts, err := do(ctx, task)
+
+		var rateLimitedErr *slack.RateLimitedError
+		if err == nil || retries >= maxPostRetries || !errors.As(err, &rateLimitedErr) ||
+			rateLimitedErr.RetryAfter > maxPostRetryAfter {
+			n.stats.record(task.Channel, err)
+			return ts, err
+		}
+
+		n.stats.retry(task.Channel)
+		select {
+		case <-time.After(rateLimitedErr.RetryAfter):
+		case <-ctx.Done():
+			n.stats.record(task.Channel, err)
+			return "", err
+		}
+	}
+}
+
+func (n *Notifier) doPost(ctx context.Context, task notifyTask) (string, error) {
+	if n.isWebhook() {
+		if len(task.Options) > 0 {
+			n.logger.WithField("channel", task.Channel).Warnf("the message options are not supported by the webhook, ignore %d options", len(task.Options))
+		}
+
+		msg := task.webhookMessage()
+		msg.Username = n.username
+		msg.IconEmoji = n.iconEmoji
+		msg.IconURL = n.iconURL
+		return "", slack.PostWebhookContext(ctx, n.getWebhookURL(task.Channel), msg)
+	}
+
+	_, ts, err := n.client.PostMessageContext(ctx, task.Channel, n.postOptions(task)...)
+	return ts, err
+}
+
+// taskLogger returns the logger with the channel and the trace id of the task.
+func (n *Notifier) taskLogger(task notifyTask) *log.Entry {
+	logger := n.logger.WithField("channel", task.Channel)
+	if len(task.TraceID) > 0 {
+		logger = logger.WithField(traceid.LogField, task.TraceID)
+	}
+	return logger
+}
+
+// logDryRun logs the task which would be posted in the dry run mode.
+func (n *Notifier) logDryRun(task notifyTask) {
+	var attachments []string
+	for _, a := range task.Attachments {
+		summary := a.Title
+		if len(summary) == 0 {
+			summary = a.Text
+		}
+		attachments = append(attachments, fmt.Sprintf("%q (%d fields)", summary, len(a.Fields)))
+	}
+
+	n.taskLogger(task).WithFields(log.Fields{
+		"attachments": attachments,
+		"blocks":      len(task.Blocks),
+	}).Infof("[dry run] slack message: %s", task.Text)
+}
+
+// postOptions composes the options of the task and the identity of the bot first, then the extra options of the
+// task, so the extra options can override them.
+func (n *Notifier) postOptions(task notifyTask) []slack.MsgOption {
+	opts := append(task.msgOptions(), n.identityOptions()...)
+	return append(opts, task.Options...)
+}
+
+func (n *Notifier) identityOptions() (opts []slack.MsgOption) {
+	if len(n.username) > 0 {
+		opts = append(opts, slack.MsgOptionUsername(n.username))
+	}
+
+	if len(n.iconEmoji) > 0 {
+		opts = append(opts, slack.MsgOptionIconEmoji(n.iconEmoji))
+	}
+
+	if len(n.iconURL) > 0 {
+		opts = append(opts, slack.MsgOptionIconURL(n.iconURL))
+	}
+
+	return opts
+}
+
 func (n *Notifier) worker() {
+	defer close(n.done)
+
 	ctx := context.Background()
-	for {
+	for task := range n.taskC {
+		limiter.Wait(ctx)
+
+		now := time.Now()
+		newThread := n.threadTask(&task, now)
+		ts, err := n.post(ctx, task)
+		if err != nil {
+			n.taskLogger(task).WithError(err).
+				Errorf("slack api error: %s", err.Error())
+		} else if newThread {
+			n.symbolThreads.start(task, ts, now)
+		}
+
+		n.pendingTasks.add(-1)
+	}
+}
+
+// enqueue sends the task to the worker unless it's suppressed by the throttler. It waits for the given timeout if the
+// queue is full, and the task is dropped if the timeout is zero or exceeded.
+func (n *Notifier) enqueue(task notifyTask, timeout time.Duration) bool {
+	if n.throttler.enabled() {
+		text, ok := n.throttler.allow(task.Channel, task.Text, time.Now())
+		if !ok {
+			n.stats.throttle(task.Channel)
+			return false
+		}
+		task.Text = text
+	}
+
+	n.closeMutex.RLock()
+	defer n.closeMutex.RUnlock()
+
+	if n.closed {
+		n.taskLogger(task).Warnf("slack notifier is closed, drop the message to channel %s", task.Channel)
+		return false
+	}
+
+	n.pendingTasks.add(1)
+
+	select {
+	case n.taskC <- task:
+		return true
+	default:
+	}
+
+	if timeout > 0 {
 		select {
-		case <-ctx.Done():
-			return
+		case n.taskC <- task:
+			return true
+		case <-time.After(timeout):
+		}
+	}
+
+	n.pendingTasks.add(-1)
+	atomic.AddUint64(&n.droppedTasks, 1)
+	n.stats.drop(task.Channel)
+	n.taskLogger(task).Warnf("slack notification queue is full, drop the message to channel %s", task.Channel)
+	return false
+}
+
+// allowRate applies the rate limit of the channel to the synchronous post, the throttled message is counted.
+func (n *Notifier) allowRate(channel string) bool {
+	if n.throttler.allowRate(channel, time.Now()) {
+		return true
+	}
+
+	n.stats.throttle(channel)
+	return false
+}
 
-		case task := <-n.taskC:
-			limiter.Wait(ctx)
+// NotifyAsync enqueues the message and returns immediately, the message is dropped if the queue is full.
+// The messages are posted in order by a single worker.
+func (n *Notifier) NotifyAsync(channel string, format string, args ...interface{}) {
+	n.enqueue(n.newTask(channel, format, args...), 0)
+}
+
+// DroppedMessages returns the number of the messages dropped since the queue is full.
+func (n *Notifier) DroppedMessages() uint64 {
+	return atomic.LoadUint64(&n.droppedTasks)
+}
+
+// Stats returns the notification counters by the channel, the counters are also exported as the prometheus metric
+// bbgo_slack_notifications_total.
+func (n *Notifier) Stats() map[string]ChannelStats {
+	return n.stats.snapshot()
+}
+
+// Flush waits until the enqueued messages are posted or the context is done. The messages enqueued while waiting are
+// waited for as well.
+func (n *Notifier) Flush(ctx context.Context) error {
+	select {
+	case <-n.pendingTasks.idle():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting new messages and waits for the enqueued messages to be posted.
+func (n *Notifier) Close() error {
+	n.closeMutex.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.taskC)
+	}
+	n.closeMutex.Unlock()
+
+	<-n.done
+	return nil
+}
+
+// Notify posts the object to the routed channel asynchronously. The attachment-only message gets the text summarized
+// from the attachments, so that the notification isn't empty.
+func (n *Notifier) Notify(obj interface{}, args ...interface{}) {
+	n.NotifyTo(n.routeChannel(obj, args...), obj, args...)
+}
+
+// NotifyMarkdown notifies the object like Notify, but the text is sent as mrkdwn without escaping, so the mentions
+// like MentionUser and the links like <https://...|text> work. The text must not contain untrusted input, which could
+// mention everyone in the channel. Notify escapes the text by default.
+func (n *Notifier) NotifyMarkdown(obj interface{}, args ...interface{}) {
+	task := n.newTask(n.routeChannel(obj, args...), obj, args...)
+	task.Markdown = true
+	n.enqueue(task, 50*time.Millisecond)
+}
+
+// NotifyWithOptions notifies the object like Notify with the extra slack message options, e.g.
+// slack.MsgOptionDisableLinkUnfurl() or slack.MsgOptionBroadcast(). The options are appended after the text and the
+// attachments, so they take precedence. The options are ignored by the webhook.
+func (n *Notifier) NotifyWithOptions(obj interface{}, options ...slack.MsgOption) {
+	task := n.newTask(n.routeChannel(obj), obj)
+	task.Options = options
+	n.enqueue(task, 50*time.Millisecond)
+}
+
+// NotifyContext notifies the object like Notify with the trace id of the context, see the traceid package. The trace
+// id is shown in the footer of the message and logged with the errors, so the notification can be correlated to the
+// order requests of the same strategy action.
+func (n *Notifier) NotifyContext(ctx context.Context, obj interface{}, args ...interface{}) {
+	task := n.newTask(n.routeChannel(obj, args...), obj, args...)
+	if id, ok := traceid.FromContext(ctx); ok {
+		task.setTraceID(id)
+	}
+	n.enqueue(task, 50*time.Millisecond)
+}
+
+// NotifyWithSeverity notifies the object with the style of the severity: the message is posted to the channel set by
+// WithSeverityChannel if any, the text is prefixed with the emoji of the severity, the attachments without a color are
+// colored, see WithSeverityStyle, and the mention set by WithSeverityMention is prepended if the severity is high
+// enough. Only the text is escaped, so the mention still works.
+func (n *Notifier) NotifyWithSeverity(severity Severity, obj interface{}, args ...interface{}) {
+	style := n.severityStyles[severity]
+
+	channel := style.channel
+	if len(channel) == 0 {
+		channel = n.routeChannel(obj, args...)
+	}
 
-			_, _, err := n.client.PostMessageContext(ctx, task.Channel, task.Opts...)
-			if err != nil {
-				log.WithError(err).
-					WithField("channel", task.Channel).
-					Errorf("slack api error: %s", err.Error())
+	task := n.newTask(channel, obj, args...)
+	if len(style.emoji) > 0 {
+		task.Text = strings.TrimSpace(style.emoji + " " + task.escapedText())
+		task.Markdown = true
+	}
+
+	if len(style.color) > 0 {
+		for i := range task.Attachments {
+			if len(task.Attachments[i].Color) == 0 {
+				task.Attachments[i].Color = style.color
 			}
 		}
 	}
+
+	n.mentionSeverity(&task, severity)
+	n.enqueue(task, 50*time.Millisecond)
 }
 
-func (n *Notifier) Notify(obj interface{}, args ...interface{}) {
-	n.NotifyTo(n.channel, obj, args...)
+// NotifyInfo is NotifyWithSeverity of SeverityInfo.
+func (n *Notifier) NotifyInfo(obj interface{}, args ...interface{}) {
+	n.NotifyWithSeverity(SeverityInfo, obj, args...)
+}
+
+// NotifyWarn is NotifyWithSeverity of SeverityWarning.
+func (n *Notifier) NotifyWarn(obj interface{}, args ...interface{}) {
+	n.NotifyWithSeverity(SeverityWarning, obj, args...)
+}
+
+// NotifyError is NotifyWithSeverity of SeverityCritical.
+func (n *Notifier) NotifyError(obj interface{}, args ...interface{}) {
+	n.NotifyWithSeverity(SeverityCritical, obj, args...)
+}
+
+// mentionSeverity prepends the mention set by WithSeverityMention to the text if the severity is high enough.
+func (n *Notifier) mentionSeverity(task *notifyTask, severity Severity) {
+	if len(n.severityMention) > 0 && severity >= n.minMentionSeverity {
+		task.Text = strings.TrimSpace(n.severityMention + " " + task.escapedText())
+		task.Markdown = true
+	}
+}
+
+func (n *Notifier) routeChannel(obj interface{}, args ...interface{}) string {
+	if n.channelRouter == nil {
+		return n.channel
+	}
+
+	for _, o := range append([]interface{}{obj}, args...) {
+		if channel := n.channelRouter(o); len(channel) > 0 {
+			return channel
+		}
+	}
+
+	return n.channel
 }
 
-func filterSlackAttachments(args []interface{}) (slackAttachments []slack.Attachment, pureArgs []interface{}) {
+// slackAttachment converts the creator to the slack attachment and applies the sign coloring.
+func (n *Notifier) slackAttachment(creator types.SlackAttachmentCreator) slack.Attachment {
+	attachment := creator.SlackAttachment()
+	if n.signValue == nil || len(attachment.Color) > 0 {
+		return attachment
+	}
+
+	value, ok := n.signValue(creator)
+	if !ok {
+		return attachment
+	}
+
+	switch value.Sign() {
+	case 1:
+		attachment.Color = style.GreenColor
+	case -1:
+		attachment.Color = style.RedColor
+	default:
+		attachment.Color = style.GrayColor
+	}
+
+	return attachment
+}
+
+func (n *Notifier) filterSlackAttachments(args []interface{}) (slackAttachments []slack.Attachment, slackBlocks []slack.Block, pureArgs []interface{}) {
 	var firstAttachmentOffset = -1
 	for idx, arg := range args {
 		switch a := arg.(type) {
@@ -95,7 +868,14 @@ func filterSlackAttachments(args []interface{}) (slackAttachments []slack.Attach
 				firstAttachmentOffset = idx
 			}
 
-			slackAttachments = append(slackAttachments, a.SlackAttachment())
+			slackAttachments = append(slackAttachments, n.slackAttachment(a))
+
+		case types.SlackBlocksCreator:
+			if firstAttachmentOffset == -1 {
+				firstAttachmentOffset = idx
+			}
+
+			slackBlocks = append(slackBlocks, a.SlackBlocks()...)
 
 		case types.PlainText:
 			if firstAttachmentOffset == -1 {
@@ -116,43 +896,414 @@ func filterSlackAttachments(args []interface{}) (slackAttachments []slack.Attach
 		pureArgs = args[:firstAttachmentOffset]
 	}
 
-	return slackAttachments, pureArgs
+	return slackAttachments, slackBlocks, pureArgs
 }
 
 func (n *Notifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	n.enqueue(n.newTask(channel, obj, args...), 50*time.Millisecond)
+}
+
+func (n *Notifier) newTask(channel string, obj interface{}, args ...interface{}) notifyTask {
 	if len(channel) == 0 {
 		channel = n.channel
 	}
 
-	slackAttachments, pureArgs := filterSlackAttachments(args)
+	threadSymbol, args := splitThreadSymbol(args)
+	slackAttachments, slackBlocks, pureArgs := n.filterSlackAttachments(args)
 
-	var opts []slack.MsgOption
+	task := notifyTask{
+		Channel:      channel,
+		Blocks:       slackBlocks,
+		ThreadSymbol: threadSymbol,
+	}
 
 	switch a := obj.(type) {
 	case string:
-		opts = append(opts, slack.MsgOptionText(fmt.Sprintf(a, pureArgs...), true),
-			slack.MsgOptionAttachments(slackAttachments...))
+		task.Text = fmt.Sprintf(a, pureArgs...)
+		task.Attachments = slackAttachments
 
 	case slack.Attachment:
-		opts = append(opts, slack.MsgOptionAttachments(append([]slack.Attachment{a}, slackAttachments...)...))
+		task.Attachments = append([]slack.Attachment{a}, slackAttachments...)
 
 	case types.SlackAttachmentCreator:
 		// convert object to slack attachment (if supported)
-		opts = append(opts, slack.MsgOptionAttachments(append([]slack.Attachment{a.SlackAttachment()}, slackAttachments...)...))
+		task.Attachments = append([]slack.Attachment{n.slackAttachment(a)}, slackAttachments...)
+
+	case types.SlackBlocksCreator:
+		task.Attachments = slackAttachments
+		task.Blocks = append(a.SlackBlocks(), slackBlocks...)
 
 	default:
-		log.Errorf("slack message conversion error, unsupported object: %T %+v", a, a)
+		n.logger.WithField("channel", channel).Errorf("slack message conversion error, unsupported object: %T %+v", a, a)
 
 	}
 
-	select {
-	case n.taskC <- notifyTask{
+	// the attachment-only message would show up as an empty notification
+	if len(task.Text) == 0 {
+		task.Text = fallbackText(task.Attachments)
+	}
+
+	task.Attachments = n.attachmentLimits.apply(n.logger, task.Channel, task.Attachments)
+	return task
+}
+
+// truncateText truncates the text to maxLength characters with an ellipsis.
+func truncateText(text string, maxLength int) string {
+	if maxLength <= 0 || len(text) <= maxLength {
+		return text
+	}
+
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+
+	if maxLength <= len(ellipsis) {
+		return string(runes[:maxLength])
+	}
+
+	return string(runes[:maxLength-len(ellipsis)]) + ellipsis
+}
+
+// truncateEscapedText truncates the escaped text like truncateText, but it doesn't split the escaped characters like
+// &amp; at the end, which would show up as the broken entity.
+func truncateEscapedText(text string, maxLength int) string {
+	truncated := truncateText(text, maxLength)
+	if truncated == text || maxLength <= len(ellipsis) {
+		return truncated
+	}
+
+	// the escaped characters are &amp;, &lt; and &gt;, the cut one ends with up to 3 letters after the &
+	kept := strings.TrimSuffix(truncated, ellipsis)
+	if amp := strings.LastIndexByte(kept, '&'); amp >= 0 && len(kept)-amp <= 4 && isLetters(kept[amp+1:]) {
+		kept = kept[:amp]
+	}
+
+	return kept + ellipsis
+}
+
+func isLetters(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// NotifyTrade posts the trade to the trade channel set by WithTradeChannel. Unlike Notify, it posts the message
+// synchronously and returns the error. The rate limit of WithChannelRateLimit applies and ErrThrottled is returned
+// beyond it, but the trades are not deduplicated since the distinct fills may render the same text.
+func (n *Notifier) NotifyTrade(trade *types.Trade) error {
+	channel := n.tradeChannel
+	if len(channel) == 0 {
+		channel = n.channel
+	}
+
+	if !n.allowRate(channel) {
+		return ErrThrottled
+	}
+
+	return n.postNow(notifyTask{
 		Channel: channel,
-		Opts:    opts,
-	}:
-	case <-time.After(50 * time.Millisecond):
-		return
+		Text: fmt.Sprintf("%s %s trade, price: %s, quantity: %s",
+			trade.Symbol, trade.Side, trade.Price.String(), trade.Quantity.String()),
+		Attachments: n.attachmentLimits.apply(n.logger, channel, []slack.Attachment{n.slackAttachment(trade)}),
+	})
+}
+
+// NotifyPnL posts the PnL report to the PnL channel set by WithPnLChannel. Like NotifyTrade, it posts the message
+// synchronously with the rate limit of the channel, and the reports are not deduplicated since the text doesn't
+// carry the numbers.
+func (n *Notifier) NotifyPnL(report *pnl.AverageCostPnLReport) error {
+	channel := n.pnlChannel
+	if len(channel) == 0 {
+		channel = n.channel
+	}
+
+	if !n.allowRate(channel) {
+		return ErrThrottled
+	}
+
+	return n.postNow(notifyTask{
+		Channel: channel,
+		Text: fmt.Sprintf(":heavy_dollar_sign: Here is your *%s* PnL report collected since %s",
+			report.Symbol, report.StartTime.Format(time.RFC822)),
+		Attachments: n.attachmentLimits.apply(n.logger, channel, []slack.Attachment{n.slackAttachment(report)}),
+	})
+}
+
+// PostMessage posts the message synchronously like NotifyTo, and returns the timestamp of the posted message which
+// can be used by Update.
+func (n *Notifier) PostMessage(channel string, obj interface{}, args ...interface{}) (string, error) {
+	if n.isWebhook() {
+		return "", ErrWebhookNotSupported
+	}
+
+	ctx := context.Background()
+	if err := limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	return n.post(ctx, n.newTask(channel, obj, args...))
+}
+
+// Update edits the message of the given timestamp in place, e.g. a live position summary. The format and the args
+// are handled like Notify. The text is always replaced, but the attachments and the blocks are only replaced when
+// they are given, otherwise the previous ones are retained. With WithFooter, the attachments are always replaced since
+// the footer of the update time is added. The message is updated with the identity of the bot like PostMessage, see
+// WithUsername. Slack may refuse to edit an old message, the error is returned in this case.
+func (n *Notifier) Update(channel, ts, format string, args ...interface{}) error {
+	if n.isWebhook() {
+		return ErrWebhookNotSupported
+	}
+
+	ctx := context.Background()
+	if err := limiter.Wait(ctx); err != nil {
+		return err
 	}
+
+	task := n.newTask(channel, format, args...)
+	_, err := n.send(ctx, task, time.Now(), func(ctx context.Context, task notifyTask) (string, error) {
+		_, _, _, err := n.client.UpdateMessageContext(ctx, task.Channel, ts, n.postOptions(task)...)
+		return ts, err
+	})
+	if err != nil {
+		switch err.Error() {
+		case "cant_update_message", "edit_window_closed":
+			return fmt.Errorf("slack message %s in channel %s can not be updated anymore: %w", ts, task.Channel, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes the message of the given timestamp, e.g. a transient status message posted by PostMessage. Slack
+// refuses to delete the message which is already deleted or can't be deleted by the bot, e.g. too old by the retention
+// policy, the descriptive error is returned in these cases.
+func (n *Notifier) Delete(channel, ts string) error {
+	if n.isWebhook() {
+		return ErrWebhookNotSupported
+	}
+
+	if len(channel) == 0 {
+		channel = n.channel
+	}
+
+	if n.dryRun {
+		n.logger.WithField("channel", channel).Infof("[dry run] slack message %s in channel %s is deleted", ts, channel)
+		n.stats.dryRun(channel)
+		return nil
+	}
+
+	ctx := context.Background()
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	_, _, err := n.client.DeleteMessageContext(ctx, channel, ts)
+	n.stats.record(channel, err)
+	if err != nil {
+		switch err.Error() {
+		case "message_not_found":
+			return fmt.Errorf("slack message %s in channel %s is not found or already deleted: %w", ts, channel, err)
+		case "cant_delete_message", "compliance_exports_prevent_deletion":
+			return fmt.Errorf("slack message %s in channel %s can not be deleted: %w", ts, channel, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Schedule schedules the message to be posted by slack at postAt, so the message is posted even if the bot is busy or
+// down at that time, e.g. the daily report. The format and the args are handled like Notify. It returns the scheduled
+// message id which can be used by DeleteScheduled. Slack only accepts postAt within 120 days.
+func (n *Notifier) Schedule(channel string, postAt time.Time, format string, args ...interface{}) (string, error) {
+	if n.isWebhook() {
+		return "", ErrWebhookNotSupported
+	}
+
+	ctx := context.Background()
+	if err := limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	// the text is rendered up front to look up the scheduled message by it, and the footer shows the time when the
+	// message is posted by slack
+	task := n.renderText(n.newTask(channel, format, args...))
+	postAtUnix := strconv.FormatInt(postAt.Unix(), 10)
+	respChannel, err := n.send(ctx, task, postAt, func(ctx context.Context, task notifyTask) (string, error) {
+		respChannel, _, _, err := n.client.SendMessageContext(ctx, task.Channel,
+			slack.MsgOptionSchedule(postAtUnix), slack.MsgOptionCompose(n.postOptions(task)...))
+		return respChannel, err
+	})
+	if err != nil || n.dryRun {
+		return "", err
+	}
+
+	// the slack client doesn't return the scheduled message id, look it up by the post time and the text instead.
+	if err := limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	messages, _, err := n.client.GetScheduledMessagesContext(ctx, &slack.GetScheduledMessagesParameters{
+		Channel: respChannel,
+		Oldest:  postAtUnix,
+		Latest:  postAtUnix,
+	})
+	if err != nil {
+		return "", fmt.Errorf("slack message is scheduled, but failed to query its scheduled message id: %w", err)
+	}
+
+	var scheduled *slack.ScheduledMessage
+	for i, message := range messages {
+		// slack may return the text with the &, < and > escaped
+		if strconv.Itoa(message.PostAt) != postAtUnix ||
+			(message.Text != task.Text && html.UnescapeString(message.Text) != html.UnescapeString(task.Text)) {
+			continue
+		}
+
+		// the latest one is ours if the same message is scheduled more than once
+		if scheduled == nil || message.DateCreated > scheduled.DateCreated {
+			scheduled = &messages[i]
+		}
+	}
+
+	if scheduled == nil {
+		return "", fmt.Errorf("slack message is scheduled, but its scheduled message id is not found, channel: %s, post at: %s",
+			task.Channel, postAt)
+	}
+
+	return scheduled.ID, nil
+}
+
+// DeleteScheduled deletes the message scheduled by Schedule before it's posted.
+func (n *Notifier) DeleteScheduled(channel, scheduledID string) error {
+	if n.isWebhook() {
+		return ErrWebhookNotSupported
+	}
+
+	if len(channel) == 0 {
+		channel = n.channel
+	}
+
+	if n.dryRun {
+		n.logger.WithField("channel", channel).Infof("[dry run] slack scheduled message %s in channel %s is deleted", scheduledID, channel)
+		return nil
+	}
+
+	ctx := context.Background()
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	_, err := n.client.DeleteScheduledMessageContext(ctx, &slack.DeleteScheduledMessageParameters{
+		Channel:            channel,
+		ScheduledMessageID: scheduledID,
+	})
+	return err
+}
+
+// NotifyChannel posts the message to the channel synchronously and returns the error of the post, the default channel
+// is used if the channel is empty. The format and the args are handled like Notify. It implements notifier.Notifier.
+func (n *Notifier) NotifyChannel(channel, format string, args ...interface{}) error {
+	return n.postNow(n.newTask(channel, format, args...))
+}
+
+// Broadcast posts the same message to all the channels, e.g. the critical alerts. The format and the args are handled
+// like Notify. It doesn't stop at the failed channel, the returned errors are in the order of the channels and the
+// error of the successful channel is nil. In the async mode set by WithAsyncBroadcast, the message is enqueued to every
+// channel and ErrNotEnqueued is returned for the channel which the message can't be enqueued to.
+func (n *Notifier) Broadcast(channels []string, format string, args ...interface{}) []error {
+	errs := make([]error, len(channels))
+	for i, channel := range channels {
+		task := n.newTask(channel, format, args...)
+		if n.asyncBroadcast {
+			if !n.enqueue(task, 50*time.Millisecond) {
+				errs[i] = ErrNotEnqueued
+			}
+			continue
+		}
+
+		errs[i] = n.postNow(task)
+	}
+
+	return errs
+}
+
+// postNow posts the task synchronously with the rate limit.
+func (n *Notifier) postNow(task notifyTask) error {
+	ctx := context.Background()
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	_, err := n.post(ctx, task)
+	return err
+}
+
+// UploadOption sets the optional parameters of UploadFile.
+type UploadOption func(params *slack.UploadFileV2Parameters)
+
+// WithInitialComment posts the comment along with the uploaded file.
+func WithInitialComment(comment string) UploadOption {
+	return func(params *slack.UploadFileV2Parameters) {
+		params.InitialComment = comment
+	}
+}
+
+// WithThreadTS uploads the file as a reply of the thread of the given message timestamp.
+func WithThreadTS(threadTS string) UploadOption {
+	return func(params *slack.UploadFileV2Parameters) {
+		params.ThreadTimestamp = threadTS
+	}
+}
+
+// UploadFile uploads the file, e.g. a rendered chart image, to the channel synchronously. The default channel is used
+// if the channel is empty. The file is uploaded with the external upload flow of slack (files.upload is retired), which
+// shares the file by the channel id, so the channel must be an id like C0123456789 instead of a name like #general.
+// The incoming webhook can't upload files, ErrWebhookNotSupported is returned in this case.
+func (n *Notifier) UploadFile(channel, title string, data []byte, filename string, options ...UploadOption) error {
+	if n.isWebhook() {
+		return ErrWebhookNotSupported
+	}
+
+	if len(channel) == 0 {
+		channel = n.channel
+	}
+
+	params := slack.UploadFileV2Parameters{
+		Reader:   bytes.NewReader(data),
+		FileSize: len(data),
+		Filename: filename,
+		Title:    title,
+		Channel:  channel,
+	}
+
+	for _, o := range options {
+		o(&params)
+	}
+
+	if n.dryRun {
+		n.logger.WithFields(log.Fields{
+			"channel":  channel,
+			"filename": filename,
+			"size":     len(data),
+		}).Infof("[dry run] slack file upload: %s", title)
+		n.stats.dryRun(channel)
+		return nil
+	}
+
+	ctx := context.Background()
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	_, err := n.client.UploadFileV2Context(ctx, params)
+	n.stats.record(channel, err)
+	return err
 }
 
 func (n *Notifier) SendPhoto(buffer *bytes.Buffer) {
@@ -160,5 +1311,14 @@ func (n *Notifier) SendPhoto(buffer *bytes.Buffer) {
 }
 
 func (n *Notifier) SendPhotoTo(channel string, buffer *bytes.Buffer) {
-	// TODO
+	// the photo is not supported by the incoming webhook, skip it silently like the other notifiers
+	if n.isWebhook() {
+		return
+	}
+
+	if err := n.UploadFile(channel, "", buffer.Bytes(), "photo.png"); err != nil {
+		n.logger.WithError(err).
+			WithField("channel", channel).
+			Errorf("slack api error: %s", err.Error())
+	}
 }
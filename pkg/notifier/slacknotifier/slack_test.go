@@ -0,0 +1,1223 @@
+package slacknotifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/style"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util/traceid"
+)
+
+func init() {
+	// the tests post many messages, don't wait for the global rate limit of the slack api.
+	limiter = rate.NewLimiter(rate.Inf, 1)
+}
+
+// webhookRecorder records the paths and the messages posted to the webhook test server.
+type webhookRecorder struct {
+	mu       sync.Mutex
+	paths    []string
+	messages []slack.WebhookMessage
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var msg slack.WebhookMessage
+	if err := json.NewDecoder(req.Body).Decode(&msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	r.paths = append(r.paths, req.URL.Path)
+	r.messages = append(r.messages, msg)
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// wait waits until n messages are posted, the messages are posted asynchronously by the worker.
+func (r *webhookRecorder) wait(t *testing.T, n int) bool {
+	return assert.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.messages) >= n
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (r *webhookRecorder) texts() (texts []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, msg := range r.messages {
+		texts = append(texts, msg.Text)
+	}
+	return texts
+}
+
+func TestNewWebhook(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL+"/default", WithChannelWebhook("#pnl", server.URL+"/pnl"))
+
+	notifier.Notify("order %s placed", "BTCUSDT")
+	notifier.NotifyTo("#pnl", "daily pnl", slack.Attachment{Title: "BTCUSDT", Text: "+10 USDT"})
+	// the channel without its webhook is posted to the default webhook
+	notifier.NotifyTo("#alerts", "position %s liquidated", "ETHUSDT")
+
+	if !recorder.wait(t, 3) {
+		return
+	}
+	assert.Equal(t, []string{"/default", "/pnl", "/default"}, recorder.paths)
+	assert.Equal(t, []string{"order BTCUSDT placed", "daily pnl", "position ETHUSDT liquidated"}, recorder.texts())
+	if assert.Len(t, recorder.messages[1].Attachments, 1) {
+		assert.Equal(t, "BTCUSDT", recorder.messages[1].Attachments[0].Title)
+		assert.Equal(t, "+10 USDT", recorder.messages[1].Attachments[0].Text)
+	}
+}
+
+type testBlocks struct {
+	text string
+}
+
+func (b testBlocks) SlackBlocks() []slack.Block {
+	return []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, b.text, false, false)),
+		slack.NewDividerBlock(),
+	}
+}
+
+func TestNotifier_blocks(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL)
+
+	notifier.Notify(testBlocks{text: "daily report"}, testBlocks{text: "BTCUSDT"})
+	// the blocks of the args are posted along with the text
+	notifier.Notify("position %s closed", "BTCUSDT", testBlocks{text: "summary"})
+	if !recorder.wait(t, 2) {
+		return
+	}
+
+	var headers [][]string
+	for _, msg := range recorder.messages {
+		var texts []string
+		if assert.NotNil(t, msg.Blocks) {
+			for _, block := range msg.Blocks.BlockSet {
+				if header, ok := block.(*slack.HeaderBlock); ok {
+					texts = append(texts, header.Text.Text)
+				}
+			}
+		}
+		headers = append(headers, texts)
+	}
+	assert.Equal(t, [][]string{{"daily report", "BTCUSDT"}, {"summary"}}, headers)
+	assert.Equal(t, []string{"", "position BTCUSDT closed"}, recorder.texts())
+	assert.Len(t, recorder.messages[0].Blocks.BlockSet, 4)
+}
+
+func TestNotifier_WithChannelRouter(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL+"/default",
+		WithChannelWebhook("#blocks", server.URL+"/blocks"),
+		WithChannelWebhook("#attachments", server.URL+"/attachments"),
+		WithChannelRouter(func(obj interface{}) string {
+			switch obj.(type) {
+			case testBlocks:
+				return "#blocks"
+			case slack.Attachment:
+				return "#attachments"
+			}
+			return ""
+		}))
+
+	notifier.Notify(testBlocks{text: "report"})
+	// the object is routed first, then the args
+	notifier.Notify("order filled", slack.Attachment{Text: "fill"})
+	notifier.Notify(testBlocks{text: "report"}, slack.Attachment{Text: "fill"})
+	// the default channel is used if no one is routed
+	notifier.Notify("position closed")
+	// NotifyTo doesn't route
+	notifier.NotifyTo("#attachments", testBlocks{text: "report"})
+
+	if recorder.wait(t, 5) {
+		assert.Equal(t, []string{"/blocks", "/attachments", "/blocks", "/default", "/attachments"}, recorder.paths)
+	}
+}
+
+func TestNotifier_NotifyAsync_Flush(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL)
+	for i := 0; i < 3; i++ {
+		notifier.NotifyAsync("#general", "message %d", i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, notifier.Flush(ctx))
+	assert.Equal(t, []string{"message 0", "message 1", "message 2"}, recorder.texts())
+	assert.Equal(t, uint64(0), notifier.DroppedMessages())
+
+	// the enqueued messages are posted on close, and the messages after close are dropped
+	notifier.NotifyAsync("#general", "message %d", 3)
+	assert.NoError(t, notifier.Close())
+	notifier.NotifyAsync("#general", "message %d", 4)
+	assert.Equal(t, []string{"message 0", "message 1", "message 2", "message 3"}, recorder.texts())
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "short", truncateText("short", 10))
+	assert.Equal(t, "exactly 10", truncateText("exactly 10", 10))
+	assert.Equal(t, "longer ...", truncateText("longer than 10", 10))
+	// the multi-byte characters are not split
+	assert.Equal(t, "價格價格...", truncateText("價格價格價格價格", 7))
+	// the length is counted in characters, not bytes
+	assert.Equal(t, "價格價格價格價格", truncateText("價格價格價格價格", 8))
+	assert.Equal(t, "lo", truncateText("long", 2))
+	// zero disables the truncation
+	assert.Equal(t, "long", truncateText("long", 0))
+}
+
+func TestTruncateEscapedText(t *testing.T) {
+	escaped := "a &amp; b &lt; c &gt; d"
+	assert.Equal(t, escaped, truncateEscapedText(escaped, 30))
+	assert.Equal(t, "a &amp; b...", truncateEscapedText(escaped, 12))
+	assert.Equal(t, "a &amp;...", truncateEscapedText(escaped, 10))
+	// the escaped character is not split
+	assert.Equal(t, "a ...", truncateEscapedText(escaped, 8))
+	assert.Equal(t, "a &amp; b ...", truncateEscapedText(escaped, 14))
+	// the & of the markdown text is kept
+	assert.Equal(t, "a & b c d...", truncateEscapedText("a & b c d e f", 12))
+}
+
+func TestNotifier_WithMaxMessageLength(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	long := strings.Repeat("x", 20)
+	notifier := NewWebhook(server.URL, WithMaxMessageLength(10))
+	notifier.Notify("%s", long, slack.Attachment{Text: long})
+	if !recorder.wait(t, 1) {
+		return
+	}
+
+	assert.Equal(t, []string{"xxxxxxx..."}, recorder.texts())
+	// the attachments are not truncated
+	assert.Equal(t, long, recorder.messages[0].Attachments[0].Text)
+
+	// the default limit
+	defaultNotifier := NewWebhook(server.URL)
+	defaultNotifier.Notify("%s", strings.Repeat("x", 5000))
+	if recorder.wait(t, 2) {
+		assert.Len(t, recorder.texts()[1], defaultMaxMessageLength)
+	}
+}
+
+func TestNotifier_WithMaxMessageLength_escaped(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL, WithMaxMessageLength(26),
+		WithSeverityMention(SeverityCritical, "<!here>"), WithSeverityStyle(SeverityCritical, ":red_circle:", ""))
+
+	// the limit applies to the escaped text
+	notifier.Notify("a & b < c > d & e")
+	// and to the text with the emoji and the mention of the severity, the escaped & is not split
+	notifier.NotifyError("a & b")
+	if !recorder.wait(t, 2) {
+		return
+	}
+
+	assert.Equal(t, []string{"a &amp; b &lt; c &gt; d...", "<!here> :red_circle: a ..."}, recorder.texts())
+}
+
+type testProfit struct {
+	profit fixedpoint.Value
+	color  string
+}
+
+func (p testProfit) SlackAttachment() slack.Attachment {
+	return slack.Attachment{Title: "profit", Text: p.profit.String(), Color: p.color}
+}
+
+func TestNotifier_WithSignColoring(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL, WithSignColoring(func(obj interface{}) (fixedpoint.Value, bool) {
+		p, ok := obj.(testProfit)
+		return p.profit, ok
+	}))
+
+	notifier.Notify(testProfit{profit: fixedpoint.NewFromInt(10)})
+	notifier.Notify(testProfit{profit: fixedpoint.NewFromInt(-10)})
+	notifier.Notify(testProfit{profit: fixedpoint.Zero})
+	// the attachment with a color is not changed
+	notifier.Notify(testProfit{profit: fixedpoint.NewFromInt(10), color: "warning"})
+	// the creator of the args is colored as well, but not the attachment
+	notifier.Notify("report", testProfit{profit: fixedpoint.NewFromInt(-1)}, slack.Attachment{Text: "fee"})
+	if !recorder.wait(t, 5) {
+		return
+	}
+
+	var colors [][]string
+	for _, msg := range recorder.messages {
+		var cs []string
+		for _, a := range msg.Attachments {
+			cs = append(cs, a.Color)
+		}
+		colors = append(colors, cs)
+	}
+	assert.Equal(t, [][]string{
+		{style.GreenColor},
+		{style.RedColor},
+		{style.GrayColor},
+		{"warning"},
+		{style.RedColor, ""},
+	}, colors)
+}
+
+func TestNotifier_NotifyTrade(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	trade := &types.Trade{
+		Exchange: types.ExchangeBybit,
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		IsBuyer:  true,
+		Price:    fixedpoint.NewFromInt(30000),
+		Quantity: fixedpoint.NewFromFloat(0.01),
+	}
+
+	notifier := NewWebhook(server.URL+"/default",
+		WithChannelWebhook("#trades", server.URL+"/trades"),
+		WithTradeChannel("#trades"),
+		WithDeduplication(time.Minute),
+		WithChannelRateLimit(time.Hour, 2))
+	defer notifier.Close()
+
+	assert.NoError(t, notifier.NotifyTrade(trade))
+	// the same fill is not deduplicated
+	assert.NoError(t, notifier.NotifyTrade(trade))
+	assert.ErrorIs(t, notifier.NotifyTrade(trade), ErrThrottled)
+
+	assert.Equal(t, []string{"/trades", "/trades"}, recorder.paths)
+	assert.Equal(t, []string{
+		"BTCUSDT BUY trade, price: 30000, quantity: 0.01",
+		"BTCUSDT BUY trade, price: 30000, quantity: 0.01",
+	}, recorder.texts())
+	if assert.Len(t, recorder.messages[0].Attachments, 1) {
+		assert.Equal(t, trade.SlackAttachment().Fields, recorder.messages[0].Attachments[0].Fields)
+	}
+	assert.Equal(t, ChannelStats{Sent: 2, Throttled: 1}, notifier.Stats()["#trades"])
+
+	// the default channel is used without the trade channel
+	defaultNotifier := NewWebhook(server.URL + "/default")
+	defer defaultNotifier.Close()
+	assert.NoError(t, defaultNotifier.NotifyTrade(trade))
+	assert.Equal(t, "/default", recorder.paths[len(recorder.paths)-1])
+
+	// the error is returned since it's posted synchronously
+	server.Close()
+	assert.Error(t, defaultNotifier.NotifyTrade(trade))
+}
+
+func TestNotifier_NotifyPnL(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	report := &pnl.AverageCostPnLReport{
+		Symbol:    "BTCUSDT",
+		StartTime: time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC),
+		Profit:    fixedpoint.NewFromInt(10),
+		NetProfit: fixedpoint.NewFromInt(8),
+	}
+
+	notifier := NewWebhook(server.URL+"/default",
+		WithChannelWebhook("#pnl", server.URL+"/pnl"),
+		WithPnLChannel("#pnl"),
+		WithDeduplication(time.Minute),
+		WithChannelRateLimit(time.Hour, 2))
+	defer notifier.Close()
+
+	assert.NoError(t, notifier.NotifyPnL(report))
+	// the report of the same text is not deduplicated
+	assert.NoError(t, notifier.NotifyPnL(report))
+	assert.ErrorIs(t, notifier.NotifyPnL(report), ErrThrottled)
+
+	assert.Equal(t, []string{"/pnl", "/pnl"}, recorder.paths)
+	assert.Equal(t, ":heavy_dollar_sign: Here is your *BTCUSDT* PnL report collected since 13 Mar 24 00:00 UTC",
+		recorder.texts()[0])
+	if assert.Len(t, recorder.messages[0].Attachments, 1) {
+		assert.Equal(t, report.SlackAttachment().Title, recorder.messages[0].Attachments[0].Title)
+	}
+	assert.Equal(t, ChannelStats{Sent: 2, Throttled: 1}, notifier.Stats()["#pnl"])
+
+	// the default channel is used without the PnL channel
+	defaultNotifier := NewWebhook(server.URL + "/default")
+	defer defaultNotifier.Close()
+	assert.NoError(t, defaultNotifier.NotifyPnL(report))
+	assert.Equal(t, "/default", recorder.paths[len(recorder.paths)-1])
+}
+
+func TestNotifier_identity(t *testing.T) {
+	options := []NotifyOption{
+		WithUsername("bbgo-xmaker"),
+		WithIconEmoji(":robot_face:"),
+		WithIconURL("https://example.com/bbgo.png"),
+	}
+
+	t.Run("webhook", func(t *testing.T) {
+		recorder := &webhookRecorder{}
+		server := httptest.NewServer(recorder)
+		defer server.Close()
+
+		notifier := NewWebhook(server.URL, options...)
+		notifier.Notify("position closed")
+		if recorder.wait(t, 1) {
+			assert.Equal(t, "bbgo-xmaker", recorder.messages[0].Username)
+			assert.Equal(t, ":robot_face:", recorder.messages[0].IconEmoji)
+			assert.Equal(t, "https://example.com/bbgo.png", recorder.messages[0].IconURL)
+		}
+	})
+
+	t.Run("api", func(t *testing.T) {
+		var mu sync.Mutex
+		forms := map[string]map[string]string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, r.ParseForm())
+
+			mu.Lock()
+			forms[r.Form.Get("channel")] = map[string]string{
+				"username":   r.Form.Get("username"),
+				"icon_emoji": r.Form.Get("icon_emoji"),
+				"icon_url":   r.Form.Get("icon_url"),
+			}
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1710374340.000100"}`))
+		}))
+		defer server.Close()
+
+		client := slack.New("token", slack.OptionAPIURL(server.URL+"/"))
+		New(client, "#general", options...).Notify("position closed")
+		// the identity is not overridden by default
+		New(client, "#plain").Notify("position closed")
+
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(forms) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, map[string]map[string]string{
+			"#general": {
+				"username":   "bbgo-xmaker",
+				"icon_emoji": ":robot_face:",
+				"icon_url":   "https://example.com/bbgo.png",
+			},
+			"#plain": {"username": "", "icon_emoji": "", "icon_url": ""},
+		}, forms)
+	})
+}
+
+func TestNotifier_PostMessage_Update(t *testing.T) {
+	var updates []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+
+		switch r.URL.Path {
+		case "/chat.postMessage":
+			assert.Equal(t, "#pnl", r.Form.Get("channel"))
+			assert.Equal(t, "position BTCUSDT: 0.1", r.Form.Get("text"))
+			assert.Equal(t, "bbgo-xmaker", r.Form.Get("username"))
+			assert.Equal(t, ":robot_face:", r.Form.Get("icon_emoji"))
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1710374340.000100"}`))
+
+		case "/chat.update":
+			if r.Form.Get("ts") != "1710374340.000100" {
+				_, _ = w.Write([]byte(`{"ok": false, "error": "edit_window_closed"}`))
+				return
+			}
+
+			_, hasAttachments := r.Form["attachments"]
+			updates = append(updates, map[string]string{
+				"channel":     r.Form.Get("channel"),
+				"text":        r.Form.Get("text"),
+				"attachments": strconv.FormatBool(hasAttachments),
+				"username":    r.Form.Get("username"),
+				"icon_emoji":  r.Form.Get("icon_emoji"),
+			})
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1710374340.000100"}`))
+
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	notifier := New(slack.New("token", slack.OptionAPIURL(server.URL+"/")), "#general",
+		WithUsername("bbgo-xmaker"), WithIconEmoji(":robot_face:"))
+
+	ts, err := notifier.PostMessage("#pnl", "position %s: %s", "BTCUSDT", "0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1710374340.000100", ts)
+
+	assert.NoError(t, notifier.Update("#pnl", ts, "position %s: %s", "BTCUSDT", "0.2"))
+	assert.NoError(t, notifier.Update("", ts, "position %s closed", "BTCUSDT", slack.Attachment{Text: "pnl"}))
+	assert.ErrorContains(t, notifier.Update("#pnl", "1600000000.000100", "position closed"), "can not be updated anymore")
+
+	// the attachments are only replaced when they are given, and the identity of the bot is kept
+	assert.Equal(t, []map[string]string{
+		{"channel": "#pnl", "text": "position BTCUSDT: 0.2", "attachments": "false",
+			"username": "bbgo-xmaker", "icon_emoji": ":robot_face:"},
+		{"channel": "#general", "text": "position BTCUSDT closed", "attachments": "true",
+			"username": "bbgo-xmaker", "icon_emoji": ":robot_face:"},
+	}, updates)
+
+	webhook := NewWebhook(server.URL)
+	_, err = webhook.PostMessage("#pnl", "position opened")
+	assert.ErrorIs(t, err, ErrWebhookNotSupported)
+	assert.ErrorIs(t, webhook.Update("#pnl", ts, "position closed"), ErrWebhookNotSupported)
+}
+
+func TestNotifier_UploadFile(t *testing.T) {
+	var uploaded, completed int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files.getUploadURLExternal":
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "equity.png", r.Form.Get("filename"))
+			assert.Equal(t, "4", r.Form.Get("length"))
+			_, _ = w.Write([]byte(`{"ok": true, "upload_url": "` + server.URL + `/upload", "file_id": "F123"}`))
+
+		case "/upload":
+			uploaded++
+			file, header, err := r.FormFile("file")
+			if assert.NoError(t, err) {
+				defer file.Close()
+				data, err := io.ReadAll(file)
+				assert.NoError(t, err)
+				assert.Equal(t, "equity.png", header.Filename)
+				assert.Equal(t, []byte("\x89PNG"), data)
+			}
+			_, _ = w.Write([]byte(`{}`))
+
+		case "/files.completeUploadExternal":
+			completed++
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "C0123456789", r.Form.Get("channel_id"))
+			assert.JSONEq(t, `[{"id": "F123", "title": "equity curve"}]`, r.Form.Get("files"))
+			assert.Equal(t, "daily equity", r.Form.Get("initial_comment"))
+			assert.Equal(t, "1710374340.000100", r.Form.Get("thread_ts"))
+			_, _ = w.Write([]byte(`{"ok": true, "files": [{"id": "F123", "title": "equity curve"}]}`))
+
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	notifier := New(slack.New("token", slack.OptionAPIURL(server.URL+"/")), "#general")
+	defer notifier.Close()
+
+	assert.NoError(t, notifier.UploadFile("C0123456789", "equity curve", []byte("\x89PNG"), "equity.png",
+		WithInitialComment("daily equity"), WithThreadTS("1710374340.000100")))
+	assert.Equal(t, 1, uploaded)
+	assert.Equal(t, 1, completed)
+
+	webhook := NewWebhook(server.URL)
+	defer webhook.Close()
+	assert.ErrorIs(t, webhook.UploadFile("C0123456789", "equity curve", []byte("\x89PNG"), "equity.png"), ErrWebhookNotSupported)
+}
+
+func TestNotifier_WithDryRun(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		t.Errorf("unexpected request in the dry run mode: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	notifier := New(slack.New("token", slack.OptionAPIURL(server.URL+"/")), "#general", WithDryRun(true))
+	defer notifier.Close()
+
+	notifier.Notify("queued")
+	notifier.NotifyTo("#pnl", "queued to %s", "pnl")
+	assert.NoError(t, notifier.NotifyTrade(&types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy}))
+
+	ts, err := notifier.PostMessage("#pnl", "posted")
+	assert.NoError(t, err)
+	assert.Empty(t, ts)
+	assert.NoError(t, notifier.Update("#pnl", "1710374340.000100", "updated"))
+	assert.NoError(t, notifier.UploadFile("#pnl", "equity curve", []byte("\x89PNG"), "equity.png"))
+
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	webhook := NewWebhook(server.URL, WithDryRun(true))
+	defer webhook.Close()
+
+	webhook.Notify("queued")
+	assert.NoError(t, webhook.Flush(context.Background()))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+}
+
+func TestNotifier_Flush_concurrentEnqueue(t *testing.T) {
+	var posted int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&posted, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL)
+	defer notifier.Close()
+
+	// the messages are enqueued while Flush is waiting, which panics with the sync.WaitGroup
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				notifier.NotifyTo("#general", "message %d", j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, notifier.Flush(context.Background()))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// every message is either posted or dropped after the flush
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, int64(200), atomic.LoadInt64(&posted)+int64(notifier.DroppedMessages()))
+}
+
+func TestNotifier_Broadcast(t *testing.T) {
+	var mu sync.Mutex
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		mu.Lock()
+		posted = append(posted, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	options := []NotifyOption{
+		WithChannelWebhook("#alerts", server.URL+"/alerts"),
+		WithChannelWebhook("#broken", server.URL+"/fail"),
+		WithChannelWebhook("#ops", server.URL+"/ops"),
+	}
+
+	t.Run("sync", func(t *testing.T) {
+		posted = nil
+		notifier := NewWebhook(server.URL, options...)
+		defer notifier.Close()
+
+		errs := notifier.Broadcast([]string{"#alerts", "#broken", "#ops"}, "position %s liquidated", "BTCUSDT")
+		if assert.Len(t, errs, 3) {
+			assert.NoError(t, errs[0])
+			assert.Error(t, errs[1])
+			assert.NoError(t, errs[2])
+		}
+		assert.Equal(t, []string{"/alerts", "/ops"}, posted)
+		assert.Equal(t, ChannelStats{Errors: 1}, notifier.Stats()["#broken"])
+	})
+
+	t.Run("async", func(t *testing.T) {
+		posted = nil
+		notifier := NewWebhook(server.URL, append(options, WithAsyncBroadcast(true))...)
+		defer notifier.Close()
+
+		errs := notifier.Broadcast([]string{"#alerts", "#ops"}, "position %s liquidated", "BTCUSDT")
+		assert.Equal(t, []error{nil, nil}, errs)
+
+		assert.NoError(t, notifier.Flush(context.Background()))
+		assert.Equal(t, []string{"/alerts", "/ops"}, posted)
+	})
+
+	t.Run("async closed", func(t *testing.T) {
+		notifier := NewWebhook(server.URL, append(options, WithAsyncBroadcast(true))...)
+		assert.NoError(t, notifier.Close())
+
+		errs := notifier.Broadcast([]string{"#alerts"}, "position liquidated")
+		assert.Equal(t, []error{ErrNotEnqueued}, errs)
+	})
+}
+
+func TestNotifier_Stats(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		n := requests[r.URL.Path]
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		// the first request of the channel and every request of the busy channel are rate limited
+		case r.URL.Path == "/busy" || n == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL+"/general",
+		WithChannelWebhook("#broken", server.URL+"/broken"),
+		WithChannelWebhook("#busy", server.URL+"/busy"),
+	)
+	defer notifier.Close()
+
+	notifier.NotifyTo("#general", "first")
+	notifier.NotifyTo("#general", "second")
+	notifier.NotifyTo("#broken", "failed")
+	notifier.NotifyTo("#busy", "rate limited")
+
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, map[string]ChannelStats{
+		"#general": {Sent: 2, Retries: 1},
+		"#broken":  {Errors: 1},
+		"#busy":    {Errors: 1, RateLimited: 1, Retries: maxPostRetries},
+	}, notifier.Stats())
+	assert.Equal(t, 1+maxPostRetries, requests["/busy"])
+
+	t.Run("dry run", func(t *testing.T) {
+		notifier := NewWebhook(server.URL+"/general", WithDryRun(true))
+		defer notifier.Close()
+
+		notifier.NotifyTo("#general", "logged only")
+		assert.NoError(t, notifier.Flush(context.Background()))
+		assert.Equal(t, map[string]ChannelStats{"#general": {DryRun: 1}}, notifier.Stats())
+	})
+}
+
+func TestNotifier_NotifyMarkdown(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slack.WebhookMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		mu.Lock()
+		texts = append(texts, msg.Text)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL, WithSeverityMention(SeverityCritical, MentionGroup("S123")))
+	defer notifier.Close()
+
+	notifier.Notify("%s <https://example.com|chart> & more", MentionUser("U123"))
+	notifier.NotifyMarkdown("%s <https://example.com|chart> & more", MentionUser("U123"))
+	notifier.NotifyWithSeverity(SeverityCritical, "BTCUSDT <= 20000")
+
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, []string{
+		"&lt;@U123&gt; &lt;https://example.com|chart&gt; &amp; more",
+		"<@U123> <https://example.com|chart> & more",
+		// only the text is escaped, the mention still works
+		"<!subteam^S123> :rotating_light: BTCUSDT &lt;= 20000",
+	}, texts)
+}
+
+func TestMention(t *testing.T) {
+	assert.Equal(t, "<@U123>", MentionUser("U123"))
+	assert.Equal(t, "<!subteam^S123>", MentionGroup("S123"))
+}
+
+func TestNotifier_WithSeverityMention(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slack.WebhookMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		mu.Lock()
+		texts = append(texts, msg.Text)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL,
+		WithSeverityMention(SeverityWarning, MentionUser("U123")),
+		WithSeverityStyle(SeverityInfo, "", ""),
+		WithSeverityStyle(SeverityWarning, "", ""),
+		WithSeverityStyle(SeverityCritical, "", ""),
+	)
+	defer notifier.Close()
+
+	notifier.NotifyInfo("drawdown %d%%", 1)
+	notifier.NotifyWarn("drawdown %d%%", 5)
+	notifier.NotifyWithSeverity(SeverityCritical, "drawdown %d%%", 10)
+	// the plain Notify has no severity, so it's never mentioned
+	notifier.Notify("drawdown %d%%", 20)
+
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, []string{
+		"drawdown 1%",
+		"<@U123> drawdown 5%",
+		"<@U123> drawdown 10%",
+		"drawdown 20%",
+	}, texts)
+}
+
+func TestNotifier_NotifySeverity(t *testing.T) {
+	var mu sync.Mutex
+	var posted []string
+	var colors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slack.WebhookMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		mu.Lock()
+		posted = append(posted, r.URL.Path+" "+msg.Text)
+		for _, a := range msg.Attachments {
+			colors = append(colors, a.Color)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL+"/general",
+		WithChannelWebhook("#alerts", server.URL+"/alerts"),
+		WithSeverityChannel(SeverityCritical, "#alerts"),
+		WithSeverityStyle(SeverityInfo, "", ""),
+		WithSeverityMention(SeverityCritical, MentionGroup("S123")),
+	)
+	defer notifier.Close()
+
+	notifier.NotifyInfo("BTCUSDT & ETHUSDT synced")
+	notifier.NotifyWarn("funding rate %s", "0.1%", slack.Attachment{Text: "detail"}, slack.Attachment{Text: "colored", Color: "good"})
+	notifier.NotifyError("position %s liquidated", "BTCUSDT")
+
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, []string{
+		// the style of the info is disabled
+		"/general BTCUSDT &amp; ETHUSDT synced",
+		"/general :warning: funding rate 0.1%",
+		// the error is routed to the channel of the severity with the mention
+		"/alerts <!subteam^S123> :rotating_light: position BTCUSDT liquidated",
+	}, posted)
+	assert.Equal(t, []string{"warning", "good"}, colors)
+}
+
+func TestNotifier_NotifyContext(t *testing.T) {
+	var mu sync.Mutex
+	var footers [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var msg slack.WebhookMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		var fs []string
+		for _, a := range msg.Attachments {
+			fs = append(fs, a.Footer)
+		}
+		mu.Lock()
+		footers = append(footers, fs)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL)
+	defer notifier.Close()
+
+	ctx := traceid.NewContext(context.Background(), "trace-1")
+	notifier.NotifyContext(ctx, "order %s placed", "BTCUSDT")
+	notifier.NotifyContext(ctx, "order %s filled", "BTCUSDT", slack.Attachment{Text: "fill"}, slack.Attachment{Text: "fee", Footer: "bybit"})
+	// no trace id in the context
+	notifier.NotifyContext(context.Background(), "order %s canceled", "BTCUSDT")
+
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, [][]string{
+		{"trace_id: trace-1"},
+		// the footer in use is kept
+		{"", "bybit", "trace_id: trace-1"},
+		nil,
+	}, footers)
+
+	// the error is logged with the trace id
+	logger, hook := logtest.NewNullLogger()
+	failing := NewWebhook(server.URL+"/fail", WithLogger(logger.WithField("strategy", "xmaker")))
+	defer failing.Close()
+
+	failing.NotifyContext(ctx, "order %s failed", "BTCUSDT")
+	assert.NoError(t, failing.Flush(context.Background()))
+	if entry := hook.LastEntry(); assert.NotNil(t, entry) {
+		assert.Equal(t, "trace-1", entry.Data["trace_id"])
+		assert.Equal(t, "xmaker", entry.Data["strategy"])
+	}
+}
+
+func TestNotifier_WithFooter(t *testing.T) {
+	var mu sync.Mutex
+	var messages []slack.WebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slack.WebhookMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		mu.Lock()
+		messages = append(messages, msg)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL, WithFooter("bbgo", "https://example.com/bbgo.png"))
+	defer notifier.Close()
+
+	start := time.Now().Unix()
+	notifier.Notify("order %s placed", "BTCUSDT")
+	notifier.NotifyContext(traceid.NewContext(context.Background(), "trace-1"), "order %s filled", "BTCUSDT",
+		slack.Attachment{Text: "fill"})
+	assert.NoError(t, notifier.Flush(context.Background()))
+	end := time.Now().Unix()
+
+	var footers []string
+	for _, msg := range messages {
+		for _, a := range msg.Attachments {
+			footers = append(footers, a.Footer)
+			assert.Equal(t, "https://example.com/bbgo.png", a.FooterIcon)
+
+			ts, err := a.Ts.Int64()
+			assert.NoError(t, err)
+			assert.True(t, ts >= start && ts <= end, "unexpected ts %d", ts)
+		}
+	}
+	// the minimal attachment is added to the text-only message
+	assert.Equal(t, []string{"bbgo", "bbgo | trace_id: trace-1"}, footers)
+}
+
+func TestNotifier_NotifyWithOptions(t *testing.T) {
+	var mu sync.Mutex
+	var forms []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "/chat.postMessage", r.URL.Path)
+
+		mu.Lock()
+		forms = append(forms, map[string]string{
+			"text":            r.Form.Get("text"),
+			"username":        r.Form.Get("username"),
+			"unfurl_links":    r.Form.Get("unfurl_links"),
+			"reply_broadcast": r.Form.Get("reply_broadcast"),
+		})
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1710374340.000100"}`))
+	}))
+	defer server.Close()
+
+	notifier := New(slack.New("token", slack.OptionAPIURL(server.URL+"/")), "#general", WithUsername("bbgo"))
+	defer notifier.Close()
+
+	notifier.NotifyWithOptions("BTCUSDT & ETHUSDT", slack.MsgOptionDisableLinkUnfurl(), slack.MsgOptionBroadcast())
+	// the appended options override the ones of the notifier
+	notifier.NotifyWithOptions("report", slack.MsgOptionUsername("reporter"))
+
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, []map[string]string{
+		{"text": "BTCUSDT &amp; ETHUSDT", "username": "bbgo", "unfurl_links": "false", "reply_broadcast": "true"},
+		{"text": "report", "username": "reporter", "unfurl_links": "", "reply_broadcast": ""},
+	}, forms)
+}
+
+func TestNotifier_WithSymbolThreads(t *testing.T) {
+	var mu sync.Mutex
+	var forms []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		forms = append(forms, map[string]string{
+			"channel":   r.Form.Get("channel"),
+			"text":      r.Form.Get("text"),
+			"thread_ts": r.Form.Get("thread_ts"),
+		})
+		ts := fmt.Sprintf("1710374340.%06d", len(forms))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "` + ts + `"}`))
+	}))
+	defer server.Close()
+
+	notifier := New(slack.New("token", slack.OptionAPIURL(server.URL+"/")), "#general", WithSymbolThreads(24*time.Hour))
+	defer notifier.Close()
+
+	notifier.Notify("BTCUSDT order placed, price: %s", ThreadSymbol("BTCUSDT"), "100")
+	notifier.Notify("ETHUSDT order placed", ThreadSymbol("ETHUSDT"))
+	notifier.Notify("BTCUSDT order filled", ThreadSymbol("BTCUSDT"))
+	notifier.NotifyTo("#trades", "BTCUSDT order filled", ThreadSymbol("BTCUSDT"))
+	notifier.Notify("daily report")
+
+	assert.NoError(t, notifier.Flush(context.Background()))
+	assert.Equal(t, []map[string]string{
+		{"channel": "#general", "text": "BTCUSDT order placed, price: 100", "thread_ts": ""},
+		{"channel": "#general", "text": "ETHUSDT order placed", "thread_ts": ""},
+		{"channel": "#general", "text": "BTCUSDT order filled", "thread_ts": "1710374340.000001"},
+		{"channel": "#trades", "text": "BTCUSDT order filled", "thread_ts": ""},
+		{"channel": "#general", "text": "daily report", "thread_ts": ""},
+	}, forms)
+}
+
+func TestSymbolThreads(t *testing.T) {
+	threads := symbolThreads{window: 24 * time.Hour, threads: map[string]symbolThread{}}
+	task := notifyTask{Channel: "#general", ThreadSymbol: "BTCUSDT"}
+
+	now := time.Date(2024, 3, 14, 23, 0, 0, 0, time.UTC)
+	_, ok := threads.threadTS(task, now)
+	assert.False(t, ok)
+
+	// the failed message doesn't start the thread
+	threads.start(task, "", now)
+	_, ok = threads.threadTS(task, now)
+	assert.False(t, ok)
+
+	threads.start(task, "1710374340.000100", now)
+	ts, ok := threads.threadTS(task, now.Add(59*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, "1710374340.000100", ts)
+
+	// a new thread is started after the midnight
+	_, ok = threads.threadTS(task, now.Add(time.Hour))
+	assert.False(t, ok)
+}
+
+func TestNotifier_Delete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "/chat.delete", r.URL.Path)
+
+		switch r.Form.Get("ts") {
+		case "1710374340.000100":
+			assert.Equal(t, "#general", r.Form.Get("channel"))
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1710374340.000100"}`))
+		case "1710374340.000200":
+			_, _ = w.Write([]byte(`{"ok": false, "error": "message_not_found"}`))
+		default:
+			_, _ = w.Write([]byte(`{"ok": false, "error": "cant_delete_message"}`))
+		}
+	}))
+	defer server.Close()
+
+	notifier := New(slack.New("token", slack.OptionAPIURL(server.URL+"/")), "#general")
+	defer notifier.Close()
+
+	// the default channel is used
+	assert.NoError(t, notifier.Delete("", "1710374340.000100"))
+	assert.ErrorContains(t, notifier.Delete("#pnl", "1710374340.000200"), "is not found or already deleted")
+	assert.ErrorContains(t, notifier.Delete("#pnl", "1600000000.000100"), "can not be deleted")
+	assert.Equal(t, ChannelStats{Errors: 2}, notifier.Stats()["#pnl"])
+
+	webhook := NewWebhook(server.URL)
+	defer webhook.Close()
+	assert.ErrorIs(t, webhook.Delete("#pnl", "1710374340.000100"), ErrWebhookNotSupported)
+}
+
+func TestNotifier_WithFooter_PostMessage_Update_Schedule(t *testing.T) {
+	postAt := time.Date(2024, 3, 13, 23, 59, 0, 0, time.UTC)
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	footers := map[string][]string{}
+	stamps := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests[r.URL.Path]++
+
+		// the first request of every method is rate limited and retried
+		if requests[r.URL.Path] == 1 && r.URL.Path != "/chat.scheduledMessages.list" {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		if attachments := r.Form.Get("attachments"); len(attachments) > 0 {
+			var as []slack.Attachment
+			assert.NoError(t, json.Unmarshal([]byte(attachments), &as))
+			for _, a := range as {
+				footers[r.URL.Path] = append(footers[r.URL.Path], a.Footer)
+				stamps[r.URL.Path] = append(stamps[r.URL.Path], a.Ts.String())
+			}
+		}
+
+		switch r.URL.Path {
+		case "/chat.postMessage", "/chat.update":
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1710374340.000100"}`))
+
+		case "/chat.scheduleMessage":
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "scheduled_message_id": "Q1", "post_at": 1710374340}`))
+
+		case "/chat.scheduledMessages.list":
+			_, _ = w.Write([]byte(`{"ok": true, "scheduled_messages": [
+				{"id": "Q1", "channel_id": "C123", "post_at": 1710374340, "date_created": 1710300000, "text": "daily report"}
+			]}`))
+
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	notifier := New(slack.New("token", slack.OptionAPIURL(server.URL+"/")), "#general", WithFooter("bbgo", ""))
+	defer notifier.Close()
+
+	start := time.Now().Unix()
+	ts, err := notifier.PostMessage("#pnl", "position %s opened", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, "1710374340.000100", ts)
+	assert.NoError(t, notifier.Update("#pnl", ts, "position %s closed", "BTCUSDT", slack.Attachment{Text: "pnl"}))
+	end := time.Now().Unix()
+
+	id, err := notifier.Schedule("#pnl", postAt, "daily report")
+	assert.NoError(t, err)
+	assert.Equal(t, "Q1", id)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]int{
+		"/chat.postMessage":            2,
+		"/chat.update":                 2,
+		"/chat.scheduleMessage":        2,
+		"/chat.scheduledMessages.list": 1,
+	}, requests)
+
+	// the footer is added to the given attachment and to the minimal attachment of the text-only message
+	assert.Equal(t, map[string][]string{
+		"/chat.postMessage":     {"bbgo"},
+		"/chat.update":          {"bbgo"},
+		"/chat.scheduleMessage": {"bbgo"},
+	}, footers)
+
+	for _, path := range []string{"/chat.postMessage", "/chat.update"} {
+		if assert.Len(t, stamps[path], 1) {
+			ts, err := strconv.ParseInt(stamps[path][0], 10, 64)
+			assert.NoError(t, err)
+			assert.True(t, ts >= start && ts <= end, "unexpected ts %d of %s", ts, path)
+		}
+	}
+
+	// the scheduled message is stamped with its post time
+	assert.Equal(t, []string{strconv.FormatInt(postAt.Unix(), 10)}, stamps["/chat.scheduleMessage"])
+}
+
+func TestNotifier_Schedule(t *testing.T) {
+	postAt := time.Date(2024, 3, 13, 23, 59, 0, 0, time.UTC)
+
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+
+		switch r.URL.Path {
+		case "/chat.scheduleMessage":
+			assert.Equal(t, "#pnl", r.Form.Get("channel"))
+			assert.Equal(t, strconv.FormatInt(postAt.Unix(), 10), r.Form.Get("post_at"))
+			// the plain text is escaped
+			assert.Equal(t, "daily report of BTCUSDT &amp; ETHUSDT", r.Form.Get("text"))
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "scheduled_message_id": "Q2", "post_at": 1710374340}`))
+
+		case "/chat.scheduledMessages.list":
+			assert.Equal(t, "C123", r.Form.Get("channel"))
+			_, _ = w.Write([]byte(`{"ok": true, "scheduled_messages": [
+				{"id": "Q1", "channel_id": "C123", "post_at": 1710374340, "date_created": 1710300000, "text": "another report"},
+				{"id": "Q0", "channel_id": "C123", "post_at": 1710374340, "date_created": 1710200000, "text": "daily report of BTCUSDT &amp; ETHUSDT"},
+				{"id": "Q2", "channel_id": "C123", "post_at": 1710374340, "date_created": 1710300000, "text": "daily report of BTCUSDT &amp; ETHUSDT"}
+			]}`))
+
+		case "/chat.deleteScheduledMessage":
+			deleted = r.Form.Get("scheduled_message_id")
+			_, _ = w.Write([]byte(`{"ok": true}`))
+
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	notifier := New(slack.New("token", slack.OptionAPIURL(server.URL+"/")), "#general")
+	defer notifier.Close()
+
+	id, err := notifier.Schedule("#pnl", postAt, "daily report of %s & %s", "BTCUSDT", "ETHUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, "Q2", id)
+
+	assert.NoError(t, notifier.DeleteScheduled("#pnl", id))
+	assert.Equal(t, "Q2", deleted)
+
+	webhook := NewWebhook(server.URL)
+	defer webhook.Close()
+	_, err = webhook.Schedule("#pnl", postAt, "report")
+	assert.ErrorIs(t, err, ErrWebhookNotSupported)
+}
+
+func TestWithLogger(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	notifier := New(nil, "#general", WithLogger(logger.WithField("strategy", "xmaker")))
+	assert.NoError(t, notifier.Close())
+
+	notifier.Notify("position closed")
+	if entry := hook.LastEntry(); assert.NotNil(t, entry) {
+		assert.Equal(t, "xmaker", entry.Data["strategy"])
+		assert.Equal(t, "slack", entry.Data["notifier"])
+		assert.Equal(t, "#general", entry.Data["channel"])
+	}
+}
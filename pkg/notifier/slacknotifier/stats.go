@@ -0,0 +1,110 @@
+package slacknotifier
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// ChannelStats is the notification counters of a channel.
+type ChannelStats struct {
+	// Sent is the number of the messages posted successfully, the messages logged in the dry run mode are not counted.
+	Sent uint64
+	// Errors is the number of the messages failed to post, including the rate limited ones.
+	Errors uint64
+	// RateLimited is the number of the messages failed since they are rejected by the slack rate limit after the
+	// retries.
+	RateLimited uint64
+	// Retries is the number of the retries of the messages rejected by the slack rate limit.
+	Retries uint64
+	// DryRun is the number of the messages logged instead of posted, see WithDryRun.
+	DryRun uint64
+	// Dropped is the number of the messages dropped since the queue is full.
+	Dropped uint64
+	// Throttled is the number of the messages suppressed by the deduplication or the channel rate limit.
+	Throttled uint64
+}
+
+type notifyStats struct {
+	mu       sync.Mutex
+	channels map[string]*ChannelStats
+}
+
+func (s *notifyStats) get(channel string) *ChannelStats {
+	if s.channels == nil {
+		s.channels = make(map[string]*ChannelStats)
+	}
+
+	stats, ok := s.channels[channel]
+	if !ok {
+		stats = &ChannelStats{}
+		s.channels[channel] = stats
+	}
+	return stats
+}
+
+// record counts the result of posting a message to the channel.
+func (s *notifyStats) record(channel string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.get(channel)
+	if err == nil {
+		stats.Sent++
+		metricsNotifications.WithLabelValues(channel, "sent").Inc()
+		return
+	}
+
+	stats.Errors++
+	metricsNotifications.WithLabelValues(channel, "error").Inc()
+
+	var rateLimitedErr *slack.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		stats.RateLimited++
+		metricsNotifications.WithLabelValues(channel, "rate_limited").Inc()
+	}
+}
+
+func (s *notifyStats) retry(channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.get(channel).Retries++
+	metricsNotifications.WithLabelValues(channel, "retry").Inc()
+}
+
+func (s *notifyStats) dryRun(channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.get(channel).DryRun++
+	metricsNotifications.WithLabelValues(channel, "dry_run").Inc()
+}
+
+func (s *notifyStats) drop(channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.get(channel).Dropped++
+	metricsNotifications.WithLabelValues(channel, "dropped").Inc()
+}
+
+func (s *notifyStats) throttle(channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.get(channel).Throttled++
+	metricsNotifications.WithLabelValues(channel, "throttled").Inc()
+}
+
+func (s *notifyStats) snapshot() map[string]ChannelStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make(map[string]ChannelStats, len(s.channels))
+	for channel, stats := range s.channels {
+		snapshot[channel] = *stats
+	}
+	return snapshot
+}
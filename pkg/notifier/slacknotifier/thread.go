@@ -0,0 +1,98 @@
+package slacknotifier
+
+import (
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// ThreadSymbol groups the message into the rolling thread of the symbol when it's given as an arg of Notify and the
+// other async notify methods, see WithSymbolThreads. It's not a format arg, so it can be given at any position.
+type ThreadSymbol string
+
+type symbolThread struct {
+	ts     string
+	period time.Time
+}
+
+// symbolThreads tracks the parent message of every channel and symbol in the current period. It's only accessed by
+// the worker, so the messages of the same symbol are threaded in order.
+type symbolThreads struct {
+	window time.Duration
+	// threads maps the channel and the symbol to the parent message.
+	threads map[string]symbolThread
+}
+
+func (t *symbolThreads) enabled() bool {
+	return t.window > 0
+}
+
+func symbolThreadKey(channel, symbol string) string {
+	return channel + ":" + symbol
+}
+
+// period returns the start of the window containing the time, the windows are aligned to the unix epoch, so a 24h
+// window starts at the midnight in UTC.
+func (t *symbolThreads) period(now time.Time) time.Time {
+	return now.Truncate(t.window)
+}
+
+// threadTS returns the timestamp of the parent message of the task in the current period, or false if the task should
+// start a new thread.
+func (t *symbolThreads) threadTS(task notifyTask, now time.Time) (string, bool) {
+	thread, ok := t.threads[symbolThreadKey(task.Channel, task.ThreadSymbol)]
+	if !ok || !thread.period.Equal(t.period(now)) {
+		return "", false
+	}
+	return thread.ts, true
+}
+
+// start records the posted message as the parent of the thread of the task in the current period.
+func (t *symbolThreads) start(task notifyTask, ts string, now time.Time) {
+	if len(ts) == 0 {
+		return
+	}
+
+	t.threads[symbolThreadKey(task.Channel, task.ThreadSymbol)] = symbolThread{ts: ts, period: t.period(now)}
+}
+
+// threadTask replies the task in the thread of its symbol if there is one, it returns true if the posted task should
+// be recorded as the parent of a new thread.
+func (n *Notifier) threadTask(task *notifyTask, now time.Time) bool {
+	if !n.symbolThreads.enabled() || len(task.ThreadSymbol) == 0 || n.isWebhook() {
+		return false
+	}
+
+	ts, ok := n.symbolThreads.threadTS(*task, now)
+	if !ok {
+		return true
+	}
+
+	task.Options = append(append([]slack.MsgOption(nil), task.Options...), slack.MsgOptionTS(ts))
+	return false
+}
+
+// splitThreadSymbol removes the ThreadSymbol from the args, the last one wins if there are many.
+func splitThreadSymbol(args []interface{}) (string, []interface{}) {
+	var symbol string
+	var rest []interface{}
+	for i, arg := range args {
+		s, ok := arg.(ThreadSymbol)
+		if !ok {
+			if rest != nil {
+				rest = append(rest, arg)
+			}
+			continue
+		}
+
+		if rest == nil {
+			rest = append(make([]interface{}, 0, len(args)-1), args[:i]...)
+		}
+		symbol = string(s)
+	}
+
+	if rest == nil {
+		return "", args
+	}
+	return symbol, rest
+}
@@ -0,0 +1,109 @@
+package slacknotifier
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxDedupEntries bounds the number of the tracked messages, the expired entries are pruned when it's exceeded.
+const maxDedupEntries = 1000
+
+type dedupEntry struct {
+	since    time.Time
+	repeated int
+}
+
+// messageThrottler suppresses the notification storms. The identical messages of a channel within the dedup window
+// are coalesced, the next message after the window carries the "repeated N times" suffix. The messages beyond the
+// per-channel rate limit are dropped.
+type messageThrottler struct {
+	mu sync.Mutex
+
+	dedupWindow time.Duration
+	// entries maps the channel and the rendered text to the dedup entry.
+	entries map[string]map[string]*dedupEntry
+
+	rateInterval time.Duration
+	rateBurst    int
+	limiters     map[string]*rate.Limiter
+}
+
+func (t *messageThrottler) enabled() bool {
+	return t.dedupWindow > 0 || t.rateInterval > 0
+}
+
+// allow returns the text to post, or false if the message should be suppressed.
+func (t *messageThrottler) allow(channel, text string, now time.Time) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.dedupWindow > 0 && len(text) > 0 {
+		var ok bool
+		if text, ok = t.dedup(channel, text, now); !ok {
+			return text, false
+		}
+	}
+
+	if !t.limit(channel, now) {
+		return text, false
+	}
+
+	return text, true
+}
+
+// allowRate returns false if the message exceeds the rate limit of the channel. Unlike allow, the message is not
+// deduplicated.
+func (t *messageThrottler) allowRate(channel string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limit(channel, now)
+}
+
+// limit must be called with the lock held.
+func (t *messageThrottler) limit(channel string, now time.Time) bool {
+	if t.rateInterval <= 0 {
+		return true
+	}
+
+	limiter, ok := t.limiters[channel]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(t.rateInterval), t.rateBurst)
+		t.limiters[channel] = limiter
+	}
+
+	return limiter.AllowN(now, 1)
+}
+
+// dedup must be called with the lock held.
+func (t *messageThrottler) dedup(channel, text string, now time.Time) (string, bool) {
+	entries, ok := t.entries[channel]
+	if !ok {
+		entries = make(map[string]*dedupEntry)
+		t.entries[channel] = entries
+	}
+
+	entry, ok := entries[text]
+	if ok && now.Sub(entry.since) < t.dedupWindow {
+		entry.repeated++
+		return text, false
+	}
+
+	if len(entries) >= maxDedupEntries {
+		for key, e := range entries {
+			if now.Sub(e.since) >= t.dedupWindow {
+				delete(entries, key)
+			}
+		}
+	}
+
+	entries[text] = &dedupEntry{since: now}
+
+	if ok && entry.repeated > 0 {
+		return fmt.Sprintf("%s (repeated %d times in last %s)", text, entry.repeated, t.dedupWindow), true
+	}
+
+	return text, true
+}
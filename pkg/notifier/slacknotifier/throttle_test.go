@@ -0,0 +1,85 @@
+package slacknotifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func newTestThrottler() *messageThrottler {
+	return &messageThrottler{
+		entries:  map[string]map[string]*dedupEntry{},
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+func TestMessageThrottler_dedup(t *testing.T) {
+	throttler := newTestThrottler()
+	throttler.dedupWindow = time.Minute
+
+	now := time.Now()
+	text, ok := throttler.allow("#alerts", "error", now)
+	assert.True(t, ok)
+	assert.Equal(t, "error", text)
+
+	for i := 1; i <= 3; i++ {
+		_, ok = throttler.allow("#alerts", "error", now.Add(time.Duration(i)*time.Second))
+		assert.False(t, ok)
+	}
+
+	// the other channel and the other text are not affected
+	_, ok = throttler.allow("#trades", "error", now)
+	assert.True(t, ok)
+	_, ok = throttler.allow("#alerts", "another error", now)
+	assert.True(t, ok)
+
+	text, ok = throttler.allow("#alerts", "error", now.Add(time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, "error (repeated 3 times in last 1m0s)", text)
+
+	// the repeat count is reset
+	text, ok = throttler.allow("#alerts", "error", now.Add(2*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, "error", text)
+}
+
+func TestMessageThrottler_rateLimit(t *testing.T) {
+	throttler := newTestThrottler()
+	throttler.rateInterval = time.Second
+	throttler.rateBurst = 2
+
+	now := time.Now()
+	_, ok := throttler.allow("#alerts", "a", now)
+	assert.True(t, ok)
+	_, ok = throttler.allow("#alerts", "b", now)
+	assert.True(t, ok)
+	_, ok = throttler.allow("#alerts", "c", now)
+	assert.False(t, ok)
+
+	_, ok = throttler.allow("#trades", "c", now)
+	assert.True(t, ok)
+
+	_, ok = throttler.allow("#alerts", "c", now.Add(time.Second))
+	assert.True(t, ok)
+}
+
+func TestMessageThrottler_allowRate(t *testing.T) {
+	throttler := newTestThrottler()
+	throttler.dedupWindow = time.Minute
+
+	// no rate limit, and the messages are not deduplicated
+	now := time.Now()
+	assert.True(t, throttler.allowRate("#trades", now))
+	assert.True(t, throttler.allowRate("#trades", now))
+
+	throttler.rateInterval = time.Second
+	throttler.rateBurst = 1
+	assert.True(t, throttler.allowRate("#trades", now))
+	assert.False(t, throttler.allowRate("#trades", now))
+	// the rate limit is shared with allow
+	_, ok := throttler.allow("#trades", "a", now)
+	assert.False(t, ok)
+	assert.True(t, throttler.allowRate("#trades", now.Add(time.Second)))
+}
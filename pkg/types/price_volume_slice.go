@@ -155,7 +155,14 @@ func (slice PriceVolumeSlice) Find(price fixedpoint.Value, descending bool) (pv
 	return pv, idx
 }
 
+// Upsert applies the price level update to the sorted slice, it inserts the level at the sorted position, updates the
+// volume if the price exists, or removes the level if the volume is zero.
+// true for descending (bid orders), false for ascending (ask orders)
 func (slice PriceVolumeSlice) Upsert(pv PriceVolume, descending bool) PriceVolumeSlice {
+	if pv.Volume.IsZero() {
+		return slice.Remove(pv.Price, descending)
+	}
+
 	if len(slice) == 0 {
 		return append(slice, pv)
 	}
@@ -170,6 +177,36 @@ func (slice PriceVolumeSlice) Upsert(pv PriceVolume, descending bool) PriceVolum
 	return slice
 }
 
+// Bucket aggregates the price levels into the price buckets of the given step, the volumes in the same bucket are
+// summed up. The bid prices (descending) are rounded down and the ask prices (ascending) are rounded up, so the
+// bucketed book never looks tighter than the original one. The slice must be sorted, it's not modified.
+func (slice PriceVolumeSlice) Bucket(step fixedpoint.Value, descending bool) PriceVolumeSlice {
+	if step.Sign() <= 0 {
+		return slice.Copy()
+	}
+
+	mode := fixedpoint.Up
+	if descending {
+		mode = fixedpoint.Down
+	}
+
+	var buckets PriceVolumeSlice
+	for _, pv := range slice {
+		// round the bucket price with the precision of the step to avoid the floating point error.
+		price := pv.Price.Div(step).Round(0, mode).Mul(step).Round(step.NumFractionalDigits(), fixedpoint.HalfUp)
+
+		last := len(buckets) - 1
+		if last >= 0 && buckets[last].Price.Compare(price) == 0 {
+			buckets[last].Volume = buckets[last].Volume.Add(pv.Volume)
+			continue
+		}
+
+		buckets = append(buckets, PriceVolume{Price: price, Volume: pv.Volume})
+	}
+
+	return buckets
+}
+
 func (slice *PriceVolumeSlice) UnmarshalJSON(b []byte) error {
 	s, err := ParsePriceVolumeSliceJSON(b)
 	if err != nil {
@@ -10,9 +10,9 @@ import (
 func TestPriceVolumeSlice_Remove(t *testing.T) {
 	for _, descending := range []bool{true, false} {
 		slice := PriceVolumeSlice{}
-		slice = slice.Upsert(PriceVolume{Price: fixedpoint.One}, descending)
-		slice = slice.Upsert(PriceVolume{Price: fixedpoint.NewFromInt(3)}, descending)
-		slice = slice.Upsert(PriceVolume{Price: fixedpoint.NewFromInt(5)}, descending)
+		slice = slice.Upsert(PriceVolume{Price: fixedpoint.One, Volume: fixedpoint.One}, descending)
+		slice = slice.Upsert(PriceVolume{Price: fixedpoint.NewFromInt(3), Volume: fixedpoint.One}, descending)
+		slice = slice.Upsert(PriceVolume{Price: fixedpoint.NewFromInt(5), Volume: fixedpoint.One}, descending)
 		assert.Equal(t, 3, len(slice), "with descending %v", descending)
 
 		slice = slice.Remove(fixedpoint.NewFromInt(2), descending)
@@ -28,3 +28,94 @@ func TestPriceVolumeSlice_Remove(t *testing.T) {
 		assert.Equal(t, 2, len(slice), "with descending %v", descending)
 	}
 }
+
+func TestPriceVolumeSlice_Upsert(t *testing.T) {
+	pv := func(price, volume float64) PriceVolume {
+		return PriceVolume{Price: fixedpoint.NewFromFloat(price), Volume: fixedpoint.NewFromFloat(volume)}
+	}
+
+	t.Run("bids", func(t *testing.T) {
+		slice := PriceVolumeSlice{}
+		slice = slice.Upsert(pv(100, 1), true)
+		slice = slice.Upsert(pv(102, 2), true)
+		slice = slice.Upsert(pv(98, 3), true)
+		slice = slice.Upsert(pv(101, 4), true)
+		assert.Equal(t, PriceVolumeSlice{pv(102, 2), pv(101, 4), pv(100, 1), pv(98, 3)}, slice)
+
+		// update in place
+		slice = slice.Upsert(pv(101, 5), true)
+		assert.Equal(t, PriceVolumeSlice{pv(102, 2), pv(101, 5), pv(100, 1), pv(98, 3)}, slice)
+
+		// remove the best, the middle and the last levels
+		slice = slice.Upsert(pv(102, 0), true)
+		slice = slice.Upsert(pv(100, 0), true)
+		slice = slice.Upsert(pv(98, 0), true)
+		assert.Equal(t, PriceVolumeSlice{pv(101, 5)}, slice)
+	})
+
+	t.Run("asks", func(t *testing.T) {
+		slice := PriceVolumeSlice{}
+		slice = slice.Upsert(pv(100, 1), false)
+		slice = slice.Upsert(pv(98, 2), false)
+		slice = slice.Upsert(pv(102, 3), false)
+		slice = slice.Upsert(pv(99, 4), false)
+		assert.Equal(t, PriceVolumeSlice{pv(98, 2), pv(99, 4), pv(100, 1), pv(102, 3)}, slice)
+
+		slice = slice.Upsert(pv(99, 5), false)
+		assert.Equal(t, PriceVolumeSlice{pv(98, 2), pv(99, 5), pv(100, 1), pv(102, 3)}, slice)
+
+		slice = slice.Upsert(pv(98, 0), false)
+		slice = slice.Upsert(pv(102, 0), false)
+		assert.Equal(t, PriceVolumeSlice{pv(99, 5), pv(100, 1)}, slice)
+	})
+
+	t.Run("remove the missing price", func(t *testing.T) {
+		slice := PriceVolumeSlice{pv(100, 1)}
+		assert.Equal(t, PriceVolumeSlice{pv(100, 1)}, slice.Upsert(pv(101, 0), true))
+		assert.Equal(t, PriceVolumeSlice{}, PriceVolumeSlice{}.Upsert(pv(101, 0), true))
+	})
+
+	t.Run("insert does not modify the other levels", func(t *testing.T) {
+		slice := make(PriceVolumeSlice, 0, 10)
+		slice = slice.Upsert(pv(100, 1), false)
+		slice = slice.Upsert(pv(102, 1), false)
+		slice = slice.Upsert(pv(101, 1), false)
+		slice = slice.Upsert(pv(99, 1), false)
+		assert.Equal(t, PriceVolumeSlice{pv(99, 1), pv(100, 1), pv(101, 1), pv(102, 1)}, slice)
+	})
+}
+
+func TestPriceVolumeSlice_Bucket(t *testing.T) {
+	bids := PriceVolumeSlice{
+		{Price: fixedpoint.MustNewFromString("100.9"), Volume: fixedpoint.MustNewFromString("1")},
+		{Price: fixedpoint.MustNewFromString("100.1"), Volume: fixedpoint.MustNewFromString("2")},
+		{Price: fixedpoint.MustNewFromString("100.0"), Volume: fixedpoint.MustNewFromString("0.5")},
+		{Price: fixedpoint.MustNewFromString("99.5"), Volume: fixedpoint.MustNewFromString("3")},
+	}
+	asks := PriceVolumeSlice{
+		{Price: fixedpoint.MustNewFromString("101.1"), Volume: fixedpoint.MustNewFromString("1")},
+		{Price: fixedpoint.MustNewFromString("101.9"), Volume: fixedpoint.MustNewFromString("2")},
+		{Price: fixedpoint.MustNewFromString("102.0"), Volume: fixedpoint.MustNewFromString("0.5")},
+		{Price: fixedpoint.MustNewFromString("102.3"), Volume: fixedpoint.MustNewFromString("3")},
+	}
+
+	assert.Equal(t, PriceVolumeSlice{
+		{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.MustNewFromString("3.5")},
+		{Price: fixedpoint.NewFromInt(99), Volume: fixedpoint.NewFromInt(3)},
+	}, bids.Bucket(fixedpoint.One, true))
+	assert.Equal(t, PriceVolumeSlice{
+		{Price: fixedpoint.NewFromInt(102), Volume: fixedpoint.MustNewFromString("3.5")},
+		{Price: fixedpoint.NewFromInt(103), Volume: fixedpoint.NewFromInt(3)},
+	}, asks.Bucket(fixedpoint.One, false))
+
+	// the original book is untouched
+	assert.Len(t, bids, 4)
+	assert.Equal(t, fixedpoint.MustNewFromString("100.9"), bids[0].Price)
+
+	// the fractional step
+	assert.Equal(t, PriceVolumeSlice{
+		{Price: fixedpoint.MustNewFromString("100.5"), Volume: fixedpoint.NewFromInt(1)},
+		{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.MustNewFromString("2.5")},
+		{Price: fixedpoint.MustNewFromString("99.5"), Volume: fixedpoint.NewFromInt(3)},
+	}, bids.Bucket(fixedpoint.MustNewFromString("0.5"), true))
+}
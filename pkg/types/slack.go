@@ -5,3 +5,8 @@ import "github.com/slack-go/slack"
 type SlackAttachmentCreator interface {
 	SlackAttachment() slack.Attachment
 }
+
+// SlackBlocksCreator creates the Block Kit blocks for the slack message
+type SlackBlocksCreator interface {
+	SlackBlocks() []slack.Block
+}
@@ -26,6 +26,11 @@ type SliceOrderBook struct {
 	// this is for binance right now.
 	LastUpdateId int64
 
+	// SequenceId is the cross sequence of the book from the server, it's comparable across the depth levels and the
+	// streams of the same symbol, the smaller sequence is generated earlier.
+	// this field is optional, not every exchange provides this information, it's for bybit right now.
+	SequenceId int64
+
 	lastUpdateTime time.Time
 
 	loadCallbacks   []func(book *SliceOrderBook)
@@ -56,6 +61,45 @@ func (b *SliceOrderBook) Spread() (fixedpoint.Value, bool) {
 	return bestAsk.Price.Sub(bestBid.Price), true
 }
 
+// MidPrice returns the middle of the best bid and the best ask, it returns false if either side is empty.
+func (b *SliceOrderBook) MidPrice() (fixedpoint.Value, bool) {
+	bestBid, ok := b.BestBid()
+	if !ok {
+		return fixedpoint.Zero, false
+	}
+
+	bestAsk, ok := b.BestAsk()
+	if !ok {
+		return fixedpoint.Zero, false
+	}
+
+	return bestBid.Price.Add(bestAsk.Price).Div(fixedpoint.Two), true
+}
+
+// VWAP returns the volume weighted average price to fill the order of the given side and size against the book, the buy
+// order walks the asks from the best ask and the sell order walks the bids from the best bid. If the book is too thin,
+// it returns the average price of the partial fill, the filled size tells it. The price is zero if nothing is filled.
+func (b *SliceOrderBook) VWAP(side SideType, size fixedpoint.Value) (price fixedpoint.Value, filled fixedpoint.Value) {
+	quote := fixedpoint.Zero
+	filled = fixedpoint.Zero
+	for _, pv := range b.SideBook(side.Reverse()) {
+		remaining := size.Sub(filled)
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		volume := fixedpoint.Min(pv.Volume, remaining)
+		quote = quote.Add(pv.Price.Mul(volume))
+		filled = filled.Add(volume)
+	}
+
+	if filled.IsZero() {
+		return fixedpoint.Zero, fixedpoint.Zero
+	}
+
+	return quote.Div(filled), filled
+}
+
 func (b *SliceOrderBook) BestBid() (PriceVolume, bool) {
 	if len(b.Bids) == 0 {
 		return PriceVolume{}, false
@@ -105,6 +149,45 @@ func (b *SliceOrderBook) IsValid() (bool, error) {
 	return true, nil
 }
 
+// Validate checks the consistency of the book, the bids must be strictly descending, the asks must be strictly
+// ascending, the book must not be crossed and there must be no zero volume level. The empty sides are valid.
+func (b *SliceOrderBook) Validate() error {
+	if err := validateSide(b.Bids, true); err != nil {
+		return fmt.Errorf("invalid bids: %w", err)
+	}
+
+	if err := validateSide(b.Asks, false); err != nil {
+		return fmt.Errorf("invalid asks: %w", err)
+	}
+
+	bid, hasBid := b.BestBid()
+	ask, hasAsk := b.BestAsk()
+	if hasBid && hasAsk && bid.Price.Compare(ask.Price) >= 0 {
+		return fmt.Errorf("crossed book, best bid price %s >= best ask price %s", bid.Price.String(), ask.Price.String())
+	}
+
+	return nil
+}
+
+func validateSide(pvs PriceVolumeSlice, descending bool) error {
+	for i, pv := range pvs {
+		if pv.Volume.Sign() <= 0 {
+			return fmt.Errorf("non-positive volume %s at price %s", pv.Volume.String(), pv.Price.String())
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		cmp := pvs[i-1].Price.Compare(pv.Price)
+		if (descending && cmp <= 0) || (!descending && cmp >= 0) {
+			return fmt.Errorf("price %s is out of order after %s", pv.Price.String(), pvs[i-1].Price.String())
+		}
+	}
+
+	return nil
+}
+
 func (b *SliceOrderBook) PriceVolumesBySide(side SideType) PriceVolumeSlice {
 	switch side {
 
@@ -120,21 +203,13 @@ func (b *SliceOrderBook) PriceVolumesBySide(side SideType) PriceVolumeSlice {
 
 func (b *SliceOrderBook) updateAsks(pvs PriceVolumeSlice) {
 	for _, pv := range pvs {
-		if pv.Volume.IsZero() {
-			b.Asks = b.Asks.Remove(pv.Price, false)
-		} else {
-			b.Asks = b.Asks.Upsert(pv, false)
-		}
+		b.Asks = b.Asks.Upsert(pv, false)
 	}
 }
 
 func (b *SliceOrderBook) updateBids(pvs PriceVolumeSlice) {
 	for _, pv := range pvs {
-		if pv.Volume.IsZero() {
-			b.Bids = b.Bids.Remove(pv.Price, true)
-		} else {
-			b.Bids = b.Bids.Upsert(pv, true)
-		}
+		b.Bids = b.Bids.Upsert(pv, true)
 	}
 }
 
@@ -198,6 +273,8 @@ func (b *SliceOrderBook) CopyDepth(limit int) OrderBook {
 	var book SliceOrderBook
 	book.Symbol = b.Symbol
 	book.Time = b.Time
+	book.LastUpdateId = b.LastUpdateId
+	book.SequenceId = b.SequenceId
 	book.Bids = b.Bids.CopyDepth(limit)
 	book.Asks = b.Asks.CopyDepth(limit)
 	return &book
@@ -207,7 +284,42 @@ func (b *SliceOrderBook) Copy() OrderBook {
 	var book SliceOrderBook
 	book.Symbol = b.Symbol
 	book.Time = b.Time
+	book.LastUpdateId = b.LastUpdateId
+	book.SequenceId = b.SequenceId
 	book.Bids = b.Bids.Copy()
 	book.Asks = b.Asks.Copy()
 	return &book
 }
+
+// Truncate returns a copy of the book limited to the top maxLevels bids and asks, the ordering of the sides is
+// preserved. Zero or a negative maxLevels copies the full depth.
+func (b *SliceOrderBook) Truncate(maxLevels int) SliceOrderBook {
+	if maxLevels < 0 {
+		maxLevels = 0
+	}
+
+	return SliceOrderBook{
+		Symbol:       b.Symbol,
+		Time:         b.Time,
+		LastUpdateId: b.LastUpdateId,
+		SequenceId:   b.SequenceId,
+		Bids:         b.Bids.CopyDepth(maxLevels),
+		Asks:         b.Asks.CopyDepth(maxLevels),
+	}
+}
+
+// TruncateInPlace limits the book to the top maxLevels bids and asks without allocating a copy, the removed levels
+// are dropped by re-slicing. Zero or a negative maxLevels keeps the full depth.
+func (b *SliceOrderBook) TruncateInPlace(maxLevels int) {
+	if maxLevels <= 0 {
+		return
+	}
+
+	if len(b.Bids) > maxLevels {
+		b.Bids = b.Bids[:maxLevels]
+	}
+
+	if len(b.Asks) > maxLevels {
+		b.Asks = b.Asks[:maxLevels]
+	}
+}
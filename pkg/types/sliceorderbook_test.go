@@ -25,3 +25,176 @@ func TestSliceOrderBook_CopyDepth(t *testing.T) {
 	assert.Equal(t, 3, len(copied.SideBook(SideTypeSell)))
 	assert.Equal(t, 4, len(copied.SideBook(SideTypeBuy)))
 }
+
+func TestSliceOrderBook_Truncate(t *testing.T) {
+	newBook := func() *SliceOrderBook {
+		return &SliceOrderBook{
+			Symbol: "BTCUSDT",
+			Bids: PriceVolumeSlice{
+				{Price: number(0.119), Volume: number(100.0)},
+				{Price: number(0.118), Volume: number(100.0)},
+				{Price: number(0.117), Volume: number(100.0)},
+			},
+			Asks: PriceVolumeSlice{
+				{Price: number(0.120), Volume: number(100.0)},
+				{Price: number(0.121), Volume: number(100.0)},
+			},
+		}
+	}
+
+	t.Run("copy", func(t *testing.T) {
+		b := newBook()
+		truncated := b.Truncate(2)
+		assert.Equal(t, "BTCUSDT", truncated.Symbol)
+		assert.Equal(t, PriceVolumeSlice{
+			{Price: number(0.119), Volume: number(100.0)},
+			{Price: number(0.118), Volume: number(100.0)},
+		}, truncated.Bids)
+		assert.Len(t, truncated.Asks, 2)
+
+		// the original book is not modified
+		truncated.Bids[0].Volume = number(1.0)
+		assert.Len(t, b.Bids, 3)
+		assert.Equal(t, number(100.0), b.Bids[0].Volume)
+
+		assert.Len(t, b.Truncate(0).Bids, 3)
+	})
+
+	t.Run("in place", func(t *testing.T) {
+		b := newBook()
+		b.TruncateInPlace(1)
+		assert.Equal(t, PriceVolumeSlice{{Price: number(0.119), Volume: number(100.0)}}, b.Bids)
+		assert.Equal(t, PriceVolumeSlice{{Price: number(0.120), Volume: number(100.0)}}, b.Asks)
+
+		b = newBook()
+		b.TruncateInPlace(0)
+		assert.Len(t, b.Bids, 3)
+	})
+}
+
+func TestSliceOrderBook_TopOfBook(t *testing.T) {
+	b := &SliceOrderBook{}
+
+	_, ok := b.BestBid()
+	assert.False(t, ok)
+	_, ok = b.Spread()
+	assert.False(t, ok)
+	_, ok = b.MidPrice()
+	assert.False(t, ok)
+
+	b.Bids = PriceVolumeSlice{{Price: number(100.0), Volume: number(1.0)}}
+	_, ok = b.MidPrice()
+	assert.False(t, ok)
+
+	b.Asks = PriceVolumeSlice{{Price: number(102.0), Volume: number(2.0)}}
+
+	bid, ok := b.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, number(100.0), bid.Price)
+
+	ask, ok := b.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, number(102.0), ask.Price)
+
+	spread, ok := b.Spread()
+	assert.True(t, ok)
+	assert.Equal(t, number(2.0), spread)
+
+	mid, ok := b.MidPrice()
+	assert.True(t, ok)
+	assert.Equal(t, number(101.0), mid)
+}
+
+func TestSliceOrderBook_VWAP(t *testing.T) {
+	b := &SliceOrderBook{
+		Bids: PriceVolumeSlice{
+			{Price: number(100.0), Volume: number(1.0)},
+			{Price: number(99.0), Volume: number(2.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{Price: number(101.0), Volume: number(1.0)},
+			{Price: number(102.0), Volume: number(3.0)},
+		},
+	}
+
+	// the buy order walks the asks
+	price, filled := b.VWAP(SideTypeBuy, number(2.0))
+	assert.Equal(t, number(101.5), price)
+	assert.Equal(t, number(2.0), filled)
+
+	// the sell order walks the bids
+	price, filled = b.VWAP(SideTypeSell, number(0.5))
+	assert.Equal(t, number(100.0), price)
+	assert.Equal(t, number(0.5), filled)
+
+	// the book is too thin
+	price, filled = b.VWAP(SideTypeSell, number(5.0))
+	assert.Equal(t, number(298.0).Div(number(3.0)), price)
+	assert.Equal(t, number(3.0), filled)
+
+	price, filled = (&SliceOrderBook{}).VWAP(SideTypeBuy, number(1.0))
+	assert.True(t, price.IsZero())
+	assert.True(t, filled.IsZero())
+}
+
+func TestSliceOrderBook_Validate(t *testing.T) {
+	pv := func(price, volume float64) PriceVolume {
+		return PriceVolume{Price: number(price), Volume: number(volume)}
+	}
+
+	tests := []struct {
+		name  string
+		book  SliceOrderBook
+		valid bool
+	}{
+		{
+			name:  "empty",
+			book:  SliceOrderBook{},
+			valid: true,
+		},
+		{
+			name: "valid",
+			book: SliceOrderBook{
+				Bids: PriceVolumeSlice{pv(100, 1), pv(99, 1)},
+				Asks: PriceVolumeSlice{pv(101, 1), pv(102, 1)},
+			},
+			valid: true,
+		},
+		{
+			name: "bids out of order",
+			book: SliceOrderBook{
+				Bids: PriceVolumeSlice{pv(99, 1), pv(100, 1)},
+			},
+		},
+		{
+			name: "duplicated ask price",
+			book: SliceOrderBook{
+				Asks: PriceVolumeSlice{pv(101, 1), pv(101, 1)},
+			},
+		},
+		{
+			name: "crossed",
+			book: SliceOrderBook{
+				Bids: PriceVolumeSlice{pv(101, 1)},
+				Asks: PriceVolumeSlice{pv(101, 1)},
+			},
+		},
+		{
+			name: "zero volume",
+			book: SliceOrderBook{
+				Bids: PriceVolumeSlice{pv(100, 1), pv(99, 0)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.book.Validate()
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type spreadSample struct {
+	Time   time.Time
+	Spread fixedpoint.Value
+	// Valid is false when one side of the book is empty, the period is excluded from the average.
+	Valid bool
+}
+
+// SpreadMonitor samples the best bid/ask spread on every top-of-book change and calculates the time-weighted
+// average spread (TWAS) over a rolling window, every spread is weighted by its dwell time.
+type SpreadMonitor struct {
+	Window time.Duration
+
+	mu      sync.Mutex
+	samples []spreadSample
+}
+
+func NewSpreadMonitor(window time.Duration) *SpreadMonitor {
+	return &SpreadMonitor{
+		Window: window,
+	}
+}
+
+// Bind samples the spread of the order book on every load and update.
+func (m *SpreadMonitor) Bind(book *StreamOrderBook) {
+	book.OnUpdate(func(update SliceOrderBook) {
+		m.updateFromStreamBook(book, defaultTime(update.Time, time.Now))
+	})
+	book.OnSnapshot(func(snapshot SliceOrderBook) {
+		m.updateFromStreamBook(book, defaultTime(snapshot.Time, time.Now))
+	})
+}
+
+func (m *SpreadMonitor) updateFromStreamBook(book *StreamOrderBook, t time.Time) {
+	bid, ask, ok := book.BestBidAndAsk()
+	m.Add(ask.Price.Sub(bid.Price), ok, t)
+}
+
+// Update samples the spread of the order book at the given time.
+func (m *SpreadMonitor) Update(book OrderBook, t time.Time) {
+	spread, ok := book.Spread()
+	m.Add(spread, ok, t)
+}
+
+// Add adds the spread sample at the given time, the invalid sample means one side of the book is empty.
+// The sample is skipped if the spread is not changed.
+func (m *SpreadMonitor) Add(spread fixedpoint.Value, valid bool, t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n := len(m.samples); n > 0 {
+		last := m.samples[n-1]
+		if last.Valid == valid && (!valid || last.Spread.Compare(spread) == 0) {
+			return
+		}
+	}
+
+	m.samples = append(m.samples, spreadSample{Time: t, Spread: spread, Valid: valid})
+	m.prune(t)
+}
+
+// prune removes the samples which end before the window, it must be called with the lock held.
+func (m *SpreadMonitor) prune(now time.Time) {
+	start := now.Add(-m.Window)
+
+	// the sample i ends at the time of the sample i+1
+	idx := 0
+	for idx+1 < len(m.samples) && !m.samples[idx+1].Time.After(start) {
+		idx++
+	}
+
+	if idx > 0 {
+		m.samples = append([]spreadSample{}, m.samples[idx:]...)
+	}
+}
+
+// TWAS returns the time-weighted average spread over the window ending at the given time. It returns false if there
+// is no valid spread in the window.
+func (m *SpreadMonitor) TWAS(now time.Time) (fixedpoint.Value, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start := now.Add(-m.Window)
+
+	sum := fixedpoint.Zero
+	var total time.Duration
+	for i, sample := range m.samples {
+		end := now
+		if i+1 < len(m.samples) {
+			end = m.samples[i+1].Time
+		}
+
+		begin := sample.Time
+		if begin.Before(start) {
+			begin = start
+		}
+
+		if !sample.Valid || !end.After(begin) {
+			continue
+		}
+
+		dwell := end.Sub(begin)
+		sum = sum.Add(sample.Spread.Mul(fixedpoint.NewFromFloat(dwell.Seconds())))
+		total += dwell
+	}
+
+	if total == 0 {
+		return fixedpoint.Zero, false
+	}
+
+	return sum.Div(fixedpoint.NewFromFloat(total.Seconds())), true
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestSpreadMonitor_TWAS(t *testing.T) {
+	t0 := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewSpreadMonitor(10 * time.Second)
+	_, ok := m.TWAS(t0)
+	assert.False(t, ok)
+
+	// spread 1 for 1s, 3 for 3s, the empty side for 2s, and then 2 for 2s
+	m.Add(fixedpoint.One, true, t0)
+	m.Add(fixedpoint.NewFromInt(3), true, t0.Add(time.Second))
+	m.Add(fixedpoint.NewFromInt(3), true, t0.Add(2*time.Second)) // not changed
+	m.Add(fixedpoint.Zero, false, t0.Add(4*time.Second))
+	m.Add(fixedpoint.NewFromInt(2), true, t0.Add(6*time.Second))
+
+	now := t0.Add(8 * time.Second)
+
+	twas, ok := m.TWAS(now)
+	if assert.True(t, ok) {
+		// (1*1 + 3*3 + 2*2) / 6
+		assert.InDelta(t, 14.0/6.0, twas.Float64(), 1e-6)
+	}
+
+	// the window starts from t0+1s
+	m.Window = 7 * time.Second
+	twas, ok = m.TWAS(now)
+	if assert.True(t, ok) {
+		// (3*3 + 2*2) / 5
+		assert.InDelta(t, 13.0/5.0, twas.Float64(), 1e-6)
+	}
+
+	// only the empty side in the window
+	m.Add(fixedpoint.Zero, false, t0.Add(10*time.Second))
+	_, ok = m.TWAS(t0.Add(20 * time.Second))
+	assert.False(t, ok)
+
+	// the samples before the window are pruned
+	m.Add(fixedpoint.One, true, t0.Add(20*time.Second))
+	assert.Len(t, m.samples, 2)
+}
+
+func TestSpreadMonitor_Update(t *testing.T) {
+	t0 := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	book := NewSliceOrderBook("BTCUSDT")
+	book.Load(SliceOrderBook{
+		Bids: PriceVolumeSlice{{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.One}},
+		Asks: PriceVolumeSlice{{Price: fixedpoint.NewFromInt(102), Volume: fixedpoint.One}},
+	})
+
+	m := NewSpreadMonitor(time.Minute)
+	m.Update(book, t0)
+
+	book.Update(SliceOrderBook{
+		Asks: PriceVolumeSlice{{Price: fixedpoint.NewFromInt(102), Volume: fixedpoint.Zero}},
+	})
+	m.Update(book, t0.Add(time.Second))
+
+	twas, ok := m.TWAS(t0.Add(5 * time.Second))
+	if assert.True(t, ok) {
+		assert.Equal(t, fixedpoint.NewFromInt(2), twas)
+	}
+}
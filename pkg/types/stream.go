@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"math/rand"
 	"net"
 	"net/http"
 	"sync"
@@ -16,8 +17,14 @@ import (
 const pingInterval = 30 * time.Second
 const readTimeout = 2 * time.Minute
 const writeTimeout = 10 * time.Second
+
+// closeTimeout is how long Close waits for the server to reply the close frame before closing the connection.
+const closeTimeout = time.Second
 const reconnectCoolDownPeriod = 15 * time.Second
 
+// defaultReconnectBackoffFactor doubles the reconnection backoff after every failed attempt.
+const defaultReconnectBackoffFactor = 2.0
+
 var defaultDialer = &websocket.Dialer{
 	Proxy:            http.ProxyFromEnvironment,
 	HandshakeTimeout: 10 * time.Second,
@@ -74,8 +81,24 @@ type StandardStream struct {
 	dispatcher   Dispatcher
 	pingInterval time.Duration
 
+	// reconnectBackoff is the cool down period before the first reconnection attempt, it's multiplied by
+	// reconnectBackoffFactor on every failed attempt up to maxReconnectBackoff. The fixed reconnectCoolDownPeriod is
+	// used if it's zero.
+	reconnectBackoff       time.Duration
+	maxReconnectBackoff    time.Duration
+	reconnectBackoffFactor float64
+
+	// reconnectJitter randomizes the cool down period, see SetReconnectJitter.
+	reconnectJitter bool
+
+	// reconnectResetAfter is how long a connection must last to reset the backoff, see SetReconnectResetAfter.
+	reconnectResetAfter time.Duration
+
 	endpointCreator EndpointCreator
 
+	// compression negotiates the permessage-deflate extension on dialing, see SetCompression.
+	compression bool
+
 	// Conn is the websocket connection
 	Conn *websocket.Conn
 
@@ -102,6 +125,10 @@ type StandardStream struct {
 	// CloseC is a signal channel for closing stream
 	CloseC chan struct{}
 
+	closeOnce sync.Once
+	// waitOnClose makes Close wait until the reader is stopped, see SetWaitOnClose.
+	waitOnClose bool
+
 	Subscriptions []Subscription
 
 	// subLock is used for locking Subscriptions fields.
@@ -310,6 +337,13 @@ func (s *StandardStream) Read(ctx context.Context, conn *websocket.Conn, cancel
 				s.EmitRawMessage(message)
 			}
 
+			// the stream might be closed while reading the message, don't dispatch any event after closing.
+			select {
+			case <-s.CloseC:
+				return
+			default:
+			}
+
 			if hasDispatcher {
 				s.dispatcher(e)
 			}
@@ -321,6 +355,77 @@ func (s *StandardStream) SetPingInterval(interval time.Duration) {
 	s.pingInterval = interval
 }
 
+// SetCompression negotiates the permessage-deflate extension with the server on the next dial, the compressed frames
+// are inflated by the connection before they reach the parser. It saves the bandwidth of the high volume streams,
+// e.g. the deep order books, at the cost of the CPU. The server may decline it, the stream falls back to the
+// uncompressed frames in this case. It's disabled by default.
+func (s *StandardStream) SetCompression(enabled bool) {
+	s.compression = enabled
+}
+
+// SetReconnectBackoff sets the exponential backoff between the reconnection attempts, the cool down period starts
+// from initial and is multiplied by the factor after every failed attempt up to max. The factor less than 1 falls back
+// to defaultReconnectBackoffFactor. It's reset once the connection is re-established, see SetReconnectResetAfter.
+// The max must not be less than the initial.
+func (s *StandardStream) SetReconnectBackoff(initial, max time.Duration, factor float64) {
+	if factor < 1 {
+		factor = defaultReconnectBackoffFactor
+	}
+
+	s.reconnectBackoff = initial
+	s.maxReconnectBackoff = max
+	s.reconnectBackoffFactor = factor
+}
+
+// SetReconnectJitter enables the full jitter of the reconnection backoff, the cool down period is a random duration
+// between zero and the backoff, so the clients disconnected by the same outage don't reconnect at the same time.
+// It's disabled by default.
+func (s *StandardStream) SetReconnectJitter(enabled bool) {
+	s.reconnectJitter = enabled
+}
+
+// SetReconnectResetAfter resets the reconnection backoff only if the connection lasted at least the given duration,
+// otherwise the disconnection counts as a failed attempt, so a connection which is dropped right after connecting
+// keeps backing off. Zero resets the backoff once the connection is re-established, which is the default.
+func (s *StandardStream) SetReconnectResetAfter(d time.Duration) {
+	s.reconnectResetAfter = d
+}
+
+// reconnectCoolDown returns the cool down period before the reconnection with the number of the failed attempts.
+func (s *StandardStream) reconnectCoolDown(failedAttempts int) time.Duration {
+	if s.reconnectBackoff <= 0 {
+		return reconnectCoolDownPeriod
+	}
+
+	factor := s.reconnectBackoffFactor
+	if factor < 1 {
+		factor = defaultReconnectBackoffFactor
+	}
+
+	coolDown := float64(s.reconnectBackoff)
+	for i := 0; i < failedAttempts && coolDown < float64(s.maxReconnectBackoff); i++ {
+		coolDown *= factor
+	}
+
+	if coolDown > float64(s.maxReconnectBackoff) {
+		coolDown = float64(s.maxReconnectBackoff)
+	}
+
+	if s.reconnectJitter {
+		return time.Duration(rand.Int63n(int64(coolDown) + 1))
+	}
+	return time.Duration(coolDown)
+}
+
+// failedAttemptsAfterDisconnect returns the number of the failed attempts after a connection lasted for the given
+// duration is dropped, a flapping connection counts as a failed attempt and only a stable one resets the backoff.
+func (s *StandardStream) failedAttemptsAfterDisconnect(failedAttempts int, connectedFor time.Duration) int {
+	if connectedFor >= s.reconnectResetAfter {
+		return 0
+	}
+	return failedAttempts + 1
+}
+
 func (s *StandardStream) ping(
 	ctx context.Context, conn *websocket.Conn, cancel context.CancelFunc,
 ) {
@@ -384,6 +489,21 @@ func (s *StandardStream) Resubscribe(fn func(old []Subscription) (new []Subscrip
 	return nil
 }
 
+// UpdateSubscriptions replaces the subscriptions with the ones returned by fn like Resubscribe, but it doesn't
+// reconnect, so the caller is responsible for sending the subscription changes through the current connection.
+// This method is thread-safe.
+func (s *StandardStream) UpdateSubscriptions(fn func(old []Subscription) (new []Subscription, err error)) error {
+	s.subLock.Lock()
+	defer s.subLock.Unlock()
+
+	subs, err := fn(s.Subscriptions)
+	if err != nil {
+		return err
+	}
+	s.Subscriptions = subs
+	return nil
+}
+
 func (s *StandardStream) Subscribe(channel Channel, symbol string, options SubscribeOptions) {
 	s.subLock.Lock()
 	defer s.subLock.Unlock()
@@ -423,6 +543,9 @@ func (s *StandardStream) Connect(ctx context.Context) error {
 }
 
 func (s *StandardStream) reconnector(ctx context.Context) {
+	failedAttempts := 0
+	// connectedAt is the time the current connection was established, the first one is established by Connect.
+	connectedAt := time.Now()
 	for {
 		select {
 
@@ -433,16 +556,26 @@ func (s *StandardStream) reconnector(ctx context.Context) {
 			return
 
 		case <-s.ReconnectC:
-			log.Warnf("received reconnect signal, cooling for %s...", reconnectCoolDownPeriod)
-			time.Sleep(reconnectCoolDownPeriod)
+			if !connectedAt.IsZero() {
+				failedAttempts = s.failedAttemptsAfterDisconnect(failedAttempts, time.Since(connectedAt))
+				connectedAt = time.Time{}
+			}
+
+			coolDown := s.reconnectCoolDown(failedAttempts)
+			log.Warnf("received reconnect signal, cooling for %s...", coolDown)
+			time.Sleep(coolDown)
 
 			log.Warnf("re-connecting...")
 			if err := s.DialAndConnect(ctx); err != nil {
 				log.WithError(err).Errorf("re-connect error, try to reconnect later")
+				failedAttempts++
 
 				// re-emit the re-connect signal if error
 				s.Reconnect()
+				continue
 			}
+
+			connectedAt = time.Now()
 		}
 	}
 }
@@ -453,6 +586,14 @@ func (s *StandardStream) DialAndConnect(ctx context.Context) error {
 		return err
 	}
 
+	// the stream is closed while dialing, e.g. by the reconnector.
+	select {
+	case <-s.CloseC:
+		_ = conn.Close()
+		return errors.New("can not connect, the stream is closed")
+	default:
+	}
+
 	connCtx, connCancel := s.SetConn(ctx, conn)
 	s.EmitConnect()
 
@@ -480,7 +621,14 @@ func (s *StandardStream) Dial(ctx context.Context, args ...string) (*websocket.C
 		return nil, errors.New("can not dial, neither url nor endpoint creator is not defined, you should pass an url to Dial() or call SetEndpointCreator()")
 	}
 
-	conn, _, err := defaultDialer.Dial(url, nil)
+	dialer := defaultDialer
+	if s.compression {
+		compressionDialer := *defaultDialer
+		compressionDialer.EnableCompression = true
+		dialer = &compressionDialer
+	}
+
+	conn, _, err := dialer.Dial(url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -502,10 +650,27 @@ func (s *StandardStream) Dial(ctx context.Context, args ...string) (*websocket.C
 	return conn, nil
 }
 
-func (s *StandardStream) Close() error {
+// SetWaitOnClose makes Close wait until the reader is stopped, so the in-flight event handlers are finished and no
+// event is dispatched after Close returns. Close must not be called by the event handlers then, since it waits for
+// them. By default, Close waits for the reader up to closeTimeout.
+func (s *StandardStream) SetWaitOnClose(enabled bool) {
+	s.waitOnClose = enabled
+}
+
+// Close closes the stream gracefully. It stops the reconnection and the ping worker, writes the close frame, and waits
+// for the reader to stop up to closeTimeout, or until it's stopped if SetWaitOnClose is enabled. It's safe to call
+// Close more than once.
+func (s *StandardStream) Close() (err error) {
+	s.closeOnce.Do(func() {
+		err = s.close()
+	})
+	return err
+}
+
+func (s *StandardStream) close() error {
 	log.Debugf("[websocket] closing stream...")
 
-	// close the close signal channel, so that reader and ping worker will stop
+	// close the close signal channel, so that reader, ping worker and reconnector will stop
 	close(s.CloseC)
 
 	// get the connection object before call the context cancel function
@@ -519,17 +684,38 @@ func (s *StandardStream) Close() error {
 		connCancel()
 	}
 
-	// gracefully write the close message to the connection
-	err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-	if err != nil {
-		return errors.Wrap(err, "websocket write close message error")
+	if conn == nil {
+		return nil
 	}
 
-	log.Debugf("[websocket] stream closed")
+	// gracefully write the close message to the connection, the reader stops after the server replies the close frame.
+	var err error
+	if err2 := conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(writeTimeout)); err2 != nil {
+		err = errors.Wrap(err2, "websocket write close message error")
+	}
 
-	// let the reader close the connection
-	<-time.After(time.Second)
-	return nil
+	stopped := make(chan struct{})
+	go func() {
+		s.sg.WaitAndClear()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(closeTimeout):
+		// the server doesn't reply the close frame, or Close is called by an event handler which the reader waits for,
+		// close the connection to stop the reader.
+		_ = conn.Close()
+		if s.waitOnClose {
+			<-stopped
+		}
+	}
+
+	_ = conn.Close()
+	log.Debugf("[websocket] stream closed")
+	return err
 }
 
 // SetHeartBeat sets the custom heart beat implementation if needed
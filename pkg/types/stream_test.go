@@ -0,0 +1,225 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandardStream_reconnectCoolDown(t *testing.T) {
+	s := NewStandardStream()
+	assert.Equal(t, reconnectCoolDownPeriod, s.reconnectCoolDown(0))
+	assert.Equal(t, reconnectCoolDownPeriod, s.reconnectCoolDown(3))
+
+	s.SetReconnectBackoff(time.Second, 10*time.Second, 2)
+	assert.Equal(t, time.Second, s.reconnectCoolDown(0))
+	assert.Equal(t, 2*time.Second, s.reconnectCoolDown(1))
+	assert.Equal(t, 8*time.Second, s.reconnectCoolDown(3))
+	assert.Equal(t, 10*time.Second, s.reconnectCoolDown(4))
+	assert.Equal(t, 10*time.Second, s.reconnectCoolDown(100))
+
+	s.SetReconnectBackoff(time.Second, 10*time.Second, 1.5)
+	assert.Equal(t, 1500*time.Millisecond, s.reconnectCoolDown(1))
+	assert.Equal(t, 3375*time.Millisecond, s.reconnectCoolDown(3))
+
+	// the factor less than 1 falls back to the default
+	s.SetReconnectBackoff(time.Second, 10*time.Second, 0)
+	assert.Equal(t, 4*time.Second, s.reconnectCoolDown(2))
+
+	// the full jitter is between zero and the backoff
+	s.SetReconnectJitter(true)
+	for i := 0; i < 100; i++ {
+		coolDown := s.reconnectCoolDown(2)
+		assert.GreaterOrEqual(t, coolDown, time.Duration(0))
+		assert.LessOrEqual(t, coolDown, 4*time.Second)
+	}
+}
+
+func TestStandardStream_failedAttemptsAfterDisconnect(t *testing.T) {
+	s := NewStandardStream()
+	// the backoff is reset once the connection is re-established by default
+	assert.Equal(t, 0, s.failedAttemptsAfterDisconnect(3, 0))
+
+	s.SetReconnectResetAfter(time.Minute)
+	assert.Equal(t, 4, s.failedAttemptsAfterDisconnect(3, time.Second))
+	assert.Equal(t, 0, s.failedAttemptsAfterDisconnect(3, time.Minute))
+}
+
+func TestStandardStream_Close(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// reply the close frame with the default close handler by reading in background
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(`{}`)); err != nil {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	s := NewStandardStream()
+	s.SetWaitOnClose(true)
+	s.SetEndpointCreator(func(ctx context.Context) (string, error) {
+		return "ws" + strings.TrimPrefix(server.URL, "http"), nil
+	})
+	s.SetParser(func(message []byte) (interface{}, error) {
+		return message, nil
+	})
+
+	var dispatched int64
+	s.SetDispatcher(func(e interface{}) {
+		atomic.AddInt64(&dispatched, 1)
+	})
+
+	assert.NoError(t, s.Connect(context.Background()))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&dispatched) > 10
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, s.Close())
+	n := atomic.LoadInt64(&dispatched)
+
+	// no event is dispatched after closing
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt64(&dispatched))
+
+	// closing twice is fine
+	assert.NoError(t, s.Close())
+}
+
+func TestStandardStream_CloseInCallback(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{}`))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	s := NewStandardStream()
+	s.SetEndpointCreator(func(ctx context.Context) (string, error) {
+		return "ws" + strings.TrimPrefix(server.URL, "http"), nil
+	})
+	s.SetParser(func(message []byte) (interface{}, error) {
+		return message, nil
+	})
+
+	closed := make(chan error, 1)
+	s.SetDispatcher(func(e interface{}) {
+		// the reader calling the dispatcher can't stop before Close returns
+		closed <- s.Close()
+	})
+
+	assert.NoError(t, s.Connect(context.Background()))
+
+	select {
+	case err := <-closed:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close called by the event handler is deadlocked")
+	}
+}
+
+func TestStandardStream_CloseWithoutConnection(t *testing.T) {
+	s := NewStandardStream()
+	assert.NoError(t, s.Close())
+}
+
+func TestStandardStream_SetCompression(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("compression %v", enabled), func(t *testing.T) {
+			upgrader := websocket.Upgrader{EnableCompression: true}
+			extensions := make(chan string, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				extensions <- r.Header.Get("Sec-Websocket-Extensions")
+
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				conn.EnableWriteCompression(true)
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"topic":"orderbook.500.BTCUSDT"}`))
+				_, _, _ = conn.ReadMessage()
+			}))
+			defer server.Close()
+
+			s := NewStandardStream()
+			s.SetCompression(enabled)
+
+			conn, err := s.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+
+			if enabled {
+				assert.Contains(t, <-extensions, "permessage-deflate")
+			} else {
+				assert.Empty(t, <-extensions)
+			}
+
+			// the compressed frame is inflated by the connection
+			_, message, err := conn.ReadMessage()
+			assert.NoError(t, err)
+			assert.Equal(t, `{"topic":"orderbook.500.BTCUSDT"}`, string(message))
+		})
+	}
+}
+
+func TestStandardStream_UpdateSubscriptions(t *testing.T) {
+	s := NewStandardStream()
+	s.Subscribe(BookChannel, "BTCUSDT", SubscribeOptions{})
+
+	err := s.UpdateSubscriptions(func(old []Subscription) ([]Subscription, error) {
+		return append(old, Subscription{Channel: BookChannel, Symbol: "ETHUSDT"}), nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, s.GetSubscriptions(), 2)
+
+	// unlike Resubscribe, it doesn't reconnect
+	select {
+	case <-s.ReconnectC:
+		t.Fatal("unexpected reconnect")
+	default:
+	}
+
+	err = s.UpdateSubscriptions(func(old []Subscription) ([]Subscription, error) {
+		return nil, fmt.Errorf("failed")
+	})
+	assert.Error(t, err)
+	assert.Len(t, s.GetSubscriptions(), 2)
+}
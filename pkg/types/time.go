@@ -72,6 +72,11 @@ func (t *MillisecondTimestamp) UnmarshalJSON(data []byte) error {
 	}
 
 	switch vt := v.(type) {
+	case nil:
+		// treat null as 0, the same as the empty string
+		*t = MillisecondTimestamp(time.Time{})
+		return nil
+
 	case string:
 		if vt == "" {
 			// treat empty string as 0
@@ -75,6 +75,16 @@ func TestMillisecondTimestamp_UnmarshalJSON(t *testing.T) {
 			args: []byte("1620289117.764"),
 			t:    MillisecondTimestamp(time.Unix(0, 1620289117764*int64(time.Millisecond))),
 		},
+		{
+			name: "empty string",
+			args: []byte(`""`),
+			t:    MillisecondTimestamp(time.Time{}),
+		},
+		{
+			name: "null",
+			args: []byte("null"),
+			t:    MillisecondTimestamp(time.Time{}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
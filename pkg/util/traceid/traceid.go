@@ -0,0 +1,46 @@
+// Package traceid carries the correlation id of a strategy action in the context, so the order requests, the
+// notifications and the errors of the same decision can be correlated in the logs.
+package traceid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// LogField is the log field of the trace id.
+const LogField = "trace_id"
+
+type contextKey struct{}
+
+// New returns a random trace id.
+func New() string {
+	return uuid.NewString()
+}
+
+// NewContext returns the context carrying the trace id, the empty id is ignored.
+func NewContext(parent context.Context, id string) context.Context {
+	if len(id) == 0 {
+		return parent
+	}
+	return context.WithValue(parent, contextKey{}, id)
+}
+
+// FromContext returns the trace id of the context, it returns false if there is no trace id.
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok
+}
+
+// Logger adds the trace id of the context to the log entry, the entry is returned as is if there is no trace id.
+func Logger(ctx context.Context, entry *logrus.Entry) *logrus.Entry {
+	if id, ok := FromContext(ctx); ok {
+		return entry.WithField(LogField, id)
+	}
+	return entry
+}
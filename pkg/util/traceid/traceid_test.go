@@ -0,0 +1,27 @@
+package traceid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, NewContext(ctx, ""), ctx)
+
+	id := New()
+	assert.NotEqual(t, id, New())
+
+	id, ok = FromContext(NewContext(ctx, id))
+	assert.True(t, ok)
+	assert.NotEmpty(t, id)
+
+	entry := logrus.NewEntry(logrus.New())
+	assert.Equal(t, id, Logger(NewContext(ctx, id), entry).Data[LogField])
+	assert.NotContains(t, Logger(ctx, entry).Data, LogField)
+}